@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/config"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/notify"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/scheduler"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
+
+// reminderSender отправляет напоминания, срок которых подошел
+type reminderSender interface {
+	SendDue(ctx context.Context) (*usecase.ReminderReport, error)
+}
+
+// budgetEvaluator проверяет бюджеты и уведомляет о достигнутых порогах
+type budgetEvaluator interface {
+	EvaluateBudgets(ctx context.Context) (*usecase.BudgetReport, error)
+}
+
+// notifier каналы доставки напоминаний и уведомлений о бюджетах
+type notifier interface {
+	usecase.Notifier
+	usecase.BudgetNotifier
+}
+
+// newScheduler создает планировщик с задачами обслуживания хранилища
+// reminders и budgets nil, если возможность отключена или для нее не настроены каналы доставки
+func newScheduler(cfg *config.Config, store *storage, reminders reminderSender, budgets budgetEvaluator) (*scheduler.Scheduler, error) {
+	s := scheduler.New(store.locker, store.jobRuns, scheduler.Config{PollInterval: cfg.Scheduler.PollInterval})
+
+	jobs := []scheduler.Job{{
+		Name:        "apply_scheduled_changes",
+		Schedule:    cfg.Scheduler.ChangesSchedule,
+		Description: "Applies scheduled price, plan and cancellation changes that have taken effect",
+		Run:         applyScheduledChanges(store.subscriptions),
+	}, {
+		Name:        "expire_subscriptions",
+		Schedule:    cfg.Scheduler.ExpireSchedule,
+		Description: "Marks subscriptions whose end month has passed as expired",
+		Run:         expireSubscriptions(store.subscriptions),
+	}}
+	if store.rollups != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:        "rebuild_monthly_spend",
+			Schedule:    cfg.Scheduler.RollupSchedule,
+			Description: "Rebuilds the monthly spend rollup from subscriptions",
+			Run:         store.rollups.RebuildMonthlySpend,
+		})
+	}
+
+	if reminders != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:        "send_reminders",
+			Schedule:    cfg.Scheduler.RemindersSchedule,
+			Description: fmt.Sprintf("Sends reminders about renewals and ends of subscriptions within %d days", cfg.Reminders.DaysAhead),
+			Run:         sendReminders(reminders),
+		})
+	}
+	if budgets != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:        "evaluate_budgets",
+			Schedule:    cfg.Scheduler.BudgetsSchedule,
+			Description: "Compares projected spend with budgets and sends threshold alerts",
+			Run:         evaluateBudgets(budgets),
+		})
+	}
+
+	for _, job := range jobs {
+		if err := s.Add(job); err != nil {
+			return nil, fmt.Errorf("failed to register job: %w", err)
+		}
+	}
+
+	slog.Info("Scheduler enabled", "instance", s.Instance(), "jobs", len(jobs))
+	return s, nil
+}
+
+// expireSubscriptions помечает истекшими подписки всех организаций, последний месяц которых прошел
+func expireSubscriptions(repo domain.SubscriptionRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		expired, err := repo.ExpireEnded(ctx, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("Subscriptions expired", "count", expired)
+		return nil
+	}
+}
+
+// applyScheduledChanges применяет запланированные изменения подписок всех организаций, вступившие в силу к текущему месяцу
+func applyScheduledChanges(repo domain.SubscriptionRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		applied, err := repo.ApplyChanges(ctx, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("Scheduled changes applied", "subscriptions", applied)
+		return nil
+	}
+}
+
+// sendReminders отправляет напоминания и пишет итог в лог
+func sendReminders(reminders reminderSender) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		report, err := reminders.SendDue(ctx)
+		if report != nil {
+			logging.FromContext(ctx).Info("Reminders processed",
+				"due", report.Due, "sent", report.Sent, "skipped", report.Skipped, "failed", report.Failed)
+		}
+		return err
+	}
+}
+
+// evaluateBudgets проверяет бюджеты и пишет итог в лог
+func evaluateBudgets(budgets budgetEvaluator) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		report, err := budgets.EvaluateBudgets(ctx)
+		if report != nil {
+			logging.FromContext(ctx).Info("Budgets evaluated",
+				"budgets", report.Budgets, "sent", report.Sent, "skipped", report.Skipped, "failed", report.Failed)
+		}
+		return err
+	}
+}
+
+// newNotifier создает каналы доставки из конфигурации; nil, если ни один не настроен
+func newNotifier(cfg config.RemindersConfig) (notifier, error) {
+	var channels notify.Multi
+	if cfg.SMTP.Addr != "" {
+		smtp, err := notify.NewSMTPNotifier(notify.SMTPConfig{
+			Addr:     cfg.SMTP.Addr,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		})
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, smtp)
+	}
+	if cfg.Webhook.URL != "" {
+		channels = append(channels, notify.NewWebhookNotifier(notify.WebhookConfig{
+			URL:     cfg.Webhook.URL,
+			Secret:  cfg.Webhook.Secret,
+			Timeout: cfg.Webhook.Timeout,
+		}))
+	}
+
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	slog.Info("Notification channels configured", "email", cfg.SMTP.Addr != "", "webhook", cfg.Webhook.URL != "")
+	return channels, nil
+}
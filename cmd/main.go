@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -12,8 +17,20 @@ import (
 
 	service "github.com/asgard-born/rest_service_subscriptions"
 	_ "github.com/asgard-born/rest_service_subscriptions/docs"
+	"github.com/asgard-born/rest_service_subscriptions/migrations"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/api"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/cache"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/config"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/health"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/postgres"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/metrics"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/ratelimit"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/scheduler"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/tlsutil"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/tracing"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
 )
 
@@ -22,53 +39,251 @@ import (
 // @description REST API для управления подписками
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT или API ключ в формате "Bearer <token>"
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	// Конфигурация: значения по умолчанию < файл (-config / CONFIG_FILE) < окружение < флаги
+	cfg, args, err := config.Load(os.Args[1:], os.LookupEnv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuration error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Подкоманде migrate нужна только база, поэтому полная проверка выполняется лишь для запуска сервера
+	migrateMode := len(args) > 0 && args[0] == "migrate"
+	if len(args) > 0 && !migrateMode {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		os.Exit(2)
+	}
+	if !migrateMode {
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\nlog: %v\n", err)
+		os.Exit(2)
+	}
 
 	slog.SetDefault(logger)
 
+	// Подкоманда управления миграциями: server migrate up|down|status|force
+	if migrateMode {
+		os.Exit(runMigrate(cfg, args[1:]))
+	}
+
 	slog.Info("Starting application...")
 
-	// Инициализация базы данных
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		slog.Error("DATABASE_URL is not set")
+	// Трассировка OpenTelemetry
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		slog.Error("Failed to configure tracing", "error", err)
 		os.Exit(1)
 	}
+	tracingEnabled := cfg.Tracing.Exporter != tracing.ExporterNone
+	if tracingEnabled {
+		slog.Info("Tracing enabled", "exporter", cfg.Tracing.Exporter)
+	}
 
-	pool, err := pgxpool.New(context.Background(), dsn)
+	// Инициализация хранилища
+	// Infrastructure layer (инфраструктурный слой, реализует доменные интерфейсы)
+	store, err := openStorage(context.Background(), cfg, tracingEnabled)
 	if err != nil {
-		slog.Error("Unable to create connection pool", "error", err)
+		slog.Error("Failed to open storage", "storage", cfg.Storage, "error", err)
 		os.Exit(1)
 	}
-	defer pool.Close()
+	defer store.close()
 
-	if err := pool.Ping(context.Background()); err != nil {
-		slog.Error("Failed to ping database", "error", err)
+	// Политика доступа (сопоставление ролей и прав)
+	policy, err := loadPolicy(cfg.Auth.PolicyFile)
+	if err != nil {
+		slog.Error("Failed to load access policy", "error", err)
 		os.Exit(1)
 	}
 
-	slog.Info("Connected to Postgres (pgxpool)")
+	useCaseOpts := []usecase.Option{usecase.WithObserver(logging.NewObserver())}
 
-	// Инициализация слоев архитектуры
-	// Infrastructure layer (инфраструктурный слой, реализует доменные интерфейсы)
-	subscriptionRepo := postgres.NewSubscriptionRepository(pool)
+	// Метрики Prometheus
+	var appMetrics *metrics.Metrics
+	statsCtx, stopStats := context.WithCancel(context.Background())
+	defer stopStats()
+	if cfg.Features.Metrics {
+		appMetrics = metrics.New()
+		business := metrics.NewBusinessCollector(store.subscriptions)
+		collectors := append(store.collectors, business)
+		if err := appMetrics.Register(collectors...); err != nil {
+			slog.Error("Failed to register metrics", "error", err)
+			os.Exit(1)
+		}
+		// Показатели по всем организациям пересчитываются по таймеру, а не при каждом запросе /metrics
+		go business.Run(statsCtx, cfg.Metrics.StatsInterval)
+		useCaseOpts = append(useCaseOpts, usecase.WithObserver(appMetrics))
+	}
+	if tracingEnabled {
+		useCaseOpts = append(useCaseOpts, usecase.WithObserver(tracing.NewObserver()))
+	}
+
+	// Кэш сумм подписок поверх репозитория
+	var subscriptionRepo domain.SubscriptionRepository = store.subscriptions
+	if cfg.Features.SummaryCache {
+		subscriptionRepo = cache.NewSubscriptionRepository(subscriptionRepo, cache.NewLRU(cfg.Cache.Size, cfg.Cache.TTL))
+		slog.Info("Summary cache enabled", "size", cfg.Cache.Size, "ttl", cfg.Cache.TTL)
+	}
 
 	// UseCase layer (бизнес-логика)
-	subscriptionUseCase := usecase.NewSubscriptionUseCase(subscriptionRepo)
+	// Каталог сервисов: канонические названия подписок и цены тарифов по умолчанию
+	var serviceUseCase *usecase.ServiceUseCase
+	var catalogOpts []usecase.Option
+	if cfg.Features.Catalog && store.services != nil {
+		serviceUseCase = usecase.NewServiceUseCase(store.services, subscriptionRepo, policy, useCaseOpts...)
+		catalogOpts = append(catalogOpts, usecase.WithServiceCatalog(store.services))
+	}
+	subscriptionUseCase := usecase.NewSubscriptionUseCase(subscriptionRepo, policy, append(catalogOpts, useCaseOpts...)...)
+	tagUseCase := usecase.NewTagUseCase(store.tags, policy, useCaseOpts...)
+	organizationUseCase := usecase.NewOrganizationUseCase(store.organizations, policy, useCaseOpts...)
+
+	var apiKeyUseCase *usecase.APIKeyUseCase
+	if cfg.Features.APIKeys && store.apiKeys != nil {
+		apiKeyUseCase = usecase.NewAPIKeyUseCase(store.apiKeys, policy, useCaseOpts...)
+	}
+
+	// Каналы доставки напоминаний и уведомлений о бюджетах
+	var channels notifier
+	if cfg.Features.Reminders || cfg.Features.Budgets {
+		channels, err = newNotifier(cfg.Reminders)
+		if err != nil {
+			slog.Error("Failed to configure notification channels", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Напоминания о продлении и окончании подписок
+	var reminderUseCase *usecase.ReminderUseCase
+	var reminders reminderSender
+	if cfg.Features.Reminders && store.reminders != nil {
+		reminderUseCase = usecase.NewReminderUseCase(store.reminders, channels, cfg.Reminders.DaysAhead, policy, useCaseOpts...)
+		if channels != nil {
+			reminders = reminderUseCase
+			slog.Info("Reminders enabled", "days_ahead", cfg.Reminders.DaysAhead)
+		} else {
+			slog.Warn("No reminder channels configured (SMTP_ADDR, REMINDER_WEBHOOK_URL); reminders are not sent")
+		}
+	}
+
+	// Бюджеты и уведомления о достижении порогов; расходы считаются тем же репозиторием, что и сумма подписок
+	var budgetUseCase *usecase.BudgetUseCase
+	var budgets budgetEvaluator
+	if cfg.Features.Budgets && store.budgets != nil {
+		budgetUseCase = usecase.NewBudgetUseCase(store.budgets, subscriptionRepo, channels, policy, append(catalogOpts, useCaseOpts...)...)
+		if channels != nil {
+			budgets = budgetUseCase
+		} else {
+			slog.Warn("No notification channels configured (SMTP_ADDR, REMINDER_WEBHOOK_URL); budget alerts are not sent")
+		}
+	}
+
+	// Фоновые задачи
+	var jobScheduler *scheduler.Scheduler
+	var jobUseCase *usecase.JobUseCase
+	if cfg.Features.Scheduler {
+		jobScheduler, err = newScheduler(cfg, store, reminders, budgets)
+		if err != nil {
+			slog.Error("Failed to configure scheduler", "error", err)
+			os.Exit(1)
+		}
+		jobUseCase = usecase.NewJobUseCase(jobScheduler, store.jobRuns, policy, useCaseOpts...)
+	}
+
+	// Аутентификация
+	authenticator, err := newAuthenticator(cfg, apiKeyUseCase)
+	if err != nil {
+		slog.Error("Failed to configure authentication", "error", err)
+		os.Exit(1)
+	}
+
+	// Ограничение частоты запросов
+	rateLimiter, err := newRateLimiter(cfg)
+	if err != nil {
+		slog.Error("Failed to configure rate limiting", "error", err)
+		os.Exit(1)
+	}
+
+	// Проверки готовности зависят от хранилища
+	probes := health.New(cfg.Health.ReadinessTimeout, store.checks...)
 
 	// API layer (хэндлеры и роутер)
-	router := api.CreateNewRouter(subscriptionUseCase)
+	routerOpts := api.RouterOptions{
+		Authenticator:  authenticator,
+		RateLimiter:    rateLimiter,
+		TrustedProxies: cfg.Server.TrustedProxyList(),
+		Metrics:        appMetrics,
+		Health:         probes,
+		Tracing:        tracingEnabled,
+		Swagger:        cfg.Features.Swagger,
+		TagUseCase:     tagUseCase,
+		// Запросы к организациям, которых нет в списке, отклоняются до обращения к данным
+		OrganizationUseCase: organizationUseCase,
+	}
+	if apiKeyUseCase != nil {
+		routerOpts.APIKeyUseCase = apiKeyUseCase
+	}
+	if jobUseCase != nil {
+		routerOpts.JobUseCase = jobUseCase
+	}
+	if reminderUseCase != nil {
+		routerOpts.ReminderUseCase = reminderUseCase
+	}
+	if budgetUseCase != nil {
+		routerOpts.BudgetUseCase = budgetUseCase
+	}
+	if serviceUseCase != nil {
+		routerOpts.ServiceUseCase = serviceUseCase
+	}
+	router := api.CreateNewRouter(subscriptionUseCase, routerOpts)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &service.Server{
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
-	slog.Info("HTTP server configured", "port", port)
+	if cfg.TLS.Enabled() {
+		certs, err := tlsutil.NewReloader(tlsutil.Config{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			ClientCAFile: cfg.TLS.ClientCAFile,
+			ClientAuth:   cfg.TLS.ClientAuth,
+		})
+		if err != nil {
+			slog.Error("Failed to load TLS certificates", "error", err)
+			os.Exit(1)
+		}
+		srv.TLSConfig = certs.TLSConfig()
+		go reloadCertificatesOnSIGHUP(certs)
+	}
+
+	port := strconv.Itoa(cfg.Server.Port)
+	slog.Info("HTTP server configured", "port", port, "tls", cfg.TLS.Enabled(), "mtls", cfg.TLS.MutualTLS())
 
-	srv := new(service.Server)
+	if jobScheduler != nil {
+		jobScheduler.Start()
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -80,16 +295,225 @@ func main() {
 		}
 	}()
 
+	// Метрики содержат расходы всех организаций, поэтому отдаются на внутреннем порту, а не рядом с публичным API
+	var metricsSrv *service.Server
+	if appMetrics != nil {
+		metricsSrv = &service.Server{IdleTimeout: cfg.Server.IdleTimeout}
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", appMetrics.Handler())
+		metricsPort := strconv.Itoa(cfg.Server.MetricsPort)
+		slog.Info("Metrics server configured", "port", metricsPort)
+
+		go func() {
+			if err := metricsSrv.Run(metricsPort, mux); err != nil {
+				slog.Error("Error occurred while running metrics server", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	<-quit
 	slog.Warn("Shutdown signal received")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// Проверка готовности начинает отвечать ошибкой до закрытия соединений,
+	// чтобы балансировщик успел убрать экземпляр из ротации
+	probes.SetShuttingDown()
+	if cfg.Server.DrainDelay > 0 {
+		slog.Info("Waiting before shutdown", "delay", cfg.Server.DrainDelay)
+		time.Sleep(cfg.Server.DrainDelay)
+	}
 
-	if err := srv.Shutdown(ctx); err != nil {
+	stopStats()
+
+	// У каждого шага свой таймаут: долгий дренаж HTTP не должен отнимать время у задач и отправки трасс
+	exitCode := 0
+	if err := shutdownWithin(cfg.Server.ShutdownTimeout, srv.Shutdown); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
-		os.Exit(1)
+		exitCode = 1
+	}
+	if metricsSrv != nil {
+		if err := shutdownWithin(cfg.Server.ShutdownTimeout, metricsSrv.Shutdown); err != nil {
+			slog.Error("Metrics server forced to shutdown", "error", err)
+		}
 	}
 
+	// Задачи останавливаются после HTTP сервера, чтобы во время ожидания не начались новые ручные запуски
+	if jobScheduler != nil {
+		if err := shutdownWithin(cfg.Scheduler.StopTimeout, jobScheduler.Stop); err != nil {
+			slog.Error("Scheduler forced to stop", "error", err)
+		}
+	}
+
+	if err := shutdownWithin(cfg.Tracing.FlushTimeout, shutdownTracing); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 	slog.Info("Server exited properly")
 }
+
+// shutdownWithin выполняет шаг остановки с собственным таймаутом
+func shutdownWithin(timeout time.Duration, stop func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return stop(ctx)
+}
+
+// newPoolConfig создает конфигурацию пула соединений; нулевые значения оставляют настройки pgxpool по умолчанию
+func newPoolConfig(cfg config.DatabaseConfig) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database url: %w", err)
+	}
+
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = int32(cfg.MaxConns)
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = int32(cfg.MinConns)
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	// Политики row-level security закрыты по умолчанию; без нее соединения их пропускают,
+	// и организации разделяют условия запросов
+	if !cfg.RowLevelSecurity {
+		postgres.BypassRowLevelSecurity(poolCfg)
+	}
+
+	return poolCfg, nil
+}
+
+// newAuthenticator создает проверку JWT, API ключей и клиентских сертификатов
+// Запуск без аутентификации возможен только при явном auth.disabled (AUTH_DISABLED=true)
+func newAuthenticator(cfg *config.Config, apiKeys *usecase.APIKeyUseCase) (auth.Authenticator, error) {
+	jwtCfg := auth.JWTConfig{
+		HS256Secret:        cfg.Auth.HS256Secret,
+		RS256PublicKeyFile: cfg.Auth.RS256PublicKeyFile,
+		JWKSFile:           cfg.Auth.JWKSFile,
+		Issuer:             cfg.Auth.Issuer,
+		Audience:           cfg.Auth.Audience,
+	}
+
+	composite := &auth.CompositeAuthenticator{}
+	if apiKeys != nil {
+		composite.APIKeys = apiKeys
+	}
+
+	switch {
+	case jwtCfg.Enabled():
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(jwtCfg)
+		if err != nil {
+			return nil, err
+		}
+		composite.JWT = jwtAuthenticator
+
+	case cfg.TLS.MutualTLS():
+		// Только клиентские сертификаты (и API ключи); JWT отклоняются
+
+	case composite.APIKeys != nil:
+		// Только API ключи для вызовов между сервисами; JWT отклоняются
+
+	case cfg.Auth.Disabled:
+		slog.Warn("Authentication is disabled, every caller has full access")
+		return nil, nil
+
+	default:
+		return nil, errors.New("no authentication configured: set JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY_FILE or JWT_JWKS_FILE, enable mTLS or API keys (or AUTH_DISABLED=true)")
+	}
+
+	slog.Info("Authentication enabled",
+		"jwt", composite.JWT != nil,
+		"api_keys", composite.APIKeys != nil,
+		"client_certificates", cfg.TLS.MutualTLS(),
+	)
+
+	return composite, nil
+}
+
+// reloadCertificatesOnSIGHUP перечитывает сертификаты TLS при получении SIGHUP
+// Если новые файлы некорректны, сервер продолжает работать со старыми
+func reloadCertificatesOnSIGHUP(certs *tlsutil.Reloader) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		if err := certs.Reload(); err != nil {
+			slog.Error("Failed to reload TLS certificates, keeping the previous ones", "error", err)
+			continue
+		}
+		slog.Info("TLS certificates reloaded")
+	}
+}
+
+// loadPolicy загружает политику доступа из файла или возвращает политику по умолчанию
+func loadPolicy(path string) (*auth.Policy, error) {
+	if path == "" {
+		return auth.DefaultPolicy(), nil
+	}
+
+	policy, err := auth.LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Access policy loaded", "path", path)
+
+	return policy, nil
+}
+
+// newRateLimiter настраивает квоты из конфигурации
+// По умолчанию самый дорогой запрос (сумма подписок) ограничен строже остальных
+func newRateLimiter(cfg *config.Config) (*ratelimit.Limiter, error) {
+	if !cfg.Features.RateLimit {
+		slog.Warn("Rate limiting is disabled")
+		return nil, nil
+	}
+
+	defaultLimit, err := ratelimit.ParseLimit(cfg.RateLimit.Default)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := ratelimit.ParseRoutes(cfg.RateLimit.Routes)
+	if err != nil {
+		return nil, err
+	}
+	perIP, err := ratelimit.ParseLimit(cfg.RateLimit.PerIP)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Rate limiting enabled", "default", cfg.RateLimit.Default, "routes", cfg.RateLimit.Routes, "per_ip", cfg.RateLimit.PerIP)
+
+	return ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{
+		Default: defaultLimit,
+		Routes:  routes,
+		PerIP:   perIP,
+	}), nil
+}
+
+// migrateOnStart применяет встроенные миграции перед запуском сервера
+// Экземпляры, стартующие одновременно, ждут advisory lock не дольше migrations.lock_timeout
+func migrateOnStart(pool *pgxpool.Pool, cfg config.MigrationsConfig) error {
+	migrator, err := postgres.NewMigrator(pool, migrations.FS, cfg.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if err := migrator.Up(); err != nil {
+		return err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	slog.Info("Database schema is up to date", "version", status.Version)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/asgard-born/rest_service_subscriptions/migrations"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/config"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/postgres"
+)
+
+const migrateUsage = `usage: server [flags] migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    roll back the last N migrations (default 1)
+  status      print the applied and the latest migration version
+  force V     set the schema version to V and clear the dirty flag without running migrations`
+
+// runMigrate выполняет подкоманду migrate и возвращает код завершения процесса
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if cfg.Database.URL == "" {
+		fmt.Fprintln(os.Stderr, "database.url is not set (DATABASE_URL)")
+		return 1
+	}
+
+	pool, err := pgxpool.New(context.Background(), cfg.Database.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create connection pool: %v\n", err)
+		return 1
+	}
+	defer pool.Close()
+
+	migrator, err := postgres.NewMigrator(pool, migrations.FS, cfg.Migrations.LockTimeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer migrator.Close()
+
+	if err := migrateCommand(migrator, args[0], args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, errMigrateUsage) {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		return 1
+	}
+
+	return 0
+}
+
+var errMigrateUsage = errors.New("invalid migrate command")
+
+func migrateCommand(migrator *postgres.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("%w: down expects a positive number of steps, got %q", errMigrateUsage, args[0])
+			}
+			steps = n
+		}
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+
+	case "force":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: force expects a version", errMigrateUsage)
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return fmt.Errorf("%w: invalid version %q", errMigrateUsage, args[0])
+		}
+		if err := migrator.Force(version); err != nil {
+			return err
+		}
+
+	case "status":
+
+	default:
+		return fmt.Errorf("%w: unknown command %q", errMigrateUsage, command)
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", status.Version, status.Dirty, status.Latest)
+	if status.Pending() {
+		fmt.Printf("pending: %d\n", status.Latest-status.Version)
+	}
+
+	return nil
+}
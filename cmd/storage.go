@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/multitracer"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/asgard-born/rest_service_subscriptions/migrations"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/config"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/health"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/postgres"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/sqlite"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/metrics"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/tracing"
+)
+
+// subscriptionStore репозиторий подписок, который также отдает показатели для метрик
+type subscriptionStore interface {
+	domain.SubscriptionRepository
+	metrics.StatsSource
+}
+
+// rollupRebuilder пересчитывает агрегаты сумм; поддерживается только PostgreSQL
+type rollupRebuilder interface {
+	RebuildMonthlySpend(ctx context.Context) error
+}
+
+// storage репозитории выбранного хранилища и связанные с ним проверки готовности и метрики
+type storage struct {
+	subscriptions subscriptionStore
+	// apiKeys nil, если хранилище не поддерживает API ключи
+	apiKeys domain.APIKeyRepository
+	// locker и jobRuns нужны планировщику фоновых задач
+	locker  domain.Locker
+	jobRuns domain.JobRunRepository
+	// reminders nil, если хранилище не поддерживает напоминания
+	reminders domain.ReminderRepository
+	// budgets nil, если хранилище не поддерживает бюджеты
+	budgets domain.BudgetRepository
+	// services nil, если хранилище не поддерживает каталог сервисов
+	services domain.ServiceRepository
+	tags     domain.TagRepository
+	// organizations список организаций, к которым допускаются запросы
+	organizations domain.OrganizationRepository
+	// rollups nil, если хранилище не ведет агрегаты сумм
+	rollups    rollupRebuilder
+	checks     []health.Check
+	collectors []prometheus.Collector
+	close      func()
+}
+
+// openStorage подключает хранилище, выбранное в cfg.Storage
+func openStorage(ctx context.Context, cfg *config.Config, tracingEnabled bool) (*storage, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		slog.Warn("Using in-memory storage, data is lost on restart; API keys are not available")
+		subs := memory.NewSubscriptionRepository()
+		return &storage{
+			subscriptions: subs,
+			reminders:     memory.NewReminderRepository(subs),
+			budgets:       memory.NewBudgetRepository(),
+			services:      memory.NewServiceRepository(subs),
+			tags:          memory.NewTagRepository(subs),
+			organizations: memory.NewOrganizationRepository(),
+			locker:        memory.NewLocker(),
+			jobRuns:       memory.NewJobRunRepository(),
+			close:         func() {},
+		}, nil
+	case config.StorageSQLite:
+		return openSQLite(cfg.SQLite)
+	}
+
+	pool, err := connectPostgres(ctx, cfg.Database, tracingEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	// Применение миграций при запуске
+	if cfg.Migrations.OnStart {
+		if err := migrateOnStart(pool, cfg.Migrations); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	schemaVersion, err := migrations.LatestVersion(migrations.FS)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	var repoOpts []postgres.Option
+	if cfg.Database.RowLevelSecurity {
+		repoOpts = append(repoOpts, postgres.WithRowLevelSecurity())
+		slog.Info("Row-level security for organizations enabled")
+	}
+
+	st := &storage{
+		apiKeys: postgres.NewAPIKeyRepository(pool, cfg.Database.RowLevelSecurity),
+		locker:  postgres.NewAdvisoryLocker(pool),
+		jobRuns: postgres.NewJobRunRepository(pool),
+		// Напоминания и проверка бюджетов читают данные всех организаций, поэтому всегда работают с основной базой
+		reminders:     postgres.NewReminderRepository(pool, cfg.Database.RowLevelSecurity),
+		budgets:       postgres.NewBudgetRepository(pool, cfg.Database.RowLevelSecurity),
+		services:      postgres.NewServiceRepository(pool, cfg.Database.RowLevelSecurity),
+		tags:          postgres.NewTagRepository(pool, cfg.Database.RowLevelSecurity),
+		organizations: postgres.NewOrganizationRepository(pool),
+		// Проверки готовности: доступность базы и версия схемы
+		checks: []health.Check{
+			{Name: "postgres", Fn: postgres.PingCheck(pool)},
+			{Name: "migrations", Fn: postgres.MigrationCheck(pool, schemaVersion)},
+		},
+		collectors: []prometheus.Collector{metrics.NewPoolCollector(pool, "primary")},
+		close:      pool.Close,
+	}
+
+	if cfg.Database.SummaryRollup {
+		repoOpts = append(repoOpts, postgres.WithMonthlyRollup())
+	}
+
+	// Реплика для чтения: миграции к ней не применяются, схема приходит репликацией
+	if cfg.Database.ReplicaURL != "" {
+		replicaCfg := cfg.Database
+		replicaCfg.URL = cfg.Database.ReplicaURL
+		replica, err := connectPostgres(ctx, replicaCfg, tracingEnabled)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+
+		repoOpts = append(repoOpts, postgres.WithReadReplica(replica))
+		if cfg.Database.ReadYourWrites {
+			repoOpts = append(repoOpts, postgres.WithReadYourWrites())
+		}
+		st.checks = append(st.checks, health.Check{Name: "postgres_replica", Fn: postgres.PingCheck(replica)})
+		st.collectors = append(st.collectors, metrics.NewPoolCollector(replica, "replica"))
+		st.close = func() {
+			replica.Close()
+			pool.Close()
+		}
+		slog.Info("Read replica enabled", "read_your_writes", cfg.Database.ReadYourWrites)
+	}
+
+	repo := postgres.NewSubscriptionRepository(pool, repoOpts...)
+	st.subscriptions = repo
+	if cfg.Database.SummaryRollup {
+		st.rollups = repo
+	}
+	return st, nil
+}
+
+// connectPostgres создает пул соединений по cfg.URL и проверяет подключение
+func connectPostgres(ctx context.Context, cfg config.DatabaseConfig, tracingEnabled bool) (*pgxpool.Pool, error) {
+	poolCfg, err := newPoolConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	poolCfg.ConnConfig.Tracer = postgres.NewQueryLogger()
+	if tracingEnabled {
+		poolCfg.ConnConfig.Tracer = multitracer.New(tracing.NewPgxTracer(), poolCfg.ConnConfig.Tracer)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	slog.Info("Connected to Postgres (pgxpool)", "host", poolCfg.ConnConfig.Host, "max_conns", poolCfg.MaxConns)
+	return pool, nil
+}
+
+// openSQLite открывает файл SQLite и применяет к нему встроенные миграции
+func openSQLite(cfg config.SQLiteConfig) (*storage, error) {
+	db, err := sqlite.Open(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := sqlite.Migrate(db, migrations.SQLiteFS)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	slog.Warn("Using SQLite storage; API keys, reminders, budgets and the service catalog are not available", "path", cfg.Path, "schema_version", version)
+
+	// Один файл обслуживает один процесс, поэтому блокировки и история задач хранятся в памяти
+
+	return &storage{
+		subscriptions: sqlite.NewSubscriptionRepository(db),
+		tags:          sqlite.NewTagRepository(db),
+		organizations: sqlite.NewOrganizationRepository(db),
+		locker:        memory.NewLocker(),
+		jobRuns:       memory.NewJobRunRepository(),
+		checks:        []health.Check{{Name: "sqlite", Fn: sqlite.PingCheck(db)}},
+		collectors:    []prometheus.Collector{collectors.NewDBStatsCollector(db, "subscriptions")},
+		close:         func() { _ = db.Close() },
+	}, nil
+}
@@ -0,0 +1,13 @@
+DROP POLICY IF EXISTS api_keys_tenant_isolation ON api_keys;
+ALTER TABLE api_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE api_keys DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS subscriptions_tenant_isolation ON subscriptions;
+ALTER TABLE subscriptions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS organization_id;
+DROP INDEX IF EXISTS subscriptions_organization_user_idx;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Организация по умолчанию для существующих данных и запросов без указания организации
+INSERT INTO organizations (id, name)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE subscriptions
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES organizations (id);
+ALTER TABLE subscriptions
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS subscriptions_organization_user_idx ON subscriptions (organization_id, user_id);
+
+ALTER TABLE api_keys
+    ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES organizations (id);
+ALTER TABLE api_keys
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+-- Row-level security как второй уровень изоляции организаций.
+-- Приложение выставляет app.organization_id в транзакции запроса; без него (миграции, фоновые задачи) доступны все строки
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY subscriptions_tenant_isolation ON subscriptions
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
+
+-- API ключи ищутся по хешу до того, как известна организация, поэтому такой запрос идет без app.organization_id
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY api_keys_tenant_isolation ON api_keys
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+DROP FUNCTION IF EXISTS subscriptions_monthly_spend();
+DROP FUNCTION IF EXISTS rebuild_monthly_spend();
+DROP FUNCTION IF EXISTS monthly_spend_add(UUID, UUID, TEXT, DATE, DATE, BIGINT);
+DROP TABLE IF EXISTS monthly_spend;
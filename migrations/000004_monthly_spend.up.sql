@@ -0,0 +1,90 @@
+-- Агрегат расходов по месяцам для быстрого подсчета суммы.
+-- Хранит изменение ежемесячного расхода: подписка добавляет +price в месяц начала и -price в месяц после окончания,
+-- поэтому расход за месяц M равен сумме delta по месяцам <= M, а подписки без даты окончания занимают одну строку
+CREATE TABLE IF NOT EXISTS monthly_spend
+(
+    organization_id UUID NOT NULL REFERENCES organizations (id),
+    month DATE NOT NULL,
+    user_id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    delta BIGINT NOT NULL,
+    PRIMARY KEY (organization_id, month, user_id, service_name)
+);
+
+-- monthly_spend_add прибавляет price к расходу с месяца start_date по месяц end_date включительно
+CREATE OR REPLACE FUNCTION monthly_spend_add(org UUID, uid UUID, svc TEXT, start_date DATE, end_date DATE, price BIGINT)
+    RETURNS void AS
+$$
+DECLARE
+    change RECORD;
+BEGIN
+    FOR change IN
+        SELECT date_trunc('month', start_date)::date AS month, price AS delta
+        UNION ALL
+        SELECT (date_trunc('month', end_date) + INTERVAL '1 month')::date, -price
+        WHERE end_date IS NOT NULL
+    LOOP
+        INSERT INTO monthly_spend AS ms (organization_id, month, user_id, service_name, delta)
+        VALUES (org, change.month, uid, svc, change.delta)
+        ON CONFLICT (organization_id, month, user_id, service_name)
+            DO UPDATE SET delta = ms.delta + EXCLUDED.delta;
+
+        DELETE FROM monthly_spend
+        WHERE organization_id = org AND month = change.month AND user_id = uid AND service_name = svc AND delta = 0;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Инкрементальное обновление агрегата в той же транзакции, что и запись подписки
+CREATE OR REPLACE FUNCTION subscriptions_monthly_spend()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM monthly_spend_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date, -OLD.price);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM monthly_spend_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date, NEW.price);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR UPDATE OR DELETE
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+-- rebuild_monthly_spend пересчитывает агрегат по всем подпискам.
+-- Блокирует запись подписок до конца транзакции, чтобы пересчет не разошелся с триггером
+CREATE OR REPLACE FUNCTION rebuild_monthly_spend()
+    RETURNS void AS
+$$
+BEGIN
+    LOCK TABLE subscriptions IN SHARE MODE;
+    DELETE FROM monthly_spend;
+
+    INSERT INTO monthly_spend (organization_id, month, user_id, service_name, delta)
+    SELECT organization_id, month, user_id, service_name, SUM(delta)
+    FROM (SELECT organization_id, date_trunc('month', start_date)::date AS month, user_id, service_name, price::bigint AS delta
+          FROM subscriptions
+          UNION ALL
+          SELECT organization_id, (date_trunc('month', end_date) + INTERVAL '1 month')::date, user_id, service_name, -price::bigint
+          FROM subscriptions
+          WHERE end_date IS NOT NULL) changes
+    GROUP BY organization_id, month, user_id, service_name
+    HAVING SUM(delta) <> 0;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT rebuild_monthly_spend();
+
+ALTER TABLE monthly_spend ENABLE ROW LEVEL SECURITY;
+ALTER TABLE monthly_spend FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY monthly_spend_tenant_isolation ON monthly_spend
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
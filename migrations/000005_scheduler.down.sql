@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS job_runs;
+
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR UPDATE OR DELETE
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS status;
@@ -0,0 +1,31 @@
+ALTER TABLE subscriptions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'expired'));
+
+UPDATE subscriptions
+SET status = 'expired'
+WHERE end_date < date_trunc('month', now())::date;
+
+-- Смена состояния не влияет на расходы, поэтому агрегат пересчитывается только при изменении полей, от которых он зависит.
+-- В списке должны быть все столбцы, которые читает subscriptions_monthly_spend(): миграция, научившая функцию
+-- читать новый столбец, пересоздает триггер с ним, иначе обновление только этого столбца не попадет в агрегат
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR DELETE OR UPDATE OF organization_id, user_id, service_name, price, start_date, end_date
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+-- История запусков фоновых задач, общая для всех организаций
+CREATE TABLE IF NOT EXISTS job_runs
+(
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job         TEXT        NOT NULL,
+    trigger     TEXT        NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    status      TEXT        NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    error       TEXT        NOT NULL DEFAULT '',
+    instance    TEXT        NOT NULL,
+    started_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_started_idx ON job_runs (job, started_at DESC);
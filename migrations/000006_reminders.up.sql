@@ -0,0 +1,41 @@
+-- Настройки напоминаний пользователя; пользователь без строки получает все напоминания
+CREATE TABLE IF NOT EXISTS reminder_preferences
+(
+    organization_id UUID        NOT NULL REFERENCES organizations (id),
+    user_id         UUID        NOT NULL,
+    email           TEXT        NOT NULL DEFAULT '',
+    renewal         BOOLEAN     NOT NULL DEFAULT true,
+    expiry          BOOLEAN     NOT NULL DEFAULT true,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+-- Отправленные напоминания: каждое отправляется не больше одного раза
+CREATE TABLE IF NOT EXISTS sent_reminders
+(
+    subscription_id UUID        NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    kind            TEXT        NOT NULL CHECK (kind IN ('renewal', 'expiry')),
+    due_date        DATE        NOT NULL,
+    organization_id UUID        NOT NULL,
+    sent_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (subscription_id, kind, due_date)
+);
+
+-- Row-level security, как у subscriptions (000003): с app.organization_id видны только строки организации
+ALTER TABLE reminder_preferences ENABLE ROW LEVEL SECURITY;
+ALTER TABLE reminder_preferences FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY reminder_preferences_tenant_isolation ON reminder_preferences
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
+
+ALTER TABLE sent_reminders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sent_reminders FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY sent_reminders_tenant_isolation ON sent_reminders
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
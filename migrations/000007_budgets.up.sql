@@ -0,0 +1,48 @@
+-- Бюджеты расходов на подписки; пустые user_id и service_name означают бюджет на всю организацию
+CREATE TABLE IF NOT EXISTS budgets
+(
+    id              UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    organization_id UUID        NOT NULL REFERENCES organizations (id),
+    name            TEXT        NOT NULL,
+    period          TEXT        NOT NULL CHECK (period IN ('monthly', 'yearly')),
+    limit_amount    BIGINT      NOT NULL CHECK (limit_amount > 0),
+    user_id         UUID,
+    service_name    TEXT,
+    thresholds      INTEGER[]   NOT NULL,
+    email           TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (cardinality(thresholds) > 0 AND 0 < ALL (thresholds))
+);
+
+CREATE INDEX IF NOT EXISTS budgets_organization_user_idx ON budgets (organization_id, user_id);
+
+-- Отправленные уведомления о порогах: каждый порог уведомляется один раз за период
+CREATE TABLE IF NOT EXISTS budget_alerts
+(
+    budget_id       UUID        NOT NULL REFERENCES budgets (id) ON DELETE CASCADE,
+    period_start    DATE        NOT NULL,
+    threshold       INTEGER     NOT NULL,
+    organization_id UUID        NOT NULL,
+    sent_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (budget_id, period_start, threshold)
+);
+
+-- Row-level security, как у subscriptions (000003): с app.organization_id видны только строки организации
+ALTER TABLE budgets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE budgets FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY budgets_tenant_isolation ON budgets
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
+
+ALTER TABLE budget_alerts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE budget_alerts FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY budget_alerts_tenant_isolation ON budget_alerts
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
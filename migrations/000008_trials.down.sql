@@ -0,0 +1,51 @@
+CREATE OR REPLACE FUNCTION subscriptions_monthly_spend()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM monthly_spend_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date, -OLD.price);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM monthly_spend_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date, NEW.price);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rebuild_monthly_spend()
+    RETURNS void AS
+$$
+BEGIN
+    LOCK TABLE subscriptions IN SHARE MODE;
+    DELETE FROM monthly_spend;
+
+    INSERT INTO monthly_spend (organization_id, month, user_id, service_name, delta)
+    SELECT organization_id, month, user_id, service_name, SUM(delta)
+    FROM (SELECT organization_id, date_trunc('month', start_date)::date AS month, user_id, service_name, price::bigint AS delta
+          FROM subscriptions
+          UNION ALL
+          SELECT organization_id, (date_trunc('month', end_date) + INTERVAL '1 month')::date, user_id, service_name, -price::bigint
+          FROM subscriptions
+          WHERE end_date IS NOT NULL) changes
+    GROUP BY organization_id, month, user_id, service_name
+    HAVING SUM(delta) <> 0;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS subscription_spend_add(UUID, UUID, TEXT, DATE, DATE, BIGINT, INTEGER, BIGINT, INTEGER);
+DROP FUNCTION IF EXISTS subscription_cost(DATE, DATE, BIGINT, INTEGER, BIGINT, INTEGER, DATE, DATE);
+DROP FUNCTION IF EXISTS month_index(DATE);
+
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR DELETE OR UPDATE OF organization_id, user_id, service_name, price, start_date, end_date
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS trial_months,
+    DROP COLUMN IF EXISTS intro_price,
+    DROP COLUMN IF EXISTS intro_months;
+
+SELECT rebuild_monthly_spend();
@@ -0,0 +1,86 @@
+-- Пробный период и вводная цена: первые trial_months месяцев бесплатны,
+-- следующие intro_months месяцев стоят intro_price, затем price
+ALTER TABLE subscriptions
+    ADD COLUMN trial_months INTEGER NOT NULL DEFAULT 0 CHECK (trial_months BETWEEN 0 AND 120),
+    ADD COLUMN intro_price  INTEGER NOT NULL DEFAULT 0 CHECK (intro_price >= 0),
+    ADD COLUMN intro_months INTEGER NOT NULL DEFAULT 0 CHECK (intro_months BETWEEN 0 AND 120);
+
+-- month_index порядковый номер месяца: year*12 + month
+CREATE OR REPLACE FUNCTION month_index(d DATE)
+    RETURNS INTEGER AS
+$$
+SELECT (EXTRACT(YEAR FROM d) * 12 + EXTRACT(MONTH FROM d))::int
+$$ LANGUAGE sql IMMUTABLE;
+
+-- subscription_cost стоимость подписки за месяцы периода [period_start, period_end], включая крайние;
+-- повторяет domain.Subscription.Cost
+CREATE OR REPLACE FUNCTION subscription_cost(start_date DATE, end_date DATE, price BIGINT, trial_months INTEGER,
+                                             intro_price BIGINT, intro_months INTEGER,
+                                             period_start DATE, period_end DATE)
+    RETURNS BIGINT AS
+$$
+SELECT intro_price * GREATEST(0, LEAST(m.regular_from - 1, m.to_month) - GREATEST(m.intro_from, m.from_month) + 1) +
+       price * GREATEST(0, m.to_month - GREATEST(m.regular_from, m.from_month) + 1)
+FROM (SELECT month_index(start_date) + trial_months                                        AS intro_from,
+             month_index(start_date) + trial_months + intro_months                         AS regular_from,
+             month_index(period_start)                                                     AS from_month,
+             LEAST(month_index(COALESCE(end_date, period_end)), month_index(period_end)) AS to_month) m
+$$ LANGUAGE sql IMMUTABLE;
+
+-- subscription_spend_add прибавляет к агрегату monthly_spend расход подписки по месяцам:
+-- intro_price за вводный период и price после него; пробные месяцы расхода не дают
+CREATE OR REPLACE FUNCTION subscription_spend_add(org UUID, uid UUID, svc TEXT, start_date DATE, end_date DATE, price BIGINT,
+                                                  trial_months INTEGER, intro_price BIGINT, intro_months INTEGER)
+    RETURNS void AS
+$$
+DECLARE
+    intro_start   DATE := (date_trunc('month', start_date) + make_interval(months => trial_months))::date;
+    regular_start DATE := (date_trunc('month', start_date) + make_interval(months => trial_months + intro_months))::date;
+BEGIN
+    IF intro_months > 0 AND (end_date IS NULL OR intro_start <= end_date) THEN
+        PERFORM monthly_spend_add(org, uid, svc, intro_start,
+                                  LEAST(end_date, (regular_start - INTERVAL '1 month')::date), intro_price);
+    END IF;
+    IF end_date IS NULL OR regular_start <= end_date THEN
+        PERFORM monthly_spend_add(org, uid, svc, regular_start, end_date, price);
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION subscriptions_monthly_spend()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM subscription_spend_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date,
+                                       -OLD.price, OLD.trial_months, -OLD.intro_price, OLD.intro_months);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM subscription_spend_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date,
+                                       NEW.price, NEW.trial_months, NEW.intro_price, NEW.intro_months);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Функция читает новые столбцы, поэтому триггер пересоздается с ними
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR DELETE OR UPDATE OF organization_id, user_id, service_name, price, start_date, end_date, trial_months, intro_price, intro_months
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+-- Пересчет по подпискам построчно: разбиение на периоды цены выполняет subscription_spend_add
+CREATE OR REPLACE FUNCTION rebuild_monthly_spend()
+    RETURNS void AS
+$$
+BEGIN
+    LOCK TABLE subscriptions IN SHARE MODE;
+    DELETE FROM monthly_spend;
+
+    PERFORM subscription_spend_add(organization_id, user_id, service_name, start_date, end_date,
+                                   price, trial_months, intro_price, intro_months)
+    FROM subscriptions;
+END;
+$$ LANGUAGE plpgsql;
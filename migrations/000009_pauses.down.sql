@@ -0,0 +1,44 @@
+CREATE OR REPLACE FUNCTION subscriptions_monthly_spend()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM subscription_spend_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date,
+                                       -OLD.price, OLD.trial_months, -OLD.intro_price, OLD.intro_months);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM subscription_spend_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date,
+                                       NEW.price, NEW.trial_months, NEW.intro_price, NEW.intro_months);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rebuild_monthly_spend()
+    RETURNS void AS
+$$
+BEGIN
+    LOCK TABLE subscriptions IN SHARE MODE;
+    DELETE FROM monthly_spend;
+
+    PERFORM subscription_spend_add(organization_id, user_id, service_name, start_date, end_date,
+                                   price, trial_months, intro_price, intro_months)
+    FROM subscriptions;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS subscription_pauses_add(UUID, UUID, TEXT, DATE, DATE, BIGINT, INTEGER, BIGINT, INTEGER, JSONB);
+DROP FUNCTION IF EXISTS subscription_range_add(UUID, UUID, TEXT, DATE, DATE, DATE, BIGINT, INTEGER, BIGINT, INTEGER);
+DROP FUNCTION IF EXISTS subscription_paused_cost(DATE, DATE, BIGINT, INTEGER, BIGINT, INTEGER, JSONB, DATE, DATE);
+DROP FUNCTION IF EXISTS subscription_paused_at(JSONB, DATE);
+
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR DELETE OR UPDATE OF organization_id, user_id, service_name, price, start_date, end_date, trial_months, intro_price, intro_months
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS pauses;
+
+SELECT rebuild_monthly_spend();
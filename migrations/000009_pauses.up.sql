@@ -0,0 +1,111 @@
+-- Приостановки оплаты: массив {"start_month": "YYYY-MM-01", "end_month": "YYYY-MM-01" | null} в порядке начала.
+-- Хранятся в строке подписки, чтобы триггер агрегата видел их вместе с остальными полями
+ALTER TABLE subscriptions
+    ADD COLUMN pauses JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(pauses) = 'array');
+
+-- subscription_paused_at проверяет, приходится ли месяц month на приостановку
+CREATE OR REPLACE FUNCTION subscription_paused_at(pauses JSONB, month DATE)
+    RETURNS BOOLEAN AS
+$$
+SELECT EXISTS (SELECT 1
+               FROM jsonb_to_recordset(pauses) AS p(start_month DATE, end_month DATE)
+               WHERE month_index(p.start_month) <= month_index(month)
+                 AND (p.end_month IS NULL OR month_index(p.end_month) >= month_index(month)))
+$$ LANGUAGE sql IMMUTABLE;
+
+-- subscription_paused_cost стоимость месяцев на паузе в периоде [period_start, period_end];
+-- domain.Subscription.Cost вычитает ее из subscription_cost
+CREATE OR REPLACE FUNCTION subscription_paused_cost(start_date DATE, end_date DATE, price BIGINT, trial_months INTEGER,
+                                                    intro_price BIGINT, intro_months INTEGER, pauses JSONB,
+                                                    period_start DATE, period_end DATE)
+    RETURNS BIGINT AS
+$$
+SELECT COALESCE(SUM(subscription_cost(start_date, end_date, price, trial_months, intro_price, intro_months,
+                                      GREATEST(p.start_month, period_start),
+                                      LEAST(COALESCE(p.end_month, period_end), period_end))), 0)::bigint
+FROM jsonb_to_recordset(pauses) AS p(start_month DATE, end_month DATE)
+$$ LANGUAGE sql IMMUTABLE;
+
+-- subscription_range_add прибавляет к агрегату расход подписки за месяцы [range_from, range_to] с учетом пробного
+-- и вводного периодов; range_to NULL означает без окончания
+CREATE OR REPLACE FUNCTION subscription_range_add(org UUID, uid UUID, svc TEXT, start_date DATE, range_from DATE, range_to DATE,
+                                                  price BIGINT, trial_months INTEGER, intro_price BIGINT, intro_months INTEGER)
+    RETURNS void AS
+$$
+DECLARE
+    intro_start   DATE := GREATEST((date_trunc('month', start_date) + make_interval(months => trial_months))::date, range_from);
+    intro_end     DATE := LEAST((date_trunc('month', start_date) + make_interval(months => trial_months + intro_months - 1))::date, range_to);
+    regular_start DATE := GREATEST((date_trunc('month', start_date) + make_interval(months => trial_months + intro_months))::date, range_from);
+BEGIN
+    IF intro_months > 0 AND intro_start <= intro_end THEN
+        PERFORM monthly_spend_add(org, uid, svc, intro_start, intro_end, intro_price);
+    END IF;
+    IF range_to IS NULL OR regular_start <= range_to THEN
+        PERFORM monthly_spend_add(org, uid, svc, regular_start, range_to, price);
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- subscription_pauses_add вычитает из агрегата расход подписки за месяцы на паузе
+CREATE OR REPLACE FUNCTION subscription_pauses_add(org UUID, uid UUID, svc TEXT, start_date DATE, end_date DATE, price BIGINT,
+                                                   trial_months INTEGER, intro_price BIGINT, intro_months INTEGER, pauses JSONB)
+    RETURNS void AS
+$$
+DECLARE
+    pause RECORD;
+BEGIN
+    FOR pause IN
+        SELECT GREATEST(p.start_month, date_trunc('month', start_date)::date) AS range_from,
+               LEAST(p.end_month, end_date)                                  AS range_to
+        FROM jsonb_to_recordset(pauses) AS p(start_month DATE, end_month DATE)
+    LOOP
+        IF pause.range_to IS NULL OR pause.range_from <= pause.range_to THEN
+            PERFORM subscription_range_add(org, uid, svc, start_date, pause.range_from, pause.range_to,
+                                           -price, trial_months, -intro_price, intro_months);
+        END IF;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION subscriptions_monthly_spend()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM subscription_spend_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date,
+                                       -OLD.price, OLD.trial_months, -OLD.intro_price, OLD.intro_months);
+        PERFORM subscription_pauses_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date,
+                                        -OLD.price, OLD.trial_months, -OLD.intro_price, OLD.intro_months, OLD.pauses);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM subscription_spend_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date,
+                                       NEW.price, NEW.trial_months, NEW.intro_price, NEW.intro_months);
+        PERFORM subscription_pauses_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date,
+                                        NEW.price, NEW.trial_months, NEW.intro_price, NEW.intro_months, NEW.pauses);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Приостановки меняются отдельным UPDATE pauses, поэтому триггер пересоздается с этим столбцом
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR DELETE OR UPDATE OF organization_id, user_id, service_name, price, start_date, end_date, trial_months, intro_price, intro_months, pauses
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+CREATE OR REPLACE FUNCTION rebuild_monthly_spend()
+    RETURNS void AS
+$$
+BEGIN
+    LOCK TABLE subscriptions IN SHARE MODE;
+    DELETE FROM monthly_spend;
+
+    PERFORM subscription_spend_add(organization_id, user_id, service_name, start_date, end_date,
+                                   price, trial_months, intro_price, intro_months),
+            subscription_pauses_add(organization_id, user_id, service_name, start_date, end_date,
+                                    price, trial_months, intro_price, intro_months, pauses)
+    FROM subscriptions;
+END;
+$$ LANGUAGE plpgsql;
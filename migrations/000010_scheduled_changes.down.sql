@@ -0,0 +1,55 @@
+CREATE OR REPLACE FUNCTION subscriptions_monthly_spend()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM subscription_spend_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date,
+                                       -OLD.price, OLD.trial_months, -OLD.intro_price, OLD.intro_months);
+        PERFORM subscription_pauses_add(OLD.organization_id, OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date,
+                                        -OLD.price, OLD.trial_months, -OLD.intro_price, OLD.intro_months, OLD.pauses);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM subscription_spend_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date,
+                                       NEW.price, NEW.trial_months, NEW.intro_price, NEW.intro_months);
+        PERFORM subscription_pauses_add(NEW.organization_id, NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date,
+                                        NEW.price, NEW.trial_months, NEW.intro_price, NEW.intro_months, NEW.pauses);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rebuild_monthly_spend()
+    RETURNS void AS
+$$
+BEGIN
+    LOCK TABLE subscriptions IN SHARE MODE;
+    DELETE FROM monthly_spend;
+
+    PERFORM subscription_spend_add(organization_id, user_id, service_name, start_date, end_date,
+                                   price, trial_months, intro_price, intro_months),
+            subscription_pauses_add(organization_id, user_id, service_name, start_date, end_date,
+                                    price, trial_months, intro_price, intro_months, pauses)
+    FROM subscriptions;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS subscription_rollup_add(subscriptions, INTEGER);
+DROP FUNCTION IF EXISTS subscription_price_at(subscriptions, DATE);
+DROP FUNCTION IF EXISTS subscription_total(subscriptions, DATE, DATE);
+DROP FUNCTION IF EXISTS subscription_window_cost(subscriptions, DATE, DATE);
+DROP FUNCTION IF EXISTS subscription_price_segments(BIGINT, JSONB, JSONB);
+DROP FUNCTION IF EXISTS subscription_end(DATE, JSONB);
+
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR DELETE OR UPDATE OF organization_id, user_id, service_name, price, start_date, end_date, trial_months, intro_price, intro_months, pauses
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS scheduled_changes,
+    DROP COLUMN IF EXISTS price_history,
+    DROP COLUMN IF EXISTS plan;
+
+SELECT rebuild_monthly_spend();
@@ -0,0 +1,133 @@
+-- Тариф, прежние цены и запланированные изменения подписки.
+-- price_history: [{"until_month": "YYYY-MM-01", "price": N}] - цены, действовавшие до применения изменений;
+-- scheduled_changes: [{"effective_month": "YYYY-MM-01", "price": N | null, "plan": "..." | null, "cancel": bool}].
+-- Как и паузы, хранятся в строке подписки, чтобы триггер агрегата видел их вместе с остальными полями
+ALTER TABLE subscriptions
+    ADD COLUMN plan              TEXT  NOT NULL DEFAULT '',
+    ADD COLUMN price_history     JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(price_history) = 'array'),
+    ADD COLUMN scheduled_changes JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(scheduled_changes) = 'array');
+
+-- subscription_end последний месяц подписки с учетом запланированной отмены; NULL, если подписка бессрочная
+CREATE OR REPLACE FUNCTION subscription_end(end_date DATE, changes JSONB)
+    RETURNS DATE AS
+$$
+SELECT LEAST(end_date, (SELECT (MIN(c.effective_month) - INTERVAL '1 month')::date
+                        FROM jsonb_to_recordset(changes) AS c(effective_month DATE, cancel BOOLEAN)
+                        WHERE c.cancel))
+$$ LANGUAGE sql IMMUTABLE;
+
+-- subscription_price_segments периоды действия цены после пробного и вводного периодов:
+-- прежние цены, текущая price и запланированные изменения; NULL в границе означает отсутствие ограничения
+CREATE OR REPLACE FUNCTION subscription_price_segments(price BIGINT, price_history JSONB, changes JSONB)
+    RETURNS TABLE (segment_price BIGINT, segment_from DATE, segment_to DATE) AS
+$$
+WITH points AS (SELECT (LAG(h.until_month) OVER (ORDER BY h.until_month) + INTERVAL '1 month')::date AS point,
+                       h.price                                                                 AS point_price
+                FROM jsonb_to_recordset(price_history) AS h(until_month DATE, price BIGINT)
+                UNION ALL
+                SELECT (SELECT (MAX(h.until_month) + INTERVAL '1 month')::date
+                        FROM jsonb_to_recordset(price_history) AS h(until_month DATE)),
+                       price
+                UNION ALL
+                SELECT c.effective_month, c.price
+                FROM jsonb_to_recordset(changes) AS c(effective_month DATE, price BIGINT)
+                WHERE c.price IS NOT NULL)
+SELECT point_price, point, (LEAD(point) OVER (ORDER BY point NULLS FIRST) - INTERVAL '1 month')::date
+FROM points
+$$ LANGUAGE sql IMMUTABLE;
+
+-- subscription_window_cost стоимость подписки за месяцы [period_start, period_end] без учета пауз
+CREATE OR REPLACE FUNCTION subscription_window_cost(s subscriptions, period_start DATE, period_end DATE)
+    RETURNS BIGINT AS
+$$
+SELECT COALESCE(SUM(subscription_cost(s.start_date, subscription_end(s.end_date, s.scheduled_changes), seg.segment_price,
+                                      s.trial_months, s.intro_price, s.intro_months,
+                                      GREATEST(period_start, seg.segment_from), LEAST(period_end, seg.segment_to))), 0)::bigint
+FROM subscription_price_segments(s.price, s.price_history, s.scheduled_changes) AS seg
+$$ LANGUAGE sql STABLE;
+
+-- subscription_total стоимость подписки за месяцы [period_start, period_end] за вычетом месяцев на паузе;
+-- повторяет domain.Subscription.Cost
+CREATE OR REPLACE FUNCTION subscription_total(s subscriptions, period_start DATE, period_end DATE)
+    RETURNS BIGINT AS
+$$
+SELECT subscription_window_cost(s, period_start, period_end) -
+       COALESCE((SELECT SUM(subscription_window_cost(s, GREATEST(p.start_month, period_start),
+                                                     LEAST(COALESCE(p.end_month, period_end), period_end)))
+                 FROM jsonb_to_recordset(s.pauses) AS p(start_month DATE, end_month DATE)), 0)::bigint
+$$ LANGUAGE sql STABLE;
+
+-- subscription_price_at стоимость месяца month без учета окончания подписки; повторяет domain.Subscription.PriceAt
+CREATE OR REPLACE FUNCTION subscription_price_at(s subscriptions, month DATE)
+    RETURNS BIGINT AS
+$$
+SELECT CASE
+           WHEN subscription_paused_at(s.pauses, month) THEN 0
+           ELSE COALESCE(SUM(subscription_cost(s.start_date, NULL, seg.segment_price, s.trial_months,
+                                               s.intro_price, s.intro_months, month, month)), 0)
+           END::bigint
+FROM subscription_price_segments(s.price, s.price_history, s.scheduled_changes) AS seg
+WHERE month >= COALESCE(seg.segment_from, month)
+  AND month <= COALESCE(seg.segment_to, month)
+$$ LANGUAGE sql STABLE;
+
+-- subscription_rollup_add прибавляет к агрегату monthly_spend расход подписки, умноженный на direction:
+-- по периодам цены до последнего месяца с учетом отмены, за вычетом месяцев на паузе
+CREATE OR REPLACE FUNCTION subscription_rollup_add(s subscriptions, direction INTEGER)
+    RETURNS void AS
+$$
+DECLARE
+    first_month DATE := date_trunc('month', s.start_date)::date;
+    last_month  DATE := subscription_end(s.end_date, s.scheduled_changes);
+    seg         RECORD;
+    pause       RECORD;
+BEGIN
+    FOR seg IN SELECT * FROM subscription_price_segments(s.price, s.price_history, s.scheduled_changes)
+    LOOP
+        PERFORM subscription_range_add(s.organization_id, s.user_id, s.service_name, s.start_date,
+                                       GREATEST(first_month, seg.segment_from), LEAST(last_month, seg.segment_to),
+                                       direction * seg.segment_price, s.trial_months, direction * s.intro_price, s.intro_months);
+
+        FOR pause IN SELECT * FROM jsonb_to_recordset(s.pauses) AS p(start_month DATE, end_month DATE)
+        LOOP
+            PERFORM subscription_range_add(s.organization_id, s.user_id, s.service_name, s.start_date,
+                                           GREATEST(first_month, seg.segment_from, pause.start_month),
+                                           LEAST(last_month, seg.segment_to, pause.end_month),
+                                           -direction * seg.segment_price, s.trial_months, -direction * s.intro_price, s.intro_months);
+        END LOOP;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION subscriptions_monthly_spend()
+    RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM subscription_rollup_add(OLD, -1);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM subscription_rollup_add(NEW, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Запланированные изменения сохраняются отдельным UPDATE scheduled_changes, поэтому триггер пересоздается с ними
+DROP TRIGGER IF EXISTS subscriptions_monthly_spend ON subscriptions;
+CREATE TRIGGER subscriptions_monthly_spend
+    AFTER INSERT OR DELETE OR UPDATE OF organization_id, user_id, service_name, price, start_date, end_date, trial_months, intro_price, intro_months, pauses, price_history, scheduled_changes
+    ON subscriptions
+    FOR EACH ROW
+EXECUTE FUNCTION subscriptions_monthly_spend();
+
+CREATE OR REPLACE FUNCTION rebuild_monthly_spend()
+    RETURNS void AS
+$$
+BEGIN
+    LOCK TABLE subscriptions IN SHARE MODE;
+    DELETE FROM monthly_spend;
+
+    PERFORM subscription_rollup_add(s, 1) FROM subscriptions s;
+END;
+$$ LANGUAGE plpgsql;
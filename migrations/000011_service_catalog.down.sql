@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS subscriptions_service_idx;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS service_id;
+DROP TABLE IF EXISTS service_names;
+DROP TABLE IF EXISTS services;
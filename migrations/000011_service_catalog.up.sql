@@ -0,0 +1,53 @@
+-- Каталог сервисов организации: каноническое название, псевдонимы, категория, сайт и тарифы
+-- plans: массив {"name": "...", "price": N} в порядке добавления
+CREATE TABLE IF NOT EXISTS services
+(
+    id              UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    organization_id UUID        NOT NULL REFERENCES organizations (id),
+    name            TEXT        NOT NULL,
+    aliases         TEXT[]      NOT NULL DEFAULT '{}',
+    category        TEXT        NOT NULL DEFAULT '',
+    vendor_url      TEXT        NOT NULL DEFAULT '',
+    plans           JSONB       NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(plans) = 'array'),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS services_organization_category_idx ON services (organization_id, category);
+
+-- Нормализованные название и псевдонимы (domain.NormalizeServiceName): по ним подписки сопоставляются с каталогом,
+-- а первичный ключ не дает двум сервисам организации претендовать на одно написание
+CREATE TABLE IF NOT EXISTS service_names
+(
+    organization_id UUID NOT NULL,
+    name_key        TEXT NOT NULL CHECK (name_key <> ''),
+    service_id      UUID NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    PRIMARY KEY (organization_id, name_key)
+);
+
+CREATE INDEX IF NOT EXISTS service_names_service_idx ON service_names (service_id);
+
+-- Подписка может ссылаться на запись каталога; при удалении записи название подписки сохраняется
+ALTER TABLE subscriptions
+    ADD COLUMN service_id UUID REFERENCES services (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS subscriptions_service_idx ON subscriptions (service_id);
+
+-- Row-level security, как у subscriptions (000003): с app.organization_id видны только строки организации
+ALTER TABLE services ENABLE ROW LEVEL SECURITY;
+ALTER TABLE services FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY services_tenant_isolation ON services
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
+
+ALTER TABLE service_names ENABLE ROW LEVEL SECURITY;
+ALTER TABLE service_names FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY service_names_tenant_isolation ON service_names
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
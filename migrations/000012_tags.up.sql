@@ -0,0 +1,37 @@
+-- Произвольные теги организации (проект, центр затрат); название хранится нормализованным (domain.NormalizeTag)
+CREATE TABLE IF NOT EXISTS tags
+(
+    id              UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    organization_id UUID        NOT NULL REFERENCES organizations (id),
+    name            TEXT        NOT NULL CHECK (name <> '' AND char_length(name) <= 50),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (organization_id, name)
+);
+
+-- Связь многие-ко-многим: подписка может иметь несколько тегов, тег - много подписок
+CREATE TABLE IF NOT EXISTS subscription_tags
+(
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    tag_id          UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_tags_tag_idx ON subscription_tags (tag_id);
+
+-- Row-level security, как у subscriptions (000003): с app.organization_id видны только строки организации
+ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tags FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tags_tenant_isolation ON tags
+    USING (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID)
+    WITH CHECK (COALESCE(current_setting('app.organization_id', true), '') = ''
+        OR organization_id = current_setting('app.organization_id', true)::UUID);
+
+ALTER TABLE subscription_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_tags FORCE ROW LEVEL SECURITY;
+
+-- У связи нет своей организации: строка видна, если виден ее тег, а тег уже ограничен политикой tags
+CREATE POLICY subscription_tags_tenant_isolation ON subscription_tags
+    USING (EXISTS (SELECT 1 FROM tags t WHERE t.id = tag_id))
+    WITH CHECK (EXISTS (SELECT 1 FROM tags t WHERE t.id = tag_id));
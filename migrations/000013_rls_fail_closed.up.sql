@@ -0,0 +1,74 @@
+-- Политики row-level security закрыты по умолчанию: без app.organization_id строки не видны и не пишутся.
+-- Запросы по всем организациям (фоновые задачи, поиск API ключа по хешу) явно выставляют app.bypass_rls = 'on';
+-- приложение с выключенной row-level security выставляет его на все соединения пула.
+-- subscription_tags не меняется: ее политика опирается на видимость tags
+
+DROP POLICY IF EXISTS subscriptions_tenant_isolation ON subscriptions;
+CREATE POLICY subscriptions_tenant_isolation ON subscriptions
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS api_keys_tenant_isolation ON api_keys;
+CREATE POLICY api_keys_tenant_isolation ON api_keys
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS monthly_spend_tenant_isolation ON monthly_spend;
+CREATE POLICY monthly_spend_tenant_isolation ON monthly_spend
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS reminder_preferences_tenant_isolation ON reminder_preferences;
+CREATE POLICY reminder_preferences_tenant_isolation ON reminder_preferences
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS sent_reminders_tenant_isolation ON sent_reminders;
+CREATE POLICY sent_reminders_tenant_isolation ON sent_reminders
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS budgets_tenant_isolation ON budgets;
+CREATE POLICY budgets_tenant_isolation ON budgets
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS budget_alerts_tenant_isolation ON budget_alerts;
+CREATE POLICY budget_alerts_tenant_isolation ON budget_alerts
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS services_tenant_isolation ON services;
+CREATE POLICY services_tenant_isolation ON services
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS service_names_tenant_isolation ON service_names;
+CREATE POLICY service_names_tenant_isolation ON service_names
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
+
+DROP POLICY IF EXISTS tags_tenant_isolation ON tags;
+CREATE POLICY tags_tenant_isolation ON tags
+    USING (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+        OR organization_id = NULLIF(current_setting('app.organization_id', true), '')::UUID);
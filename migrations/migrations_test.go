@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sets = map[string]fs.FS{
+	"postgres": FS,
+	"sqlite":   SQLiteFS,
+}
+
+func TestLatestVersion(t *testing.T) {
+	for name, fsys := range sets {
+		t.Run(name, func(t *testing.T) {
+			ups, err := fs.Glob(fsys, "*.up.sql")
+			require.NoError(t, err)
+
+			var expected uint
+			for _, up := range ups {
+				n, err := strconv.ParseUint(strings.SplitN(up, "_", 2)[0], 10, 64)
+				require.NoError(t, err, up)
+				expected = max(expected, uint(n))
+			}
+
+			version, err := LatestVersion(fsys)
+			require.NoError(t, err)
+			assert.Equal(t, expected, version)
+		})
+	}
+}
+
+func TestEveryMigrationHasDown(t *testing.T) {
+	for name, fsys := range sets {
+		t.Run(name, func(t *testing.T) {
+			ups, err := fs.Glob(fsys, "*.up.sql")
+			require.NoError(t, err)
+			require.NotEmpty(t, ups)
+
+			for _, up := range ups {
+				down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+				_, err := fs.Stat(fsys, down)
+				assert.NoError(t, err, "missing %s", down)
+			}
+		})
+	}
+}
+
+// monthlySpendTrigger находит события в определениях триггера агрегата monthly_spend
+var monthlySpendTrigger = regexp.MustCompile(`(?s)CREATE TRIGGER subscriptions_monthly_spend\s+AFTER (.*?)\s+ON subscriptions`)
+
+// Триггер агрегата должен срабатывать на изменение каждого столбца, который читает subscriptions_monthly_spend()
+func TestMonthlySpendTriggerColumns(t *testing.T) {
+	rollupColumns := []string{"organization_id", "user_id", "service_name", "price", "start_date", "end_date",
+		"trial_months", "intro_price", "intro_months", "pauses",
+		"price_history", "scheduled_changes"}
+
+	ups, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+
+	var events string
+	for _, up := range ups {
+		data, err := fs.ReadFile(FS, up)
+		require.NoError(t, err)
+		for _, m := range monthlySpendTrigger.FindAllStringSubmatch(string(data), -1) {
+			events = m[1]
+		}
+	}
+	require.NotEmpty(t, events, "trigger subscriptions_monthly_spend is not defined")
+
+	_, list, limited := strings.Cut(events, "UPDATE OF ")
+	if !limited {
+		return
+	}
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		columns = append(columns, strings.TrimSpace(column))
+	}
+	for _, column := range rollupColumns {
+		assert.Contains(t, columns, column, "UPDATE OF of subscriptions_monthly_spend")
+	}
+}
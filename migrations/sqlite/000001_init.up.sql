@@ -0,0 +1,18 @@
+-- Схема SQLite повторяет схему PostgreSQL с учетом типов SQLite:
+-- UUID хранятся текстом в каноническом виде, даты - текстом YYYY-MM-DD,
+-- метки времени - текстом в UTC с микросекундами, чтобы строковое сравнение совпадало с хронологическим
+CREATE TABLE IF NOT EXISTS subscriptions
+(
+    id              TEXT PRIMARY KEY,
+    organization_id TEXT    NOT NULL,
+    service_name    TEXT    NOT NULL,
+    price           INTEGER NOT NULL CHECK (price >= 0 AND price <= 2147483647),
+    user_id         TEXT    NOT NULL,
+    start_date      TEXT    NOT NULL,
+    end_date        TEXT,
+    created_at      TEXT    NOT NULL,
+    updated_at      TEXT    NOT NULL,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_organization_user_idx ON subscriptions (organization_id, user_id);
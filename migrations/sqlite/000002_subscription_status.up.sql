@@ -0,0 +1,6 @@
+ALTER TABLE subscriptions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'expired'));
+
+UPDATE subscriptions
+SET status = 'expired'
+WHERE end_date < strftime('%Y-%m-01', 'now');
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions DROP COLUMN intro_months;
+ALTER TABLE subscriptions DROP COLUMN intro_price;
+ALTER TABLE subscriptions DROP COLUMN trial_months;
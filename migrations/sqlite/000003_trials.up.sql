@@ -0,0 +1,6 @@
+ALTER TABLE subscriptions
+    ADD COLUMN trial_months INTEGER NOT NULL DEFAULT 0 CHECK (trial_months BETWEEN 0 AND 120);
+ALTER TABLE subscriptions
+    ADD COLUMN intro_price INTEGER NOT NULL DEFAULT 0 CHECK (intro_price >= 0 AND intro_price <= 2147483647);
+ALTER TABLE subscriptions
+    ADD COLUMN intro_months INTEGER NOT NULL DEFAULT 0 CHECK (intro_months BETWEEN 0 AND 120);
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions
+    ADD COLUMN pauses TEXT NOT NULL DEFAULT '[]' CHECK (json_type(pauses) = 'array');
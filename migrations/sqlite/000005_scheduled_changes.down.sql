@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions DROP COLUMN scheduled_changes;
+ALTER TABLE subscriptions DROP COLUMN price_history;
+ALTER TABLE subscriptions DROP COLUMN plan;
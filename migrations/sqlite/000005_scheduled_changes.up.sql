@@ -0,0 +1,6 @@
+ALTER TABLE subscriptions
+    ADD COLUMN plan TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscriptions
+    ADD COLUMN price_history TEXT NOT NULL DEFAULT '[]' CHECK (json_type(price_history) = 'array');
+ALTER TABLE subscriptions
+    ADD COLUMN scheduled_changes TEXT NOT NULL DEFAULT '[]' CHECK (json_type(scheduled_changes) = 'array');
@@ -0,0 +1,3 @@
+-- Ссылка на каталог сервисов; сам каталог в SQLite недоступен, колонка хранит идентификатор как есть
+ALTER TABLE subscriptions
+    ADD COLUMN service_id TEXT;
@@ -0,0 +1,18 @@
+-- Теги организации и их связь с подписками, как в PostgreSQL
+CREATE TABLE IF NOT EXISTS tags
+(
+    id              TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    name            TEXT NOT NULL CHECK (name <> '' AND length(name) <= 50),
+    created_at      TEXT NOT NULL,
+    UNIQUE (organization_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS subscription_tags
+(
+    subscription_id TEXT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    tag_id          TEXT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_tags_tag_idx ON subscription_tags (tag_id);
@@ -0,0 +1,15 @@
+-- Организации, как в PostgreSQL: запросы с неизвестной организацией отклоняются
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE CHECK (name <> ''),
+    created_at TEXT NOT NULL
+);
+
+INSERT OR IGNORE INTO organizations (id, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default', strftime('%Y-%m-%dT%H:%M:%S', 'now') || '.000000Z');
+
+-- Организации, у которых уже есть данные, сохраняются под своим ID вместо названия
+INSERT OR IGNORE INTO organizations (id, name, created_at)
+SELECT organization_id, organization_id, strftime('%Y-%m-%dT%H:%M:%S', 'now') || '.000000Z'
+FROM (SELECT organization_id FROM subscriptions UNION SELECT organization_id FROM tags);
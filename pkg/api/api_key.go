@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
+
+// APIKeyUseCase определяет интерфейс use case для управления API ключами
+type APIKeyUseCase interface {
+	CreateAPIKey(ctx context.Context, req usecase.CreateAPIKeyInput) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+// CreateAPIKeyRequest represents data for creating an API key
+// swagger:model CreateAPIKeyRequest
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Scopes    []string `json:"scopes" binding:"required,min=1,dive,oneof=read write admin"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+// APIKeyResponse represents API key metadata in API response
+// swagger:model APIKeyResponse
+type APIKeyResponse struct {
+	ID             string   `json:"id"`
+	OrganizationID string   `json:"organization_id"`
+	Name           string   `json:"name"`
+	Prefix         string   `json:"prefix"`
+	Scopes         []string `json:"scopes"`
+	ExpiresAt      string   `json:"expires_at,omitempty"`
+	RevokedAt      string   `json:"revoked_at,omitempty"`
+	LastUsedAt     string   `json:"last_used_at,omitempty"`
+	CreatedAt      string   `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse represents a newly created API key with its secret value
+// swagger:model CreatedAPIKeyResponse
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
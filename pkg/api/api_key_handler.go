@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyUseCase APIKeyUseCase
+}
+
+// NewAPIKeyHandler создает новый экземпляр хэндлера API ключей
+func NewAPIKeyHandler(apiKeyUseCase APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Создать API ключ
+// @Description Создаёт ключ для межсервисного доступа. Значение ключа возвращается только в этом ответе
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body CreateAPIKeyRequest true "Данные ключа"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 201 {object} CreatedAPIKeyResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	requestLogger(c).Info("CreateAPIKey called")
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	// Вызов use case
+	key, plaintext, err := h.apiKeyUseCase.CreateAPIKey(c.Request.Context(), usecase.CreateAPIKeyInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to create api key", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("API key created", "id", key.ID, "scopes", key.Scopes)
+	RespondSuccess(c, http.StatusCreated, CreatedAPIKeyResponse{
+		APIKeyResponse: ToAPIKeyResponse(key),
+		Key:            plaintext,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary Список API ключей
+// @Description Возвращает все API ключи без их значений
+// @Tags admin
+// @Produce json
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {array} APIKeyResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	requestLogger(c).Info("ListAPIKeys called")
+
+	keys, err := h.apiKeyUseCase.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("Failed to list api keys", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	responses := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, ToAPIKeyResponse(key))
+	}
+
+	requestLogger(c).Info("api keys listed", "count", len(responses))
+	RespondSuccess(c, http.StatusOK, responses)
+}
+
+// RevokeAPIKey godoc
+// @Summary Отозвать API ключ
+// @Description Отзывает API ключ по ID
+// @Tags admin
+// @Produce json
+// @Param id path string true "ID ключа"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	requestLogger(c).Info("RevokeAPIKey called")
+
+	id := c.Param("id")
+	if err := h.apiKeyUseCase.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		requestLogger(c).Error("Failed to revoke api key", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("API key revoked", "id", id)
+	RespondSuccess(c, http.StatusOK, gin.H{
+		"message": "api key revoked successfully",
+	})
+}
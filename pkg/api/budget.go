@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
+
+// BudgetUseCase определяет интерфейс use case для работы с бюджетами
+type BudgetUseCase interface {
+	CreateBudget(ctx context.Context, req usecase.CreateBudgetInput) (*domain.Budget, error)
+	GetBudget(ctx context.Context, id string) (*domain.Budget, error)
+	UpdateBudget(ctx context.Context, id string, req usecase.UpdateBudgetInput) (*domain.Budget, error)
+	DeleteBudget(ctx context.Context, id string) error
+	ListBudgets(ctx context.Context, filters usecase.BudgetFiltersInput) ([]*domain.Budget, error)
+	GetBudgetStatus(ctx context.Context, id string) (*domain.BudgetStatus, error)
+}
+
+// CreateBudgetRequest represents data for creating a budget
+// Without user_id and service_name the budget covers all subscriptions of the organization
+// swagger:model CreateBudgetRequest
+type CreateBudgetRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Period      string `json:"period" binding:"required,oneof=monthly yearly"`
+	Limit       int64  `json:"limit" binding:"required,min=1"`
+	UserID      string `json:"user_id,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	// Thresholds are alert levels in percent of the limit; defaults to 80 and 100
+	Thresholds []int  `json:"thresholds,omitempty"`
+	Email      string `json:"email,omitempty"`
+}
+
+// UpdateBudgetRequest represents data for updating a budget; the user of a budget cannot be changed
+// swagger:model UpdateBudgetRequest
+type UpdateBudgetRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Period      string `json:"period" binding:"required,oneof=monthly yearly"`
+	Limit       int64  `json:"limit" binding:"required,min=1"`
+	ServiceName string `json:"service_name,omitempty"`
+	Thresholds  []int  `json:"thresholds,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+// BudgetResponse represents budget data in API response
+// swagger:model BudgetResponse
+type BudgetResponse struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	Name           string `json:"name"`
+	Period         string `json:"period"`
+	Limit          int64  `json:"limit"`
+	Currency       string `json:"currency"`
+	Scope          string `json:"scope"`
+	UserID         string `json:"user_id,omitempty"`
+	ServiceName    string `json:"service_name,omitempty"`
+	Thresholds     []int  `json:"thresholds"`
+	Email          string `json:"email,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// BudgetStatusResponse represents projected spend of the current budget period
+// swagger:model BudgetStatusResponse
+type BudgetStatusResponse struct {
+	Budget      BudgetResponse `json:"budget"`
+	PeriodStart string         `json:"period_start"`
+	PeriodEnd   string         `json:"period_end"`
+	// Spent is the projected spend for the whole period, calculated like the subscriptions summary
+	Spent     int64 `json:"spent"`
+	Remaining int64 `json:"remaining"`
+	Percent   int64 `json:"percent"`
+	// Reached lists the thresholds reached in the period
+	Reached  []int `json:"reached"`
+	Exceeded bool  `json:"exceeded"`
+}
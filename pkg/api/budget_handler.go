@@ -0,0 +1,244 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetHandler struct {
+	budgetUseCase BudgetUseCase
+}
+
+// NewBudgetHandler создает новый экземпляр хэндлера бюджетов
+func NewBudgetHandler(budgetUseCase BudgetUseCase) *BudgetHandler {
+	return &BudgetHandler{
+		budgetUseCase: budgetUseCase,
+	}
+}
+
+// CreateBudget godoc
+// @Summary Создать бюджет
+// @Description Создаёт месячный или годовой бюджет на подписки пользователя (user_id), сервиса (service_name) или всей организации. Бюджеты сервисов и организации требуют права budgets:write_all
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param budget body CreateBudgetRequest true "Данные бюджета"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 201 {object} BudgetResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	requestLogger(c).Info("CreateBudget called")
+
+	var req CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	budget, err := h.budgetUseCase.CreateBudget(c.Request.Context(), usecase.CreateBudgetInput{
+		Name:        req.Name,
+		Period:      req.Period,
+		Limit:       req.Limit,
+		UserID:      req.UserID,
+		ServiceName: req.ServiceName,
+		Thresholds:  req.Thresholds,
+		Email:       req.Email,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to create budget", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Budget created", "id", budget.ID, "scope", budget.Scope())
+	RespondSuccess(c, http.StatusCreated, ToBudgetResponse(budget))
+}
+
+// GetBudget godoc
+// @Summary Получить бюджет
+// @Description Возвращает бюджет по ID
+// @Tags budgets
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} BudgetResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /budgets/{id} [get]
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	requestLogger(c).Info("GetBudget called")
+
+	id := c.Param("id")
+	budget, err := h.budgetUseCase.GetBudget(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Failed to get budget", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	RespondSuccess(c, http.StatusOK, ToBudgetResponse(budget))
+}
+
+// UpdateBudget godoc
+// @Summary Обновить бюджет
+// @Description Заменяет параметры бюджета. Пользователь бюджета не меняется
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param budget body UpdateBudgetRequest true "Данные для обновления"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} BudgetResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	requestLogger(c).Info("UpdateBudget called")
+
+	var req UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	budget, err := h.budgetUseCase.UpdateBudget(c.Request.Context(), id, usecase.UpdateBudgetInput{
+		Name:        req.Name,
+		Period:      req.Period,
+		Limit:       req.Limit,
+		ServiceName: req.ServiceName,
+		Thresholds:  req.Thresholds,
+		Email:       req.Email,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to update budget", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Budget updated", "id", id)
+	RespondSuccess(c, http.StatusOK, ToBudgetResponse(budget))
+}
+
+// DeleteBudget godoc
+// @Summary Удалить бюджет
+// @Description Удаляет бюджет по ID
+// @Tags budgets
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	requestLogger(c).Info("DeleteBudget called")
+
+	id := c.Param("id")
+	if err := h.budgetUseCase.DeleteBudget(c.Request.Context(), id); err != nil {
+		requestLogger(c).Error("Failed to delete budget", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Budget deleted", "id", id)
+	RespondSuccess(c, http.StatusOK, gin.H{
+		"message": "budget deleted successfully",
+	})
+}
+
+// ListBudgets godoc
+// @Summary Список бюджетов
+// @Description Возвращает бюджеты организации. Без права budgets:read_all возвращаются только бюджеты вызывающего
+// @Tags budgets
+// @Produce json
+// @Param user_id query string false "Фильтр по user_id"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {array} BudgetResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /budgets [get]
+func (h *BudgetHandler) ListBudgets(c *gin.Context) {
+	userID := c.Query("user_id")
+	requestLogger(c).Info("ListBudgets called", "user_id", userID)
+
+	budgets, err := h.budgetUseCase.ListBudgets(c.Request.Context(), usecase.BudgetFiltersInput{UserID: userID})
+	if err != nil {
+		requestLogger(c).Error("Failed to list budgets", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	responses := make([]BudgetResponse, 0, len(budgets))
+	for _, budget := range budgets {
+		responses = append(responses, ToBudgetResponse(budget))
+	}
+
+	RespondSuccess(c, http.StatusOK, responses)
+}
+
+// GetBudgetStatus godoc
+// @Summary Состояние бюджета
+// @Description Возвращает ожидаемые расходы за текущий месяц или год бюджета, долю лимита и достигнутые пороги. Расходы считаются так же, как сумма подписок
+// @Tags budgets
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} BudgetStatusResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /budgets/{id}/status [get]
+func (h *BudgetHandler) GetBudgetStatus(c *gin.Context) {
+	requestLogger(c).Info("GetBudgetStatus called")
+
+	id := c.Param("id")
+	status, err := h.budgetUseCase.GetBudgetStatus(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Failed to get budget status", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Budget status calculated", "id", id, "spent", status.Spent, "limit", status.Budget.Limit)
+	RespondSuccess(c, http.StatusOK, ToBudgetStatusResponse(status))
+}
@@ -1,12 +1,14 @@
 package api
 
 import (
-	"log/slog"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/asgard-born/rest_service_subscriptions/pkg/cache"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
 	"github.com/gin-gonic/gin"
 )
@@ -25,39 +27,85 @@ func NewHandler(subscriptionUseCase SubscriptionUseCase) *Handler {
 // CreateSubscriptionRequest represents data for creating a subscription
 // swagger:model CreateSubscriptionRequest
 type CreateSubscriptionRequest struct {
-	ServiceName string `json:"service_name" binding:"required"`
-	Price       int    `json:"price" binding:"required,min=0"`
+	ServiceName string `json:"service_name,omitempty"` // matched to the catalog by name or alias, required without service_id
+	ServiceID   string `json:"service_id,omitempty"`   // catalog entry
+	Plan        string `json:"plan,omitempty"`
+	Price       *int   `json:"price,omitempty" binding:"omitempty,min=0"` // defaults to the price of the catalog plan
 	UserID      string `json:"user_id" binding:"required"`
 	StartDate   string `json:"start_date" binding:"required"`
 	EndDate     string `json:"end_date,omitempty"`
+	TrialMonths int    `json:"trial_months,omitempty" binding:"min=0,max=120"` // free months from start_date
+	IntroPrice  int    `json:"intro_price,omitempty" binding:"min=0"`          // monthly price for intro_months after the trial
+	IntroMonths int    `json:"intro_months,omitempty" binding:"min=0,max=120"`
+	// Tags are free-form labels such as a project or a cost center, compared ignoring case and extra spaces
+	Tags []string `json:"tags,omitempty"`
 }
 
 // UpdateSubscriptionRequest represents data for updating a subscription
 // swagger:model UpdateSubscriptionRequest
 type UpdateSubscriptionRequest struct {
-	ServiceName string `json:"service_name" binding:"required"`
-	Price       int    `json:"price" binding:"required,min=0"`
+	ServiceName string `json:"service_name,omitempty"` // matched to the catalog by name or alias, required without service_id
+	ServiceID   string `json:"service_id,omitempty"`   // catalog entry
+	Plan        string `json:"plan,omitempty"`
+	Price       *int   `json:"price,omitempty" binding:"omitempty,min=0"` // defaults to the price of the catalog plan
 	StartDate   string `json:"start_date" binding:"required"`
 	EndDate     string `json:"end_date,omitempty"`
+	TrialMonths int    `json:"trial_months,omitempty" binding:"min=0,max=120"`
+	IntroPrice  int    `json:"intro_price,omitempty" binding:"min=0"`
+	IntroMonths int    `json:"intro_months,omitempty" binding:"min=0,max=120"`
+}
+
+// PauseSubscriptionRequest represents the months of a subscription pause in MM-YYYY format
+// swagger:model PauseSubscriptionRequest
+type PauseSubscriptionRequest struct {
+	From  string `json:"from,omitempty"`  // first paused month, defaults to the current month
+	Until string `json:"until,omitempty"` // last paused month, open-ended if empty
+}
+
+// ResumeSubscriptionRequest represents the month from which a paused subscription is billed again
+// swagger:model ResumeSubscriptionRequest
+type ResumeSubscriptionRequest struct {
+	From string `json:"from,omitempty"` // defaults to the current month
+}
+
+// ScheduleChangeRequest represents a subscription change effective from a future month in MM-YYYY format
+// swagger:model ScheduleChangeRequest
+type ScheduleChangeRequest struct {
+	EffectiveFrom string  `json:"effective_from" binding:"required"`
+	Price         *int    `json:"price,omitempty" binding:"omitempty,min=0"` // new monthly price
+	Plan          *string `json:"plan,omitempty"`                            // new plan
+	Cancel        bool    `json:"cancel,omitempty"`                          // the month before effective_from becomes the last one
+}
+
+// SetSubscriptionTagsRequest represents the full list of tags of a subscription; an empty list removes all tags
+// swagger:model SetSubscriptionTagsRequest
+type SetSubscriptionTagsRequest struct {
+	Tags []string `json:"tags"`
 }
 
 // CreateSubscription godoc
 // @Summary Создать подписку
-// @Description Создаёт новую подписку для пользователя
+// @Description Создаёт новую подписку для пользователя. Первые trial_months месяцев бесплатны, следующие intro_months стоят intro_price
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param subscription body CreateSubscriptionRequest true "Данные подписки"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
 // @Success 201 {object} SubscriptionResponse
 // @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
 // @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /subscriptions [post]
 func (h *Handler) CreateSubscription(c *gin.Context) {
-	slog.Info("CreateSubscription called")
+	requestLogger(c).Info("CreateSubscription called")
 
 	var req CreateSubscriptionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		slog.Warn("Failed to bind JSON", "error", err)
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
 		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
 		return
 	}
@@ -65,21 +113,27 @@ func (h *Handler) CreateSubscription(c *gin.Context) {
 	// Преобразование HTTP запроса в use case запрос
 	useCaseReq := usecase.CreateSubscriptionInput{
 		ServiceName: req.ServiceName,
+		ServiceID:   req.ServiceID,
+		Plan:        req.Plan,
 		Price:       req.Price,
 		UserID:      req.UserID,
 		StartDate:   req.StartDate,
 		EndDate:     req.EndDate,
+		TrialMonths: req.TrialMonths,
+		IntroPrice:  req.IntroPrice,
+		IntroMonths: req.IntroMonths,
+		Tags:        req.Tags,
 	}
 
 	// Вызов use case
 	sub, err := h.subscriptionUseCase.CreateSubscription(c.Request.Context(), useCaseReq)
 	if err != nil {
-		slog.Error("Failed to create subscription", "error", err)
+		requestLogger(c).Error("Failed to create subscription", "error", err)
 		handleError(c, err)
 		return
 	}
 
-	slog.Info("Subscription created", "id", sub.ID)
+	requestLogger(c).Info("Subscription created", "id", sub.ID)
 	RespondSuccess(c, http.StatusCreated, ToSubscriptionResponse(sub))
 }
 
@@ -89,17 +143,23 @@ func (h *Handler) CreateSubscription(c *gin.Context) {
 // @Tags subscriptions
 // @Produce json
 // @Param id path string true "ID подписки"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
 // @Success 200 {object} SubscriptionResponse
 // @Failure 400 {object} APIResponse
 // @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
 // @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /subscriptions/{id} [get]
 func (h *Handler) GetSubscription(c *gin.Context) {
-	slog.Info("GetSubscription called")
+	requestLogger(c).Info("GetSubscription called")
 
 	id := c.Param("id")
 	if id == "" {
-		slog.Warn("Missing id param")
+		requestLogger(c).Warn("Missing id param")
 		RespondError(c, http.StatusBadRequest, "id is required")
 		return
 	}
@@ -107,12 +167,12 @@ func (h *Handler) GetSubscription(c *gin.Context) {
 	// Вызов use case
 	sub, err := h.subscriptionUseCase.GetSubscription(c.Request.Context(), id)
 	if err != nil {
-		slog.Error("Failed to get subscription", "id", id, "error", err)
+		requestLogger(c).Error("Failed to get subscription", "id", id, "error", err)
 		handleError(c, err)
 		return
 	}
 
-	slog.Info("Subscription retrieved", "id", id)
+	requestLogger(c).Info("Subscription retrieved", "id", id)
 	RespondSuccess(c, http.StatusOK, ToSubscriptionResponse(sub))
 }
 
@@ -124,24 +184,30 @@ func (h *Handler) GetSubscription(c *gin.Context) {
 // @Produce json
 // @Param id path string true "ID подписки"
 // @Param subscription body UpdateSubscriptionRequest true "Данные для обновления"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
 // @Success 200 {object} SubscriptionResponse
 // @Failure 400 {object} APIResponse
 // @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
 // @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /subscriptions/{id} [put]
 func (h *Handler) UpdateSubscription(c *gin.Context) {
-	slog.Info("UpdateSubscription called")
+	requestLogger(c).Info("UpdateSubscription called")
 
 	id := c.Param("id")
 	if id == "" {
-		slog.Warn("Missing id param")
+		requestLogger(c).Warn("Missing id param")
 		RespondError(c, http.StatusBadRequest, "id is required")
 		return
 	}
 
 	var req UpdateSubscriptionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		slog.Warn("Failed to bind JSON", "error", err)
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
 		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
 		return
 	}
@@ -149,20 +215,238 @@ func (h *Handler) UpdateSubscription(c *gin.Context) {
 	// Преобразование HTTP запроса в use case запрос
 	useCaseReq := usecase.UpdateSubscriptionInput{
 		ServiceName: req.ServiceName,
+		ServiceID:   req.ServiceID,
+		Plan:        req.Plan,
 		Price:       req.Price,
 		StartDate:   req.StartDate,
 		EndDate:     req.EndDate,
+		TrialMonths: req.TrialMonths,
+		IntroPrice:  req.IntroPrice,
+		IntroMonths: req.IntroMonths,
 	}
 
 	// Вызов use case
 	sub, err := h.subscriptionUseCase.UpdateSubscription(c.Request.Context(), id, useCaseReq)
 	if err != nil {
-		slog.Error("Failed to update subscription", "id", id, "error", err)
+		requestLogger(c).Error("Failed to update subscription", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Subscription updated", "id", id)
+	RespondSuccess(c, http.StatusOK, ToSubscriptionResponse(sub))
+}
+
+// PauseSubscription godoc
+// @Summary Приостановить подписку
+// @Description Приостанавливает оплату подписки с месяца from по месяц until включительно. Месяцы паузы не входят в суммы, бюджеты и напоминания о продлении
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param pause body PauseSubscriptionRequest false "Месяцы паузы"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /subscriptions/{id}/pause [post]
+func (h *Handler) PauseSubscription(c *gin.Context) {
+	requestLogger(c).Info("PauseSubscription called")
+
+	id := c.Param("id")
+
+	// Тело необязательно: без него пауза начинается с текущего месяца и длится до возобновления
+	var req PauseSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	sub, err := h.subscriptionUseCase.PauseSubscription(c.Request.Context(), id, usecase.PauseInput{
+		From:  req.From,
+		Until: req.Until,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to pause subscription", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Subscription paused", "id", id)
+	RespondSuccess(c, http.StatusOK, ToSubscriptionResponse(sub))
+}
+
+// ResumeSubscription godoc
+// @Summary Возобновить подписку
+// @Description Возобновляет оплату приостановленной подписки с месяца from. Пауза, которая еще не началась, отменяется
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param resume body ResumeSubscriptionRequest false "Месяц возобновления"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /subscriptions/{id}/resume [post]
+func (h *Handler) ResumeSubscription(c *gin.Context) {
+	requestLogger(c).Info("ResumeSubscription called")
+
+	id := c.Param("id")
+
+	var req ResumeSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	sub, err := h.subscriptionUseCase.ResumeSubscription(c.Request.Context(), id, usecase.ResumeInput{From: req.From})
+	if err != nil {
+		requestLogger(c).Error("Failed to resume subscription", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Subscription resumed", "id", id)
+	RespondSuccess(c, http.StatusOK, ToSubscriptionResponse(sub))
+}
+
+// ScheduleChange godoc
+// @Summary Запланировать изменение подписки
+// @Description Планирует новую цену, тариф или отмену подписки с будущего месяца effective_from. Суммы и прогнозы учитывают изменение сразу, к подписке его применяет фоновая задача
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param change body ScheduleChangeRequest true "Изменение"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /subscriptions/{id}/changes [post]
+func (h *Handler) ScheduleChange(c *gin.Context) {
+	requestLogger(c).Info("ScheduleChange called")
+
+	id := c.Param("id")
+
+	var req ScheduleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	sub, err := h.subscriptionUseCase.ScheduleChange(c.Request.Context(), id, usecase.ScheduleChangeInput{
+		EffectiveFrom: req.EffectiveFrom,
+		Price:         req.Price,
+		Plan:          req.Plan,
+		Cancel:        req.Cancel,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to schedule change", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Subscription change scheduled", "id", id, "effective_from", req.EffectiveFrom)
+	RespondSuccess(c, http.StatusOK, ToSubscriptionResponse(sub))
+}
+
+// DeleteScheduledChange godoc
+// @Summary Отменить запланированное изменение
+// @Description Удаляет изменение подписки, запланированное на месяц month
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param month path string true "Месяц изменения (MM-YYYY)"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /subscriptions/{id}/changes/{month} [delete]
+func (h *Handler) DeleteScheduledChange(c *gin.Context) {
+	requestLogger(c).Info("DeleteScheduledChange called")
+
+	id, month := c.Param("id"), c.Param("month")
+
+	sub, err := h.subscriptionUseCase.DeleteScheduledChange(c.Request.Context(), id, month)
+	if err != nil {
+		requestLogger(c).Error("Failed to delete scheduled change", "id", id, "month", month, "error", err)
 		handleError(c, err)
 		return
 	}
 
-	slog.Info("Subscription updated", "id", id)
+	requestLogger(c).Info("Scheduled change deleted", "id", id, "month", month)
+	RespondSuccess(c, http.StatusOK, ToSubscriptionResponse(sub))
+}
+
+// SetSubscriptionTags godoc
+// @Summary Задать теги подписки
+// @Description Заменяет теги подписки (проект, центр затрат и т.п.). Теги приводятся к нижнему регистру, новые добавляются в список тегов организации
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param tags body SetSubscriptionTagsRequest true "Теги подписки"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /subscriptions/{id}/tags [put]
+func (h *Handler) SetSubscriptionTags(c *gin.Context) {
+	requestLogger(c).Info("SetSubscriptionTags called")
+
+	var req SetSubscriptionTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	sub, err := h.subscriptionUseCase.SetSubscriptionTags(c.Request.Context(), id, req.Tags)
+	if err != nil {
+		requestLogger(c).Error("Failed to set subscription tags", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Subscription tags updated", "id", id, "tags", sub.Tags)
 	RespondSuccess(c, http.StatusOK, ToSubscriptionResponse(sub))
 }
 
@@ -172,17 +456,23 @@ func (h *Handler) UpdateSubscription(c *gin.Context) {
 // @Tags subscriptions
 // @Produce json
 // @Param id path string true "ID подписки"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
 // @Success 200 {object} APIResponse
 // @Failure 400 {object} APIResponse
 // @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
 // @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /subscriptions/{id} [delete]
 func (h *Handler) DeleteSubscription(c *gin.Context) {
-	slog.Info("DeleteSubscription called")
+	requestLogger(c).Info("DeleteSubscription called")
 
 	id := c.Param("id")
 	if id == "" {
-		slog.Warn("Missing id param")
+		requestLogger(c).Warn("Missing id param")
 		RespondError(c, http.StatusBadRequest, "id is required")
 		return
 	}
@@ -190,17 +480,60 @@ func (h *Handler) DeleteSubscription(c *gin.Context) {
 	// Вызов use case
 	err := h.subscriptionUseCase.DeleteSubscription(c.Request.Context(), id)
 	if err != nil {
-		slog.Error("Failed to delete subscription", "id", id, "error", err)
+		requestLogger(c).Error("Failed to delete subscription", "id", id, "error", err)
 		handleError(c, err)
 		return
 	}
 
-	slog.Info("Subscription deleted", "id", id)
+	requestLogger(c).Info("Subscription deleted", "id", id)
 	RespondSuccess(c, http.StatusOK, gin.H{
 		"message": "subscription deleted successfully",
 	})
 }
 
+// DeleteSubscriptions godoc
+// @Summary Массово удалить подписки
+// @Description Удаляет все подписки пользователя и/или сервиса. Доступно только администраторам
+// @Tags subscriptions
+// @Produce json
+// @Param user_id query string false "Фильтр по user_id"
+// @Param service_name query string false "Фильтр по service_name"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} object{deleted=int64}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /subscriptions [delete]
+func (h *Handler) DeleteSubscriptions(c *gin.Context) {
+	userID := c.Query("user_id")
+	serviceName := c.Query("service_name")
+
+	requestLogger(c).Info("DeleteSubscriptions called",
+		"user_id", userID,
+		"service_name", serviceName,
+	)
+
+	// Вызов use case
+	deleted, err := h.subscriptionUseCase.DeleteSubscriptions(c.Request.Context(), usecase.DeleteFiltersInput{
+		UserID:      userID,
+		ServiceName: serviceName,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to delete subscriptions", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Subscriptions deleted", "count", deleted)
+	RespondSuccess(c, http.StatusOK, gin.H{
+		"deleted": deleted,
+	})
+}
+
 // ListSubscriptions godoc
 // @Summary Список подписок
 // @Description Возвращает список подписок с пагинацией и фильтрацией
@@ -208,51 +541,72 @@ func (h *Handler) DeleteSubscription(c *gin.Context) {
 // @Produce json
 // @Param user_id query string false "Фильтр по user_id"
 // @Param service_name query string false "Фильтр по service_name"
+// @Param tag query string false "Фильтр по тегу"
+// @Param trial_ending_days query int false "Только подписки, пробный период которых заканчивается в ближайшие N дней"
 // @Param limit query int false "Лимит (по умолчанию 10)" default(10)
 // @Param offset query int false "Смещение (по умолчанию 0)" default(0)
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
 // @Success 200 {array} SubscriptionResponse
 // @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
 // @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /subscriptions [get]
 func (h *Handler) ListSubscriptions(c *gin.Context) {
 	userID := c.Query("user_id")
 	serviceName := c.Query("service_name")
+	tag := c.Query("tag")
+	trialEndingStr := c.DefaultQuery("trial_ending_days", "0")
 	limitStr := c.DefaultQuery("limit", "10")
 	offsetStr := c.DefaultQuery("offset", "0")
 
-	slog.Info("ListSubscriptions called",
+	requestLogger(c).Info("ListSubscriptions called",
 		"user_id", userID,
 		"service_name", serviceName,
+		"tag", tag,
+		"trial_ending_days", trialEndingStr,
 		"limit", limitStr,
 		"offset", offsetStr,
 	)
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
-		slog.Warn("invalid limit", "value", limitStr, "err", err)
+		requestLogger(c).Warn("invalid limit", "value", limitStr, "err", err)
 		RespondError(c, http.StatusBadRequest, "invalid limit")
 		return
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < 0 {
-		slog.Warn("invalid offset", "value", offsetStr, "err", err)
+		requestLogger(c).Warn("invalid offset", "value", offsetStr, "err", err)
 		RespondError(c, http.StatusBadRequest, "invalid offset")
 		return
 	}
 
+	trialEndingDays, err := strconv.Atoi(trialEndingStr)
+	if err != nil || trialEndingDays < 0 {
+		requestLogger(c).Warn("invalid trial_ending_days", "value", trialEndingStr, "err", err)
+		RespondError(c, http.StatusBadRequest, "invalid trial_ending_days")
+		return
+	}
+
 	// Преобразование HTTP запроса в use case запрос
 	useCaseReq := usecase.ListFiltersInput{
-		UserID:      userID,
-		ServiceName: serviceName,
-		Limit:       limit,
-		Offset:      offset,
+		UserID:          userID,
+		ServiceName:     serviceName,
+		Tag:             tag,
+		TrialEndingDays: trialEndingDays,
+		Limit:           limit,
+		Offset:          offset,
 	}
 
 	// Вызов use case
 	subs, err := h.subscriptionUseCase.ListSubscriptions(c.Request.Context(), useCaseReq)
 	if err != nil {
-		slog.Error("Failed to list subscriptions", "error", err)
+		requestLogger(c).Error("Failed to list subscriptions", "error", err)
 		handleError(c, err)
 		return
 	}
@@ -263,32 +617,48 @@ func (h *Handler) ListSubscriptions(c *gin.Context) {
 		responses = append(responses, ToSubscriptionResponse(sub))
 	}
 
-	slog.Info("subscriptions listed", "count", len(responses))
+	requestLogger(c).Info("subscriptions listed", "count", len(responses))
 	RespondSuccess(c, http.StatusOK, responses)
 }
 
+// CacheHeader заголовок ответа, сообщающий, была ли сумма взята из кэша (HIT или MISS)
+const CacheHeader = "X-Cache"
+
 // GetSubscriptionsSummary godoc
 // @Summary Сумма подписок
-// @Description Возвращает общую стоимость подписок за период
+// @Description Возвращает общую стоимость подписок за период. С group_by дополнительно возвращает суммы по тегам или категориям каталога; подписка с несколькими тегами входит в каждую группу, подписки без тега или категории - в группу с пустым key
 // @Tags subscriptions
 // @Produce json
 // @Param user_id query string false "Фильтр по user_id"
 // @Param service_name query string false "Фильтр по service_name"
+// @Param tag query string false "Фильтр по тегу"
+// @Param group_by query string false "Группировка: tag или category" Enums(tag, category)
 // @Param period_start query string true "Начало периода (MM-YYYY)"
 // @Param period_end query string true "Конец периода (MM-YYYY)"
-// @Success 200 {object} object{total=int64,from=string,to=string,user_id=string,service=string,timestamp=string}
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} object{total=int64,from=string,to=string,user_id=string,service=string,tag=string,group_by=string,groups=[]SummaryGroupResponse,timestamp=string,cache=string}
+// @Header 200 {string} X-Cache "HIT или MISS, если включен кэш сумм"
 // @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
 // @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /subscriptions/summary [get]
 func (h *Handler) GetSubscriptionsSummary(c *gin.Context) {
 	userID := c.Query("user_id")
 	serviceName := c.Query("service_name")
+	tag := c.Query("tag")
+	groupBy := c.Query("group_by")
 	periodStartQuery := c.Query("period_start")
 	periodEndQuery := c.Query("period_end")
 
-	slog.Info("GetSubscriptionsSummary called",
+	requestLogger(c).Info("GetSubscriptionsSummary called",
 		"user_id", userID,
 		"service_name", serviceName,
+		"tag", tag,
+		"group_by", groupBy,
 		"period_start", periodStartQuery,
 		"period_end", periodEndQuery,
 	)
@@ -297,32 +667,60 @@ func (h *Handler) GetSubscriptionsSummary(c *gin.Context) {
 	useCaseReq := usecase.SummaryFiltersInput{
 		UserID:      userID,
 		ServiceName: serviceName,
+		Tag:         tag,
 		PeriodStart: periodStartQuery,
 		PeriodEnd:   periodEndQuery,
 	}
 
-	// Вызов use case
-	total, err := h.subscriptionUseCase.GetSubscriptionsSummary(c.Request.Context(), useCaseReq)
+	// Суммы по группам считаются до общей суммы, чтобы неверный group_by не тратил запрос
+	var groups []SummaryGroupResponse
+	if groupBy != "" {
+		found, err := h.subscriptionUseCase.GetSubscriptionsSummaryGroups(c.Request.Context(), useCaseReq, groupBy)
+		if err != nil {
+			requestLogger(c).Error("Failed to get grouped summary", "group_by", groupBy, "error", err)
+			handleError(c, err)
+			return
+		}
+		groups = ToSummaryGroupResponses(found)
+	}
+
+	// Вызов use case; lookup покажет, была ли сумма взята из кэша
+	ctx, lookup := cache.Track(c.Request.Context())
+	total, err := h.subscriptionUseCase.GetSubscriptionsSummary(ctx, useCaseReq)
 	if err != nil {
-		slog.Error("Failed to get summary", "error", err)
+		requestLogger(c).Error("Failed to get summary", "error", err)
 		handleError(c, err)
 		return
 	}
 
-	slog.Info("summary calculated",
+	requestLogger(c).Info("summary calculated",
 		"total", total,
 		"from", periodStartQuery,
 		"to", periodEndQuery,
+		"cache", lookup.Result(),
 	)
 
-	RespondSuccess(c, http.StatusOK, gin.H{
+	response := gin.H{
 		"total":     total,
 		"from":      periodStartQuery,
 		"to":        periodEndQuery,
 		"user_id":   userID,
 		"service":   serviceName,
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-	})
+	}
+	if tag != "" {
+		response["tag"] = tag
+	}
+	if groupBy != "" {
+		response["group_by"] = groupBy
+		response["groups"] = groups
+	}
+	if result := lookup.Result(); result != "" {
+		c.Header(CacheHeader, strings.ToUpper(result))
+		response["cache"] = result
+	}
+
+	RespondSuccess(c, http.StatusOK, response)
 }
 
 // handleError обрабатывает ошибки от use case и возвращает соответствующий HTTP статус
@@ -335,8 +733,13 @@ func handleError(c *gin.Context, err error) {
 
 	// Определяем тип ошибки по содержимому сообщения
 	switch {
+	case strings.Contains(errMsg, "forbidden"):
+		RespondError(c, http.StatusForbidden, errMsg)
 	case strings.Contains(errMsg, "not found"):
 		RespondError(c, http.StatusNotFound, errMsg)
+	case strings.Contains(errMsg, "already running") || strings.Contains(errMsg, "already paused") || strings.Contains(errMsg, "not paused") ||
+		strings.Contains(errMsg, "already scheduled") || strings.Contains(errMsg, "already used"):
+		RespondError(c, http.StatusConflict, errMsg)
 	case strings.Contains(errMsg, "invalid") || strings.Contains(errMsg, "required") || strings.Contains(errMsg, "must be"):
 		RespondError(c, http.StatusBadRequest, errMsg)
 	default:
@@ -3,11 +3,14 @@ package api
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
@@ -45,11 +48,48 @@ func (m *MockSubscriptionUseCase) UpdateSubscription(ctx context.Context, id str
 	return args.Get(0).(*domain.Subscription), args.Error(1)
 }
 
+func (m *MockSubscriptionUseCase) PauseSubscription(ctx context.Context, id string, req usecase.PauseInput) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionUseCase) ResumeSubscription(ctx context.Context, id string, req usecase.ResumeInput) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionUseCase) ScheduleChange(ctx context.Context, id string, req usecase.ScheduleChangeInput) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionUseCase) DeleteScheduledChange(ctx context.Context, id, month string) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
 func (m *MockSubscriptionUseCase) DeleteSubscription(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockSubscriptionUseCase) DeleteSubscriptions(ctx context.Context, filters usecase.DeleteFiltersInput) (int64, error) {
+	args := m.Called(ctx, filters)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockSubscriptionUseCase) ListSubscriptions(ctx context.Context, filters usecase.ListFiltersInput) ([]*domain.Subscription, error) {
 	args := m.Called(ctx, filters)
 	if args.Get(0) == nil {
@@ -63,6 +103,22 @@ func (m *MockSubscriptionUseCase) GetSubscriptionsSummary(ctx context.Context, f
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockSubscriptionUseCase) GetSubscriptionsSummaryGroups(ctx context.Context, filters usecase.SummaryFiltersInput, groupBy string) ([]domain.SummaryGroup, error) {
+	args := m.Called(ctx, filters, groupBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SummaryGroup), args.Error(1)
+}
+
+func (m *MockSubscriptionUseCase) SetSubscriptionTags(ctx context.Context, id string, tags []string) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
 func TestHandler_CreateSubscription(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -77,7 +133,7 @@ func TestHandler_CreateSubscription(t *testing.T) {
 			name: "success",
 			requestBody: CreateSubscriptionRequest{
 				ServiceName: "Netflix",
-				Price:       1000,
+				Price:       intPtr(1000),
 				UserID:      "user-123",
 				StartDate:   "2024-01-01",
 				EndDate:     "2024-12-31",
@@ -85,7 +141,7 @@ func TestHandler_CreateSubscription(t *testing.T) {
 			mockSetup: func(muc *MockSubscriptionUseCase) {
 				muc.On("CreateSubscription", mock.Anything, usecase.CreateSubscriptionInput{
 					ServiceName: "Netflix",
-					Price:       1000,
+					Price:       intPtr(1000),
 					UserID:      "user-123",
 					StartDate:   "2024-01-01",
 					EndDate:     "2024-12-31",
@@ -231,6 +287,171 @@ func TestHandler_GetSubscription(t *testing.T) {
 	})
 }
 
+func TestHandler_PauseSubscription(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := &MockSubscriptionUseCase{}
+	handler := NewHandler(mockUC)
+
+	router := gin.New()
+	router.POST("/subscriptions/:id/pause", handler.PauseSubscription)
+
+	t.Run("without body", func(t *testing.T) {
+		mockUC.On("PauseSubscription", mock.Anything, "sub-123", usecase.PauseInput{}).
+			Return(&domain.Subscription{
+				ID:     "sub-123",
+				Pauses: []domain.Pause{{Start: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)}},
+			}, nil)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/subscriptions/sub-123/pause", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response)) {
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, []interface{}{map[string]interface{}{"start": "03-2025"}}, data["pauses"])
+		}
+	})
+
+	t.Run("already paused", func(t *testing.T) {
+		input := usecase.PauseInput{From: "04-2025", Until: "05-2025"}
+		mockUC.On("PauseSubscription", mock.Anything, "sub-456", input).
+			Return(nil, errors.New("subscription is already paused from 03-2025"))
+
+		body, _ := json.Marshal(PauseSubscriptionRequest{From: input.From, Until: input.Until})
+		req := httptest.NewRequest("POST", "/subscriptions/sub-456/pause", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestHandler_ScheduleChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := &MockSubscriptionUseCase{}
+	handler := NewHandler(mockUC)
+
+	router := gin.New()
+	router.POST("/subscriptions/:id/changes", handler.ScheduleChange)
+	router.DELETE("/subscriptions/:id/changes/:month", handler.DeleteScheduledChange)
+
+	t.Run("new price", func(t *testing.T) {
+		price := 500
+		mockUC.On("ScheduleChange", mock.Anything, "sub-123", usecase.ScheduleChangeInput{EffectiveFrom: "05-2025", Price: &price}).
+			Return(&domain.Subscription{
+				ID:    "sub-123",
+				Price: 400,
+				Changes: []domain.ScheduledChange{{
+					EffectiveFrom: time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC),
+					Price:         sql.NullInt64{Int64: 500, Valid: true},
+				}},
+			}, nil)
+
+		req := httptest.NewRequest("POST", "/subscriptions/sub-123/changes", strings.NewReader(`{"effective_from":"05-2025","price":500}`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response)) {
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, []interface{}{map[string]interface{}{"effective_from": "05-2025", "price": 500.0, "cancel": false}}, data["scheduled_changes"])
+		}
+	})
+
+	t.Run("missing effective_from", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/subscriptions/sub-123/changes", strings.NewReader(`{"cancel":true}`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("delete unknown change", func(t *testing.T) {
+		mockUC.On("DeleteScheduledChange", mock.Anything, "sub-123", "07-2025").
+			Return(nil, errors.New("scheduled change not found"))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("DELETE", "/subscriptions/sub-123/changes/07-2025", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestHandler_SubscriptionTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := &MockSubscriptionUseCase{}
+	handler := NewHandler(mockUC)
+
+	router := gin.New()
+	router.PUT("/subscriptions/:id/tags", handler.SetSubscriptionTags)
+	router.GET("/subscriptions/summary", handler.GetSubscriptionsSummary)
+
+	t.Run("set tags", func(t *testing.T) {
+		mockUC.On("SetSubscriptionTags", mock.Anything, "sub-123", []string{"Project X"}).
+			Return(&domain.Subscription{ID: "sub-123", Tags: []string{"project x"}}, nil)
+
+		req := httptest.NewRequest("PUT", "/subscriptions/sub-123/tags", strings.NewReader(`{"tags":["Project X"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response)) {
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, []interface{}{"project x"}, data["tags"])
+		}
+	})
+
+	t.Run("summary grouped by tag", func(t *testing.T) {
+		filters := usecase.SummaryFiltersInput{Tag: "infra", PeriodStart: "01-2025", PeriodEnd: "03-2025"}
+		mockUC.On("GetSubscriptionsSummaryGroups", mock.Anything, filters, "tag").
+			Return([]domain.SummaryGroup{{Key: "infra", Total: 300}, {Key: "project x", Total: 120}}, nil)
+		mockUC.On("GetSubscriptionsSummary", mock.Anything, filters).Return(int64(300), nil)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/subscriptions/summary?tag=infra&group_by=tag&period_start=01-2025&period_end=03-2025", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response)) {
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, 300.0, data["total"])
+			assert.Equal(t, "tag", data["group_by"])
+			assert.Len(t, data["groups"], 2)
+		}
+	})
+
+	t.Run("invalid grouping", func(t *testing.T) {
+		filters := usecase.SummaryFiltersInput{PeriodStart: "01-2025", PeriodEnd: "03-2025"}
+		mockUC.On("GetSubscriptionsSummaryGroups", mock.Anything, filters, "user").
+			Return(nil, errors.New(`invalid group_by "user": must be tag or category`))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/subscriptions/summary?group_by=user&period_start=01-2025&period_end=03-2025", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockUC.AssertExpectations(t)
+}
+
 func TestHandler_ListSubscriptions(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -290,6 +511,10 @@ func TestHandleError(t *testing.T) {
 	}{
 		{"not found", errors.New("subscription not found"), http.StatusNotFound},
 		{"invalid input", errors.New("invalid input data"), http.StatusBadRequest},
+		{"conflict", errors.New(`job "expire" is already running`), http.StatusConflict},
+		{"already paused", errors.New("subscription is already paused from 03-2025"), http.StatusConflict},
+		{"not paused", errors.New("subscription is not paused"), http.StatusConflict},
+		{"already scheduled", errors.New("a change is already scheduled for 05-2025"), http.StatusConflict},
 		{"internal error", errors.New("internal server error"), http.StatusInternalServerError},
 	}
 
@@ -308,3 +533,8 @@ func TestHandleError(t *testing.T) {
 		})
 	}
 }
+
+// Вспомогательная функция для необязательной цены
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	health *health.Health
+}
+
+// NewHealthHandler создает новый экземпляр хэндлера проверок состояния
+func NewHealthHandler(h *health.Health) *HealthHandler {
+	return &HealthHandler{
+		health: h,
+	}
+}
+
+// Liveness godoc
+// @Summary Проверка жизнеспособности
+// @Description Отвечает 200, пока процесс способен обрабатывать запросы. Зависимости не проверяются
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusOK, Checks: map[string]health.CheckResult{}})
+}
+
+// Readiness godoc
+// @Summary Проверка готовности
+// @Description Проверяет базу данных и версию миграций. Во время остановки сервиса возвращает 503
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.health.Readiness(c.Request.Context())
+	if !report.Ready() {
+		requestLogger(c).Warn("Readiness check failed", "status", report.Status, "checks", report.Checks)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
+
+// JobUseCase определяет интерфейс use case для управления фоновыми задачами
+type JobUseCase interface {
+	ListJobs(ctx context.Context) (*usecase.JobList, error)
+	ListJobRuns(ctx context.Context, req usecase.JobRunsInput) ([]*domain.JobRun, error)
+	TriggerJob(ctx context.Context, name string) (*domain.JobRun, error)
+}
+
+// JobRunResponse represents a background job run in API response
+// swagger:model JobRunResponse
+type JobRunResponse struct {
+	ID         string `json:"id"`
+	Job        string `json:"job"`
+	Trigger    string `json:"trigger"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Instance   string `json:"instance"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// JobResponse represents a scheduled background job in API response
+// swagger:model JobResponse
+type JobResponse struct {
+	Name        string          `json:"name"`
+	Schedule    string          `json:"schedule"`
+	Description string          `json:"description"`
+	NextRun     string          `json:"next_run"`
+	Running     bool            `json:"running"`
+	LastRun     *JobRunResponse `json:"last_run,omitempty"`
+}
+
+// JobListResponse represents scheduled jobs and the state of the responding instance
+// swagger:model JobListResponse
+type JobListResponse struct {
+	Instance string        `json:"instance"`
+	Leader   bool          `json:"leader"`
+	Jobs     []JobResponse `json:"jobs"`
+}
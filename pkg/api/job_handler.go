@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	jobUseCase JobUseCase
+}
+
+// NewJobHandler создает новый экземпляр хэндлера фоновых задач
+func NewJobHandler(jobUseCase JobUseCase) *JobHandler {
+	return &JobHandler{
+		jobUseCase: jobUseCase,
+	}
+}
+
+// ListJobs godoc
+// @Summary Список фоновых задач
+// @Description Возвращает задачи планировщика с расписанием, временем следующего и результатом последнего запуска
+// @Tags admin
+// @Produce json
+// @Success 200 {object} JobListResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	requestLogger(c).Info("ListJobs called")
+
+	list, err := h.jobUseCase.ListJobs(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("Failed to list jobs", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Jobs listed", "count", len(list.Jobs))
+	RespondSuccess(c, http.StatusOK, ToJobListResponse(list))
+}
+
+// ListJobRuns godoc
+// @Summary История запусков задачи
+// @Description Возвращает запуски задачи от новых к старым
+// @Tags admin
+// @Produce json
+// @Param name path string true "Имя задачи"
+// @Param limit query int false "Количество запусков (по умолчанию 20, не больше 100)"
+// @Success 200 {array} JobRunResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/jobs/{name}/runs [get]
+func (h *JobHandler) ListJobRuns(c *gin.Context) {
+	name := c.Param("name")
+	limitStr := c.DefaultQuery("limit", "0")
+
+	requestLogger(c).Info("ListJobRuns called", "job", name, "limit", limitStr)
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 0 {
+		requestLogger(c).Warn("invalid limit", "value", limitStr, "err", err)
+		RespondError(c, http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	runs, err := h.jobUseCase.ListJobRuns(c.Request.Context(), usecase.JobRunsInput{Job: name, Limit: limit})
+	if err != nil {
+		requestLogger(c).Error("Failed to list job runs", "job", name, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	responses := make([]JobRunResponse, 0, len(runs))
+	for _, run := range runs {
+		responses = append(responses, ToJobRunResponse(run))
+	}
+
+	requestLogger(c).Info("Job runs listed", "job", name, "count", len(responses))
+	RespondSuccess(c, http.StatusOK, responses)
+}
+
+// TriggerJob godoc
+// @Summary Запустить задачу
+// @Description Запускает задачу вне расписания. Задача выполняется в фоне, результат появляется в истории запусков
+// @Tags admin
+// @Produce json
+// @Param name path string true "Имя задачи"
+// @Success 202 {object} JobRunResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/jobs/{name}/run [post]
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	name := c.Param("name")
+	requestLogger(c).Info("TriggerJob called", "job", name)
+
+	run, err := h.jobUseCase.TriggerJob(c.Request.Context(), name)
+	if err != nil {
+		requestLogger(c).Error("Failed to trigger job", "job", name, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Job triggered", "job", name, "run_id", run.ID)
+	RespondSuccess(c, http.StatusAccepted, ToJobRunResponse(run))
+}
@@ -1,6 +1,9 @@
 package api
 
-import "github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+import (
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
 
 func ToSubscriptionResponse(s *domain.Subscription) SubscriptionResponse {
 	var endDate string
@@ -8,15 +11,232 @@ func ToSubscriptionResponse(s *domain.Subscription) SubscriptionResponse {
 		endDate = s.EndDate.Time.Format("01-2006")
 	}
 
+	var trialEndsAt string
+	if end, ok := s.TrialEndsAt(); ok {
+		trialEndsAt = end.Format("2006-01-02")
+	}
+
+	pauses := make([]PauseResponse, 0, len(s.Pauses))
+	for _, p := range s.Pauses {
+		pause := PauseResponse{Start: p.Start.Format("01-2006")}
+		if p.End.Valid {
+			pause.End = p.End.Time.Format("01-2006")
+		}
+		pauses = append(pauses, pause)
+	}
+
+	changes := make([]ScheduledChangeResponse, 0, len(s.Changes))
+	for _, c := range s.Changes {
+		change := ScheduledChangeResponse{EffectiveFrom: c.EffectiveFrom.Format("01-2006"), Cancel: c.Cancel}
+		if c.Price.Valid {
+			change.Price = &c.Price.Int64
+		}
+		if c.Plan.Valid {
+			change.Plan = &c.Plan.String
+		}
+		changes = append(changes, change)
+	}
+
+	history := make([]PricePeriodResponse, 0, len(s.PriceHistory))
+	for _, p := range s.PriceHistory {
+		history = append(history, PricePeriodResponse{Until: p.Until.Format("01-2006"), Price: p.Price})
+	}
+
+	tags := s.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
 	return SubscriptionResponse{
-		ID:          s.ID,
-		ServiceName: s.ServiceName,
-		Price:       s.Price,
-		Currency:    "RUB",
-		UserID:      s.UserID,
-		StartDate:   s.StartDate.Format("01-2006"),
-		EndDate:     endDate,
-		CreatedAt:   s.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:   s.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:               s.ID,
+		OrganizationID:   s.OrganizationID,
+		ServiceName:      s.ServiceName,
+		ServiceID:        s.ServiceID,
+		Plan:             s.Plan,
+		Price:            s.Price,
+		Currency:         "RUB",
+		UserID:           s.UserID,
+		StartDate:        s.StartDate.Format("01-2006"),
+		EndDate:          endDate,
+		TrialMonths:      s.TrialMonths,
+		TrialEndsAt:      trialEndsAt,
+		IntroPrice:       s.IntroPrice,
+		IntroMonths:      s.IntroMonths,
+		Pauses:           pauses,
+		ScheduledChanges: changes,
+		PriceHistory:     history,
+		Tags:             tags,
+		Status:           s.Status,
+		CreatedAt:        s.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        s.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func ToAPIKeyResponse(k *domain.APIKey) APIKeyResponse {
+	const layout = "2006-01-02 15:04:05"
+
+	resp := APIKeyResponse{
+		ID:             k.ID,
+		OrganizationID: k.OrganizationID,
+		Name:           k.Name,
+		Prefix:         k.Prefix,
+		Scopes:         k.Scopes,
+		CreatedAt:      k.CreatedAt.Format(layout),
+	}
+	if k.ExpiresAt.Valid {
+		resp.ExpiresAt = k.ExpiresAt.Time.Format(layout)
+	}
+	if k.RevokedAt.Valid {
+		resp.RevokedAt = k.RevokedAt.Time.Format(layout)
+	}
+	if k.LastUsedAt.Valid {
+		resp.LastUsedAt = k.LastUsedAt.Time.Format(layout)
+	}
+
+	return resp
+}
+
+func ToJobRunResponse(r *domain.JobRun) JobRunResponse {
+	const layout = "2006-01-02 15:04:05"
+
+	resp := JobRunResponse{
+		ID:        r.ID,
+		Job:       r.Job,
+		Trigger:   r.Trigger,
+		Status:    r.Status,
+		Error:     r.Error,
+		Instance:  r.Instance,
+		StartedAt: r.StartedAt.Format(layout),
+	}
+	if r.FinishedAt.Valid {
+		resp.FinishedAt = r.FinishedAt.Time.Format(layout)
+	}
+
+	return resp
+}
+
+func ToJobListResponse(l *usecase.JobList) JobListResponse {
+	const layout = "2006-01-02 15:04:05"
+
+	resp := JobListResponse{
+		Instance: l.Instance,
+		Leader:   l.Leader,
+		Jobs:     make([]JobResponse, 0, len(l.Jobs)),
+	}
+	for _, job := range l.Jobs {
+		j := JobResponse{
+			Name:        job.Name,
+			Schedule:    job.Schedule,
+			Description: job.Description,
+			NextRun:     job.Next.Format(layout),
+			Running:     job.Running,
+		}
+		if job.LastRun != nil {
+			lastRun := ToJobRunResponse(job.LastRun)
+			j.LastRun = &lastRun
+		}
+		resp.Jobs = append(resp.Jobs, j)
+	}
+
+	return resp
+}
+
+func ToReminderPreferencesResponse(p *domain.ReminderPreferences) ReminderPreferencesResponse {
+	resp := ReminderPreferencesResponse{
+		UserID:  p.UserID,
+		Email:   p.Email,
+		Renewal: p.Renewal,
+		Expiry:  p.Expiry,
+	}
+	if !p.UpdatedAt.IsZero() {
+		resp.UpdatedAt = p.UpdatedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return resp
+}
+
+func ToBudgetResponse(b *domain.Budget) BudgetResponse {
+	return BudgetResponse{
+		ID:             b.ID,
+		OrganizationID: b.OrganizationID,
+		Name:           b.Name,
+		Period:         b.Period,
+		Limit:          b.Limit,
+		Currency:       "RUB",
+		Scope:          b.Scope(),
+		UserID:         b.UserID,
+		ServiceName:    b.ServiceName,
+		Thresholds:     b.Thresholds,
+		Email:          b.Email,
+		CreatedAt:      b.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      b.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func ToBudgetStatusResponse(s *domain.BudgetStatus) BudgetStatusResponse {
+	reached := s.Reached()
+	if reached == nil {
+		reached = []int{}
+	}
+
+	return BudgetStatusResponse{
+		Budget:      ToBudgetResponse(s.Budget),
+		PeriodStart: s.PeriodStart.Format("01-2006"),
+		PeriodEnd:   s.PeriodEnd.Format("01-2006"),
+		Spent:       s.Spent,
+		Remaining:   s.Remaining(),
+		Percent:     s.Percent(),
+		Reached:     reached,
+		Exceeded:    s.Exceeded(),
+	}
+}
+
+func ToServiceResponse(s *domain.Service) ServiceResponse {
+	aliases := s.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	plans := make([]ServicePlanResponse, 0, len(s.Plans))
+	for _, p := range s.Plans {
+		plans = append(plans, ServicePlanResponse{Name: p.Name, Price: p.Price, Currency: "RUB"})
+	}
+
+	return ServiceResponse{
+		ID:             s.ID,
+		OrganizationID: s.OrganizationID,
+		Name:           s.Name,
+		Aliases:        aliases,
+		Category:       s.Category,
+		VendorURL:      s.VendorURL,
+		Plans:          plans,
+		CreatedAt:      s.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      s.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func ToSummaryGroupResponses(groups []domain.SummaryGroup) []SummaryGroupResponse {
+	responses := make([]SummaryGroupResponse, 0, len(groups))
+	for _, g := range groups {
+		responses = append(responses, SummaryGroupResponse{Key: g.Key, Total: g.Total})
+	}
+	return responses
+}
+
+func ToTagResponse(t *domain.Tag) TagResponse {
+	return TagResponse{
+		ID:             t.ID,
+		OrganizationID: t.OrganizationID,
+		Name:           t.Name,
+		Subscriptions:  t.Subscriptions,
+		CreatedAt:      t.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func ToOrganizationResponse(o *domain.Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt.Format("2006-01-02 15:04:05"),
 	}
 }
@@ -0,0 +1,347 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/metrics"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/ratelimit"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину идентификатора запроса, принятого от клиента
+const maxRequestIDLength = 128
+
+// RequestLoggerMiddleware присваивает запросу идентификатор (или берет его из X-Request-ID),
+// кладет в контекст логгер с идентификатором и маршрутом и по завершении пишет строку о запросе
+// со статусом и длительностью
+func RequestLoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		route := c.FullPath()
+		logger := slog.Default().With("request_id", requestID, "method", c.Request.Method, "route", route)
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			logger = logger.With("trace_id", span.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.Log(c.Request.Context(), level, "HTTP request",
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"size", c.Writer.Size(),
+		)
+	}
+}
+
+// clientCertPrincipal возвращает вызывающего по клиентскому сертификату, прошедшему проверку при TLS рукопожатии
+func clientCertPrincipal(c *gin.Context) *auth.Principal {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return auth.PrincipalFromCertificate(state.VerifiedChains[0][0])
+}
+
+// requestLogger возвращает логгер текущего запроса
+func requestLogger(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context())
+}
+
+// validRequestID проверяет идентификатор запроса от клиента: непустой, ограниченной длины, из печатных ASCII символов
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID генерирует случайный идентификатор запроса
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AuthMiddleware проверяет токен из заголовка X-API-Key или Authorization и кладет вызывающего в контекст запроса
+// Если токена нет, а соединение установлено с проверенным клиентским сертификатом (mTLS),
+// вызывающий определяется по сертификату
+func AuthMiddleware(authenticator auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := requestToken(c)
+		if !ok {
+			if principal := clientCertPrincipal(c); principal != nil {
+				c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+				c.Next()
+				return
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="subscriptions"`)
+			RespondError(c, http.StatusUnauthorized, "missing bearer token")
+			c.Abort()
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			requestLogger(c).Warn("Authentication failed", "error", err)
+			c.Header("WWW-Authenticate", `Bearer realm="subscriptions", error="invalid_token"`)
+			RespondError(c, http.StatusUnauthorized, "invalid token")
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// TenantMiddleware определяет организацию (арендатора) запроса и кладет её в контекст
+// Организация берется из токена; заголовок X-Organization-ID учитывается, только если токен её не задает,
+// а вызывающий - администратор или аутентификация отключена. Иначе используется организация по умолчанию.
+// Запросы к неизвестной организации отклоняются с 403; nil organizations отключает эту проверку
+func TenantMiddleware(organizations OrganizationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := strings.TrimSpace(c.GetHeader("X-Organization-ID"))
+		if header != "" && !utils.IsUUID(header) {
+			RespondError(c, http.StatusBadRequest, "invalid X-Organization-ID")
+			c.Abort()
+			return
+		}
+
+		organizationID := domain.DefaultOrganizationID
+		principal, authenticated := auth.PrincipalFromContext(c.Request.Context())
+
+		switch {
+		case authenticated && principal.OrganizationID != "":
+			if header != "" && !strings.EqualFold(header, principal.OrganizationID) {
+				RespondError(c, http.StatusForbidden, "forbidden: organization does not match credentials")
+				c.Abort()
+				return
+			}
+			organizationID = principal.OrganizationID
+		case header != "" && (!authenticated || principal.IsAdmin()):
+			organizationID = header
+		case header != "":
+			RespondError(c, http.StatusForbidden, "forbidden: organization cannot be selected by this caller")
+			c.Abort()
+			return
+		}
+
+		if !utils.IsUUID(organizationID) {
+			RespondError(c, http.StatusForbidden, "forbidden: invalid organization in credentials")
+			c.Abort()
+			return
+		}
+		if organizations != nil {
+			exists, err := organizations.OrganizationExists(c.Request.Context(), organizationID)
+			if err != nil {
+				requestLogger(c).Error("Failed to check organization", "organization_id", organizationID, "error", err)
+				RespondError(c, http.StatusInternalServerError, "internal server error")
+				c.Abort()
+				return
+			}
+			if !exists {
+				RespondError(c, http.StatusForbidden, "forbidden: unknown organization")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Request = c.Request.WithContext(domain.WithOrganization(c.Request.Context(), organizationID))
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware ограничивает частоту запросов по квотам маршрутов
+// Аутентифицированные вызывающие (пользователи и API ключи) учитываются по субъекту, остальные - по IP
+func RateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+
+		caller := "ip:" + c.ClientIP()
+		if principal, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+			caller = "sub:" + principal.Subject
+		}
+
+		res, limited, err := limiter.Allow(c.Request.Context(), route, caller)
+		if err != nil {
+			// Недоступность хранилища квот не должна останавливать сервис
+			requestLogger(c).Error("Rate limit store failed", "route", route, "error", err)
+			c.Next()
+			return
+		}
+		if !limited {
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, res)
+
+		if !res.Allowed {
+			requestLogger(c).Warn("Rate limit exceeded", "route", route, "caller", caller)
+			rejectRateLimited(c, res)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// IPRateLimitMiddleware применяет общую квоту клиентского IP до аутентификации
+// Заголовки квоты выставляются только при отказе: у успешных запросов их задает RateLimitMiddleware
+func IPRateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+
+		res, limited, err := limiter.AllowIP(c.Request.Context(), ip)
+		if err != nil {
+			requestLogger(c).Error("Rate limit store failed", "ip", ip, "error", err)
+			c.Next()
+			return
+		}
+		if limited && !res.Allowed {
+			requestLogger(c).Warn("Rate limit exceeded", "ip", ip)
+			setRateLimitHeaders(c, res)
+			rejectRateLimited(c, res)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders выставляет заголовки RateLimit-* по результату квоты
+func setRateLimitHeaders(c *gin.Context, res ratelimit.Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+}
+
+// rejectRateLimited отвечает 429 с Retry-After и прерывает обработку
+func rejectRateLimited(c *gin.Context, res ratelimit.Result) {
+	c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+	RespondError(c, http.StatusTooManyRequests, "rate limit exceeded")
+	c.Abort()
+}
+
+// WriteTrackingMiddleware отмечает контекст запроса для read-your-writes:
+// после записи остальные чтения запроса идут в основную базу, а не в реплику
+func WriteTrackingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(domain.WithWriteTracking(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// MetricsMiddleware учитывает длительность и статус каждого запроса
+// Запросы к несуществующим маршрутам учитываются под единым шаблоном, чтобы не плодить серии
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// TracingMiddleware открывает серверный спан на каждый запрос
+// Контекст трассировки берется из заголовка traceparent (W3C), если он передан
+func TracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer("github.com/asgard-born/rest_service_subscriptions/pkg/api")
+
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method
+		}
+
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// requestToken извлекает API ключ из X-API-Key, а при его отсутствии - токен из Authorization
+func requestToken(c *gin.Context) (string, bool) {
+	if key := strings.TrimSpace(c.GetHeader("X-API-Key")); key != "" {
+		return key, true
+	}
+	return bearerToken(c.GetHeader("Authorization"))
+}
+
+// bearerToken извлекает токен из значения заголовка Authorization
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
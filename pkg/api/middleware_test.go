@@ -0,0 +1,408 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// staticAuthenticator принимает единственный токен и возвращает заданного вызывающего
+type staticAuthenticator struct {
+	token     string
+	principal *auth.Principal
+}
+
+func (a staticAuthenticator) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	if token != a.token {
+		return nil, errors.New("unauthorized")
+	}
+	return a.principal, nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticator := staticAuthenticator{token: "good", principal: &auth.Principal{Subject: "u1", UserID: "u1"}}
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{"missing token", nil, http.StatusUnauthorized},
+		{"invalid token", map[string]string{"Authorization": "Bearer bad"}, http.StatusUnauthorized},
+		{"bearer token", map[string]string{"Authorization": "Bearer good"}, http.StatusOK},
+		{"api key header", map[string]string{"X-API-Key": "good"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/test", AuthMiddleware(authenticator), func(c *gin.Context) {
+				p, _ := auth.PrincipalFromContext(c.Request.Context())
+				c.String(http.StatusOK, p.UserID)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_ClientCertificate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticator := staticAuthenticator{token: "good", principal: &auth.Principal{Subject: "u1", UserID: "u1"}}
+	router := gin.New()
+	router.GET("/test", AuthMiddleware(authenticator), func(c *gin.Context) {
+		p, _ := auth.PrincipalFromContext(c.Request.Context())
+		c.String(http.StatusOK, p.Subject)
+	})
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}}
+	do := func(state *tls.ConnectionState, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.TLS = state
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "cert:billing", rr.Body.String())
+
+	// Токен имеет приоритет над сертификатом
+	rr = do(&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}, "good")
+	assert.Equal(t, "u1", rr.Body.String())
+
+	// Непроверенный сертификат не аутентифицирует
+	rr = do(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const orgA = "11111111-1111-1111-1111-111111111111"
+	const orgB = "22222222-2222-2222-2222-222222222222"
+
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		header         string
+		expectedStatus int
+		expectedOrg    string
+	}{
+		{"no auth and no header uses default", nil, "", http.StatusOK, domain.DefaultOrganizationID},
+		{"no auth uses header", nil, orgA, http.StatusOK, orgA},
+		{"token organization", &auth.Principal{Subject: "u", OrganizationID: orgA}, "", http.StatusOK, orgA},
+		{"header matching token", &auth.Principal{Subject: "u", OrganizationID: orgA}, orgA, http.StatusOK, orgA},
+		{"header not matching token", &auth.Principal{Subject: "u", OrganizationID: orgA}, orgB, http.StatusForbidden, ""},
+		{"admin without organization selects one", &auth.Principal{Subject: "a", Roles: []string{auth.RoleAdmin}}, orgB, http.StatusOK, orgB},
+		{"user without organization cannot select one", &auth.Principal{Subject: "u"}, orgB, http.StatusForbidden, ""},
+		{"invalid header", nil, "not-a-uuid", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/test", func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), tt.principal))
+				}
+				c.Next()
+			}, TenantMiddleware(nil), func(c *gin.Context) {
+				c.String(http.StatusOK, domain.OrganizationFromContext(c.Request.Context()))
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Organization-ID", tt.header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedOrg, rr.Body.String())
+			}
+		})
+	}
+}
+
+// stubOrganizations организации, известные TenantMiddleware в тестах
+type stubOrganizations map[string]bool
+
+func (s stubOrganizations) OrganizationExists(_ context.Context, id string) (bool, error) {
+	if id == brokenOrganizationID {
+		return false, errors.New("connection refused")
+	}
+	return s[id], nil
+}
+
+const brokenOrganizationID = "33333333-3333-3333-3333-333333333333"
+
+func TestTenantMiddleware_UnknownOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const known = "11111111-1111-1111-1111-111111111111"
+	const unknown = "22222222-2222-2222-2222-222222222222"
+	organizations := stubOrganizations{domain.DefaultOrganizationID: true, known: true}
+
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		header         string
+		expectedStatus int
+	}{
+		{"default organization", nil, "", http.StatusOK},
+		{"known token organization", &auth.Principal{Subject: "u", OrganizationID: known}, "", http.StatusOK},
+		{"unknown token organization", &auth.Principal{Subject: "u", OrganizationID: unknown}, "", http.StatusForbidden},
+		{"token organization is not a uuid", &auth.Principal{Subject: "u", OrganizationID: "acme"}, "", http.StatusForbidden},
+		{"admin selects unknown organization", &auth.Principal{Subject: "a", Roles: []string{auth.RoleAdmin}}, unknown, http.StatusForbidden},
+		{"lookup failure", &auth.Principal{Subject: "u", OrganizationID: brokenOrganizationID}, "", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/test", func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), tt.principal))
+				}
+				c.Next()
+			}, TenantMiddleware(organizations), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Organization-ID", tt.header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{
+		Routes: map[string]ratelimit.Limit{
+			"GET /subscriptions/summary": {Rate: 0.1, Burst: 1},
+		},
+	})
+
+	router := gin.New()
+	router.Use(RateLimitMiddleware(limiter))
+	router.GET("/subscriptions/summary", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/subscriptions/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = ip + ":1234"
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("/subscriptions/summary", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+	rr = do("/subscriptions/summary", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+
+	// Квота считается отдельно для каждого IP
+	rr = do("/subscriptions/summary", "10.0.0.2")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Для маршрута без квоты заголовки не выставляются
+	rr = do("/subscriptions/abc", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+}
+
+func TestIPRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{
+		PerIP: ratelimit.Limit{Rate: 0.1, Burst: 2},
+	})
+
+	// Квота по IP расходуется и запросами, отклоненными аутентификацией
+	router := gin.New()
+	router.Use(IPRateLimitMiddleware(limiter))
+	router.GET("/subscriptions/:id", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+
+	do := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = ip + ":1234"
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("/subscriptions/a", "10.0.0.1")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, http.StatusUnauthorized, do("/subscriptions/b", "10.0.0.1").Code)
+
+	rr = do("/subscriptions/c", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusUnauthorized, do("/subscriptions/c", "10.0.0.2").Code)
+}
+
+func TestRouter_ClientIPIgnoresUntrustedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(trusted []string) *gin.Engine {
+		mockUC := &MockSubscriptionUseCase{}
+		mockUC.On("GetSubscription", mock.Anything, mock.Anything).Return(nil, errors.New("subscription not found"))
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{
+			Default: ratelimit.Limit{Rate: 100, Burst: 100},
+			PerIP:   ratelimit.Limit{Rate: 0.1, Burst: 2},
+		})
+		return CreateNewRouter(mockUC, RouterOptions{RateLimiter: limiter, TrustedProxies: trusted})
+	}
+	do := func(router *gin.Engine, forwardedFor string) int {
+		req := httptest.NewRequest("GET", "/subscriptions/abc", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("spoofed header from untrusted peer", func(t *testing.T) {
+		router := newRouter(nil)
+		assert.Equal(t, http.StatusNotFound, do(router, "203.0.113.1"))
+		assert.Equal(t, http.StatusNotFound, do(router, "203.0.113.2"))
+		assert.Equal(t, http.StatusTooManyRequests, do(router, "203.0.113.3"))
+	})
+
+	t.Run("header from trusted proxy", func(t *testing.T) {
+		router := newRouter([]string{"10.0.0.0/8"})
+		assert.Equal(t, http.StatusNotFound, do(router, "203.0.113.1"))
+		assert.Equal(t, http.StatusNotFound, do(router, "203.0.113.2"))
+		assert.Equal(t, http.StatusNotFound, do(router, "203.0.113.3"))
+	})
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	router := gin.New()
+	router.Use(TracingMiddleware())
+	router.GET("/subscriptions/:id", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	req := httptest.NewRequest("GET", "/subscriptions/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /subscriptions/:id", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestRequestLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	router := gin.New()
+	router.Use(RequestLoggerMiddleware())
+	router.GET("/subscriptions/:id", func(c *gin.Context) {
+		requestLogger(c).Info("handler called")
+		c.Status(http.StatusNotFound)
+	})
+
+	t.Run("propagates request id", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest("GET", "/subscriptions/abc", nil)
+		req.Header.Set(RequestIDHeader, "req-123")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, "req-123", rr.Header().Get(RequestIDHeader))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		for _, line := range lines {
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			assert.Equal(t, "req-123", entry["request_id"])
+			assert.Equal(t, "/subscriptions/:id", entry["route"])
+		}
+
+		var summary map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &summary))
+		assert.Equal(t, "HTTP request", summary["msg"])
+		assert.Equal(t, float64(http.StatusNotFound), summary["status"])
+		assert.Contains(t, summary, "latency")
+	})
+
+	t.Run("generates request id", func(t *testing.T) {
+		for _, header := range []string{"", "bad id with spaces", strings.Repeat("a", maxRequestIDLength+1)} {
+			req := httptest.NewRequest("GET", "/subscriptions/abc", nil)
+			if header != "" {
+				req.Header.Set(RequestIDHeader, header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Len(t, rr.Header().Get(RequestIDHeader), 32)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
+
+// OrganizationChecker проверяет, что организация запроса существует
+type OrganizationChecker interface {
+	OrganizationExists(ctx context.Context, id string) (bool, error)
+}
+
+// OrganizationUseCase определяет интерфейс use case для учета организаций платформы
+type OrganizationUseCase interface {
+	OrganizationChecker
+	CreateOrganization(ctx context.Context, req usecase.CreateOrganizationInput) (*domain.Organization, error)
+	ListOrganizations(ctx context.Context) ([]*domain.Organization, error)
+}
+
+// OrganizationRequest represents a new organization
+// swagger:model OrganizationRequest
+type OrganizationRequest struct {
+	ID   string `json:"id,omitempty"` // organization ID used in the org_id claim of tokens; generated when empty
+	Name string `json:"name" binding:"required"`
+}
+
+// OrganizationResponse represents an organization (tenant) in API response
+// swagger:model OrganizationResponse
+type OrganizationResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	organizationUseCase OrganizationUseCase
+}
+
+// NewOrganizationHandler создает новый экземпляр хэндлера организаций
+func NewOrganizationHandler(organizationUseCase OrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationUseCase: organizationUseCase,
+	}
+}
+
+// CreateOrganization godoc
+// @Summary Создать организацию
+// @Description Регистрирует организацию (арендатора). Запросы с организацией, которой нет в списке, отклоняются с 403. Требует права platform:organizations
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param organization body OrganizationRequest true "Организация: название и, если нужно, ID из claim org_id токенов"
+// @Success 201 {object} OrganizationResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	requestLogger(c).Info("CreateOrganization called")
+
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationUseCase.CreateOrganization(c.Request.Context(), usecase.CreateOrganizationInput{
+		ID:   req.ID,
+		Name: req.Name,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to create organization", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Organization created", "id", org.ID, "name", org.Name)
+	RespondSuccess(c, http.StatusCreated, ToOrganizationResponse(org))
+}
+
+// ListOrganizations godoc
+// @Summary Список организаций
+// @Description Возвращает организации платформы по названию. Требует права platform:organizations
+// @Tags admin
+// @Produce json
+// @Success 200 {array} OrganizationResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	requestLogger(c).Info("ListOrganizations called")
+
+	orgs, err := h.organizationUseCase.ListOrganizations(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("Failed to list organizations", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	responses := make([]OrganizationResponse, 0, len(orgs))
+	for _, org := range orgs {
+		responses = append(responses, ToOrganizationResponse(org))
+	}
+
+	RespondSuccess(c, http.StatusOK, responses)
+}
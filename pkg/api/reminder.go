@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
+
+// ReminderUseCase определяет интерфейс use case для настроек напоминаний
+type ReminderUseCase interface {
+	GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error)
+	UpdatePreferences(ctx context.Context, userID string, req usecase.UpdateReminderPreferencesInput) (*domain.ReminderPreferences, error)
+}
+
+// UpdateReminderPreferencesRequest represents changes of reminder preferences; omitted fields keep their values
+// swagger:model UpdateReminderPreferencesRequest
+type UpdateReminderPreferencesRequest struct {
+	// Email address for email reminders; an empty string removes it
+	Email   *string `json:"email,omitempty"`
+	Renewal *bool   `json:"renewal,omitempty"`
+	Expiry  *bool   `json:"expiry,omitempty"`
+}
+
+// ReminderPreferencesResponse represents reminder preferences of a user in API response
+// swagger:model ReminderPreferencesResponse
+type ReminderPreferencesResponse struct {
+	UserID  string `json:"user_id"`
+	Email   string `json:"email"`
+	Renewal bool   `json:"renewal"`
+	Expiry  bool   `json:"expiry"`
+	// UpdatedAt is omitted until the user changes the defaults
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
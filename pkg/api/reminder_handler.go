@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ReminderHandler struct {
+	reminderUseCase ReminderUseCase
+}
+
+// NewReminderHandler создает новый экземпляр хэндлера настроек напоминаний
+func NewReminderHandler(reminderUseCase ReminderUseCase) *ReminderHandler {
+	return &ReminderHandler{
+		reminderUseCase: reminderUseCase,
+	}
+}
+
+// GetReminderPreferences godoc
+// @Summary Получить настройки напоминаний
+// @Description Возвращает адрес и включенные напоминания пользователя. Пока пользователь их не менял, включены все напоминания
+// @Tags reminders
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Success 200 {object} ReminderPreferencesResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /users/{user_id}/reminder-preferences [get]
+func (h *ReminderHandler) GetReminderPreferences(c *gin.Context) {
+	requestLogger(c).Info("GetReminderPreferences called")
+
+	userID := c.Param("user_id")
+	prefs, err := h.reminderUseCase.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Failed to get reminder preferences", "user_id", userID, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	RespondSuccess(c, http.StatusOK, ToReminderPreferencesResponse(prefs))
+}
+
+// UpdateReminderPreferences godoc
+// @Summary Изменить настройки напоминаний
+// @Description Меняет адрес для писем и включает или отключает напоминания о продлении (renewal) и окончании (expiry) подписок. Непереданные поля не меняются
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Param preferences body UpdateReminderPreferencesRequest true "Изменения настроек"
+// @Success 200 {object} ReminderPreferencesResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /users/{user_id}/reminder-preferences [put]
+func (h *ReminderHandler) UpdateReminderPreferences(c *gin.Context) {
+	requestLogger(c).Info("UpdateReminderPreferences called")
+
+	var req UpdateReminderPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	userID := c.Param("user_id")
+	prefs, err := h.reminderUseCase.UpdatePreferences(c.Request.Context(), userID, usecase.UpdateReminderPreferencesInput{
+		Email:   req.Email,
+		Renewal: req.Renewal,
+		Expiry:  req.Expiry,
+	})
+	if err != nil {
+		requestLogger(c).Error("Failed to update reminder preferences", "user_id", userID, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Reminder preferences updated", "user_id", prefs.UserID, "renewal", prefs.Renewal, "expiry", prefs.Expiry)
+	RespondSuccess(c, http.StatusOK, ToReminderPreferencesResponse(prefs))
+}
@@ -1,30 +1,176 @@
 package api
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/health"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/metrics"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// RouterOptions содержит необязательные зависимости роутера
+type RouterOptions struct {
+	// Authenticator проверяет токены запросов к API; nil отключает аутентификацию
+	Authenticator auth.Authenticator
+	// APIKeyUseCase включает административные эндпоинты управления API ключами
+	APIKeyUseCase APIKeyUseCase
+	// JobUseCase включает административные эндпоинты фоновых задач
+	JobUseCase JobUseCase
+	// OrganizationUseCase включает административные эндпоинты организаций и отклонение запросов к неизвестным организациям
+	OrganizationUseCase OrganizationUseCase
+	// ReminderUseCase включает эндпоинты настроек напоминаний пользователей
+	ReminderUseCase ReminderUseCase
+	// BudgetUseCase включает эндпоинты бюджетов
+	BudgetUseCase BudgetUseCase
+	// ServiceUseCase включает эндпоинты каталога сервисов
+	ServiceUseCase ServiceUseCase
+	// TagUseCase включает эндпоинты управления тегами
+	TagUseCase TagUseCase
+	// TrustedProxies адреса прокси, чьему X-Forwarded-For доверяем при определении IP клиента; пустой список означает RemoteAddr
+	TrustedProxies []string
+	// RateLimiter ограничивает частоту запросов к API; nil отключает ограничение
+	RateLimiter *ratelimit.Limiter
+	// Metrics включает учет HTTP запросов; /metrics отдается отдельным внутренним сервером, а не этим роутером
+	Metrics *metrics.Metrics
+	// Health включает эндпоинты /healthz и /readyz
+	Health *health.Health
+	// Tracing включает серверные спаны OpenTelemetry для каждого запроса
+	Tracing bool
+	// Swagger включает Swagger UI по адресу /swagger
+	Swagger bool
+}
+
 // CreateNewRouter создает новый роутер с инициализированными хэндлерами
-func CreateNewRouter(subscriptionUseCase SubscriptionUseCase) *gin.Engine {
+func CreateNewRouter(subscriptionUseCase SubscriptionUseCase, opts RouterOptions) *gin.Engine {
 	h := NewHandler(subscriptionUseCase)
 
 	router := gin.New()
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	// Без явного списка gin доверяет X-Forwarded-For от любого источника, и клиент может подменить свой IP
+	if err := router.SetTrustedProxies(opts.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("invalid trusted proxies: %v", err))
+	}
+	if opts.Tracing {
+		router.Use(TracingMiddleware())
+	}
+	router.Use(RequestLoggerMiddleware())
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, recoverPanic))
+	if opts.Metrics != nil {
+		router.Use(MetricsMiddleware(opts.Metrics))
+	}
 
-	subscriptions := router.Group("/subscriptions")
+	subscriptions := router.Group("/subscriptions", apiMiddlewares(opts)...)
 	{
 		subscriptions.POST("/", h.CreateSubscription)
 		subscriptions.GET("/:id", h.GetSubscription)
 		subscriptions.PUT("/:id", h.UpdateSubscription)
+		subscriptions.POST("/:id/pause", h.PauseSubscription)
+		subscriptions.POST("/:id/resume", h.ResumeSubscription)
+		subscriptions.POST("/:id/changes", h.ScheduleChange)
+		subscriptions.DELETE("/:id/changes/:month", h.DeleteScheduledChange)
+		subscriptions.PUT("/:id/tags", h.SetSubscriptionTags)
 		subscriptions.DELETE("/:id", h.DeleteSubscription)
+		subscriptions.DELETE("/", h.DeleteSubscriptions)
 		subscriptions.GET("/", h.ListSubscriptions)
 		subscriptions.GET("/summary", h.GetSubscriptionsSummary)
 	}
 
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if opts.ReminderUseCase != nil {
+		reminders := NewReminderHandler(opts.ReminderUseCase)
+		users := router.Group("/users", apiMiddlewares(opts)...)
+		users.GET("/:user_id/reminder-preferences", reminders.GetReminderPreferences)
+		users.PUT("/:user_id/reminder-preferences", reminders.UpdateReminderPreferences)
+	}
+
+	if opts.BudgetUseCase != nil {
+		h := NewBudgetHandler(opts.BudgetUseCase)
+		budgets := router.Group("/budgets", apiMiddlewares(opts)...)
+		budgets.POST("/", h.CreateBudget)
+		budgets.GET("/", h.ListBudgets)
+		budgets.GET("/:id", h.GetBudget)
+		budgets.PUT("/:id", h.UpdateBudget)
+		budgets.DELETE("/:id", h.DeleteBudget)
+		budgets.GET("/:id/status", h.GetBudgetStatus)
+	}
+
+	if opts.ServiceUseCase != nil {
+		h := NewServiceHandler(opts.ServiceUseCase)
+		services := router.Group("/services", apiMiddlewares(opts)...)
+		services.POST("/", h.CreateService)
+		services.GET("/", h.ListServices)
+		services.GET("/:id", h.GetService)
+		services.PUT("/:id", h.UpdateService)
+		services.DELETE("/:id", h.DeleteService)
+	}
+
+	if opts.TagUseCase != nil {
+		h := NewTagHandler(opts.TagUseCase)
+		tags := router.Group("/tags", apiMiddlewares(opts)...)
+		tags.POST("/", h.CreateTag)
+		tags.GET("/", h.ListTags)
+		tags.PUT("/:id", h.RenameTag)
+		tags.DELETE("/:id", h.DeleteTag)
+	}
+
+	admin := router.Group("/admin", apiMiddlewares(opts)...)
+	if opts.APIKeyUseCase != nil {
+		keys := NewAPIKeyHandler(opts.APIKeyUseCase)
+		admin.POST("/api-keys", keys.CreateAPIKey)
+		admin.GET("/api-keys", keys.ListAPIKeys)
+		admin.DELETE("/api-keys/:id", keys.RevokeAPIKey)
+	}
+	if opts.JobUseCase != nil {
+		jobs := NewJobHandler(opts.JobUseCase)
+		admin.GET("/jobs", jobs.ListJobs)
+		admin.GET("/jobs/:name/runs", jobs.ListJobRuns)
+		admin.POST("/jobs/:name/run", jobs.TriggerJob)
+	}
+	if opts.OrganizationUseCase != nil {
+		orgs := NewOrganizationHandler(opts.OrganizationUseCase)
+		admin.POST("/organizations", orgs.CreateOrganization)
+		admin.GET("/organizations", orgs.ListOrganizations)
+	}
+
+	if opts.Health != nil {
+		probes := NewHealthHandler(opts.Health)
+		router.GET("/healthz", probes.Liveness)
+		router.GET("/readyz", probes.Readiness)
+	}
+
+	if opts.Swagger {
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
 
 	return router
 }
+
+// apiMiddlewares возвращает цепочку middleware для эндпоинтов API:
+// аутентификация, определение организации и ограничение частоты запросов
+func apiMiddlewares(opts RouterOptions) []gin.HandlerFunc {
+	var chain []gin.HandlerFunc
+	// Квота по IP проверяется до аутентификации, поэтому 401 не обходит ограничение
+	if opts.RateLimiter != nil {
+		chain = append(chain, IPRateLimitMiddleware(opts.RateLimiter))
+	}
+	if opts.Authenticator != nil {
+		chain = append(chain, AuthMiddleware(opts.Authenticator))
+	}
+	chain = append(chain, TenantMiddleware(opts.OrganizationUseCase), WriteTrackingMiddleware())
+	if opts.RateLimiter != nil {
+		chain = append(chain, RateLimitMiddleware(opts.RateLimiter))
+	}
+	return chain
+}
+
+// recoverPanic пишет панику обработчика в лог запроса и отвечает 500
+func recoverPanic(c *gin.Context, recovered any) {
+	requestLogger(c).Error("Panic recovered", "panic", recovered, "stack", string(debug.Stack()))
+	RespondError(c, http.StatusInternalServerError, "internal server error")
+	c.Abort()
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+)
+
+// ServiceUseCase определяет интерфейс use case для работы с каталогом сервисов
+type ServiceUseCase interface {
+	CreateService(ctx context.Context, req usecase.ServiceInput) (*domain.Service, error)
+	GetService(ctx context.Context, id string) (*domain.Service, error)
+	UpdateService(ctx context.Context, id string, req usecase.ServiceInput) (*domain.Service, error)
+	DeleteService(ctx context.Context, id string) error
+	ListServices(ctx context.Context, filters usecase.ServiceFiltersInput) ([]*domain.Service, error)
+}
+
+// ServiceRequest represents data for creating or replacing a catalog entry
+// Subscriptions named like the service or one of its aliases are linked to it
+// swagger:model ServiceRequest
+type ServiceRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Aliases are other spellings of the name, compared ignoring case, spaces and punctuation
+	Aliases   []string             `json:"aliases,omitempty"`
+	Category  string               `json:"category,omitempty"` // e.g. entertainment, dev tools, cloud
+	VendorURL string               `json:"vendor_url,omitempty"`
+	Plans     []ServicePlanRequest `json:"plans,omitempty" binding:"omitempty,dive"`
+}
+
+// ServicePlanRequest represents a plan of a service with its default monthly price
+// swagger:model ServicePlanRequest
+type ServicePlanRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Price int    `json:"price" binding:"min=0"`
+}
+
+// ServiceResponse represents a catalog entry in API response
+// swagger:model ServiceResponse
+type ServiceResponse struct {
+	ID             string                `json:"id"`
+	OrganizationID string                `json:"organization_id"`
+	Name           string                `json:"name"`
+	Aliases        []string              `json:"aliases"`
+	Category       string                `json:"category,omitempty"`
+	VendorURL      string                `json:"vendor_url,omitempty"`
+	Plans          []ServicePlanResponse `json:"plans"`
+	CreatedAt      string                `json:"created_at"`
+	UpdatedAt      string                `json:"updated_at"`
+}
+
+// ServicePlanResponse represents a plan of a service in API response
+// swagger:model ServicePlanResponse
+type ServicePlanResponse struct {
+	Name     string `json:"name"`
+	Price    int64  `json:"price"`
+	Currency string `json:"currency"`
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceHandler struct {
+	serviceUseCase ServiceUseCase
+}
+
+// NewServiceHandler создает новый экземпляр хэндлера каталога сервисов
+func NewServiceHandler(serviceUseCase ServiceUseCase) *ServiceHandler {
+	return &ServiceHandler{
+		serviceUseCase: serviceUseCase,
+	}
+}
+
+// CreateService godoc
+// @Summary Добавить сервис в каталог
+// @Description Добавляет сервис с каноническим названием, псевдонимами, категорией и тарифами. Подписки, названные так же или одним из псевдонимов, связываются с сервисом и получают каноническое название. Требует права catalog:write
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body ServiceRequest true "Данные сервиса"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 201 {object} ServiceResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services [post]
+func (h *ServiceHandler) CreateService(c *gin.Context) {
+	requestLogger(c).Info("CreateService called")
+
+	var req ServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	svc, err := h.serviceUseCase.CreateService(c.Request.Context(), toServiceInput(req))
+	if err != nil {
+		requestLogger(c).Error("Failed to create service", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Service created", "id", svc.ID, "name", svc.Name)
+	RespondSuccess(c, http.StatusCreated, ToServiceResponse(svc))
+}
+
+// GetService godoc
+// @Summary Получить сервис каталога
+// @Description Возвращает сервис каталога по ID
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} ServiceResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id} [get]
+func (h *ServiceHandler) GetService(c *gin.Context) {
+	requestLogger(c).Info("GetService called")
+
+	id := c.Param("id")
+	svc, err := h.serviceUseCase.GetService(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Failed to get service", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	RespondSuccess(c, http.StatusOK, ToServiceResponse(svc))
+}
+
+// UpdateService godoc
+// @Summary Обновить сервис каталога
+// @Description Заменяет данные сервиса. Связанные подписки получают новое каноническое название, подписки с новыми псевдонимами связываются с сервисом
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param service body ServiceRequest true "Данные для обновления"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} ServiceResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id} [put]
+func (h *ServiceHandler) UpdateService(c *gin.Context) {
+	requestLogger(c).Info("UpdateService called")
+
+	var req ServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	svc, err := h.serviceUseCase.UpdateService(c.Request.Context(), id, toServiceInput(req))
+	if err != nil {
+		requestLogger(c).Error("Failed to update service", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Service updated", "id", id)
+	RespondSuccess(c, http.StatusOK, ToServiceResponse(svc))
+}
+
+// DeleteService godoc
+// @Summary Удалить сервис из каталога
+// @Description Удаляет сервис по ID. Подписки сохраняют название, но больше не связаны с каталогом
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services/{id} [delete]
+func (h *ServiceHandler) DeleteService(c *gin.Context) {
+	requestLogger(c).Info("DeleteService called")
+
+	id := c.Param("id")
+	if err := h.serviceUseCase.DeleteService(c.Request.Context(), id); err != nil {
+		requestLogger(c).Error("Failed to delete service", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Service deleted", "id", id)
+	RespondSuccess(c, http.StatusOK, gin.H{
+		"message": "service deleted successfully",
+	})
+}
+
+// ListServices godoc
+// @Summary Каталог сервисов
+// @Description Возвращает сервисы каталога организации, отсортированные по названию
+// @Tags services
+// @Produce json
+// @Param category query string false "Фильтр по категории"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {array} ServiceResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /services [get]
+func (h *ServiceHandler) ListServices(c *gin.Context) {
+	category := c.Query("category")
+	requestLogger(c).Info("ListServices called", "category", category)
+
+	services, err := h.serviceUseCase.ListServices(c.Request.Context(), usecase.ServiceFiltersInput{Category: category})
+	if err != nil {
+		requestLogger(c).Error("Failed to list services", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	responses := make([]ServiceResponse, 0, len(services))
+	for _, svc := range services {
+		responses = append(responses, ToServiceResponse(svc))
+	}
+
+	RespondSuccess(c, http.StatusOK, responses)
+}
+
+// toServiceInput преобразует HTTP запрос в use case запрос
+func toServiceInput(req ServiceRequest) usecase.ServiceInput {
+	plans := make([]usecase.ServicePlanInput, 0, len(req.Plans))
+	for _, p := range req.Plans {
+		plans = append(plans, usecase.ServicePlanInput{Name: p.Name, Price: p.Price})
+	}
+
+	return usecase.ServiceInput{
+		Name:      req.Name,
+		Aliases:   req.Aliases,
+		Category:  req.Category,
+		VendorURL: req.VendorURL,
+		Plans:     plans,
+	}
+}
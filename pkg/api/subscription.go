@@ -14,20 +14,69 @@ type SubscriptionUseCase interface {
 	GetSubscription(ctx context.Context, id string) (*domain.Subscription, error)
 	UpdateSubscription(ctx context.Context, id string, req usecase.UpdateSubscriptionInput) (*domain.Subscription, error)
 	DeleteSubscription(ctx context.Context, id string) error
+	DeleteSubscriptions(ctx context.Context, filters usecase.DeleteFiltersInput) (int64, error)
 	ListSubscriptions(ctx context.Context, filters usecase.ListFiltersInput) ([]*domain.Subscription, error)
 	GetSubscriptionsSummary(ctx context.Context, filters usecase.SummaryFiltersInput) (int64, error)
+	GetSubscriptionsSummaryGroups(ctx context.Context, filters usecase.SummaryFiltersInput, groupBy string) ([]domain.SummaryGroup, error)
+	SetSubscriptionTags(ctx context.Context, id string, tags []string) (*domain.Subscription, error)
+	PauseSubscription(ctx context.Context, id string, req usecase.PauseInput) (*domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, id string, req usecase.ResumeInput) (*domain.Subscription, error)
+	ScheduleChange(ctx context.Context, id string, req usecase.ScheduleChangeInput) (*domain.Subscription, error)
+	DeleteScheduledChange(ctx context.Context, id, month string) (*domain.Subscription, error)
 }
 
 // SubscriptionResponse represents subscription data in API response
 // swagger:model SubscriptionResponse
 type SubscriptionResponse struct {
-	ID          string `json:"id"`
-	ServiceName string `json:"service_name"`
-	Price       int64  `json:"price"`
-	Currency    string `json:"currency"`
-	UserID      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	ID               string                    `json:"id"`
+	OrganizationID   string                    `json:"organization_id"`
+	ServiceName      string                    `json:"service_name"`
+	ServiceID        string                    `json:"service_id,omitempty"` // catalog entry the subscription is linked to
+	Plan             string                    `json:"plan"`
+	Price            int64                     `json:"price"`
+	Currency         string                    `json:"currency"`
+	UserID           string                    `json:"user_id"`
+	StartDate        string                    `json:"start_date"`
+	EndDate          string                    `json:"end_date"`
+	TrialMonths      int                       `json:"trial_months"`
+	TrialEndsAt      string                    `json:"trial_ends_at,omitempty"` // first day after the trial, e.g. "2025-04-01"
+	IntroPrice       int64                     `json:"intro_price"`
+	IntroMonths      int                       `json:"intro_months"`
+	Pauses           []PauseResponse           `json:"pauses"`
+	ScheduledChanges []ScheduledChangeResponse `json:"scheduled_changes"`
+	PriceHistory     []PricePeriodResponse     `json:"price_history"`
+	Tags             []string                  `json:"tags"`
+	Status           string                    `json:"status"`
+	CreatedAt        string                    `json:"created_at"`
+	UpdatedAt        string                    `json:"updated_at"`
+}
+
+// PauseResponse represents a billing pause of a subscription; months are inclusive
+// swagger:model PauseResponse
+type PauseResponse struct {
+	Start string `json:"start"`
+	End   string `json:"end,omitempty"` // empty until the subscription is resumed
+}
+
+// ScheduledChangeResponse represents a pending subscription change
+// swagger:model ScheduledChangeResponse
+type ScheduledChangeResponse struct {
+	EffectiveFrom string  `json:"effective_from"`
+	Price         *int64  `json:"price,omitempty"`
+	Plan          *string `json:"plan,omitempty"`
+	Cancel        bool    `json:"cancel"`
+}
+
+// PricePeriodResponse represents a former price that was charged up to and including month until
+// swagger:model PricePeriodResponse
+type PricePeriodResponse struct {
+	Until string `json:"until"`
+	Price int64  `json:"price"`
+}
+
+// SummaryGroupResponse represents the total of one group of a grouped summary
+// swagger:model SummaryGroupResponse
+type SummaryGroupResponse struct {
+	Key   string `json:"key"` // tag or category; empty for subscriptions without one
+	Total int64  `json:"total"`
 }
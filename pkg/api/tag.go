@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// TagUseCase определяет интерфейс use case для управления тегами
+type TagUseCase interface {
+	ListTags(ctx context.Context) ([]*domain.Tag, error)
+	CreateTag(ctx context.Context, name string) (*domain.Tag, error)
+	RenameTag(ctx context.Context, id, name string) (*domain.Tag, error)
+	DeleteTag(ctx context.Context, id string) error
+}
+
+// TagRequest represents the name of a tag; names are compared ignoring case and extra spaces
+// swagger:model TagRequest
+type TagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// TagResponse represents a tag of the organization in API response
+// swagger:model TagResponse
+type TagResponse struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	Name           string `json:"name"`
+	Subscriptions  int64  `json:"subscriptions"` // number of subscriptions with the tag
+	CreatedAt      string `json:"created_at"`
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagUseCase TagUseCase
+}
+
+// NewTagHandler создает новый экземпляр хэндлера тегов
+func NewTagHandler(tagUseCase TagUseCase) *TagHandler {
+	return &TagHandler{
+		tagUseCase: tagUseCase,
+	}
+}
+
+// CreateTag godoc
+// @Summary Добавить тег
+// @Description Добавляет тег организации до его назначения подпискам. Название приводится к нижнему регистру. Требует права tags:manage
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body TagRequest true "Название тега"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 201 {object} TagResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	requestLogger(c).Info("CreateTag called")
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	tag, err := h.tagUseCase.CreateTag(c.Request.Context(), req.Name)
+	if err != nil {
+		requestLogger(c).Error("Failed to create tag", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Tag created", "id", tag.ID, "name", tag.Name)
+	RespondSuccess(c, http.StatusCreated, ToTagResponse(tag))
+}
+
+// RenameTag godoc
+// @Summary Переименовать тег
+// @Description Меняет название тега у всех подписок организации. Требует права tags:manage
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "ID тега"
+// @Param tag body TagRequest true "Новое название тега"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} TagResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tags/{id} [put]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	requestLogger(c).Info("RenameTag called")
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		requestLogger(c).Warn("Failed to bind JSON", "error", err)
+		RespondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	tag, err := h.tagUseCase.RenameTag(c.Request.Context(), id, req.Name)
+	if err != nil {
+		requestLogger(c).Error("Failed to rename tag", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Tag renamed", "id", tag.ID, "name", tag.Name)
+	RespondSuccess(c, http.StatusOK, ToTagResponse(tag))
+}
+
+// DeleteTag godoc
+// @Summary Удалить тег
+// @Description Удаляет тег и снимает его со всех подписок организации. Требует права tags:manage
+// @Tags tags
+// @Produce json
+// @Param id path string true "ID тега"
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	requestLogger(c).Info("DeleteTag called")
+
+	id := c.Param("id")
+	if err := h.tagUseCase.DeleteTag(c.Request.Context(), id); err != nil {
+		requestLogger(c).Error("Failed to delete tag", "id", id, "error", err)
+		handleError(c, err)
+		return
+	}
+
+	requestLogger(c).Info("Tag deleted", "id", id)
+	RespondSuccess(c, http.StatusOK, gin.H{
+		"message": "tag deleted successfully",
+	})
+}
+
+// ListTags godoc
+// @Summary Теги организации
+// @Description Возвращает теги организации, отсортированные по названию, с числом подписок
+// @Tags tags
+// @Produce json
+// @Param X-Organization-ID header string false "Организация (для администраторов без организации в токене)"
+// @Success 200 {array} TagResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /tags [get]
+func (h *TagHandler) ListTags(c *gin.Context) {
+	requestLogger(c).Info("ListTags called")
+
+	tags, err := h.tagUseCase.ListTags(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("Failed to list tags", "error", err)
+		handleError(c, err)
+		return
+	}
+
+	responses := make([]TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		responses = append(responses, ToTagResponse(tag))
+	}
+
+	RespondSuccess(c, http.StatusOK, responses)
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"crypto/x509"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/utils"
+)
+
+// CertificateSubjectPrefix префикс Subject вызывающих, аутентифицированных клиентским сертификатом
+const CertificateSubjectPrefix = "cert:"
+
+// PrincipalFromCertificate сопоставляет проверенный клиентский сертификат вызывающему:
+//   - CN субъекта - идентификатор пользователя (Subject = "cert:<CN>")
+//   - OU субъекта - роли
+//   - O субъекта - организация, если значение является UUID
+//
+// Возвращает nil, если в сертификате нет CN
+func PrincipalFromCertificate(cert *x509.Certificate) *Principal {
+	if cert == nil || cert.Subject.CommonName == "" {
+		return nil
+	}
+
+	p := &Principal{
+		Subject: CertificateSubjectPrefix + cert.Subject.CommonName,
+		UserID:  cert.Subject.CommonName,
+		Roles:   append([]string(nil), cert.Subject.OrganizationalUnit...),
+	}
+	for _, org := range cert.Subject.Organization {
+		if utils.IsUUID(org) {
+			p.OrganizationID = org
+			break
+		}
+	}
+
+	return p
+}
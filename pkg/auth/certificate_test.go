@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrincipalFromCertificate(t *testing.T) {
+	const org = "11111111-1111-1111-1111-111111111111"
+
+	p := PrincipalFromCertificate(&x509.Certificate{Subject: pkix.Name{
+		CommonName:         "billing",
+		OrganizationalUnit: []string{RoleAdmin},
+		Organization:       []string{"Acme", org},
+	}})
+	require.NotNil(t, p)
+	assert.Equal(t, "cert:billing", p.Subject)
+	assert.Equal(t, "billing", p.UserID)
+	assert.Equal(t, []string{RoleAdmin}, p.Roles)
+	assert.Equal(t, org, p.OrganizationID)
+
+	assert.Nil(t, PrincipalFromCertificate(&x509.Certificate{Subject: pkix.Name{Organization: []string{org}}}))
+	assert.Nil(t, PrincipalFromCertificate(nil))
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix префикс, по которому API ключи отличаются от JWT
+const APIKeyPrefix = "subs_"
+
+// CompositeAuthenticator выбирает способ проверки по виду токена
+type CompositeAuthenticator struct {
+	JWT     Authenticator
+	APIKeys Authenticator
+}
+
+// Проверка, что CompositeAuthenticator реализует интерфейс Authenticator
+var _ Authenticator = (*CompositeAuthenticator)(nil)
+
+// Authenticate передает API ключи в APIKeys, а остальные токены в JWT
+func (a *CompositeAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	next := a.JWT
+	if strings.HasPrefix(token, APIKeyPrefix) {
+		next = a.APIKeys
+	}
+	if next == nil {
+		return nil, fmt.Errorf("%w: unsupported credentials", ErrUnauthorized)
+	}
+	return next.Authenticate(ctx, token)
+}
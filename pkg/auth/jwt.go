@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig содержит параметры проверки JWT
+// Должен быть задан ровно один источник ключа: HS256Secret, RS256PublicKeyFile или JWKSFile
+type JWTConfig struct {
+	HS256Secret        string
+	RS256PublicKeyFile string
+	JWKSFile           string
+	Issuer             string
+	Audience           string
+}
+
+// Enabled сообщает, задан ли хотя бы один источник ключа
+func (c JWTConfig) Enabled() bool {
+	return c.HS256Secret != "" || c.RS256PublicKeyFile != "" || c.JWKSFile != ""
+}
+
+// Claims описывает поля токена, которые использует сервис
+type Claims struct {
+	Roles          []string `json:"roles,omitempty"`
+	OrganizationID string   `json:"org_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// JWTAuthenticator проверяет JWT, подписанные HS256 или RS256
+// Ключи загружаются из конфигурации или локальных файлов, сетевые запросы не выполняются
+type JWTAuthenticator struct {
+	keyFunc jwt.Keyfunc
+	parser  *jwt.Parser
+}
+
+// Проверка, что JWTAuthenticator реализует интерфейс Authenticator
+var _ Authenticator = (*JWTAuthenticator)(nil)
+
+// NewJWTAuthenticator создает аутентификатор по конфигурации
+func NewJWTAuthenticator(cfg JWTConfig) (*JWTAuthenticator, error) {
+	sources := 0
+	for _, s := range []string{cfg.HS256Secret, cfg.RS256PublicKeyFile, cfg.JWKSFile} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("exactly one of HS256 secret, RS256 public key file or JWKS file must be set")
+	}
+
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	var keyFunc jwt.Keyfunc
+	switch {
+	case cfg.HS256Secret != "":
+		secret := []byte(cfg.HS256Secret)
+		keyFunc = func(*jwt.Token) (any, error) { return secret, nil }
+		opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	case cfg.RS256PublicKeyFile != "":
+		pemBytes, err := os.ReadFile(cfg.RS256PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RS256 public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RS256 public key: %w", err)
+		}
+		keyFunc = func(*jwt.Token) (any, error) { return key, nil }
+		opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+
+	default:
+		data, err := os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		keys, err := ParseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+		keyFunc = func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			if key, ok := keys[kid]; ok {
+				return key, nil
+			}
+			// Токен без kid допустим, если в наборе единственный ключ
+			if kid == "" && len(keys) == 1 {
+				for _, key := range keys {
+					return key, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+	}
+
+	return &JWTAuthenticator{
+		keyFunc: keyFunc,
+		parser:  jwt.NewParser(opts...),
+	}, nil
+}
+
+// Authenticate проверяет подпись и срок действия токена
+// Идентификатор пользователя берется из claim sub, роли - из claim roles, организация - из claim org_id
+func (a *JWTAuthenticator) Authenticate(_ context.Context, token string) (*Principal, error) {
+	var claims Claims
+	if _, err := a.parser.ParseWithClaims(token, &claims, a.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrUnauthorized)
+	}
+
+	return &Principal{
+		Subject:        claims.Subject,
+		UserID:         claims.Subject,
+		Roles:          claims.Roles,
+		OrganizationID: claims.OrganizationID,
+	}, nil
+}
+
+// ParseJWKS разбирает набор ключей в формате JWKS и возвращает RSA ключи по kid
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key %q: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no RSA signing keys")
+	}
+
+	return keys, nil
+}
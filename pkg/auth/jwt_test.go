@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signHS256(t *testing.T, secret string, claims Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims(sub string, roles ...string) Claims {
+	return Claims{
+		Roles: roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestJWTAuthenticator_HS256(t *testing.T) {
+	a, err := NewJWTAuthenticator(JWTConfig{HS256Secret: "secret"})
+	require.NoError(t, err)
+
+	t.Run("valid token", func(t *testing.T) {
+		p, err := a.Authenticate(context.Background(), signHS256(t, "secret", validClaims("user-1", "admin")))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", p.UserID)
+		assert.True(t, p.IsAdmin())
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := a.Authenticate(context.Background(), signHS256(t, "other", validClaims("user-1")))
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		claims := validClaims("user-1")
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		_, err := a.Authenticate(context.Background(), signHS256(t, "secret", claims))
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		_, err := a.Authenticate(context.Background(), signHS256(t, "secret", validClaims("")))
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestJWTAuthenticator_JWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	a, err := NewJWTAuthenticator(JWTConfig{JWKSFile: path})
+	require.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims("user-2"))
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	p, err := a.Authenticate(context.Background(), signed)
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", p.UserID)
+	assert.False(t, p.IsAdmin())
+
+	// HS256 токен не должен приниматься при настроенном RS256
+	_, err = a.Authenticate(context.Background(), signHS256(t, "secret", validClaims("user-2")))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestNewJWTAuthenticator_RequiresSingleKeySource(t *testing.T) {
+	_, err := NewJWTAuthenticator(JWTConfig{})
+	assert.Error(t, err)
+
+	_, err = NewJWTAuthenticator(JWTConfig{HS256Secret: "a", JWKSFile: "b"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Permission описывает право на операцию
+type Permission string
+
+// Права на операции с подписками
+// Права с суффиксом _all снимают ограничение собственными подписками вызывающего
+const (
+	PermSubscriptionsRead       Permission = "subscriptions:read"
+	PermSubscriptionsReadAll    Permission = "subscriptions:read_all"
+	PermSubscriptionsWrite      Permission = "subscriptions:write"
+	PermSubscriptionsWriteAll   Permission = "subscriptions:write_all"
+	PermSubscriptionsBulkDelete Permission = "subscriptions:bulk_delete"
+	PermSummaryRead             Permission = "summary:read"
+	PermSummaryReadAll          Permission = "summary:read_all"
+	PermAPIKeysManage           Permission = "api_keys:manage"
+	PermRemindersManage         Permission = "reminders:manage"
+	PermRemindersManageAll      Permission = "reminders:manage_all"
+	PermBudgetsRead             Permission = "budgets:read"
+	PermBudgetsReadAll          Permission = "budgets:read_all"
+	PermBudgetsWrite            Permission = "budgets:write"
+	PermBudgetsWriteAll         Permission = "budgets:write_all"
+	PermCatalogRead             Permission = "catalog:read"
+	PermCatalogWrite            Permission = "catalog:write"
+	PermTagsRead                Permission = "tags:read"
+	PermTagsManage              Permission = "tags:manage"
+)
+
+// Права уровня платформы действуют на все организации сразу
+// Поэтому "*" их не включает: администратор организации не получает их вместе с остальными правами
+const (
+	PermPlatformJobs          Permission = "platform:jobs"
+	PermPlatformOrganizations Permission = "platform:organizations"
+)
+
+// platformPrefix префикс прав уровня платформы
+const platformPrefix = "platform:"
+
+// Роли, которые получают API ключи в зависимости от областей доступа
+const (
+	RoleServiceReader = "service_reader"
+	RoleServiceWriter = "service_writer"
+)
+
+// RolePlatformAdmin роль оператора платформы: все права организации и права уровня платформы
+const RolePlatformAdmin = "platform_admin"
+
+// wildcard в списке прав роли означает все права
+const wildcard = "*"
+
+// defaultPolicy сопоставление ролей и прав по умолчанию
+const defaultPolicy = `
+default_role: editor
+roles:
+  viewer:
+    - subscriptions:read
+    - summary:read
+    - reminders:manage
+    - budgets:read
+    - catalog:read
+    - tags:read
+  editor:
+    - subscriptions:read
+    - subscriptions:write
+    - summary:read
+    - reminders:manage
+    - budgets:read
+    - budgets:write
+    - catalog:read
+    - tags:read
+  finance:
+    - subscriptions:read
+    - summary:read
+    - summary:read_all
+    - reminders:manage
+    - budgets:read
+    - budgets:read_all
+    - budgets:write
+    - budgets:write_all
+    - catalog:read
+    - catalog:write
+    - tags:read
+    - tags:manage
+  admin:
+    - "*"
+  platform_admin:
+    - "*"
+    - platform:jobs
+    - platform:organizations
+  service_reader:
+    - subscriptions:read_all
+    - summary:read_all
+    - budgets:read_all
+    - catalog:read
+    - tags:read
+  service_writer:
+    - subscriptions:write_all
+`
+
+// PolicyConfig описывает декларативное сопоставление ролей и прав
+type PolicyConfig struct {
+	// DefaultRole назначается пользователям, в токене которых нет ролей
+	DefaultRole string `yaml:"default_role"`
+	// Roles содержит список прав для каждой роли
+	Roles map[string][]Permission `yaml:"roles"`
+}
+
+// Policy проверяет права вызывающих по их ролям
+type Policy struct {
+	defaultRole string
+	roles       map[string][]Permission
+}
+
+// NewPolicy создает политику по конфигурации
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	if len(cfg.Roles) == 0 {
+		return nil, fmt.Errorf("policy must define at least one role")
+	}
+	if cfg.DefaultRole != "" {
+		if _, ok := cfg.Roles[cfg.DefaultRole]; !ok {
+			return nil, fmt.Errorf("default role %q is not defined", cfg.DefaultRole)
+		}
+	}
+
+	known := []Permission{
+		PermSubscriptionsRead, PermSubscriptionsReadAll,
+		PermSubscriptionsWrite, PermSubscriptionsWriteAll,
+		PermSubscriptionsBulkDelete,
+		PermSummaryRead, PermSummaryReadAll,
+		PermAPIKeysManage,
+		PermRemindersManage, PermRemindersManageAll,
+		PermBudgetsRead, PermBudgetsReadAll,
+		PermBudgetsWrite, PermBudgetsWriteAll,
+		PermCatalogRead, PermCatalogWrite,
+		PermTagsRead, PermTagsManage,
+		PermPlatformJobs, PermPlatformOrganizations,
+		wildcard,
+	}
+	for role, perms := range cfg.Roles {
+		for _, perm := range perms {
+			if !slices.Contains(known, perm) {
+				return nil, fmt.Errorf("role %q has unknown permission %q", role, perm)
+			}
+		}
+	}
+
+	return &Policy{defaultRole: cfg.DefaultRole, roles: cfg.Roles}, nil
+}
+
+// ParsePolicy разбирает политику в формате YAML
+func ParsePolicy(data []byte) (*Policy, error) {
+	var cfg PolicyConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return NewPolicy(cfg)
+}
+
+// LoadPolicy загружает политику из YAML файла
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// DefaultPolicy возвращает политику по умолчанию
+func DefaultPolicy() *Policy {
+	policy, err := ParsePolicy([]byte(defaultPolicy))
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+// Allowed сообщает, есть ли у вызывающего право perm
+func (p *Policy) Allowed(principal *Principal, perm Permission) bool {
+	roles := principal.Roles
+	if len(roles) == 0 && p.defaultRole != "" {
+		roles = []string{p.defaultRole}
+	}
+
+	for _, role := range roles {
+		for _, granted := range p.roles[role] {
+			if granted == perm || granted == wildcard && !strings.HasPrefix(string(perm), platformPrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize проверяет право вызывающего из контекста
+// Запросы без вызывающего (аутентификация отключена) разрешены
+func (p *Policy) Authorize(ctx context.Context, perm Permission) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if ok && !p.Allowed(principal, perm) {
+		return fmt.Errorf("%w: %s permission required", ErrForbidden, perm)
+	}
+	return nil
+}
+
+// AuthorizeRole проверяет, что у вызывающего из контекста есть все права роли
+// Так вызывающий не может выдать (например, API ключу) больше прав, чем есть у него самого
+func (p *Policy) AuthorizeRole(ctx context.Context, role string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, perm := range p.roles[role] {
+		if !p.Allowed(principal, perm) {
+			return fmt.Errorf("%w: role %s grants %s permission the caller does not have", ErrForbidden, role, perm)
+		}
+	}
+	return nil
+}
+
+// ScopeUserID определяет, какими подписками ограничен вызывающий
+// own - право на собственные подписки, all - на подписки всех пользователей.
+// Возвращает user_id для фильтрации и признак того, что ограничение действует
+func (p *Policy) ScopeUserID(ctx context.Context, own, all Permission) (string, bool, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || p.Allowed(principal, all) {
+		return "", false, nil
+	}
+	if !p.Allowed(principal, own) {
+		return "", true, fmt.Errorf("%w: %s permission required", ErrForbidden, own)
+	}
+	if principal.UserID == "" {
+		return "", true, fmt.Errorf("%w: caller has no user_id", ErrForbidden)
+	}
+	return principal.UserID, true, nil
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	policy := DefaultPolicy()
+
+	tests := []struct {
+		name    string
+		roles   []string
+		perm    Permission
+		allowed bool
+	}{
+		{"viewer reads own", []string{"viewer"}, PermSubscriptionsRead, true},
+		{"viewer cannot write", []string{"viewer"}, PermSubscriptionsWrite, false},
+		{"no roles falls back to editor", nil, PermSubscriptionsWrite, true},
+		{"editor cannot read all", []string{"editor"}, PermSubscriptionsReadAll, false},
+		{"finance reads all summaries", []string{"finance"}, PermSummaryReadAll, true},
+		{"finance cannot bulk delete", []string{"finance"}, PermSubscriptionsBulkDelete, false},
+		{"admin bulk deletes", []string{"admin"}, PermSubscriptionsBulkDelete, true},
+		{"viewer manages own reminders", []string{"viewer"}, PermRemindersManage, true},
+		{"finance cannot manage reminders of others", []string{"finance"}, PermRemindersManageAll, false},
+		{"viewer cannot change budgets", []string{"viewer"}, PermBudgetsWrite, false},
+		{"finance manages organization budgets", []string{"finance"}, PermBudgetsWriteAll, true},
+		{"admin cannot run platform jobs", []string{"admin"}, PermPlatformJobs, false},
+		{"platform admin runs platform jobs", []string{"platform_admin"}, PermPlatformJobs, true},
+		{"platform admin has organization permissions", []string{"platform_admin"}, PermAPIKeysManage, true},
+		{"admin cannot manage organizations", []string{"admin"}, PermPlatformOrganizations, false},
+		{"platform admin manages organizations", []string{"platform_admin"}, PermPlatformOrganizations, true},
+		{"unknown role has nothing", []string{"guest"}, PermSubscriptionsRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, policy.Allowed(&Principal{Subject: "s", Roles: tt.roles}, tt.perm))
+		})
+	}
+}
+
+func TestPolicy_ScopeUserID(t *testing.T) {
+	policy := DefaultPolicy()
+
+	t.Run("without principal there is no restriction", func(t *testing.T) {
+		_, restricted, err := policy.ScopeUserID(context.Background(), PermSummaryRead, PermSummaryReadAll)
+		require.NoError(t, err)
+		assert.False(t, restricted)
+	})
+
+	t.Run("editor is restricted to own user_id", func(t *testing.T) {
+		ctx := WithPrincipal(context.Background(), &Principal{Subject: "u1", UserID: "u1"})
+		userID, restricted, err := policy.ScopeUserID(ctx, PermSummaryRead, PermSummaryReadAll)
+		require.NoError(t, err)
+		assert.True(t, restricted)
+		assert.Equal(t, "u1", userID)
+	})
+
+	t.Run("service writer cannot read", func(t *testing.T) {
+		ctx := WithPrincipal(context.Background(), &Principal{Subject: "k", Roles: []string{RoleServiceWriter}})
+		_, _, err := policy.ScopeUserID(ctx, PermSubscriptionsRead, PermSubscriptionsReadAll)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+default_role: reader
+roles:
+  reader: [subscriptions:read]
+`))
+	require.NoError(t, err)
+	assert.True(t, policy.Allowed(&Principal{Subject: "s"}, PermSubscriptionsRead))
+
+	_, err = ParsePolicy([]byte(`roles: {reader: [subscriptions:fly]}`))
+	assert.ErrorContains(t, err, "unknown permission")
+
+	_, err = ParsePolicy([]byte(`{default_role: missing, roles: {reader: [subscriptions:read]}}`))
+	assert.ErrorContains(t, err, "not defined")
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// RoleAdmin роль администратора
+const RoleAdmin = "admin"
+
+var (
+	// ErrUnauthorized возвращается, если учетные данные отсутствуют или недействительны
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden возвращается, если вызывающему запрещен доступ к ресурсу
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Principal описывает аутентифицированного вызывающего
+type Principal struct {
+	Subject string
+	UserID  string
+	Roles   []string
+	// OrganizationID организация (арендатор) вызывающего; пусто, если токен её не задает
+	OrganizationID string
+}
+
+// IsAdmin сообщает, обладает ли вызывающий ролью администратора
+func (p *Principal) IsAdmin() bool {
+	return p != nil && slices.Contains(p.Roles, RoleAdmin)
+}
+
+// Authenticator проверяет токен и возвращает соответствующего ему вызывающего
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal кладет вызывающего в контекст запроса
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext достает вызывающего из контекста запроса
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,50 @@
+// Package cache кэширует результаты подсчета суммы подписок
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Store хранилище кэша
+// По умолчанию используется LRU в памяти процесса; общий кэш (например, Redis) подключается реализацией интерфейса.
+// Записи помечаются тегами, и Invalidate удаляет все записи с любым из переданных тегов
+type Store interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, tags []string) error
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// Результаты обращения к кэшу
+const (
+	ResultHit  = "hit"
+	ResultMiss = "miss"
+)
+
+type lookupKey struct{}
+
+// Lookup результат обращения к кэшу в рамках запроса
+type Lookup struct {
+	result atomic.Value
+}
+
+// Track отмечает контекст запроса, чтобы узнать, был ли ответ взят из кэша
+func Track(ctx context.Context) (context.Context, *Lookup) {
+	l := &Lookup{}
+	return context.WithValue(ctx, lookupKey{}, l), l
+}
+
+// Result возвращает ResultHit, ResultMiss или пустую строку, если кэш не использовался
+func (l *Lookup) Result() string {
+	if l == nil {
+		return ""
+	}
+	result, _ := l.result.Load().(string)
+	return result
+}
+
+func record(ctx context.Context, result string) {
+	if l, ok := ctx.Value(lookupKey{}).(*Lookup); ok {
+		l.result.Store(result)
+	}
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2, time.Minute)
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), []string{"x"}))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), []string{"y"}))
+
+	// Чтение "a" делает "b" давно не использованной записью
+	v, ok, _ := c.Get(ctx, "a")
+	require.True(t, ok)
+	assert.Equal(t, "1", string(v))
+
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), []string{"x"}))
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok, "least recently used entry must be evicted")
+	assert.Equal(t, 2, c.Len())
+
+	require.NoError(t, c.Invalidate(ctx, "x"))
+	assert.Equal(t, 0, c.Len())
+
+	require.NoError(t, c.Set(ctx, "d", []byte("4"), nil))
+	now = now.Add(time.Minute)
+	_, ok, _ = c.Get(ctx, "d")
+	assert.False(t, ok, "expired entry must not be returned")
+	assert.Empty(t, c.tags)
+}
+
+func TestSubscriptionRepository_Conformance(t *testing.T) {
+	repotest.RunSubscriptionRepositoryTests(t, func(*testing.T) domain.SubscriptionRepository {
+		return NewSubscriptionRepository(memory.NewSubscriptionRepository(), NewLRU(100, 0))
+	})
+}
+
+func TestSubscriptionRepository_Summary(t *testing.T) {
+	const (
+		userA = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+		userB = "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"
+	)
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+	repo := NewSubscriptionRepository(memory.NewSubscriptionRepository(), NewLRU(100, 0))
+
+	netflixA, err := repo.Create(ctx, &domain.Subscription{ServiceName: "Netflix", Price: 100, UserID: userA, StartDate: jan})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, &domain.Subscription{ServiceName: "Spotify", Price: 10, UserID: userB, StartDate: jan})
+	require.NoError(t, err)
+
+	summary := func(f domain.SummaryFilters) (int64, string) {
+		t.Helper()
+		f.PeriodStart, f.PeriodEnd = jan, jan
+		ctx, lookup := Track(ctx)
+		total, err := repo.GetSummary(ctx, f)
+		require.NoError(t, err)
+		return total, lookup.Result()
+	}
+	assertSummary := func(f domain.SummaryFilters, total int64, result string) {
+		t.Helper()
+		gotTotal, gotResult := summary(f)
+		assert.Equal(t, total, gotTotal)
+		assert.Equal(t, result, gotResult)
+	}
+
+	byUserA := domain.SummaryFilters{UserID: userA}
+	byUserB := domain.SummaryFilters{UserID: userB}
+	bySpotify := domain.SummaryFilters{ServiceName: "Spotify"}
+	all := domain.SummaryFilters{}
+
+	assertSummary(byUserA, 100, ResultMiss)
+	assertSummary(byUserA, 100, ResultHit)
+	// Ключ строится из нормализованных фильтров
+	assertSummary(domain.SummaryFilters{UserID: "60601FEE-2BF1-4721-AE6F-7636E79A0CBA"}, 100, ResultHit)
+	assertSummary(byUserB, 10, ResultMiss)
+	assertSummary(bySpotify, 10, ResultMiss)
+	assertSummary(all, 110, ResultMiss)
+
+	// Запись пользователя A не трогает суммы пользователя B и сервиса Spotify
+	_, err = repo.Create(ctx, &domain.Subscription{ServiceName: "Netflix", Price: 1000, UserID: userA, StartDate: jan})
+	require.NoError(t, err)
+	assertSummary(byUserA, 1100, ResultMiss)
+	assertSummary(byUserB, 10, ResultHit)
+	assertSummary(bySpotify, 10, ResultHit)
+	assertSummary(all, 1110, ResultMiss)
+
+	// Смена сервиса сбрасывает суммы и старого, и нового сервиса
+	_, err = repo.Update(ctx, netflixA.ID, &domain.Subscription{ServiceName: "Spotify", Price: 100, StartDate: jan})
+	require.NoError(t, err)
+	assertSummary(bySpotify, 110, ResultMiss)
+	assertSummary(byUserB, 10, ResultHit)
+
+	// Суммы с фильтром по тегу не кэшируются
+	_, err = repo.SetTags(ctx, netflixA.ID, []string{"media"})
+	require.NoError(t, err)
+	byMedia := domain.SummaryFilters{Tag: "media"}
+	assertSummary(byMedia, 100, "")
+	assertSummary(byMedia, 100, "")
+
+	// Массовое удаление сбрасывает все суммы организации
+	_, err = repo.DeleteMany(ctx, domain.DeleteFilters{UserID: userB})
+	require.NoError(t, err)
+	assertSummary(byUserB, 0, ResultMiss)
+	assertSummary(bySpotify, 100, ResultMiss)
+
+	// Организации не видят кэш друг друга
+	other := domain.WithOrganization(ctx, repotest.OtherOrganizationID)
+	total, err := repo.GetSummary(other, domain.SummaryFilters{UserID: userA, PeriodStart: jan, PeriodEnd: jan})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+)
+
+// Проверка, что SubscriptionRepository реализует интерфейс domain.SubscriptionRepository
+var _ domain.SubscriptionRepository = (*SubscriptionRepository)(nil)
+
+// SubscriptionRepository декоратор репозитория, кэширующий результаты GetSummary
+//
+// Запись кэша помечается тегом по самому узкому фильтру (пользователь, иначе сервис, иначе вся организация)
+// и тегом организации. Изменение подписки сбрасывает записи ее пользователя, ее сервиса (до и после изменения)
+// и записи без фильтров; массовое удаление сбрасывает все записи организации.
+// Суммы с фильтром по тегу и суммы по группам не кэшируются: теги меняются отдельно от подписок.
+// Ошибки кэша не влияют на ответ: запрос уходит в репозиторий
+type SubscriptionRepository struct {
+	next  domain.SubscriptionRepository
+	store Store
+}
+
+// NewSubscriptionRepository оборачивает репозиторий кэшем сумм
+func NewSubscriptionRepository(next domain.SubscriptionRepository, store Store) *SubscriptionRepository {
+	return &SubscriptionRepository{next: next, store: store}
+}
+
+// Create создает подписку и сбрасывает зависящие от нее суммы
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) (*domain.Subscription, error) {
+	created, err := r.next.Create(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, subscriptionTags(created)...)
+	return created, nil
+}
+
+// GetByID получает подписку по ID
+func (r *SubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.Subscription, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+// Update обновляет подписку и сбрасывает суммы, зависящие от нее до и после изменения
+func (r *SubscriptionRepository) Update(ctx context.Context, id string, sub *domain.Subscription) (*domain.Subscription, error) {
+	before, _ := r.next.GetByID(ctx, id)
+
+	updated, err := r.next.Update(ctx, id, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := subscriptionTags(updated)
+	if before != nil {
+		tags = append(tags, subscriptionTags(before)...)
+	}
+	r.invalidate(ctx, tags...)
+	return updated, nil
+}
+
+// Delete удаляет подписку и сбрасывает зависящие от нее суммы
+func (r *SubscriptionRepository) Delete(ctx context.Context, id string) error {
+	before, _ := r.next.GetByID(ctx, id)
+
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if before != nil {
+		r.invalidate(ctx, subscriptionTags(before)...)
+	} else {
+		r.invalidate(ctx, organizationTag(domain.OrganizationFromContext(ctx)))
+	}
+	return nil
+}
+
+// UpdatePauses заменяет приостановки подписки и сбрасывает зависящие от нее суммы
+func (r *SubscriptionRepository) UpdatePauses(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.Pause, error)) (*domain.Subscription, error) {
+	updated, err := r.next.UpdatePauses(ctx, id, update)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, subscriptionTags(updated)...)
+	return updated, nil
+}
+
+// UpdateChanges заменяет запланированные изменения подписки и сбрасывает зависящие от нее суммы
+func (r *SubscriptionRepository) UpdateChanges(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.ScheduledChange, error)) (*domain.Subscription, error) {
+	updated, err := r.next.UpdateChanges(ctx, id, update)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, subscriptionTags(updated)...)
+	return updated, nil
+}
+
+// SetTags заменяет теги подписки; кэшируемые суммы не зависят от тегов, поэтому кэш не сбрасывается
+func (r *SubscriptionRepository) SetTags(ctx context.Context, id string, tags []string) (*domain.Subscription, error) {
+	return r.next.SetTags(ctx, id, tags)
+}
+
+// DeleteMany удаляет подписки и сбрасывает все суммы организации
+func (r *SubscriptionRepository) DeleteMany(ctx context.Context, filters domain.DeleteFilters) (int64, error) {
+	deleted, err := r.next.DeleteMany(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		r.invalidate(ctx, organizationTag(domain.OrganizationFromContext(ctx)))
+	}
+	return deleted, nil
+}
+
+// LinkService связывает подписки с сервисом каталога; названия подписок меняются, поэтому сбрасываются все суммы организации
+func (r *SubscriptionRepository) LinkService(ctx context.Context, svc *domain.Service) (int64, error) {
+	linked, err := r.next.LinkService(ctx, svc)
+	if err != nil {
+		return 0, err
+	}
+	if linked > 0 {
+		r.invalidate(ctx, organizationTag(domain.OrganizationFromContext(ctx)))
+	}
+	return linked, nil
+}
+
+// ExpireEnded помечает истекшие подписки; состояние подписки не влияет на суммы, поэтому кэш не сбрасывается
+func (r *SubscriptionRepository) ExpireEnded(ctx context.Context, before time.Time) (int64, error) {
+	return r.next.ExpireEnded(ctx, before)
+}
+
+// ApplyChanges применяет запланированные изменения; суммы уже учитывали их, поэтому кэш не сбрасывается
+func (r *SubscriptionRepository) ApplyChanges(ctx context.Context, month time.Time) (int64, error) {
+	return r.next.ApplyChanges(ctx, month)
+}
+
+// List возвращает список подписок с фильтрацией
+func (r *SubscriptionRepository) List(ctx context.Context, filters domain.ListFilters) ([]*domain.Subscription, error) {
+	return r.next.List(ctx, filters)
+}
+
+// GetSummary возвращает сумму из кэша или вычисляет ее и сохраняет
+// Сумма с фильтром по тегу всегда вычисляется репозиторием
+func (r *SubscriptionRepository) GetSummary(ctx context.Context, filters domain.SummaryFilters) (int64, error) {
+	if filters.Tag != "" {
+		return r.next.GetSummary(ctx, filters)
+	}
+
+	organizationID := domain.OrganizationFromContext(ctx)
+	key := summaryKey(organizationID, filters)
+
+	value, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Summary cache read failed", "error", err)
+	}
+	if ok {
+		if total, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			record(ctx, ResultHit)
+			return total, nil
+		}
+	}
+
+	total, err := r.next.GetSummary(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+	record(ctx, ResultMiss)
+
+	tags := []string{summaryScopeTag(organizationID, filters), organizationTag(organizationID)}
+	if err := r.store.Set(ctx, key, []byte(strconv.FormatInt(total, 10)), tags); err != nil {
+		logging.FromContext(ctx).Warn("Summary cache write failed", "error", err)
+	}
+
+	return total, nil
+}
+
+// GetGroupedSummary вычисляет суммы по группам без кэша
+func (r *SubscriptionRepository) GetGroupedSummary(ctx context.Context, filters domain.SummaryFilters, groupBy string) ([]domain.SummaryGroup, error) {
+	return r.next.GetGroupedSummary(ctx, filters, groupBy)
+}
+
+func (r *SubscriptionRepository) invalidate(ctx context.Context, tags ...string) {
+	if err := r.store.Invalidate(ctx, tags...); err != nil {
+		logging.FromContext(ctx).Warn("Summary cache invalidation failed", "error", err)
+	}
+}
+
+// summaryKey строит ключ из нормализованных фильтров: UUID пользователя в нижнем регистре, период с точностью до месяца
+func summaryKey(organizationID string, f domain.SummaryFilters) string {
+	return fmt.Sprintf("summary|org=%s|user=%s|service=%q|from=%s|to=%s",
+		organizationID,
+		normalizeUserID(f.UserID),
+		f.ServiceName,
+		f.PeriodStart.Format("2006-01"),
+		f.PeriodEnd.Format("2006-01"),
+	)
+}
+
+// summaryScopeTag тег самого узкого фильтра записи
+func summaryScopeTag(organizationID string, f domain.SummaryFilters) string {
+	switch {
+	case f.UserID != "":
+		return userTag(organizationID, f.UserID)
+	case f.ServiceName != "":
+		return serviceTag(organizationID, f.ServiceName)
+	default:
+		return unfilteredTag(organizationID)
+	}
+}
+
+// subscriptionTags теги записей, на которые влияет изменение подписки
+func subscriptionTags(sub *domain.Subscription) []string {
+	return []string{
+		userTag(sub.OrganizationID, sub.UserID),
+		serviceTag(sub.OrganizationID, sub.ServiceName),
+		unfilteredTag(sub.OrganizationID),
+	}
+}
+
+func userTag(organizationID, userID string) string {
+	return "user|" + organizationID + "|" + normalizeUserID(userID)
+}
+
+func serviceTag(organizationID, serviceName string) string {
+	return "service|" + organizationID + "|" + serviceName
+}
+
+func unfilteredTag(organizationID string) string {
+	return "all|" + organizationID
+}
+
+func organizationTag(organizationID string) string {
+	return "org|" + organizationID
+}
+
+func normalizeUserID(userID string) string {
+	return strings.ToLower(strings.TrimSpace(userID))
+}
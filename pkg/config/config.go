@@ -0,0 +1,423 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/ratelimit"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/scheduler"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/tlsutil"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/tracing"
+)
+
+// Config содержит все параметры сервиса
+// Значения берутся из (в порядке возрастания приоритета): значений по умолчанию, файла конфигурации,
+// переменных окружения и флагов командной строки
+//
+// Теги полей:
+//   - yaml: имя ключа в файле (для TOML используется то же имя) и часть имени флага (-server.port)
+//   - env: переменная окружения
+//   - default: значение по умолчанию
+//   - usage: описание для -help
+type Config struct {
+	Storage    string           `yaml:"storage" env:"STORAGE" default:"postgres" usage:"subscription storage: postgres, sqlite or memory (in-process, data is lost on restart)"`
+	Server     ServerConfig     `yaml:"server"`
+	TLS        TLSConfig        `yaml:"tls"`
+	Database   DatabaseConfig   `yaml:"database"`
+	SQLite     SQLiteConfig     `yaml:"sqlite"`
+	Migrations MigrationsConfig `yaml:"migrations"`
+	Log        LogConfig        `yaml:"log"`
+	Auth       AuthConfig       `yaml:"auth"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Health     HealthConfig     `yaml:"health"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Cache      CacheConfig      `yaml:"cache"`
+	Scheduler  SchedulerConfig  `yaml:"scheduler"`
+	Reminders  RemindersConfig  `yaml:"reminders"`
+	Features   FeaturesConfig   `yaml:"features"`
+}
+
+// Хранилища подписок
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+// ServerConfig параметры HTTP сервера
+type ServerConfig struct {
+	Port            int           `yaml:"port" env:"PORT" default:"8080" usage:"HTTP port"`
+	MetricsPort     int           `yaml:"metrics_port" env:"METRICS_PORT" default:"9090" usage:"internal HTTP port serving /metrics, kept off the public API"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"10s" usage:"maximum duration for reading the whole request"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"10s" usage:"maximum duration before timing out writes of the response"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" default:"60s" usage:"maximum time to wait for the next request on keep-alive connections"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"5s" usage:"time to finish in-flight requests on shutdown"`
+	DrainDelay      time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY" default:"0s" usage:"delay between failing readiness and draining connections"`
+	TrustedProxies  string        `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" usage:"comma-separated proxy IPs or CIDRs whose X-Forwarded-For is trusted; empty trusts none"`
+}
+
+// TrustedProxyList возвращает адреса доверенных прокси; пустой список означает, что заголовкам X-Forwarded-For не доверяем
+func (s ServerConfig) TrustedProxyList() []string {
+	var proxies []string
+	for _, p := range strings.Split(s.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// TLSConfig параметры TLS; сервер работает по HTTPS, если заданы сертификат и ключ
+// Файлы перечитываются по сигналу SIGHUP
+type TLSConfig struct {
+	CertFile     string `yaml:"cert_file" env:"TLS_CERT_FILE" usage:"PEM certificate file"`
+	KeyFile      string `yaml:"key_file" env:"TLS_KEY_FILE" usage:"PEM private key file"`
+	ClientCAFile string `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE" usage:"PEM CA bundle for verifying client certificates (mTLS)"`
+	ClientAuth   string `yaml:"client_auth" env:"TLS_CLIENT_AUTH" usage:"client certificate mode: none, optional or require (default optional when client_ca_file is set)"`
+}
+
+// MutualTLS сообщает, проверяются ли клиентские сертификаты
+func (c TLSConfig) MutualTLS() bool {
+	return c.ClientCAFile != "" && c.ClientAuth != tlsutil.ClientAuthNone
+}
+
+// Enabled сообщает, включен ли TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// DatabaseConfig параметры подключения к PostgreSQL и пула соединений
+// Нулевые значения параметров пула означают значения по умолчанию pgxpool
+type DatabaseConfig struct {
+	URL              string        `yaml:"url" env:"DATABASE_URL" usage:"PostgreSQL connection string"`
+	MaxConns         int           `yaml:"max_conns" env:"DB_MAX_CONNS" default:"0" usage:"maximum pool size (0 - pgxpool default)"`
+	MinConns         int           `yaml:"min_conns" env:"DB_MIN_CONNS" default:"0" usage:"minimum number of idle connections kept open"`
+	MaxConnLifetime  time.Duration `yaml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME" default:"0s" usage:"maximum connection age (0 - pgxpool default)"`
+	MaxConnIdleTime  time.Duration `yaml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME" default:"0s" usage:"close connections idle longer than this (0 - pgxpool default)"`
+	RowLevelSecurity bool          `yaml:"row_level_security" env:"TENANT_RLS" default:"false" usage:"run queries with app.organization_id for row-level security"`
+	ReplicaURL       string        `yaml:"replica_url" env:"DATABASE_REPLICA_URL" usage:"PostgreSQL read replica for GetByID, List and summary queries (empty - read from the primary)"`
+	ReadYourWrites   bool          `yaml:"read_your_writes" env:"DB_READ_YOUR_WRITES" default:"true" usage:"read from the primary for the rest of a request after it has written"`
+	SummaryRollup    bool          `yaml:"summary_rollup" env:"DB_SUMMARY_ROLLUP" default:"true" usage:"calculate summaries from the monthly_spend rollup table"`
+}
+
+// SQLiteConfig параметры хранилища SQLite (storage: sqlite)
+type SQLiteConfig struct {
+	Path string `yaml:"path" env:"SQLITE_PATH" default:"subscriptions.db" usage:"SQLite database file"`
+}
+
+// MigrationsConfig параметры применения миграций
+type MigrationsConfig struct {
+	OnStart     bool          `yaml:"on_start" env:"MIGRATE_ON_START" default:"false" usage:"apply pending migrations before serving"`
+	LockTimeout time.Duration `yaml:"lock_timeout" env:"MIGRATE_LOCK_TIMEOUT" default:"1m" usage:"how long to wait for another instance's migration lock"`
+}
+
+// LogConfig параметры логирования
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" default:"info" usage:"log level: debug, info, warn or error"`
+	Format string `yaml:"format" env:"LOG_FORMAT" default:"text" usage:"log format: text or json"`
+}
+
+// AuthConfig параметры аутентификации и авторизации
+type AuthConfig struct {
+	Disabled           bool   `yaml:"disabled" env:"AUTH_DISABLED" default:"false" usage:"run without authentication (development only)"`
+	HS256Secret        string `yaml:"jwt_hs256_secret" env:"JWT_HS256_SECRET" usage:"shared secret for HS256 tokens"`
+	RS256PublicKeyFile string `yaml:"jwt_rs256_public_key_file" env:"JWT_RS256_PUBLIC_KEY_FILE" usage:"PEM public key for RS256 tokens"`
+	JWKSFile           string `yaml:"jwt_jwks_file" env:"JWT_JWKS_FILE" usage:"JWKS file with RS256 keys"`
+	Issuer             string `yaml:"jwt_issuer" env:"JWT_ISSUER" usage:"required iss claim"`
+	Audience           string `yaml:"jwt_audience" env:"JWT_AUDIENCE" usage:"required aud claim"`
+	PolicyFile         string `yaml:"policy_file" env:"RBAC_POLICY_FILE" usage:"YAML file with the role-to-permission policy"`
+}
+
+// RateLimitConfig квоты запросов
+type RateLimitConfig struct {
+	Default string `yaml:"default" env:"RATE_LIMIT_DEFAULT" default:"600/m" usage:"quota for every route, N/s|m|h[:burst]"`
+	Routes  string `yaml:"routes" env:"RATE_LIMIT_ROUTES" default:"GET /subscriptions/summary=60/m" usage:"per-route quotas, \"METHOD /path=N/m; ...\""`
+	PerIP   string `yaml:"per_ip" env:"RATE_LIMIT_PER_IP" default:"1200/m" usage:"quota per client IP over all routes, checked before authentication"`
+}
+
+// TracingConfig параметры трассировки OpenTelemetry
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" default:"none" usage:"trace exporter: otlp, stdout or none"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" usage:"OTLP/HTTP collector endpoint"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE" default:"false" usage:"send traces without TLS"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" default:"subscriptions" usage:"service name in traces"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1" usage:"share of new traces to record, (0, 1]"`
+	// FlushTimeout время на отправку накопленных спанов при остановке
+	FlushTimeout time.Duration `yaml:"flush_timeout" env:"TRACING_FLUSH_TIMEOUT" default:"5s" usage:"time to export buffered spans on shutdown"`
+}
+
+// HealthConfig параметры проверок готовности
+type HealthConfig struct {
+	ReadinessTimeout time.Duration `yaml:"readiness_timeout" env:"READINESS_TIMEOUT" default:"2s" usage:"timeout of each readiness check"`
+}
+
+// MetricsConfig параметры метрик Prometheus
+type MetricsConfig struct {
+	// StatsInterval период обновления бизнес-показателей; запрос обходит подписки всех организаций
+	StatsInterval time.Duration `yaml:"stats_interval" env:"METRICS_STATS_INTERVAL" default:"1m" usage:"how often active subscription and spend gauges are recomputed"`
+}
+
+// CacheConfig параметры кэша сумм подписок
+type CacheConfig struct {
+	Size int           `yaml:"size" env:"SUMMARY_CACHE_SIZE" default:"1024" usage:"maximum number of cached summaries"`
+	TTL  time.Duration `yaml:"ttl" env:"SUMMARY_CACHE_TTL" default:"1m" usage:"lifetime of a cached summary (0 - until evicted or invalidated)"`
+}
+
+// SchedulerConfig параметры фоновых задач
+// Расписания задаются в формате cron из пяти полей или дескриптором (@daily, @every 1h), время UTC
+type SchedulerConfig struct {
+	PollInterval   time.Duration `yaml:"poll_interval" env:"SCHEDULER_POLL_INTERVAL" default:"15s" usage:"how often schedules and the leader lock are checked"`
+	StopTimeout    time.Duration `yaml:"stop_timeout" env:"SCHEDULER_STOP_TIMEOUT" default:"30s" usage:"time for running jobs to finish on shutdown"`
+	ExpireSchedule string        `yaml:"expire_schedule" env:"JOB_EXPIRE_SCHEDULE" default:"5 0 * * *" usage:"schedule of marking ended subscriptions as expired"`
+	RollupSchedule string        `yaml:"rollup_schedule" env:"JOB_ROLLUP_SCHEDULE" default:"30 3 * * 0" usage:"schedule of rebuilding the monthly spend rollup (postgres only)"`
+	// ChangesSchedule расписание применения запланированных изменений подписок
+	ChangesSchedule string `yaml:"changes_schedule" env:"JOB_CHANGES_SCHEDULE" default:"1 0 * * *" usage:"schedule of applying scheduled subscription changes"`
+	// RemindersSchedule расписание отправки напоминаний
+	RemindersSchedule string `yaml:"reminders_schedule" env:"JOB_REMINDERS_SCHEDULE" default:"0 8 * * *" usage:"schedule of sending renewal and expiry reminders"`
+	// BudgetsSchedule расписание проверки бюджетов
+	BudgetsSchedule string `yaml:"budgets_schedule" env:"JOB_BUDGETS_SCHEDULE" default:"0 * * * *" usage:"schedule of checking budgets and sending threshold alerts"`
+}
+
+// RemindersConfig параметры напоминаний о продлении и окончании подписок
+// Напоминания отправляются через все настроенные каналы: почту и webhook. Через них же отправляются уведомления о бюджетах
+type RemindersConfig struct {
+	DaysAhead int           `yaml:"days_ahead" env:"REMINDER_DAYS_AHEAD" default:"3" usage:"remind this many days before a renewal or the end of a subscription"`
+	SMTP      SMTPConfig    `yaml:"smtp"`
+	Webhook   WebhookConfig `yaml:"webhook"`
+}
+
+// SMTPConfig параметры отправки напоминаний по почте; пустой адрес сервера отключает почту
+type SMTPConfig struct {
+	Addr     string `yaml:"addr" env:"SMTP_ADDR" usage:"SMTP server host:port; STARTTLS is used when the server offers it"`
+	Username string `yaml:"username" env:"SMTP_USERNAME" usage:"SMTP PLAIN auth username (empty - no auth)"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" usage:"SMTP PLAIN auth password"`
+	From     string `yaml:"from" env:"SMTP_FROM" usage:"sender address of reminder emails"`
+}
+
+// WebhookConfig параметры отправки напоминаний на webhook; пустой URL отключает webhook
+type WebhookConfig struct {
+	URL     string        `yaml:"url" env:"REMINDER_WEBHOOK_URL" usage:"URL that receives reminders as JSON POST requests"`
+	Secret  string        `yaml:"secret" env:"REMINDER_WEBHOOK_SECRET" usage:"HMAC-SHA256 key for the X-Signature-256 header (empty - unsigned)"`
+	Timeout time.Duration `yaml:"timeout" env:"REMINDER_WEBHOOK_TIMEOUT" default:"5s" usage:"timeout of a webhook request"`
+}
+
+// FeaturesConfig включает и отключает отдельные возможности сервиса
+type FeaturesConfig struct {
+	RateLimit    bool `yaml:"rate_limit" env:"FEATURE_RATE_LIMIT" default:"true" usage:"enable rate limiting"`
+	Metrics      bool `yaml:"metrics" env:"FEATURE_METRICS" default:"true" usage:"enable the /metrics endpoint on server.metrics_port"`
+	Swagger      bool `yaml:"swagger" env:"FEATURE_SWAGGER" default:"true" usage:"serve Swagger UI at /swagger"`
+	APIKeys      bool `yaml:"api_keys" env:"FEATURE_API_KEYS" default:"true" usage:"enable API key authentication and management"`
+	SummaryCache bool `yaml:"summary_cache" env:"FEATURE_SUMMARY_CACHE" default:"true" usage:"cache summary results in process memory"`
+	Scheduler    bool `yaml:"scheduler" env:"FEATURE_SCHEDULER" default:"true" usage:"run background jobs and serve /admin/jobs"`
+	Reminders    bool `yaml:"reminders" env:"FEATURE_REMINDERS" default:"true" usage:"send renewal and expiry reminders and serve reminder preferences"`
+	Budgets      bool `yaml:"budgets" env:"FEATURE_BUDGETS" default:"true" usage:"serve /budgets and send budget threshold alerts"`
+	Catalog      bool `yaml:"catalog" env:"FEATURE_CATALOG" default:"true" usage:"serve /services and match subscription names to the service catalog"`
+}
+
+// APIKeysAvailable сообщает, включены ли API ключи; они хранятся только в PostgreSQL
+func (c *Config) APIKeysAvailable() bool {
+	return c.Features.APIKeys && c.Storage == StoragePostgres
+}
+
+// Validate проверяет значения и возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Features.Metrics {
+		if c.Server.MetricsPort < 1 || c.Server.MetricsPort > 65535 {
+			fail("server.metrics_port", "must be between 1 and 65535, got %d", c.Server.MetricsPort)
+		} else if c.Server.MetricsPort == c.Server.Port {
+			fail("server.metrics_port", "must differ from server.port, metrics are not served on the public port")
+		}
+		if c.Metrics.StatsInterval <= 0 {
+			fail("metrics.stats_interval", "must be positive, got %s", c.Metrics.StatsInterval)
+		}
+	}
+	for _, p := range c.Server.TrustedProxyList() {
+		if net.ParseIP(p) == nil {
+			if _, _, err := net.ParseCIDR(p); err != nil {
+				fail("server.trusted_proxies", "invalid IP or CIDR %q", p)
+			}
+		}
+	}
+	type durationParam struct {
+		key string
+		d   time.Duration
+	}
+	for _, p := range []durationParam{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"health.readiness_timeout", c.Health.ReadinessTimeout},
+		{"tracing.flush_timeout", c.Tracing.FlushTimeout},
+	} {
+		if p.d <= 0 {
+			fail(p.key, "must be positive, got %s", p.d)
+		}
+	}
+	for _, p := range []durationParam{
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.drain_delay", c.Server.DrainDelay},
+		{"database.max_conn_lifetime", c.Database.MaxConnLifetime},
+		{"database.max_conn_idle_time", c.Database.MaxConnIdleTime},
+		{"migrations.lock_timeout", c.Migrations.LockTimeout},
+		{"cache.ttl", c.Cache.TTL},
+	} {
+		if p.d < 0 {
+			fail(p.key, "must not be negative, got %s", p.d)
+		}
+	}
+
+	if c.Features.SummaryCache && c.Cache.Size <= 0 {
+		fail("cache.size", "must be positive, got %d", c.Cache.Size)
+	}
+
+	if c.Features.Scheduler {
+		if c.Scheduler.PollInterval <= 0 {
+			fail("scheduler.poll_interval", "must be positive, got %s", c.Scheduler.PollInterval)
+		}
+		if c.Scheduler.StopTimeout <= 0 {
+			fail("scheduler.stop_timeout", "must be positive, got %s", c.Scheduler.StopTimeout)
+		}
+		for _, p := range [][2]string{
+			{"scheduler.expire_schedule", c.Scheduler.ExpireSchedule},
+			{"scheduler.rollup_schedule", c.Scheduler.RollupSchedule},
+			{"scheduler.changes_schedule", c.Scheduler.ChangesSchedule},
+			{"scheduler.reminders_schedule", c.Scheduler.RemindersSchedule},
+			{"scheduler.budgets_schedule", c.Scheduler.BudgetsSchedule},
+		} {
+			if _, err := scheduler.ParseSchedule(p[1]); err != nil {
+				fail(p[0], "%v", err)
+			}
+		}
+	}
+
+	if c.Features.Reminders && (c.Reminders.DaysAhead < 1 || c.Reminders.DaysAhead > 365) {
+		fail("reminders.days_ahead", "must be between 1 and 365, got %d", c.Reminders.DaysAhead)
+	}
+	// Каналы доставки общие для напоминаний и уведомлений о бюджетах
+	if c.Features.Reminders || c.Features.Budgets {
+		if c.Reminders.SMTP.Addr != "" {
+			if _, _, err := net.SplitHostPort(c.Reminders.SMTP.Addr); err != nil {
+				fail("reminders.smtp.addr", "must be host:port: %v", err)
+			}
+			if _, err := mail.ParseAddress(c.Reminders.SMTP.From); err != nil {
+				fail("reminders.smtp.from", "must be an email address: %v", err)
+			}
+		}
+		if c.Reminders.Webhook.URL != "" {
+			if u, err := url.Parse(c.Reminders.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				fail("reminders.webhook.url", "must be an http or https URL, got %q", c.Reminders.Webhook.URL)
+			}
+			if c.Reminders.Webhook.Timeout <= 0 {
+				fail("reminders.webhook.timeout", "must be positive, got %s", c.Reminders.Webhook.Timeout)
+			}
+		}
+	}
+
+	if c.TLS.Enabled() {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			fail("tls", "cert_file and key_file must be set together")
+		}
+		for _, p := range [][2]string{{"tls.cert_file", c.TLS.CertFile}, {"tls.key_file", c.TLS.KeyFile}, {"tls.client_ca_file", c.TLS.ClientCAFile}} {
+			if p[1] != "" {
+				if _, err := os.Stat(p[1]); err != nil {
+					fail(p[0], "%v", err)
+				}
+			}
+		}
+	}
+
+	if !c.TLS.Enabled() && (c.TLS.ClientCAFile != "" || c.TLS.ClientAuth != "") {
+		fail("tls", "client_ca_file and client_auth require cert_file and key_file")
+	}
+	switch c.TLS.ClientAuth {
+	case "", tlsutil.ClientAuthNone:
+	case tlsutil.ClientAuthOptional, tlsutil.ClientAuthRequire:
+		if c.TLS.ClientCAFile == "" {
+			fail("tls.client_auth", "%q requires tls.client_ca_file", c.TLS.ClientAuth)
+		}
+	default:
+		fail("tls.client_auth", "must be none, optional or require, got %q", c.TLS.ClientAuth)
+	}
+
+	switch c.Storage {
+	case StoragePostgres:
+		if c.Database.URL == "" {
+			fail("database.url", "is required (set DATABASE_URL)")
+		}
+	case StorageSQLite:
+		if c.SQLite.Path == "" {
+			fail("sqlite.path", "is required (set SQLITE_PATH)")
+		}
+	case StorageMemory:
+	default:
+		fail("storage", "must be postgres, sqlite or memory, got %q", c.Storage)
+	}
+	if c.Database.MaxConns < 0 {
+		fail("database.max_conns", "must not be negative, got %d", c.Database.MaxConns)
+	}
+	if c.Database.MinConns < 0 {
+		fail("database.min_conns", "must not be negative, got %d", c.Database.MinConns)
+	}
+	if c.Database.MaxConns > 0 && c.Database.MinConns > c.Database.MaxConns {
+		fail("database.min_conns", "must not exceed database.max_conns (%d), got %d", c.Database.MaxConns, c.Database.MinConns)
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		fail("log.level", "%v", err)
+	}
+	if f := strings.ToLower(c.Log.Format); f != logging.FormatText && f != logging.FormatJSON {
+		fail("log.format", "must be text or json, got %q", c.Log.Format)
+	}
+
+	sources := 0
+	for _, s := range []string{c.Auth.HS256Secret, c.Auth.RS256PublicKeyFile, c.Auth.JWKSFile} {
+		if s != "" {
+			sources++
+		}
+	}
+	switch {
+	case sources > 1:
+		fail("auth", "only one of jwt_hs256_secret, jwt_rs256_public_key_file and jwt_jwks_file may be set")
+	case sources == 0 && !c.Auth.Disabled && !c.TLS.MutualTLS() && !c.APIKeysAvailable():
+		fail("auth", "no authentication configured: set JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY_FILE or JWT_JWKS_FILE, enable mTLS with TLS_CLIENT_CA_FILE or API keys with PostgreSQL storage (or AUTH_DISABLED=true)")
+	}
+
+	if c.Features.RateLimit {
+		if _, err := ratelimit.ParseLimit(c.RateLimit.Default); err != nil {
+			fail("rate_limit.default", "%v", err)
+		}
+		if _, err := ratelimit.ParseRoutes(c.RateLimit.Routes); err != nil {
+			fail("rate_limit.routes", "%v", err)
+		}
+		if _, err := ratelimit.ParseLimit(c.RateLimit.PerIP); err != nil {
+			fail("rate_limit.per_ip", "%v", err)
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	default:
+		fail("tracing.exporter", "must be otlp, stdout or none, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio <= 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio", "must be in (0, 1], got %v", c.Tracing.SampleRatio)
+	}
+
+	return errors.Join(errs...)
+}
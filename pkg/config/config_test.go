@@ -0,0 +1,223 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envMap(m map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, args, err := Load(nil, envMap(nil), io.Discard)
+	require.NoError(t, err)
+	assert.Empty(t, args)
+
+	assert.Equal(t, 8080, cfg.Server.Port)
+	assert.Equal(t, 10*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 5*time.Second, cfg.Server.ShutdownTimeout)
+	assert.Equal(t, "info", cfg.Log.Level)
+	assert.Equal(t, "600/m", cfg.RateLimit.Default)
+	assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+	assert.True(t, cfg.Features.Metrics)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  read_timeout: 3s
+  write_timeout: 4s
+log:
+  level: debug
+database:
+  max_conns: 20
+`)
+
+	cfg, args, err := Load(
+		[]string{"-config", path, "-server.port=9100", "-features.swagger=false", "migrate", "up"},
+		envMap(map[string]string{"SERVER_READ_TIMEOUT": "7s", "PORT": "9050", "DATABASE_URL": "postgres://localhost/db"}),
+		io.Discard,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"migrate", "up"}, args)
+	assert.Equal(t, 9100, cfg.Server.Port, "flag wins over env and file")
+	assert.Equal(t, 7*time.Second, cfg.Server.ReadTimeout, "env wins over file")
+	assert.Equal(t, 4*time.Second, cfg.Server.WriteTimeout, "file wins over default")
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, 20, cfg.Database.MaxConns)
+	assert.Equal(t, "postgres://localhost/db", cfg.Database.URL)
+	assert.False(t, cfg.Features.Swagger)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[server]
+port = 9200
+idle_timeout = "2m"
+
+[features]
+rate_limit = false
+`)
+
+	cfg, _, err := Load(nil, envMap(map[string]string{"CONFIG_FILE": path}), io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, 9200, cfg.Server.Port)
+	assert.Equal(t, 2*time.Minute, cfg.Server.IdleTimeout)
+	assert.False(t, cfg.Features.RateLimit)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		file string
+		err  string
+	}{
+		{"unknown file key", nil, nil, "server:\n  prot: 1\n", `unknown key "server.prot"`},
+		{"bad file value", nil, nil, "server:\n  read_timeout: 10\n", "server.read_timeout: invalid duration"},
+		{"bad env value", nil, map[string]string{"PORT": "http"}, "", `env PORT (server.port): invalid integer "http"`},
+		{"bad flag value", []string{"-features.metrics=maybe"}, nil, "", `flag -features.metrics: invalid boolean "maybe"`},
+		{"unknown flag", []string{"-nope"}, nil, "", "flag provided but not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "config.yml", tt.file)}, args...)
+			}
+			_, _, err := Load(args, envMap(tt.env), io.Discard)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *Config {
+		cfg, _, err := Load(nil, envMap(map[string]string{
+			"DATABASE_URL":     "postgres://localhost/db",
+			"JWT_HS256_SECRET": "secret",
+		}), io.Discard)
+		require.NoError(t, err)
+		return cfg
+	}
+
+	require.NoError(t, valid().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		err    string
+	}{
+		{"port out of range", func(c *Config) { c.Server.Port = 70000 }, "server.port"},
+		{"metrics on public port", func(c *Config) { c.Server.MetricsPort = c.Server.Port }, "server.metrics_port: must differ"},
+		{"zero read timeout", func(c *Config) { c.Server.ReadTimeout = 0 }, "server.read_timeout: must be positive"},
+		{"zero scheduler stop timeout", func(c *Config) { c.Scheduler.StopTimeout = 0 }, "scheduler.stop_timeout: must be positive"},
+		{"negative drain delay", func(c *Config) { c.Server.DrainDelay = -time.Second }, "server.drain_delay"},
+		{"trusted proxy", func(c *Config) { c.Server.TrustedProxies = "10.0.0.1, lb" }, `server.trusted_proxies: invalid IP or CIDR "lb"`},
+		{"missing database url", func(c *Config) { c.Database.URL = "" }, "database.url"},
+		{"unknown storage", func(c *Config) { c.Storage = "redis" }, "storage: must be postgres, sqlite or memory"},
+		{"min conns above max", func(c *Config) { c.Database.MaxConns = 2; c.Database.MinConns = 5 }, "database.min_conns"},
+		{"tls without key", func(c *Config) { c.TLS.CertFile = "cert.pem" }, "cert_file and key_file must be set together"},
+		{"client ca without tls", func(c *Config) { c.TLS.ClientCAFile = "ca.pem" }, "client_ca_file and client_auth require"},
+		{"client auth mode", func(c *Config) { c.TLS.ClientAuth = "always" }, "tls.client_auth"},
+		{"log level", func(c *Config) { c.Log.Level = "verbose" }, "log.level"},
+		{"two jwt key sources", func(c *Config) { c.Auth.JWKSFile = "jwks.json" }, "only one of"},
+		{"no authentication", func(c *Config) { c.Auth.HS256Secret = ""; c.Features.APIKeys = false }, "no authentication configured"},
+		{"rate limit spec", func(c *Config) { c.RateLimit.Default = "fast" }, "rate_limit.default"},
+		{"tracing exporter", func(c *Config) { c.Tracing.Exporter = "jaeger" }, "tracing.exporter"},
+		{"reminders schedule", func(c *Config) { c.Scheduler.RemindersSchedule = "daily" }, "scheduler.reminders_schedule"},
+		{"reminder days", func(c *Config) { c.Reminders.DaysAhead = 0 }, "reminders.days_ahead"},
+		{"smtp without sender", func(c *Config) { c.Reminders.SMTP.Addr = "localhost:25" }, "reminders.smtp.from"},
+		{"webhook url", func(c *Config) { c.Reminders.Webhook.URL = "ftp://example.com" }, "reminders.webhook.url"},
+		{"budgets schedule", func(c *Config) { c.Scheduler.BudgetsSchedule = "hourly" }, "scheduler.budgets_schedule"},
+		{"budget channels without reminders", func(c *Config) {
+			c.Features.Reminders = false
+			c.Reminders.Webhook.URL = "ftp://example.com"
+		}, "reminders.webhook.url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	t.Run("auth disabled without key", func(t *testing.T) {
+		cfg := valid()
+		cfg.Auth.HS256Secret = ""
+		cfg.Auth.Disabled = true
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("api keys without jwt key", func(t *testing.T) {
+		cfg := valid()
+		cfg.Auth.HS256Secret = ""
+		assert.NoError(t, cfg.Validate())
+
+		cfg.Features.APIKeys = false
+		assert.ErrorContains(t, cfg.Validate(), "no authentication configured")
+
+		cfg.Features.APIKeys = true
+		cfg.Storage = StorageMemory
+		assert.ErrorContains(t, cfg.Validate(), "no authentication configured")
+	})
+
+	t.Run("memory storage without database url", func(t *testing.T) {
+		cfg := valid()
+		cfg.Storage = StorageMemory
+		cfg.Database.URL = ""
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("sqlite storage without database url", func(t *testing.T) {
+		cfg := valid()
+		cfg.Storage = StorageSQLite
+		cfg.Database.URL = ""
+		assert.NoError(t, cfg.Validate())
+
+		cfg.SQLite.Path = ""
+		assert.ErrorContains(t, cfg.Validate(), "sqlite.path")
+	})
+
+	t.Run("mtls without jwt key", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"cert.pem", "key.pem", "ca.pem"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("pem"), 0o600))
+		}
+
+		cfg := valid()
+		cfg.Auth.HS256Secret = ""
+		cfg.TLS = TLSConfig{
+			CertFile:     filepath.Join(dir, "cert.pem"),
+			KeyFile:      filepath.Join(dir, "key.pem"),
+			ClientCAFile: filepath.Join(dir, "ca.pem"),
+		}
+		assert.NoError(t, cfg.Validate())
+	})
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// field описывает один параметр конфигурации
+type field struct {
+	key   string
+	env   string
+	def   string
+	usage string
+	value reflect.Value
+}
+
+// Load собирает конфигурацию из значений по умолчанию, файла, переменных окружения и флагов
+// Путь к файлу задается флагом -config или переменной CONFIG_FILE; формат определяется расширением (.yaml, .yml, .toml)
+// args - аргументы командной строки без имени программы; вторым значением возвращаются
+// оставшиеся позиционные аргументы (например, подкоманда migrate)
+// Load не проверяет значения, для этого есть Validate
+func Load(args []string, lookupEnv func(string) (string, bool), output io.Writer) (*Config, []string, error) {
+	cfg := &Config{}
+	fields := collectFields(reflect.ValueOf(cfg).Elem(), "")
+
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+		if err := setValue(f.value, f.def); err != nil {
+			return nil, nil, fmt.Errorf("default of %s: %w", f.key, err)
+		}
+	}
+
+	// Флаги разбираются первыми, чтобы узнать путь к файлу, но применяются последними
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(output)
+	configFile := fs.String("config", "", "configuration file (.yaml, .yml or .toml), env CONFIG_FILE")
+
+	var flagValues []func() error
+	for _, f := range fields {
+		usage := f.usage
+		if f.env != "" {
+			usage += ", env " + f.env
+		}
+		fs.Var(&flagValue{
+			field: f,
+			set: func(s string) {
+				flagValues = append(flagValues, func() error {
+					if err := setValue(f.value, s); err != nil {
+						return fmt.Errorf("flag -%s: %w", f.key, err)
+					}
+					return nil
+				})
+			},
+		}, f.key, usage)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: server [flags] [migrate <command>]\n\nFlags take precedence over environment variables, which take precedence over the config file.\n\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(path, fields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if v, ok := lookupEnv(f.env); ok {
+			if err := setValue(f.value, v); err != nil {
+				return nil, nil, fmt.Errorf("env %s (%s): %w", f.env, f.key, err)
+			}
+		}
+	}
+
+	for _, apply := range flagValues {
+		if err := apply(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// loadFile применяет значения из файла конфигурации
+// Неизвестные ключи считаются ошибкой, чтобы опечатки не проходили незамеченными
+func loadFile(path string, fields []field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("unsupported config file extension %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	byKey := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+	}
+
+	values := map[string]any{}
+	flatten("", raw, values)
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f, ok := byKey[key]
+		if !ok {
+			return fmt.Errorf("%s: unknown key %q", path, key)
+		}
+		if err := setValue(f.value, fmt.Sprint(values[key])); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+	}
+
+	return nil
+}
+
+// flatten превращает вложенные таблицы в ключи вида server.port
+func flatten(prefix string, in map[string]any, out map[string]any) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// collectFields обходит структуру конфигурации и возвращает ее параметры
+func collectFields(v reflect.Value, prefix string) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("yaml")
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+			fields = append(fields, collectFields(v.Field(i), name)...)
+			continue
+		}
+
+		fields = append(fields, field{
+			key:   name,
+			env:   sf.Tag.Get("env"),
+			def:   sf.Tag.Get("default"),
+			usage: sf.Tag.Get("usage"),
+			value: v.Field(i),
+		})
+	}
+	return fields
+}
+
+// setValue разбирает строку в значение поля по его типу
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected a value like 500ms, 5s or 1m", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, expected true or false", s)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(x)
+	default:
+		return errors.New("unsupported config field type " + v.Type().String())
+	}
+	return nil
+}
+
+// flagValue флаг командной строки, откладывающий применение значения
+type flagValue struct {
+	field field
+	set   func(string)
+}
+
+func (f *flagValue) String() string {
+	if f == nil || f.field.def == "" {
+		return ""
+	}
+	return f.field.def
+}
+
+func (f *flagValue) Set(s string) error {
+	f.set(s)
+	return nil
+}
+
+// IsBoolFlag позволяет писать -features.metrics вместо -features.metrics=true
+func (f *flagValue) IsBoolFlag() bool {
+	return f.field.value.Kind() == reflect.Bool
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Области доступа API ключей
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// APIKey представляет ключ для межсервисного доступа
+// Сам ключ не хранится, хранится только его хэш
+type APIKey struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	Prefix         string
+	Hash           []byte
+	Scopes         []string
+	ExpiresAt      sql.NullTime
+	RevokedAt      sql.NullTime
+	LastUsedAt     sql.NullTime
+	CreatedAt      time.Time
+}
+
+// Active сообщает, можно ли использовать ключ в момент now
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt.Valid {
+		return false
+	}
+	return !k.ExpiresAt.Valid || now.Before(k.ExpiresAt.Time)
+}
+
+// APIKeyRepository определяет интерфейс репозитория API ключей
+// Create, List и Revoke ограничены организацией из контекста, GetByHash ищет по всем организациям
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) (*APIKey, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string) error
+	GetByHash(ctx context.Context, hash []byte) (*APIKey, error)
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Периоды бюджета
+const (
+	BudgetMonthly = "monthly"
+	BudgetYearly  = "yearly"
+)
+
+// Области действия бюджета; определяются заполненными фильтрами
+const (
+	BudgetScopeGlobal  = "global"
+	BudgetScopeUser    = "user"
+	BudgetScopeService = "service"
+)
+
+// DefaultBudgetThresholds пороги в процентах от лимита, если они не заданы при создании бюджета
+var DefaultBudgetThresholds = []int{80, 100}
+
+// Budget ограничение расходов на подписки за месяц или год
+// Пустые UserID и ServiceName означают бюджет на все подписки организации
+type Budget struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	Period         string
+	Limit          int64
+	UserID         string
+	ServiceName    string
+	// Thresholds пороги уведомлений в процентах от лимита по возрастанию
+	Thresholds []int
+	// Email адрес для уведомлений о порогах; пустой - только webhook
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Scope возвращает область действия бюджета
+// Бюджет с пользователем и сервисом одновременно относится к пользователю
+func (b *Budget) Scope() string {
+	switch {
+	case b.UserID != "":
+		return BudgetScopeUser
+	case b.ServiceName != "":
+		return BudgetScopeService
+	}
+	return BudgetScopeGlobal
+}
+
+// PeriodAt возвращает первый и последний месяцы периода бюджета, в который попадает t
+func (b *Budget) PeriodAt(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	if b.Period == BudgetYearly {
+		start := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 11, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start
+}
+
+// BudgetFilters содержит параметры фильтрации для списка бюджетов
+type BudgetFilters struct {
+	UserID string
+}
+
+// BudgetStatus расходы за текущий период бюджета
+type BudgetStatus struct {
+	Budget      *Budget
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// Spent ожидаемые расходы за весь период по подпискам, как в сумме подписок
+	Spent int64
+}
+
+// Percent доля лимита, израсходованная за период, в процентах с округлением вниз
+func (s *BudgetStatus) Percent() int64 {
+	return s.Spent * 100 / s.Budget.Limit
+}
+
+// Remaining остаток лимита; отрицательный, если лимит превышен
+func (s *BudgetStatus) Remaining() int64 {
+	return s.Budget.Limit - s.Spent
+}
+
+// Exceeded сообщает, превышен ли лимит
+func (s *BudgetStatus) Exceeded() bool {
+	return s.Spent > s.Budget.Limit
+}
+
+// Reached возвращает достигнутые пороги по возрастанию
+func (s *BudgetStatus) Reached() []int {
+	var reached []int
+	for _, threshold := range s.Budget.Thresholds {
+		if s.Spent*100 >= int64(threshold)*s.Budget.Limit {
+			reached = append(reached, threshold)
+		}
+	}
+	return reached
+}
+
+// BudgetAlert уведомление о достижении порога бюджета в периоде
+type BudgetAlert struct {
+	OrganizationID string
+	BudgetID       string
+	Name           string
+	Period         string
+	PeriodStart    time.Time
+	Threshold      int
+	Limit          int64
+	Spent          int64
+	UserID         string
+	ServiceName    string
+	Email          string
+}
+
+// BudgetRepository определяет интерфейс хранилища бюджетов и отправленных уведомлений
+// Все методы, кроме ListAll и работы с уведомлениями, ограничены организацией из контекста
+type BudgetRepository interface {
+	Create(ctx context.Context, budget *Budget) (*Budget, error)
+	GetByID(ctx context.Context, id string) (*Budget, error)
+	Update(ctx context.Context, id string, budget *Budget) (*Budget, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filters BudgetFilters) ([]*Budget, error)
+	// ListAll возвращает бюджеты всех организаций для фоновой проверки
+	ListAll(ctx context.Context) ([]*Budget, error)
+	// ClaimAlert отмечает уведомление о пороге отправленным; false означает, что оно уже было отмечено
+	ClaimAlert(ctx context.Context, alert *BudgetAlert) (bool, error)
+	// ReleaseAlert снимает отметку, если уведомление не удалось отправить
+	ReleaseAlert(ctx context.Context, alert *BudgetAlert) error
+}
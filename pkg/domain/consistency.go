@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type writeTrackerKey struct{}
+
+// WithWriteTracking отмечает контекст запроса для отслеживания записей (read-your-writes)
+// После первой записи в рамках такого контекста репозитории с репликой читают с основной базы,
+// чтобы запрос увидел собственные изменения, даже если реплика отстает
+func WithWriteTracking(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(writeTrackerKey{}).(*atomic.Bool); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, writeTrackerKey{}, new(atomic.Bool))
+}
+
+// MarkWritten отмечает, что в рамках контекста выполнена запись; без WithWriteTracking ничего не делает
+func MarkWritten(ctx context.Context) {
+	if wrote, ok := ctx.Value(writeTrackerKey{}).(*atomic.Bool); ok {
+		wrote.Store(true)
+	}
+}
+
+// HasWritten сообщает, выполнялась ли запись в рамках контекста
+func HasWritten(ctx context.Context) bool {
+	wrote, ok := ctx.Value(writeTrackerKey{}).(*atomic.Bool)
+	return ok && wrote.Load()
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Способы запуска фоновой задачи
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// Состояния запуска фоновой задачи
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// JobRun запись истории запуска фоновой задачи
+type JobRun struct {
+	ID      string
+	Job     string
+	Trigger string
+	Status  string
+	// Error текст ошибки неудачного запуска
+	Error string
+	// Instance экземпляр сервиса, выполнявший задачу
+	Instance   string
+	StartedAt  time.Time
+	FinishedAt sql.NullTime
+}
+
+// JobRunFilters содержит параметры выборки истории запусков
+type JobRunFilters struct {
+	// Job ограничивает выборку одной задачей; пустое значение - все задачи
+	Job   string
+	Limit int
+}
+
+// JobRunRepository определяет интерфейс хранилища истории запусков фоновых задач
+// История общая для всех организаций
+type JobRunRepository interface {
+	Create(ctx context.Context, run *JobRun) (*JobRun, error)
+	// Finish сохраняет состояние, ошибку и время окончания запуска
+	Finish(ctx context.Context, run *JobRun) error
+	// List возвращает запуски от новых к старым
+	List(ctx context.Context, filters JobRunFilters) ([]*JobRun, error)
+}
+
+// Lock удерживаемая блокировка
+type Lock interface {
+	// Check проверяет, что блокировка все еще удерживается
+	Check(ctx context.Context) error
+	Unlock()
+}
+
+// Locker выдает именованные блокировки, общие для всех экземпляров сервиса
+type Locker interface {
+	// TryLock берет блокировку без ожидания; nil без ошибки означает, что она занята
+	TryLock(ctx context.Context, name string) (Lock, error)
+}
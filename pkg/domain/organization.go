@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultOrganizationID организация, к которой относятся запросы без явно указанной организации
+const DefaultOrganizationID = "00000000-0000-0000-0000-000000000000"
+
+type organizationKey struct{}
+
+// WithOrganization кладет идентификатор организации (арендатора) в контекст запроса
+func WithOrganization(ctx context.Context, organizationID string) context.Context {
+	return context.WithValue(ctx, organizationKey{}, organizationID)
+}
+
+// OrganizationFromContext возвращает организацию из контекста или организацию по умолчанию
+// Все запросы репозиториев ограничиваются этой организацией
+func OrganizationFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(organizationKey{}).(string); ok && id != "" {
+		return id
+	}
+	return DefaultOrganizationID
+}
+
+// Organization организация (арендатор), к которой относятся подписки, ключи и остальные данные
+type Organization struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// OrganizationRepository определяет интерфейс репозитория организаций
+// Организации относятся к платформе, поэтому запросы не ограничиваются организацией из контекста
+type OrganizationRepository interface {
+	// Create создает организацию с ID из org или новым, если он пуст; ID и названия организаций уникальны
+	Create(ctx context.Context, org *Organization) (*Organization, error)
+	// Exists сообщает, что организация с таким ID существует
+	Exists(ctx context.Context, id string) (bool, error)
+	// List возвращает организации по названию
+	List(ctx context.Context) ([]*Organization, error)
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Виды напоминаний
+const (
+	// ReminderRenewal подписка продлится и будет списана оплата за следующий месяц
+	ReminderRenewal = "renewal"
+	// ReminderExpiry подписка закончится: ее последний месяц (end_date) подходит к концу
+	ReminderExpiry = "expiry"
+)
+
+// ErrNoRecipient возвращается каналом уведомлений, если у пользователя нет адреса для этого канала
+var ErrNoRecipient = errors.New("reminder has no recipient address")
+
+// Reminder напоминание о предстоящем списании или окончании подписки
+type Reminder struct {
+	OrganizationID string
+	SubscriptionID string
+	UserID         string
+	ServiceName    string
+	Price          int64
+	Kind           string
+	// Date первое число месяца, в котором произойдет списание или с которого подписка перестанет действовать
+	Date time.Time
+	// Email адрес из настроек пользователя; пустой, если не задан
+	Email string
+}
+
+// ReminderPreferences настройки напоминаний пользователя
+type ReminderPreferences struct {
+	UserID  string
+	Email   string
+	Renewal bool
+	Expiry  bool
+	// UpdatedAt нулевое, если пользователь не менял настройки
+	UpdatedAt time.Time
+}
+
+// DefaultReminderPreferences возвращает настройки пользователя, который их не менял: все напоминания включены
+func DefaultReminderPreferences(userID string) *ReminderPreferences {
+	return &ReminderPreferences{UserID: userID, Renewal: true, Expiry: true}
+}
+
+// ReminderRepository определяет интерфейс хранилища напоминаний и настроек пользователей
+type ReminderRepository interface {
+	// Due возвращает напоминания всех организаций с датой в интервале (from, to],
+	// которые еще не отправлены и от которых пользователь не отказался
+	Due(ctx context.Context, from, to time.Time) ([]*Reminder, error)
+	// Claim отмечает напоминание отправленным; false означает, что оно уже было отмечено
+	Claim(ctx context.Context, r *Reminder) (bool, error)
+	// Release снимает отметку, если напоминание не удалось отправить
+	Release(ctx context.Context, r *Reminder) error
+	// GetPreferences возвращает настройки пользователя организации из контекста или настройки по умолчанию
+	GetPreferences(ctx context.Context, userID string) (*ReminderPreferences, error)
+	SavePreferences(ctx context.Context, prefs *ReminderPreferences) (*ReminderPreferences, error)
+}
+
+// ReminderDates возвращает первые числа месяцев в интервале (from, to], в которые могут прийтись напоминания
+func ReminderDates(from, to time.Time) []time.Time {
+	from, to = from.UTC(), to.UTC()
+	var dates []time.Time
+	for d := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0); !d.After(to); d = d.AddDate(0, 1, 0) {
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// ReminderKind определяет вид напоминания по подписке на первое число месяца date
+// Подписка, действующая в предыдущем месяце, либо продлевается, либо заканчивается, если это ее последний месяц.
+// О продлении на месяц паузы не напоминают: оплаты в нем нет. Запланированная отмена считается окончанием
+func ReminderKind(sub *Subscription, date time.Time) (string, bool) {
+	last := date.AddDate(0, -1, 0)
+	end := sub.EffectiveEnd()
+	switch {
+	case sub.StartDate.After(last):
+		return "", false
+	case !end.Valid || end.Time.After(last):
+		if sub.PausedAt(date) {
+			return "", false
+		}
+		return ReminderRenewal, true
+	case end.Time.Equal(last):
+		return ReminderExpiry, true
+	}
+	return "", false
+}
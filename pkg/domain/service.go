@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Service запись каталога сервисов организации
+// Подписки с названием сервиса или одним из его псевдонимов получают каноническое название Name
+type Service struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	// Aliases другие написания названия, например "Кинопоиск" для "Yandex Plus"
+	Aliases   []string
+	Category  string
+	VendorURL string
+	// Plans тарифы сервиса с ценами по умолчанию в порядке добавления
+	Plans     []ServicePlan
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ServicePlan тариф сервиса; Price подставляется в подписку, если цена не указана
+type ServicePlan struct {
+	Name  string
+	Price int64
+}
+
+// Keys возвращает нормализованные название и псевдонимы сервиса без повторов
+func (s *Service) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, name := range append([]string{s.Name}, s.Aliases...) {
+		key := NormalizeServiceName(name)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Covers проверяет, относится ли к сервису подписка: связана с ним или, не связанная с каталогом, названа
+// одним из его написаний
+func (s *Service) Covers(serviceID, serviceName string) bool {
+	if serviceID != "" {
+		return serviceID == s.ID
+	}
+	key := NormalizeServiceName(serviceName)
+	for _, k := range s.Keys() {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan ищет тариф по названию без учета регистра, пробелов и знаков
+func (s *Service) Plan(name string) (ServicePlan, bool) {
+	key := NormalizeServiceName(name)
+	for _, p := range s.Plans {
+		if NormalizeServiceName(p.Name) == key {
+			return p, true
+		}
+	}
+	return ServicePlan{}, false
+}
+
+// NormalizeServiceName приводит название к ключу сравнения: буквы в нижнем регистре и цифры
+// "Yandex Plus", "yandex plus" и "YandexPlus" дают один ключ
+func NormalizeServiceName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// ServiceFilters содержит параметры фильтрации для списка сервисов
+type ServiceFilters struct {
+	Category string
+}
+
+// ServiceRepository определяет интерфейс репозитория каталога сервисов
+// Ключи названий и псевдонимов уникальны в пределах организации
+type ServiceRepository interface {
+	Create(ctx context.Context, svc *Service) (*Service, error)
+	GetByID(ctx context.Context, id string) (*Service, error)
+	Update(ctx context.Context, id string, svc *Service) (*Service, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filters ServiceFilters) ([]*Service, error)
+	// FindByName ищет сервис, название или псевдоним которого совпадает с name после NormalizeServiceName
+	// Возвращает nil без ошибки, если такого сервиса нет
+	FindByName(ctx context.Context, name string) (*Service, error)
+}
@@ -3,44 +3,376 @@ package domain
 import (
 	"context"
 	"database/sql"
+	"math"
+	"sort"
 	"time"
 )
 
+// Состояния подписки
+const (
+	SubscriptionActive = "active"
+	// SubscriptionExpired подписка, чей последний месяц уже прошел; выставляется фоновой задачей
+	SubscriptionExpired = "expired"
+)
+
 // Subscription представляет доменную модель подписки
 type Subscription struct {
-	ID          string
-	ServiceName string
-	Price       int64
-	UserID      string
-	StartDate   time.Time
-	EndDate     sql.NullTime
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             string
+	OrganizationID string
+	ServiceName    string
+	// ServiceID запись каталога сервисов; пустой, если подписка не связана с каталогом
+	ServiceID string
+	// Plan тариф сервиса; пустой, если не указан
+	Plan string
+	// Price текущая цена за месяц после пробного и вводного периодов
+	Price     int64
+	UserID    string
+	StartDate time.Time
+	EndDate   sql.NullTime
+	// TrialMonths число бесплатных месяцев с месяца начала подписки
+	TrialMonths int
+	// IntroPrice цена за месяц в течение IntroMonths месяцев после пробного периода
+	IntroPrice  int64
+	IntroMonths int
+	// Pauses приостановки оплаты в порядке начала; не пересекаются
+	Pauses []Pause
+	// PriceHistory прежние цены в порядке месяцев; Price действует с месяца после последней из них
+	PriceHistory []PricePeriod
+	// Changes запланированные изменения в порядке месяцев, еще не примененные к подписке
+	Changes []ScheduledChange
+	// Tags теги подписки по алфавиту
+	Tags      []string
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Pause приостановка оплаты подписки с месяца Start по месяц End включительно
+// End не задан, пока подписка не возобновлена
+type Pause struct {
+	Start time.Time
+	End   sql.NullTime
+}
+
+// Covers проверяет, приходится ли месяц month на приостановку
+func (p Pause) Covers(month time.Time) bool {
+	k := monthIndex(month)
+	return monthIndex(p.Start) <= k && (!p.End.Valid || k <= monthIndex(p.End.Time))
+}
+
+// PricePeriod цена, действовавшая по месяц Until включительно
+// Записывается при применении запланированного изменения цены, чтобы суммы за прошлые месяцы не менялись
+type PricePeriod struct {
+	Until time.Time
+	Price int64
+}
+
+// ScheduledChange изменение подписки с первого числа месяца EffectiveFrom:
+// новая цена и (или) тариф либо отмена, после которой последним месяцем подписки станет предыдущий
+type ScheduledChange struct {
+	EffectiveFrom time.Time
+	Price         sql.NullInt64
+	Plan          sql.NullString
+	Cancel        bool
+}
+
+// MaxPromoMonths наибольшая длительность пробного и вводного периодов
+const MaxPromoMonths = 120
+
+// TrialEndsAt первое число месяца, с которого заканчивается пробный период; false, если его нет
+func (s *Subscription) TrialEndsAt() (time.Time, bool) {
+	if s.TrialMonths <= 0 {
+		return time.Time{}, false
+	}
+	return monthOf(s.StartDate).AddDate(0, s.TrialMonths, 0), true
+}
+
+// PausedAt проверяет, приостановлена ли подписка в месяце month
+func (s *Subscription) PausedAt(month time.Time) bool {
+	for _, p := range s.Pauses {
+		if p.Covers(month) {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveEnd последний месяц подписки с учетом запланированной отмены; Valid false, если подписка бессрочная
+func (s *Subscription) EffectiveEnd() sql.NullTime {
+	end := s.EndDate
+	for _, c := range s.Changes {
+		if !c.Cancel {
+			continue
+		}
+		last := monthOf(c.EffectiveFrom).AddDate(0, -1, 0)
+		if !end.Valid || last.Before(end.Time) {
+			end = sql.NullTime{Time: last, Valid: true}
+		}
+	}
+	return end
+}
+
+// RegularPriceAt цена месяца month после пробного и вводного периодов с учетом прежних цен и запланированных изменений
+func (s *Subscription) RegularPriceAt(month time.Time) int64 {
+	k := monthIndex(month)
+	for _, seg := range s.priceSegments() {
+		if seg.from <= k && k <= seg.to {
+			return seg.price
+		}
+	}
+	return s.Price
+}
+
+// PriceAt стоимость месяца month: ноль в пробный период и на паузе, IntroPrice во вводный, затем RegularPriceAt
+// Не проверяет, действует ли подписка в этом месяце
+func (s *Subscription) PriceAt(month time.Time) int64 {
+	if s.PausedAt(month) {
+		return 0
+	}
+	k := monthIndex(month) - monthIndex(s.StartDate)
+	switch {
+	case k < s.TrialMonths:
+		return 0
+	case k < s.TrialMonths+s.IntroMonths:
+		return s.IntroPrice
+	}
+	return s.RegularPriceAt(month)
+}
+
+// Cost стоимость подписки за месяцы периода [start, end], включая крайние, без месяцев на паузе
+// Подписка без даты окончания действует до конца периода
+func (s *Subscription) Cost(start, end time.Time) int64 {
+	from, to := monthIndex(start), monthIndex(end)
+
+	total := s.cost(from, to)
+	for _, p := range s.Pauses {
+		pauseTo := to
+		if p.End.Valid {
+			pauseTo = min(pauseTo, monthIndex(p.End.Time))
+		}
+		total -= s.cost(max(from, monthIndex(p.Start)), pauseTo)
+	}
+	return total
+}
+
+// ApplyChanges применяет изменения, вступившие в силу к месяцу month: цена переходит в PriceHistory,
+// тариф заменяется, отмена сокращает EndDate. Стоимость подписки за любой период при этом не меняется.
+// Возвращает false, если применять нечего
+func (s *Subscription) ApplyChanges(month time.Time) bool {
+	month = monthOf(month)
+
+	var pending []ScheduledChange
+	applied := false
+	for _, c := range s.Changes {
+		if c.EffectiveFrom.After(month) {
+			pending = append(pending, c)
+			continue
+		}
+		applied = true
+
+		last := monthOf(c.EffectiveFrom).AddDate(0, -1, 0)
+		if c.Price.Valid {
+			s.PriceHistory = append(s.PriceHistory, PricePeriod{Until: last, Price: s.Price})
+			s.Price = c.Price.Int64
+		}
+		if c.Plan.Valid {
+			s.Plan = c.Plan.String
+		}
+		if c.Cancel && (!s.EndDate.Valid || last.Before(s.EndDate.Time)) {
+			s.EndDate = sql.NullTime{Time: last, Valid: true}
+		}
+	}
+	if !applied {
+		return false
+	}
+
+	s.Changes = pending
+	if s.EndDate.Valid && s.EndDate.Time.Before(month) {
+		s.Status = SubscriptionExpired
+	}
+	return true
+}
+
+// priceSegment цена, действующая в месяцах с номерами [from, to]
+type priceSegment struct {
+	from, to int
+	price    int64
+}
+
+// priceSegments разбивает время на периоды действия цены: прежние цены, текущая и запланированные изменения
+func (s *Subscription) priceSegments() []priceSegment {
+	var segments []priceSegment
+	from := math.MinInt
+	for _, p := range s.PriceHistory {
+		segments = append(segments, priceSegment{from: from, to: monthIndex(p.Until), price: p.Price})
+		from = monthIndex(p.Until) + 1
+	}
+
+	price := s.Price
+	for _, c := range s.Changes {
+		if !c.Price.Valid {
+			continue
+		}
+		k := monthIndex(c.EffectiveFrom)
+		segments = append(segments, priceSegment{from: from, to: k - 1, price: price})
+		from, price = k, c.Price.Int64
+	}
+	return append(segments, priceSegment{from: from, to: math.MaxInt, price: price})
+}
+
+// cost стоимость подписки за месяцы с номерами [from, to] без учета пауз
+func (s *Subscription) cost(from, to int) int64 {
+	last := to
+	if end := s.EffectiveEnd(); end.Valid {
+		last = min(last, monthIndex(end.Time))
+	}
+
+	var total int64
+	for _, seg := range s.priceSegments() {
+		total += s.phaseCost(seg.price, max(from, seg.from), min(last, seg.to))
+	}
+	return total
+}
+
+// phaseCost стоимость месяцев с номерами [from, to] при цене price после пробного и вводного периодов
+func (s *Subscription) phaseCost(price int64, from, to int) int64 {
+	introFrom := monthIndex(s.StartDate) + s.TrialMonths
+	regularFrom := introFrom + s.IntroMonths
+
+	return s.IntroPrice*overlap(max(introFrom, from), min(regularFrom-1, to)) +
+		price*overlap(max(regularFrom, from), to)
+}
+
+// monthIndex порядковый номер месяца: year*12 + month
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month())
+}
+
+// overlap число месяцев в [from, to]
+func overlap(from, to int) int64 {
+	if to < from {
+		return 0
+	}
+	return int64(to - from + 1)
+}
+
+// monthOf первое число месяца t
+func monthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
 }
 
 // ListFilters содержит параметры фильтрации для списка подписок
 type ListFilters struct {
 	UserID      string
 	ServiceName string
-	Limit       int
-	Offset      int
+	Tag         string
+	// TrialEndsFrom и TrialEndsTo отбирают подписки, пробный период которых заканчивается в [from, to];
+	// нулевые значения не ограничивают выборку
+	TrialEndsFrom time.Time
+	TrialEndsTo   time.Time
+	Limit         int
+	Offset        int
 }
 
 // SummaryFilters содержит параметры фильтрации для подсчета суммы
 type SummaryFilters struct {
 	UserID      string
 	ServiceName string
+	Tag         string
 	PeriodStart time.Time
 	PeriodEnd   time.Time
 }
 
+// Группировки суммы подписок
+const (
+	// SummaryByTag сумма по тегам; подписка с несколькими тегами входит в каждую группу
+	SummaryByTag = "tag"
+	// SummaryByCategory сумма по категориям каталога сервисов
+	SummaryByCategory = "category"
+)
+
+// SummaryGroup сумма подписок одной группы; пустой Key объединяет подписки без тега или категории
+type SummaryGroup struct {
+	Key   string
+	Total int64
+}
+
+// GroupCosts суммирует стоимость подписок за период по группам keys(sub); подписка без групп входит в группу ""
+// Возвращает группы по убыванию суммы, при равенстве по ключу, без групп с нулевой суммой
+func GroupCosts(subs []*Subscription, start, end time.Time, keys func(*Subscription) []string) []SummaryGroup {
+	totals := make(map[string]int64)
+	for _, sub := range subs {
+		cost := sub.Cost(start, end)
+		if cost == 0 {
+			continue
+		}
+		subKeys := keys(sub)
+		if len(subKeys) == 0 {
+			subKeys = []string{""}
+		}
+		for _, key := range subKeys {
+			totals[key] += cost
+		}
+	}
+
+	var groups []SummaryGroup
+	for key, total := range totals {
+		if total != 0 {
+			groups = append(groups, SummaryGroup{Key: key, Total: total})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// DeleteFilters содержит параметры отбора подписок для массового удаления
+type DeleteFilters struct {
+	UserID      string
+	ServiceName string
+}
+
+// SubscriptionStats содержит агрегированные показатели подписок организации за месяц
+type SubscriptionStats struct {
+	OrganizationID string
+	ActiveCount    int64
+	MonthlySpend   int64
+}
+
 // SubscriptionRepository определяет интерфейс репозитория подписок
 // Интерфейс находится в доменном слое, так как он определяет контракт для работы с доменными сущностями
 type SubscriptionRepository interface {
+	// Create сохраняет подписку вместе с тегами sub.Tags; недостающие теги организации создаются
 	Create(ctx context.Context, sub *Subscription) (*Subscription, error)
 	GetByID(ctx context.Context, id string) (*Subscription, error)
 	Update(ctx context.Context, id string, sub *Subscription) (*Subscription, error)
 	Delete(ctx context.Context, id string) error
+	// UpdatePauses заменяет приостановки подписки на возвращенные update; пересечения проверяет update
+	// Подписка не меняется другими записями от передачи в update до сохранения результата.
+	// Ошибка update отменяет запись и возвращается без изменений
+	UpdatePauses(ctx context.Context, id string, update func(*Subscription) ([]Pause, error)) (*Subscription, error)
+	// UpdateChanges заменяет запланированные изменения подписки на возвращенные update; допустимость изменений
+	// проверяет update. Гарантии те же, что у UpdatePauses, в том числе относительно ApplyChanges
+	UpdateChanges(ctx context.Context, id string, update func(*Subscription) ([]ScheduledChange, error)) (*Subscription, error)
+	DeleteMany(ctx context.Context, filters DeleteFilters) (int64, error)
 	List(ctx context.Context, filters ListFilters) ([]*Subscription, error)
 	GetSummary(ctx context.Context, filters SummaryFilters) (int64, error)
+	// GetGroupedSummary вычисляет суммы подписок за период по группам groupBy (SummaryByTag, SummaryByCategory)
+	// Группы возвращаются по убыванию суммы, группы с нулевой суммой пропускаются
+	GetGroupedSummary(ctx context.Context, filters SummaryFilters, groupBy string) ([]SummaryGroup, error)
+	// SetTags заменяет теги подписки; недостающие теги организации создаются
+	SetTags(ctx context.Context, id string, tags []string) (*Subscription, error)
+	// LinkService связывает с сервисом каталога подписки организации, для которых svc.Covers, и выставляет им
+	// каноническое название. Возвращает число измененных подписок
+	LinkService(ctx context.Context, svc *Service) (int64, error)
+	// ExpireEnded помечает истекшими подписки всех организаций, закончившиеся раньше месяца before
+	// Возвращает число измененных подписок
+	ExpireEnded(ctx context.Context, before time.Time) (int64, error)
+	// ApplyChanges применяет запланированные изменения подписок всех организаций, вступившие в силу к месяцу month
+	// Возвращает число измененных подписок
+	ApplyChanges(ctx context.Context, month time.Time) (int64, error)
 }
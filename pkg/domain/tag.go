@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MaxTagLength наибольшая длина тега в символах
+const MaxTagLength = 50
+
+// Tag произвольная метка подписок организации, например проект или центр затрат
+// Подписка может иметь несколько тегов, тег - много подписок
+type Tag struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	// Subscriptions число подписок с тегом; заполняется в List
+	Subscriptions int64
+	CreatedAt     time.Time
+}
+
+// NormalizeTag приводит тег к хранимому виду: нижний регистр, пробелы по краям убраны, внутренние схлопнуты
+// "Cost Center  42" и "cost center 42" дают один тег
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// TagRepository определяет интерфейс репозитория тегов
+// Названия тегов уникальны в пределах организации и передаются уже нормализованными
+type TagRepository interface {
+	Create(ctx context.Context, name string) (*Tag, error)
+	GetByID(ctx context.Context, id string) (*Tag, error)
+	// Rename меняет название тега у всех его подписок
+	Rename(ctx context.Context, id, name string) (*Tag, error)
+	// Delete удаляет тег и снимает его с подписок
+	Delete(ctx context.Context, id string) error
+	// List возвращает теги организации по названию с числом подписок
+	List(ctx context.Context) ([]*Tag, error)
+}
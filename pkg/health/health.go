@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Статусы проверок
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// defaultTimeout время на проверку одной зависимости, если не задано иное
+const defaultTimeout = 2 * time.Second
+
+// CheckFunc проверяет доступность зависимости
+type CheckFunc func(ctx context.Context) error
+
+// Check именованная проверка зависимости
+type Check struct {
+	Name string
+	Fn   CheckFunc
+}
+
+// CheckResult результат проверки одной зависимости
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report результат проверки готовности сервиса
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Ready сообщает, готов ли сервис принимать запросы
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// Health выполняет проверки готовности и хранит признак остановки сервиса
+type Health struct {
+	checks       []Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// New создает набор проверок готовности
+// timeout ограничивает каждую проверку; 0 означает значение по умолчанию (2 секунды)
+func New(timeout time.Duration, checks ...Check) *Health {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Health{checks: checks, timeout: timeout}
+}
+
+// SetShuttingDown переводит сервис в состояние остановки: проверка готовности начинает возвращать ошибку,
+// чтобы балансировщик перестал направлять новые запросы до закрытия соединений
+func (h *Health) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Readiness выполняет все проверки параллельно и возвращает отчет по каждой зависимости
+func (h *Health) Readiness(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(h.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := h.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+
+	if h.shuttingDown.Load() {
+		report.Status = StatusShuttingDown
+	}
+
+	return report
+}
+
+func (h *Health) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Fn(ctx)
+	result := CheckResult{Status: StatusOK, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Readiness(t *testing.T) {
+	ok := Check{Name: "postgres", Fn: func(context.Context) error { return nil }}
+	failing := Check{Name: "migrations", Fn: func(context.Context) error { return errors.New("schema version 2, expected 3") }}
+	slow := Check{Name: "slow", Fn: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	t.Run("all checks pass", func(t *testing.T) {
+		report := New(0, ok).Readiness(context.Background())
+		assert.True(t, report.Ready())
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+	})
+
+	t.Run("failing check", func(t *testing.T) {
+		report := New(0, ok, failing).Readiness(context.Background())
+		assert.False(t, report.Ready())
+		assert.Equal(t, StatusFail, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+		assert.Equal(t, "schema version 2, expected 3", report.Checks["migrations"].Error)
+	})
+
+	t.Run("check timeout", func(t *testing.T) {
+		report := New(10*time.Millisecond, slow).Readiness(context.Background())
+		assert.False(t, report.Ready())
+		assert.Contains(t, report.Checks["slow"].Error, "deadline exceeded")
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		h := New(0, ok)
+		h.SetShuttingDown()
+		report := h.Readiness(context.Background())
+		assert.False(t, report.Ready())
+		assert.Equal(t, StatusShuttingDown, report.Status)
+	})
+}
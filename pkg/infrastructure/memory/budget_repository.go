@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что BudgetRepository реализует интерфейс domain.BudgetRepository
+var _ domain.BudgetRepository = (*BudgetRepository)(nil)
+
+// BudgetRepository хранит бюджеты и отметки об отправленных уведомлениях в памяти процесса
+type BudgetRepository struct {
+	mu      sync.Mutex
+	budgets map[string]*budgetRecord
+	alerts  map[alertKey]struct{}
+	seq     int64
+	now     func() time.Time
+}
+
+// budgetRecord бюджет с порядковым номером вставки для стабильной сортировки
+type budgetRecord struct {
+	budget *domain.Budget
+	seq    int64
+}
+
+type alertKey struct {
+	budgetID    string
+	periodStart time.Time
+	threshold   int
+}
+
+// NewBudgetRepository создает пустой репозиторий бюджетов
+func NewBudgetRepository() *BudgetRepository {
+	return &BudgetRepository{
+		budgets: make(map[string]*budgetRecord),
+		alerts:  make(map[alertKey]struct{}),
+		now:     time.Now,
+	}
+}
+
+// Create создает новый бюджет
+func (r *BudgetRepository) Create(ctx context.Context, budget *domain.Budget) (*domain.Budget, error) {
+	userID, err := parseOptionalUUID(budget.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+	if err := checkBudget(budget); err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	created := cloneBudget(budget)
+	created.ID = uuid.NewString()
+	created.OrganizationID = domain.OrganizationFromContext(ctx)
+	created.UserID = userID
+	created.CreatedAt = now
+	created.UpdatedAt = now
+
+	r.seq++
+	r.budgets[created.ID] = &budgetRecord{budget: created, seq: r.seq}
+
+	return cloneBudget(created), nil
+}
+
+// GetByID получает бюджет по ID
+func (r *BudgetRepository) GetByID(ctx context.Context, id string) (*domain.Budget, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("budget not found")
+	}
+
+	return cloneBudget(rec.budget), nil
+}
+
+// Update заменяет параметры бюджета; пользователь бюджета не меняется
+func (r *BudgetRepository) Update(ctx context.Context, id string, budget *domain.Budget) (*domain.Budget, error) {
+	if err := checkBudget(budget); err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("budget not found")
+	}
+
+	updated := cloneBudget(rec.budget)
+	updated.Name = budget.Name
+	updated.Period = budget.Period
+	updated.Limit = budget.Limit
+	updated.ServiceName = budget.ServiceName
+	updated.Thresholds = slices.Clone(budget.Thresholds)
+	updated.Email = budget.Email
+	updated.UpdatedAt = r.now()
+	rec.budget = updated
+
+	return cloneBudget(updated), nil
+}
+
+// Delete удаляет бюджет вместе с отметками об уведомлениях
+func (r *BudgetRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	if rec == nil {
+		return fmt.Errorf("budget not found")
+	}
+
+	delete(r.budgets, rec.budget.ID)
+	for key := range r.alerts {
+		if key.budgetID == rec.budget.ID {
+			delete(r.alerts, key)
+		}
+	}
+	return nil
+}
+
+// List возвращает бюджеты организации из контекста, сначала созданные раньше
+func (r *BudgetRepository) List(ctx context.Context, filters domain.BudgetFilters) ([]*domain.Budget, error) {
+	userID, err := parseOptionalUUID(filters.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	organizationID := domain.OrganizationFromContext(ctx)
+
+	return r.list(func(b *domain.Budget) bool {
+		return b.OrganizationID == organizationID && (userID == "" || b.UserID == userID)
+	}), nil
+}
+
+// ListAll возвращает бюджеты всех организаций
+func (r *BudgetRepository) ListAll(_ context.Context) ([]*domain.Budget, error) {
+	return r.list(func(*domain.Budget) bool { return true }), nil
+}
+
+// ClaimAlert отмечает уведомление о пороге отправленным
+func (r *BudgetRepository) ClaimAlert(_ context.Context, alert *domain.BudgetAlert) (bool, error) {
+	key := alertKey{alert.BudgetID, truncateDate(alert.PeriodStart), alert.Threshold}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.budgets[alert.BudgetID]; !ok {
+		return false, fmt.Errorf("budget not found")
+	}
+	if _, sent := r.alerts[key]; sent {
+		return false, nil
+	}
+	r.alerts[key] = struct{}{}
+	return true, nil
+}
+
+// ReleaseAlert снимает отметку об отправке
+func (r *BudgetRepository) ReleaseAlert(_ context.Context, alert *domain.BudgetAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.alerts, alertKey{alert.BudgetID, truncateDate(alert.PeriodStart), alert.Threshold})
+	return nil
+}
+
+// list возвращает копии бюджетов, подходящих под условие, в порядке создания
+func (r *BudgetRepository) list(match func(*domain.Budget) bool) []*domain.Budget {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []*budgetRecord
+	for _, rec := range r.budgets {
+		if match(rec.budget) {
+			found = append(found, rec)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].seq < found[j].seq })
+
+	var budgets []*domain.Budget
+	for _, rec := range found {
+		budgets = append(budgets, cloneBudget(rec.budget))
+	}
+	return budgets
+}
+
+// get ищет бюджет организации из контекста; nil без ошибки означает, что бюджета нет
+// Вызывается под блокировкой
+func (r *BudgetRepository) get(ctx context.Context, id string) (*budgetRecord, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, ok := r.budgets[key]
+	if !ok || rec.budget.OrganizationID != domain.OrganizationFromContext(ctx) {
+		return nil, nil
+	}
+	return rec, nil
+}
+
+// checkBudget проверяет ограничения, которые в PostgreSQL задает схема таблицы
+func checkBudget(budget *domain.Budget) error {
+	if budget.Period != domain.BudgetMonthly && budget.Period != domain.BudgetYearly {
+		return fmt.Errorf("invalid budget period %q", budget.Period)
+	}
+	if budget.Limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+	return nil
+}
+
+// parseOptionalUUID как parseUUID, но пропускает пустой идентификатор
+func parseOptionalUUID(id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	return parseUUID(id)
+}
+
+// cloneBudget возвращает копию бюджета, чтобы вызывающие не могли изменить хранимые данные
+func cloneBudget(budget *domain.Budget) *domain.Budget {
+	c := *budget
+	c.Thresholds = slices.Clone(budget.Thresholds)
+	return &c
+}
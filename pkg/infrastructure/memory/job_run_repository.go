@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что JobRunRepository реализует интерфейс domain.JobRunRepository
+var _ domain.JobRunRepository = (*JobRunRepository)(nil)
+
+// maxJobRuns число последних запусков, которые хранятся в памяти
+const maxJobRuns = 1000
+
+// JobRunRepository хранит историю запусков фоновых задач в памяти процесса
+// Хранятся только последние maxJobRuns запусков; история теряется при перезапуске
+type JobRunRepository struct {
+	mu   sync.Mutex
+	runs []*domain.JobRun // в порядке создания
+}
+
+// NewJobRunRepository создает пустую историю запусков
+func NewJobRunRepository() *JobRunRepository {
+	return &JobRunRepository{}
+}
+
+// Create сохраняет новый запуск
+func (r *JobRunRepository) Create(_ context.Context, run *domain.JobRun) (*domain.JobRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := *run
+	created.ID = uuid.NewString()
+	r.runs = append(r.runs, &created)
+	if len(r.runs) > maxJobRuns {
+		r.runs = r.runs[len(r.runs)-maxJobRuns:]
+	}
+
+	c := created
+	return &c, nil
+}
+
+// Finish сохраняет результат запуска
+func (r *JobRunRepository) Finish(_ context.Context, run *domain.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, stored := range r.runs {
+		if stored.ID == run.ID {
+			finished := *stored
+			finished.Status = run.Status
+			finished.Error = run.Error
+			finished.FinishedAt = run.FinishedAt
+			r.runs[i] = &finished
+			return nil
+		}
+	}
+	return fmt.Errorf("job run not found")
+}
+
+// List возвращает запуски от новых к старым
+func (r *JobRunRepository) List(_ context.Context, filters domain.JobRunFilters) ([]*domain.JobRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs := make([]*domain.JobRun, 0, len(r.runs))
+	for i := len(r.runs) - 1; i >= 0; i-- {
+		if filters.Job == "" || r.runs[i].Job == filters.Job {
+			c := *r.runs[i]
+			runs = append(runs, &c)
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+
+	if filters.Limit > 0 && len(runs) > filters.Limit {
+		runs = runs[:filters.Limit]
+	}
+	return runs, nil
+}
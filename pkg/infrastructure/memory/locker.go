@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// Проверка, что Locker реализует интерфейс domain.Locker
+var _ domain.Locker = (*Locker)(nil)
+
+// Locker выдает блокировки в пределах одного процесса
+// Подходит для хранилищ без общего сервера (memory, SQLite), где работает единственный экземпляр сервиса
+type Locker struct {
+	mu   sync.Mutex
+	held map[string]struct{}
+}
+
+// NewLocker создает пустой набор блокировок
+func NewLocker() *Locker {
+	return &Locker{held: make(map[string]struct{})}
+}
+
+// TryLock берет блокировку без ожидания; nil означает, что она уже занята
+func (l *Locker) TryLock(_ context.Context, name string) (domain.Lock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.held[name]; ok {
+		return nil, nil
+	}
+	l.held[name] = struct{}{}
+	return &lock{locker: l, name: name}, nil
+}
+
+type lock struct {
+	locker *Locker
+	name   string
+	once   sync.Once
+}
+
+// Check всегда успешна: блокировка в памяти не теряется
+func (l *lock) Check(context.Context) error {
+	return nil
+}
+
+// Unlock освобождает блокировку; повторный вызов ничего не делает
+func (l *lock) Unlock() {
+	l.once.Do(func() {
+		l.locker.mu.Lock()
+		defer l.locker.mu.Unlock()
+		delete(l.locker.held, l.name)
+	})
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что OrganizationRepository реализует интерфейс domain.OrganizationRepository
+var _ domain.OrganizationRepository = (*OrganizationRepository)(nil)
+
+// OrganizationRepository хранит организации в памяти процесса
+type OrganizationRepository struct {
+	mu            sync.RWMutex
+	organizations map[string]*domain.Organization
+	now           func() time.Time
+}
+
+// NewOrganizationRepository создает репозиторий с организацией по умолчанию, как миграции PostgreSQL
+func NewOrganizationRepository() *OrganizationRepository {
+	r := &OrganizationRepository{organizations: make(map[string]*domain.Organization), now: time.Now}
+	r.organizations[domain.DefaultOrganizationID] = &domain.Organization{
+		ID:        domain.DefaultOrganizationID,
+		Name:      "default",
+		CreatedAt: r.now(),
+	}
+	return r
+}
+
+// Create создает организацию
+func (r *OrganizationRepository) Create(_ context.Context, org *domain.Organization) (*domain.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := org.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	if _, ok := r.organizations[id]; ok {
+		return nil, fmt.Errorf("failed to create organization: id %s is already used", id)
+	}
+	for _, existing := range r.organizations {
+		if existing.Name == org.Name {
+			return nil, fmt.Errorf("failed to create organization: name %q is already used", org.Name)
+		}
+	}
+
+	stored := &domain.Organization{ID: id, Name: org.Name, CreatedAt: r.now()}
+	r.organizations[id] = stored
+
+	created := *stored
+	return &created, nil
+}
+
+// Exists сообщает, что организация существует
+func (r *OrganizationRepository) Exists(_ context.Context, id string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.organizations[id]
+	return ok, nil
+}
+
+// List возвращает организации по названию
+func (r *OrganizationRepository) List(_ context.Context) ([]*domain.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orgs := make([]*domain.Organization, 0, len(r.organizations))
+	for _, org := range r.organizations {
+		copied := *org
+		orgs = append(orgs, &copied)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+
+	return orgs, nil
+}
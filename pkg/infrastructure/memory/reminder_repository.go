@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// Проверка, что ReminderRepository реализует интерфейс domain.ReminderRepository
+var _ domain.ReminderRepository = (*ReminderRepository)(nil)
+
+// ReminderRepository хранит настройки и отметки об отправке напоминаний в памяти процесса
+// Напоминания строятся по подпискам из репозитория subs
+type ReminderRepository struct {
+	subs *SubscriptionRepository
+
+	mu    sync.Mutex
+	prefs map[preferencesKey]*domain.ReminderPreferences
+	sent  map[sentKey]struct{}
+	now   func() time.Time
+}
+
+type preferencesKey struct {
+	organizationID string
+	userID         string
+}
+
+type sentKey struct {
+	subscriptionID string
+	kind           string
+	date           time.Time
+}
+
+// NewReminderRepository создает репозиторий напоминаний для подписок subs
+func NewReminderRepository(subs *SubscriptionRepository) *ReminderRepository {
+	return &ReminderRepository{
+		subs:  subs,
+		prefs: make(map[preferencesKey]*domain.ReminderPreferences),
+		sent:  make(map[sentKey]struct{}),
+		now:   time.Now,
+	}
+}
+
+// Due возвращает неотправленные напоминания всех организаций с датой в интервале (from, to]
+func (r *ReminderRepository) Due(_ context.Context, from, to time.Time) ([]*domain.Reminder, error) {
+	dates := domain.ReminderDates(from, to)
+
+	r.subs.mu.RLock()
+	subs := make([]*domain.Subscription, 0, len(r.subs.subs))
+	for _, rec := range r.subs.subs {
+		subs = append(subs, clone(rec.sub))
+	}
+	r.subs.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reminders []*domain.Reminder
+	for _, sub := range subs {
+		prefs := r.preferences(sub.OrganizationID, sub.UserID)
+		for _, date := range dates {
+			kind, ok := domain.ReminderKind(sub, date)
+			if !ok || !enabled(prefs, kind) {
+				continue
+			}
+			if _, sent := r.sent[sentKey{sub.ID, kind, date}]; sent {
+				continue
+			}
+			reminders = append(reminders, &domain.Reminder{
+				OrganizationID: sub.OrganizationID,
+				SubscriptionID: sub.ID,
+				UserID:         sub.UserID,
+				ServiceName:    sub.ServiceName,
+				Price:          sub.PriceAt(date),
+				Kind:           kind,
+				Date:           date,
+				Email:          prefs.Email,
+			})
+		}
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		a, b := reminders[i], reminders[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.SubscriptionID < b.SubscriptionID
+	})
+
+	return reminders, nil
+}
+
+// Claim отмечает напоминание отправленным
+func (r *ReminderRepository) Claim(_ context.Context, reminder *domain.Reminder) (bool, error) {
+	key := sentKey{reminder.SubscriptionID, reminder.Kind, truncateDate(reminder.Date)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, sent := r.sent[key]; sent {
+		return false, nil
+	}
+	r.sent[key] = struct{}{}
+	return true, nil
+}
+
+// Release снимает отметку об отправке
+func (r *ReminderRepository) Release(_ context.Context, reminder *domain.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sent, sentKey{reminder.SubscriptionID, reminder.Kind, truncateDate(reminder.Date)})
+	return nil
+}
+
+// GetPreferences возвращает настройки пользователя или настройки по умолчанию
+func (r *ReminderRepository) GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error) {
+	id, err := parseUUID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder preferences: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefs := *r.preferences(domain.OrganizationFromContext(ctx), id)
+	return &prefs, nil
+}
+
+// SavePreferences создает или заменяет настройки пользователя
+func (r *ReminderRepository) SavePreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	id, err := parseUUID(prefs.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reminder preferences: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *prefs
+	saved.UserID = id
+	saved.UpdatedAt = r.now()
+	r.prefs[preferencesKey{domain.OrganizationFromContext(ctx), id}] = &saved
+
+	result := saved
+	return &result, nil
+}
+
+// preferences возвращает настройки пользователя; вызывается под блокировкой
+func (r *ReminderRepository) preferences(organizationID, userID string) *domain.ReminderPreferences {
+	if prefs, ok := r.prefs[preferencesKey{organizationID, userID}]; ok {
+		return prefs
+	}
+	return domain.DefaultReminderPreferences(userID)
+}
+
+func enabled(prefs *domain.ReminderPreferences, kind string) bool {
+	if kind == domain.ReminderExpiry {
+		return prefs.Expiry
+	}
+	return prefs.Renewal
+}
@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что ServiceRepository реализует интерфейс domain.ServiceRepository
+var _ domain.ServiceRepository = (*ServiceRepository)(nil)
+
+// ServiceRepository хранит каталог сервисов в памяти процесса
+// При удалении сервиса отвязывает от него подписки репозитория subs
+type ServiceRepository struct {
+	mu       sync.Mutex
+	subs     *SubscriptionRepository
+	services map[string]*serviceRecord
+	seq      int64
+	now      func() time.Time
+}
+
+// serviceRecord сервис с порядковым номером вставки для стабильной сортировки
+type serviceRecord struct {
+	svc *domain.Service
+	seq int64
+}
+
+// NewServiceRepository создает пустой каталог сервисов поверх репозитория подписок
+// Категории сервисов становятся доступны сумме подписок по категориям
+func NewServiceRepository(subs *SubscriptionRepository) *ServiceRepository {
+	r := &ServiceRepository{
+		subs:     subs,
+		services: make(map[string]*serviceRecord),
+		now:      time.Now,
+	}
+	subs.catalog = r
+	return r
+}
+
+// Create добавляет сервис в каталог
+func (r *ServiceRepository) Create(ctx context.Context, svc *domain.Service) (*domain.Service, error) {
+	if err := checkService(svc); err != nil {
+		return nil, fmt.Errorf("failed to create service: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	organizationID := domain.OrganizationFromContext(ctx)
+	if err := r.checkKeys(organizationID, "", svc); err != nil {
+		return nil, fmt.Errorf("failed to create service: %w", err)
+	}
+
+	now := r.now()
+	created := cloneService(svc)
+	created.ID = uuid.NewString()
+	created.OrganizationID = organizationID
+	created.CreatedAt = now
+	created.UpdatedAt = now
+
+	r.seq++
+	r.services[created.ID] = &serviceRecord{svc: created, seq: r.seq}
+
+	return cloneService(created), nil
+}
+
+// GetByID получает сервис по ID
+func (r *ServiceRepository) GetByID(ctx context.Context, id string) (*domain.Service, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("service not found")
+	}
+
+	return cloneService(rec.svc), nil
+}
+
+// Update заменяет данные сервиса
+func (r *ServiceRepository) Update(ctx context.Context, id string, svc *domain.Service) (*domain.Service, error) {
+	if err := checkService(svc); err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("service not found")
+	}
+	if err := r.checkKeys(rec.svc.OrganizationID, rec.svc.ID, svc); err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+
+	updated := cloneService(svc)
+	updated.ID = rec.svc.ID
+	updated.OrganizationID = rec.svc.OrganizationID
+	updated.CreatedAt = rec.svc.CreatedAt
+	updated.UpdatedAt = r.now()
+	rec.svc = updated
+
+	return cloneService(updated), nil
+}
+
+// Delete удаляет сервис из каталога; подписки остаются со своим названием, но без ссылки на каталог
+func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	if rec == nil {
+		return fmt.Errorf("service not found")
+	}
+
+	delete(r.services, rec.svc.ID)
+	r.subs.unlinkService(rec.svc.ID)
+	return nil
+}
+
+// List возвращает сервисы организации из контекста по названию
+func (r *ServiceRepository) List(ctx context.Context, filters domain.ServiceFilters) ([]*domain.Service, error) {
+	organizationID := domain.OrganizationFromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var services []*domain.Service
+	for _, rec := range r.services {
+		if rec.svc.OrganizationID == organizationID && (filters.Category == "" || rec.svc.Category == filters.Category) {
+			services = append(services, cloneService(rec.svc))
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Name != services[j].Name {
+			return services[i].Name < services[j].Name
+		}
+		return services[i].ID < services[j].ID
+	})
+	return services, nil
+}
+
+// FindByName ищет сервис организации по названию или псевдониму
+func (r *ServiceRepository) FindByName(ctx context.Context, name string) (*domain.Service, error) {
+	key := domain.NormalizeServiceName(name)
+	if key == "" {
+		return nil, nil
+	}
+	organizationID := domain.OrganizationFromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rec := range r.services {
+		if rec.svc.OrganizationID == organizationID && slices.Contains(rec.svc.Keys(), key) {
+			return cloneService(rec.svc), nil
+		}
+	}
+	return nil, nil
+}
+
+// categories возвращает категории сервисов организации по ID сервиса
+func (r *ServiceRepository) categories(organizationID string) map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	categories := make(map[string]string)
+	for id, rec := range r.services {
+		if rec.svc.OrganizationID == organizationID {
+			categories[id] = rec.svc.Category
+		}
+	}
+	return categories
+}
+
+// get ищет сервис организации из контекста; nil без ошибки означает, что сервиса нет
+// Вызывается под блокировкой
+func (r *ServiceRepository) get(ctx context.Context, id string) (*serviceRecord, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, ok := r.services[key]
+	if !ok || rec.svc.OrganizationID != domain.OrganizationFromContext(ctx) {
+		return nil, nil
+	}
+	return rec, nil
+}
+
+// checkKeys проверяет, что названия сервиса не заняты другими сервисами организации
+// Вызывается под блокировкой; exceptID исключает из проверки обновляемый сервис
+func (r *ServiceRepository) checkKeys(organizationID, exceptID string, svc *domain.Service) error {
+	keys := svc.Keys()
+	for _, rec := range r.services {
+		if rec.svc.OrganizationID != organizationID || rec.svc.ID == exceptID {
+			continue
+		}
+		for _, key := range rec.svc.Keys() {
+			if slices.Contains(keys, key) {
+				return fmt.Errorf("service name %q is already used by %s", key, rec.svc.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkService проверяет ограничения, которые в PostgreSQL задает схема таблиц
+func checkService(svc *domain.Service) error {
+	if domain.NormalizeServiceName(svc.Name) == "" {
+		return fmt.Errorf("service name must contain letters or digits")
+	}
+	for _, p := range svc.Plans {
+		if p.Price < 0 || p.Price > math.MaxInt32 {
+			return fmt.Errorf("plan price %d is out of range", p.Price)
+		}
+	}
+	return nil
+}
+
+// cloneService возвращает копию сервиса, чтобы вызывающие не могли изменить хранимые данные
+func cloneService(svc *domain.Service) *domain.Service {
+	c := *svc
+	c.Aliases = slices.Clone(svc.Aliases)
+	c.Plans = slices.Clone(svc.Plans)
+	return &c
+}
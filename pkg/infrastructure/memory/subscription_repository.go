@@ -0,0 +1,644 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что SubscriptionRepository реализует интерфейс domain.SubscriptionRepository
+var _ domain.SubscriptionRepository = (*SubscriptionRepository)(nil)
+
+// SubscriptionRepository хранит подписки в памяти процесса
+// Повторяет поведение репозитория PostgreSQL: ограничения схемы, фильтры, порядок, пагинацию и расчет суммы.
+// Безопасен для конкурентного использования; данные теряются при перезапуске
+type SubscriptionRepository struct {
+	mu   sync.RWMutex
+	subs map[string]*record
+	// tags теги организаций по ID; подписки хранят названия своих тегов
+	tags map[string]*domain.Tag
+	seq  int64
+	now  func() time.Time
+	// catalog задает категории сервисов для GetGroupedSummary; nil, если каталог не подключен
+	catalog *ServiceRepository
+}
+
+// record подписка с порядковым номером вставки для стабильной сортировки
+type record struct {
+	sub *domain.Subscription
+	seq int64
+}
+
+// NewSubscriptionRepository создает пустой репозиторий подписок
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{
+		subs: make(map[string]*record),
+		tags: make(map[string]*domain.Tag),
+		now:  time.Now,
+	}
+}
+
+// Create создает новую подписку
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) (*domain.Subscription, error) {
+	userID, err := parseUUID(sub.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	serviceID, err := parseOptionalUUID(sub.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	if err := checkSubscription(sub); err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	if err := checkTags(sub.Tags); err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	created := &domain.Subscription{
+		ID:             uuid.NewString(),
+		OrganizationID: domain.OrganizationFromContext(ctx),
+		ServiceName:    sub.ServiceName,
+		ServiceID:      serviceID,
+		Plan:           sub.Plan,
+		Price:          sub.Price,
+		UserID:         userID,
+		StartDate:      truncateDate(sub.StartDate),
+		EndDate:        truncateNullDate(sub.EndDate),
+		TrialMonths:    sub.TrialMonths,
+		IntroPrice:     sub.IntroPrice,
+		IntroMonths:    sub.IntroMonths,
+		Tags:           r.ensureTags(domain.OrganizationFromContext(ctx), sub.Tags),
+		Status:         domain.SubscriptionActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	r.seq++
+	r.subs[created.ID] = &record{sub: created, seq: r.seq}
+
+	return clone(created), nil
+}
+
+// GetByID получает подписку по ID
+func (r *SubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("subscription not found")
+	}
+
+	return clone(rec.sub), nil
+}
+
+// Update обновляет подписку
+func (r *SubscriptionRepository) Update(ctx context.Context, id string, sub *domain.Subscription) (*domain.Subscription, error) {
+	serviceID, err := parseOptionalUUID(sub.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if err := checkSubscription(sub); err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("subscription not found")
+	}
+
+	updated := clone(rec.sub)
+	updated.ServiceName = sub.ServiceName
+	updated.ServiceID = serviceID
+	updated.Plan = sub.Plan
+	updated.Price = sub.Price
+	updated.StartDate = truncateDate(sub.StartDate)
+	updated.EndDate = truncateNullDate(sub.EndDate)
+	updated.TrialMonths = sub.TrialMonths
+	updated.IntroPrice = sub.IntroPrice
+	updated.IntroMonths = sub.IntroMonths
+	updated.UpdatedAt = r.now()
+	// Продленная подписка снова активна; истекшей она станет при следующем запуске ExpireEnded
+	if !updated.EndDate.Valid || !updated.EndDate.Time.Before(monthStart(updated.UpdatedAt)) {
+		updated.Status = domain.SubscriptionActive
+	}
+	rec.sub = updated
+
+	return clone(updated), nil
+}
+
+// Delete удаляет подписку
+func (r *SubscriptionRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	if rec == nil {
+		return fmt.Errorf("subscription not found")
+	}
+
+	delete(r.subs, rec.sub.ID)
+	return nil
+}
+
+// UpdatePauses заменяет приостановки подписки на возвращенные update; update вызывается под блокировкой хранилища
+func (r *SubscriptionRepository) UpdatePauses(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.Pause, error)) (*domain.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription pauses: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("subscription not found")
+	}
+
+	pauses, err := update(clone(rec.sub))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pauses {
+		if p.End.Valid && p.End.Time.Before(p.Start) {
+			return nil, fmt.Errorf("failed to update subscription pauses: pause end must not be before its start")
+		}
+	}
+
+	updated := clone(rec.sub)
+	updated.Pauses = nil
+	for _, p := range pauses {
+		updated.Pauses = append(updated.Pauses, domain.Pause{Start: truncateDate(p.Start), End: truncateNullDate(p.End)})
+	}
+	sort.Slice(updated.Pauses, func(i, j int) bool { return updated.Pauses[i].Start.Before(updated.Pauses[j].Start) })
+	updated.UpdatedAt = r.now()
+	rec.sub = updated
+
+	return clone(updated), nil
+}
+
+// UpdateChanges заменяет запланированные изменения подписки на возвращенные update; update вызывается под блокировкой хранилища
+func (r *SubscriptionRepository) UpdateChanges(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.ScheduledChange, error)) (*domain.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled changes: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("subscription not found")
+	}
+
+	changes, err := update(clone(rec.sub))
+	if err != nil {
+		return nil, err
+	}
+
+	updated := clone(rec.sub)
+	updated.Changes = nil
+	for _, c := range changes {
+		c.EffectiveFrom = monthStart(c.EffectiveFrom)
+		updated.Changes = append(updated.Changes, c)
+	}
+	sort.Slice(updated.Changes, func(i, j int) bool {
+		return updated.Changes[i].EffectiveFrom.Before(updated.Changes[j].EffectiveFrom)
+	})
+	updated.UpdatedAt = r.now()
+	rec.sub = updated
+
+	return clone(updated), nil
+}
+
+// SetTags заменяет теги подписки; теги не относятся к полям подписки, поэтому UpdatedAt не меняется
+func (r *SubscriptionRepository) SetTags(ctx context.Context, id string, tags []string) (*domain.Subscription, error) {
+	if err := checkTags(tags); err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("subscription not found")
+	}
+
+	updated := clone(rec.sub)
+	updated.Tags = r.ensureTags(updated.OrganizationID, tags)
+	rec.sub = updated
+
+	return clone(updated), nil
+}
+
+// ensureTags создает недостающие теги организации и возвращает названия по алфавиту без повторов
+// Вызывается под блокировкой
+func (r *SubscriptionRepository) ensureTags(organizationID string, names []string) []string {
+	var tags []string
+	for _, name := range names {
+		if !slices.Contains(tags, name) {
+			tags = append(tags, name)
+		}
+		if r.findTag(organizationID, name) == nil {
+			tag := &domain.Tag{ID: uuid.NewString(), OrganizationID: organizationID, Name: name, CreatedAt: r.now()}
+			r.tags[tag.ID] = tag
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// findTag ищет тег организации по названию; вызывается под блокировкой
+func (r *SubscriptionRepository) findTag(organizationID, name string) *domain.Tag {
+	for _, tag := range r.tags {
+		if tag.OrganizationID == organizationID && tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+// DeleteMany удаляет подписки, подходящие под фильтры, и возвращает их количество
+func (r *SubscriptionRepository) DeleteMany(ctx context.Context, filters domain.DeleteFilters) (int64, error) {
+	match, err := matcher(ctx, filters.UserID, filters.ServiceName, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, rec := range r.subs {
+		if match(rec.sub) {
+			delete(r.subs, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// ExpireEnded помечает истекшими подписки всех организаций, закончившиеся раньше месяца before
+func (r *SubscriptionRepository) ExpireEnded(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before = truncateDate(before)
+	now := r.now()
+
+	var expired int64
+	for _, rec := range r.subs {
+		sub := rec.sub
+		if sub.Status == domain.SubscriptionActive && sub.EndDate.Valid && sub.EndDate.Time.Before(before) {
+			updated := clone(sub)
+			updated.Status = domain.SubscriptionExpired
+			updated.UpdatedAt = now
+			rec.sub = updated
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+// ApplyChanges применяет запланированные изменения подписок всех организаций, вступившие в силу к месяцу month
+func (r *SubscriptionRepository) ApplyChanges(_ context.Context, month time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	var applied int64
+	for _, rec := range r.subs {
+		updated := clone(rec.sub)
+		if !updated.ApplyChanges(month) {
+			continue
+		}
+		updated.UpdatedAt = now
+		rec.sub = updated
+		applied++
+	}
+
+	return applied, nil
+}
+
+// LinkService связывает подписки организации с сервисом каталога
+func (r *SubscriptionRepository) LinkService(ctx context.Context, svc *domain.Service) (int64, error) {
+	organizationID := domain.OrganizationFromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	var linked int64
+	for _, rec := range r.subs {
+		sub := rec.sub
+		if sub.OrganizationID != organizationID || !svc.Covers(sub.ServiceID, sub.ServiceName) ||
+			(sub.ServiceID == svc.ID && sub.ServiceName == svc.Name) {
+			continue
+		}
+		updated := clone(sub)
+		updated.ServiceID = svc.ID
+		updated.ServiceName = svc.Name
+		updated.UpdatedAt = now
+		rec.sub = updated
+		linked++
+	}
+
+	return linked, nil
+}
+
+// unlinkService отвязывает подписки от удаленной записи каталога, как ON DELETE SET NULL в PostgreSQL
+func (r *SubscriptionRepository) unlinkService(serviceID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rec := range r.subs {
+		if rec.sub.ServiceID == serviceID {
+			updated := clone(rec.sub)
+			updated.ServiceID = ""
+			rec.sub = updated
+		}
+	}
+}
+
+// List возвращает список подписок с фильтрацией
+// Порядок совпадает с PostgreSQL: сначала созданные позже
+func (r *SubscriptionRepository) List(ctx context.Context, filters domain.ListFilters) ([]*domain.Subscription, error) {
+	if filters.Limit < 0 || filters.Offset < 0 {
+		return nil, fmt.Errorf("failed to query subscriptions: LIMIT and OFFSET must not be negative")
+	}
+
+	match, err := matcher(ctx, filters.UserID, filters.ServiceName, filters.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*record
+	for _, rec := range r.subs {
+		if match(rec.sub) && trialEndsIn(rec.sub, filters.TrialEndsFrom, filters.TrialEndsTo) {
+			found = append(found, rec)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if !a.sub.CreatedAt.Equal(b.sub.CreatedAt) {
+			return a.sub.CreatedAt.After(b.sub.CreatedAt)
+		}
+		return a.seq > b.seq
+	})
+
+	if filters.Offset >= len(found) {
+		return nil, nil
+	}
+	found = found[filters.Offset:]
+	if filters.Limit < len(found) {
+		found = found[:filters.Limit]
+	}
+
+	var subs []*domain.Subscription
+	for _, rec := range found {
+		subs = append(subs, clone(rec.sub))
+	}
+
+	return subs, nil
+}
+
+// GetSummary вычисляет общую стоимость подписок за период
+// Подписка учитывается за каждый месяц пересечения с периодом, включая крайние
+func (r *SubscriptionRepository) GetSummary(ctx context.Context, filters domain.SummaryFilters) (int64, error) {
+	match, err := matcher(ctx, filters.UserID, filters.ServiceName, filters.Tag)
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var total int64
+	for _, rec := range r.subs {
+		if match(rec.sub) {
+			total += rec.sub.Cost(filters.PeriodStart, filters.PeriodEnd)
+		}
+	}
+
+	return total, nil
+}
+
+// GetGroupedSummary вычисляет суммы подписок за период по тегам или категориям каталога
+func (r *SubscriptionRepository) GetGroupedSummary(ctx context.Context, filters domain.SummaryFilters, groupBy string) ([]domain.SummaryGroup, error) {
+	match, err := matcher(ctx, filters.UserID, filters.ServiceName, filters.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	var keys func(*domain.Subscription) []string
+	switch groupBy {
+	case domain.SummaryByTag:
+		keys = func(sub *domain.Subscription) []string { return sub.Tags }
+	case domain.SummaryByCategory:
+		// Категории читаются до блокировки подписок: каталог блокирует подписки при удалении сервиса
+		var categories map[string]string
+		if r.catalog != nil {
+			categories = r.catalog.categories(domain.OrganizationFromContext(ctx))
+		}
+		keys = func(sub *domain.Subscription) []string {
+			if category := categories[sub.ServiceID]; category != "" {
+				return []string{category}
+			}
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("failed to calculate summary: unknown grouping %q", groupBy)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subs []*domain.Subscription
+	for _, rec := range r.subs {
+		if match(rec.sub) {
+			subs = append(subs, rec.sub)
+		}
+	}
+
+	return domain.GroupCosts(subs, filters.PeriodStart, filters.PeriodEnd, keys), nil
+}
+
+// Stats возвращает число активных подписок и их суммарную стоимость за месяц по каждой организации
+// Подписки на паузе в этом месяце не учитываются.
+// Используется для метрик и не ограничивается организацией из контекста
+func (r *SubscriptionRepository) Stats(_ context.Context, month time.Time) ([]domain.SubscriptionStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byOrg := make(map[string]*domain.SubscriptionStats)
+	var stats []domain.SubscriptionStats
+	for _, rec := range r.subs {
+		sub := rec.sub
+		end := sub.EffectiveEnd()
+		if sub.StartDate.After(month) || (end.Valid && end.Time.Before(month)) || sub.PausedAt(month) {
+			continue
+		}
+		s, ok := byOrg[sub.OrganizationID]
+		if !ok {
+			s = &domain.SubscriptionStats{OrganizationID: sub.OrganizationID}
+			byOrg[sub.OrganizationID] = s
+		}
+		s.ActiveCount++
+		s.MonthlySpend += sub.PriceAt(month)
+	}
+
+	for _, s := range byOrg {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].OrganizationID < stats[j].OrganizationID })
+
+	return stats, nil
+}
+
+// get ищет подписку организации из контекста; nil без ошибки означает, что подписки нет
+// Вызывается под блокировкой
+func (r *SubscriptionRepository) get(ctx context.Context, id string) (*record, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, ok := r.subs[key]
+	if !ok || rec.sub.OrganizationID != domain.OrganizationFromContext(ctx) {
+		return nil, nil
+	}
+	return rec, nil
+}
+
+// matcher возвращает условие отбора подписок организации из контекста по пользователю, сервису и тегу
+func matcher(ctx context.Context, userID, serviceName, tag string) (func(*domain.Subscription) bool, error) {
+	if userID != "" {
+		var err error
+		if userID, err = parseUUID(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	organizationID := domain.OrganizationFromContext(ctx)
+	return func(sub *domain.Subscription) bool {
+		return sub.OrganizationID == organizationID &&
+			(userID == "" || sub.UserID == userID) &&
+			(serviceName == "" || sub.ServiceName == serviceName) &&
+			(tag == "" || slices.Contains(sub.Tags, tag))
+	}, nil
+}
+
+// trialEndsIn проверяет, что пробный период подписки заканчивается в [from, to]; нулевые границы не ограничивают
+func trialEndsIn(sub *domain.Subscription, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	end, ok := sub.TrialEndsAt()
+	return ok && (from.IsZero() || !end.Before(from)) && (to.IsZero() || !end.After(to))
+}
+
+// checkSubscription проверяет ограничения, которые в PostgreSQL задает схема таблицы
+func checkSubscription(sub *domain.Subscription) error {
+	if sub.Price < 0 {
+		return fmt.Errorf("price must be non-negative")
+	}
+	if sub.Price > math.MaxInt32 {
+		return fmt.Errorf("price %d is out of range for type integer", sub.Price)
+	}
+	if sub.IntroPrice < 0 || sub.IntroPrice > math.MaxInt32 {
+		return fmt.Errorf("intro_price %d is out of range", sub.IntroPrice)
+	}
+	if sub.TrialMonths < 0 || sub.TrialMonths > domain.MaxPromoMonths || sub.IntroMonths < 0 || sub.IntroMonths > domain.MaxPromoMonths {
+		return fmt.Errorf("trial_months and intro_months must be between 0 and %d", domain.MaxPromoMonths)
+	}
+	if sub.EndDate.Valid && truncateDate(sub.EndDate.Time).Before(truncateDate(sub.StartDate)) {
+		return fmt.Errorf("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// checkTags проверяет ограничения, которые в PostgreSQL задает схема таблицы тегов
+func checkTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" {
+			return fmt.Errorf("tag must not be empty")
+		}
+		if utf8.RuneCountInString(tag) > domain.MaxTagLength {
+			return fmt.Errorf("value too long for tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// parseUUID проверяет идентификатор и приводит его к каноническому виду, как тип UUID в PostgreSQL
+func parseUUID(id string) (string, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid input syntax for type uuid: %q", id)
+	}
+	return parsed.String(), nil
+}
+
+// truncateDate отбрасывает время, как тип DATE в PostgreSQL
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// monthStart первый день месяца t в UTC, как date_trunc('month', now()) в PostgreSQL
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func truncateNullDate(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: truncateDate(t.Time), Valid: true}
+}
+
+// clone возвращает копию подписки, чтобы вызывающие не могли изменить хранимые данные
+func clone(sub *domain.Subscription) *domain.Subscription {
+	c := *sub
+	c.Pauses = append([]domain.Pause(nil), sub.Pauses...)
+	c.PriceHistory = append([]domain.PricePeriod(nil), sub.PriceHistory...)
+	c.Changes = append([]domain.ScheduledChange(nil), sub.Changes...)
+	c.Tags = append([]string(nil), sub.Tags...)
+	return &c
+}
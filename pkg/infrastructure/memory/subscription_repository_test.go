@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionRepository_Conformance(t *testing.T) {
+	repotest.RunSubscriptionRepositoryTests(t, func(*testing.T) domain.SubscriptionRepository {
+		return NewSubscriptionRepository()
+	})
+}
+
+func TestSubscriptionRepository_Concurrent(t *testing.T) {
+	repo := NewSubscriptionRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			created, err := repo.Create(ctx, &domain.Subscription{
+				ServiceName: "Netflix",
+				Price:       100,
+				UserID:      "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+				StartDate:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, err = repo.GetByID(ctx, created.ID)
+			assert.NoError(t, err)
+			_, err = repo.List(ctx, domain.ListFilters{Limit: 5})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	subs, err := repo.List(ctx, domain.ListFilters{Limit: 100})
+	require.NoError(t, err)
+	assert.Len(t, subs, 20)
+}
+
+func TestSubscriptionRepository_ReturnsCopies(t *testing.T) {
+	repo := NewSubscriptionRepository()
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, &domain.Subscription{
+		ServiceName: "Netflix",
+		Price:       100,
+		UserID:      "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		StartDate:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	created.Price = 1
+
+	got, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), got.Price)
+}
+
+func TestSubscriptionRepository_Stats(t *testing.T) {
+	repo := NewSubscriptionRepository()
+	ctx := context.Background()
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, sub := range []domain.Subscription{
+		{Price: 100, StartDate: jan},
+		{Price: 50, StartDate: jan.AddDate(0, -3, 0), EndDate: sql.NullTime{Time: jan, Valid: true}},
+		{Price: 7, StartDate: jan.AddDate(0, 1, 0)},
+		{Price: 30, StartDate: jan},
+	} {
+		sub.ServiceName = "s"
+		sub.UserID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+		created, err := repo.Create(ctx, &sub)
+		require.NoError(t, err)
+
+		// Подписка на паузе в январе не учитывается
+		if sub.Price == 30 {
+			_, err = repo.UpdatePauses(ctx, created.ID, func(*domain.Subscription) ([]domain.Pause, error) {
+				return []domain.Pause{{Start: jan.AddDate(0, -1, 0)}}, nil
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	stats, err := repo.Stats(ctx, jan)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SubscriptionStats{{OrganizationID: domain.DefaultOrganizationID, ActiveCount: 2, MonthlySpend: 150}}, stats)
+}
+
+func TestReminderRepository_Conformance(t *testing.T) {
+	repotest.RunReminderRepositoryTests(t, func(*testing.T) (domain.SubscriptionRepository, domain.ReminderRepository) {
+		subs := NewSubscriptionRepository()
+		return subs, NewReminderRepository(subs)
+	})
+}
+
+func TestBudgetRepository_Conformance(t *testing.T) {
+	repotest.RunBudgetRepositoryTests(t, func(*testing.T) domain.BudgetRepository {
+		return NewBudgetRepository()
+	})
+}
+
+func TestServiceRepository_Conformance(t *testing.T) {
+	repotest.RunServiceRepositoryTests(t, func(*testing.T) (domain.SubscriptionRepository, domain.ServiceRepository) {
+		subs := NewSubscriptionRepository()
+		return subs, NewServiceRepository(subs)
+	})
+}
+
+func TestTagRepository_Conformance(t *testing.T) {
+	repotest.RunTagRepositoryTests(t, func(*testing.T) (domain.SubscriptionRepository, domain.TagRepository) {
+		subs := NewSubscriptionRepository()
+		return subs, NewTagRepository(subs)
+	})
+}
+
+func TestOrganizationRepository_Conformance(t *testing.T) {
+	repotest.RunOrganizationRepositoryTests(t, func(*testing.T) domain.OrganizationRepository {
+		return NewOrganizationRepository()
+	})
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что TagRepository реализует интерфейс domain.TagRepository
+var _ domain.TagRepository = (*TagRepository)(nil)
+
+// TagRepository управляет тегами, которые хранит репозиторий подписок subs
+// Переименование и удаление тега сразу видны в подписках, как при связи через таблицу subscription_tags
+type TagRepository struct {
+	subs *SubscriptionRepository
+}
+
+// NewTagRepository создает репозиторий тегов поверх репозитория подписок
+func NewTagRepository(subs *SubscriptionRepository) *TagRepository {
+	return &TagRepository{subs: subs}
+}
+
+// Create добавляет тег организации
+func (r *TagRepository) Create(ctx context.Context, name string) (*domain.Tag, error) {
+	if err := checkTags([]string{name}); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	r.subs.mu.Lock()
+	defer r.subs.mu.Unlock()
+
+	organizationID := domain.OrganizationFromContext(ctx)
+	if r.subs.findTag(organizationID, name) != nil {
+		return nil, fmt.Errorf("failed to create tag: tag %q is already used", name)
+	}
+
+	tag := &domain.Tag{ID: uuid.NewString(), OrganizationID: organizationID, Name: name, CreatedAt: r.subs.now()}
+	r.subs.tags[tag.ID] = tag
+
+	created := *tag
+	return &created, nil
+}
+
+// GetByID получает тег по ID
+func (r *TagRepository) GetByID(ctx context.Context, id string) (*domain.Tag, error) {
+	r.subs.mu.RLock()
+	defer r.subs.mu.RUnlock()
+
+	tag, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	if tag == nil {
+		return nil, fmt.Errorf("tag not found")
+	}
+
+	found := *tag
+	found.Subscriptions = r.count(tag)
+	return &found, nil
+}
+
+// Rename меняет название тега у всех его подписок
+func (r *TagRepository) Rename(ctx context.Context, id, name string) (*domain.Tag, error) {
+	if err := checkTags([]string{name}); err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	r.subs.mu.Lock()
+	defer r.subs.mu.Unlock()
+
+	tag, err := r.get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+	if tag == nil {
+		return nil, fmt.Errorf("tag not found")
+	}
+	if other := r.subs.findTag(tag.OrganizationID, name); other != nil && other.ID != tag.ID {
+		return nil, fmt.Errorf("failed to rename tag: tag %q is already used", name)
+	}
+
+	r.retag(tag, name)
+	renamed := *tag
+	renamed.Name = name
+	r.subs.tags[tag.ID] = &renamed
+
+	found := renamed
+	found.Subscriptions = r.count(&renamed)
+	return &found, nil
+}
+
+// Delete удаляет тег и снимает его с подписок
+func (r *TagRepository) Delete(ctx context.Context, id string) error {
+	r.subs.mu.Lock()
+	defer r.subs.mu.Unlock()
+
+	tag, err := r.get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if tag == nil {
+		return fmt.Errorf("tag not found")
+	}
+
+	r.retag(tag, "")
+	delete(r.subs.tags, tag.ID)
+	return nil
+}
+
+// List возвращает теги организации по названию с числом подписок
+func (r *TagRepository) List(ctx context.Context) ([]*domain.Tag, error) {
+	organizationID := domain.OrganizationFromContext(ctx)
+
+	r.subs.mu.RLock()
+	defer r.subs.mu.RUnlock()
+
+	var tags []*domain.Tag
+	for _, tag := range r.subs.tags {
+		if tag.OrganizationID == organizationID {
+			found := *tag
+			found.Subscriptions = r.count(tag)
+			tags = append(tags, &found)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// get ищет тег организации из контекста; nil без ошибки означает, что тега нет
+// Вызывается под блокировкой
+func (r *TagRepository) get(ctx context.Context, id string) (*domain.Tag, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, ok := r.subs.tags[key]
+	if !ok || tag.OrganizationID != domain.OrganizationFromContext(ctx) {
+		return nil, nil
+	}
+	return tag, nil
+}
+
+// count число подписок с тегом; вызывается под блокировкой
+func (r *TagRepository) count(tag *domain.Tag) int64 {
+	var n int64
+	for _, rec := range r.subs.subs {
+		if rec.sub.OrganizationID == tag.OrganizationID && slices.Contains(rec.sub.Tags, tag.Name) {
+			n++
+		}
+	}
+	return n
+}
+
+// retag заменяет тег в подписках названием name или снимает его, если name пустое
+// Вызывается под блокировкой
+func (r *TagRepository) retag(tag *domain.Tag, name string) {
+	for _, rec := range r.subs.subs {
+		sub := rec.sub
+		if sub.OrganizationID != tag.OrganizationID || !slices.Contains(sub.Tags, tag.Name) {
+			continue
+		}
+		updated := clone(sub)
+		updated.Tags = slices.DeleteFunc(updated.Tags, func(t string) bool { return t == tag.Name })
+		if name != "" {
+			updated.Tags = append(updated.Tags, name)
+			sort.Strings(updated.Tags)
+		}
+		rec.sub = updated
+	}
+}
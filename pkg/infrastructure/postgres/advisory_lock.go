@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что AdvisoryLocker реализует интерфейс domain.Locker
+var _ domain.Locker = (*AdvisoryLocker)(nil)
+
+// advisoryLockPrefix отделяет блокировки сервиса от других пользователей advisory lock в той же базе
+const advisoryLockPrefix = "subscriptions:"
+
+// unlockTimeout ограничивает снятие блокировки при остановке или потере соединения
+const unlockTimeout = 5 * time.Second
+
+// AdvisoryLocker выдает блокировки через сессионные advisory lock PostgreSQL
+// Блокировка удерживается на отдельном соединении из пула и снимается базой, если соединение потеряно
+type AdvisoryLocker struct {
+	pool *pgxpool.Pool
+}
+
+// NewAdvisoryLocker создает блокировки поверх пула соединений
+func NewAdvisoryLocker(pool *pgxpool.Pool) *AdvisoryLocker {
+	return &AdvisoryLocker{pool: pool}
+}
+
+// TryLock берет блокировку без ожидания; nil означает, что ее удерживает другая сессия
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (domain.Lock, error) {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection for lock %q: %w", name, err)
+	}
+
+	key := advisoryLockPrefix + name
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, key).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to take lock %q: %w", name, err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, nil
+	}
+
+	return &advisoryLock{conn: conn, key: key}, nil
+}
+
+type advisoryLock struct {
+	conn *pgxpool.Conn
+	key  string
+	once sync.Once
+}
+
+// Check проверяет соединение, на котором удерживается блокировка
+func (l *advisoryLock) Check(ctx context.Context) error {
+	if err := l.conn.Ping(ctx); err != nil {
+		return fmt.Errorf("lock connection lost: %w", err)
+	}
+	return nil
+}
+
+// Unlock снимает блокировку и возвращает соединение в пул
+// Если снять блокировку не удалось, соединение закрывается, чтобы блокировка не осталась в пуле
+func (l *advisoryLock) Unlock() {
+	l.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+
+		if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, l.key); err != nil {
+			_ = l.conn.Conn().Close(ctx)
+		}
+		l.conn.Release()
+	})
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что APIKeyRepository реализует интерфейс domain.APIKeyRepository
+var _ domain.APIKeyRepository = (*APIKeyRepository)(nil)
+
+// lastUsedResolution задает, как часто обновляется время последнего использования ключа
+const lastUsedResolution = time.Minute
+
+// APIKeyRepository реализует интерфейс репозитория API ключей для PostgreSQL
+type APIKeyRepository struct {
+	db  *pgxpool.Pool
+	rls bool
+}
+
+// NewAPIKeyRepository создает новый экземпляр репозитория API ключей
+// rls включает выполнение запросов в транзакции с app.organization_id, как WithRowLevelSecurity у подписок
+func NewAPIKeyRepository(db *pgxpool.Pool, rls bool) *APIKeyRepository {
+	return &APIKeyRepository{db: db, rls: rls}
+}
+
+const apiKeyColumns = `id, organization_id, name, key_prefix, key_hash, scopes, expires_at, revoked_at, last_used_at, created_at`
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := row.Scan(
+		&k.ID,
+		&k.OrganizationID,
+		&k.Name,
+		&k.Prefix,
+		&k.Hash,
+		&k.Scopes,
+		&k.ExpiresAt,
+		&k.RevokedAt,
+		&k.LastUsedAt,
+		&k.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Create сохраняет новый API ключ
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	var created *domain.APIKey
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		created, err = scanAPIKey(q.QueryRow(
+			ctx,
+			`INSERT INTO api_keys (organization_id, name, key_prefix, key_hash, scopes, expires_at)
+             VALUES ($1, $2, $3, $4, $5, $6)
+             RETURNING `+apiKeyColumns,
+			domain.OrganizationFromContext(ctx), key.Name, key.Prefix, key.Hash, key.Scopes, key.ExpiresAt,
+		))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return created, nil
+}
+
+// List возвращает все API ключи организации, включая отозванные
+func (r *APIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		rows, err := q.Query(
+			ctx,
+			`SELECT `+apiKeyColumns+` FROM api_keys WHERE organization_id = $1 ORDER BY created_at DESC`,
+			domain.OrganizationFromContext(ctx),
+		)
+		if err != nil {
+			return err
+		}
+		keys, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.APIKey, error) {
+			return scanAPIKey(row)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke отзывает API ключ
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string) error {
+	var revoked int64
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL`,
+			id, domain.OrganizationFromContext(ctx),
+		)
+		revoked = cmdTag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if revoked == 0 {
+		return fmt.Errorf("api key not found")
+	}
+
+	return nil
+}
+
+// GetByHash ищет API ключ по хэшу среди ключей всех организаций: организация становится известна из найденного ключа
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash []byte) (*domain.APIKey, error) {
+	var key *domain.APIKey
+	err := runUnscoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		key, err = scanAPIKey(q.QueryRow(
+			ctx,
+			`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`,
+			hash,
+		))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("api key not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// TouchLastUsed обновляет время последнего использования ключа
+// Запись выполняется не чаще раза в lastUsedResolution, чтобы не нагружать базу на каждом запросе
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	// Вызывается при аутентификации, пока организация запроса еще не выставлена
+	err := runUnscoped(ctx, r.db, r.rls, func(q querier) error {
+		_, err := q.Exec(
+			ctx,
+			`UPDATE api_keys
+             SET last_used_at = $2
+             WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`,
+			id, at, at.Add(-lastUsedResolution),
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что BudgetRepository реализует интерфейс domain.BudgetRepository
+var _ domain.BudgetRepository = (*BudgetRepository)(nil)
+
+// BudgetRepository хранит бюджеты в budgets и отметки об уведомлениях в budget_alerts
+type BudgetRepository struct {
+	db  *pgxpool.Pool
+	rls bool
+}
+
+// NewBudgetRepository создает новый экземпляр репозитория бюджетов
+// rls включает выполнение запросов в транзакции с app.organization_id, как WithRowLevelSecurity у подписок
+func NewBudgetRepository(db *pgxpool.Pool, rls bool) *BudgetRepository {
+	return &BudgetRepository{db: db, rls: rls}
+}
+
+const budgetColumns = `id, organization_id, name, period, limit_amount, COALESCE(user_id::text, ''), COALESCE(service_name, ''), thresholds, email, created_at, updated_at`
+
+func scanBudget(row pgx.Row) (*domain.Budget, error) {
+	var b domain.Budget
+	err := row.Scan(
+		&b.ID,
+		&b.OrganizationID,
+		&b.Name,
+		&b.Period,
+		&b.Limit,
+		&b.UserID,
+		&b.ServiceName,
+		&b.Thresholds,
+		&b.Email,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Create создает новый бюджет
+func (r *BudgetRepository) Create(ctx context.Context, budget *domain.Budget) (*domain.Budget, error) {
+	var created *domain.Budget
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		created, err = scanBudget(q.QueryRow(
+			ctx,
+			`INSERT INTO budgets (organization_id, name, period, limit_amount, user_id, service_name, thresholds, email)
+             VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, NULLIF($6, ''), $7, $8)
+             RETURNING `+budgetColumns,
+			domain.OrganizationFromContext(ctx), budget.Name, budget.Period, budget.Limit,
+			budget.UserID, budget.ServiceName, budget.Thresholds, budget.Email,
+		))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID получает бюджет по ID
+func (r *BudgetRepository) GetByID(ctx context.Context, id string) (*domain.Budget, error) {
+	var budget *domain.Budget
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		budget, err = scanBudget(q.QueryRow(
+			ctx,
+			`SELECT `+budgetColumns+` FROM budgets WHERE id = $1 AND organization_id = $2`,
+			id, domain.OrganizationFromContext(ctx),
+		))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("budget not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	return budget, nil
+}
+
+// Update заменяет параметры бюджета; пользователь бюджета не меняется
+func (r *BudgetRepository) Update(ctx context.Context, id string, budget *domain.Budget) (*domain.Budget, error) {
+	var updated *domain.Budget
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		updated, err = scanBudget(q.QueryRow(
+			ctx,
+			`UPDATE budgets
+             SET name = $3, period = $4, limit_amount = $5, service_name = NULLIF($6, ''), thresholds = $7, email = $8, updated_at = now()
+             WHERE id = $1 AND organization_id = $2
+             RETURNING `+budgetColumns,
+			id, domain.OrganizationFromContext(ctx),
+			budget.Name, budget.Period, budget.Limit, budget.ServiceName, budget.Thresholds, budget.Email,
+		))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("budget not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Delete удаляет бюджет вместе с отметками об уведомлениях
+func (r *BudgetRepository) Delete(ctx context.Context, id string) error {
+	var deleted int64
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`DELETE FROM budgets WHERE id = $1 AND organization_id = $2`,
+			id, domain.OrganizationFromContext(ctx),
+		)
+		deleted = cmdTag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	if deleted == 0 {
+		return fmt.Errorf("budget not found")
+	}
+
+	return nil
+}
+
+// List возвращает бюджеты организации из контекста, сначала созданные раньше
+func (r *BudgetRepository) List(ctx context.Context, filters domain.BudgetFilters) ([]*domain.Budget, error) {
+	return r.query(
+		ctx, runScoped,
+		`SELECT `+budgetColumns+` FROM budgets
+         WHERE organization_id = $1 AND ($2 = '' OR user_id = NULLIF($2, '')::uuid)
+         ORDER BY created_at, id`,
+		domain.OrganizationFromContext(ctx), filters.UserID,
+	)
+}
+
+// ListAll возвращает бюджеты всех организаций
+func (r *BudgetRepository) ListAll(ctx context.Context) ([]*domain.Budget, error) {
+	return r.query(ctx, runUnscoped, `SELECT `+budgetColumns+` FROM budgets ORDER BY organization_id, created_at, id`)
+}
+
+// ClaimAlert отмечает уведомление о пороге отправленным
+// Фоновая проверка идет без организации в контексте, поэтому запись ограничивается организацией бюджета
+func (r *BudgetRepository) ClaimAlert(ctx context.Context, alert *domain.BudgetAlert) (bool, error) {
+	var claimed bool
+	err := runScoped(domain.WithOrganization(ctx, alert.OrganizationID), r.db, r.rls, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`INSERT INTO budget_alerts (budget_id, period_start, threshold, organization_id)
+             VALUES ($1, $2, $3, $4)
+             ON CONFLICT DO NOTHING`,
+			alert.BudgetID, alert.PeriodStart, alert.Threshold, alert.OrganizationID,
+		)
+		claimed = cmdTag.RowsAffected() == 1
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim budget alert: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// ReleaseAlert снимает отметку об отправке
+func (r *BudgetRepository) ReleaseAlert(ctx context.Context, alert *domain.BudgetAlert) error {
+	err := runScoped(domain.WithOrganization(ctx, alert.OrganizationID), r.db, r.rls, func(q querier) error {
+		_, err := q.Exec(
+			ctx,
+			`DELETE FROM budget_alerts WHERE budget_id = $1 AND period_start = $2 AND threshold = $3 AND organization_id = $4`,
+			alert.BudgetID, alert.PeriodStart, alert.Threshold, alert.OrganizationID,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release budget alert: %w", err)
+	}
+
+	return nil
+}
+
+// query выполняет выборку бюджетов через run: runScoped для организации из контекста, runUnscoped для всех
+func (r *BudgetRepository) query(ctx context.Context, run runner, sql string, args ...any) ([]*domain.Budget, error) {
+	var budgets []*domain.Budget
+	err := run(ctx, r.db, r.rls, func(q querier) error {
+		rows, err := q.Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		budgets, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Budget, error) {
+			return scanBudget(row)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+
+	return budgets, nil
+}
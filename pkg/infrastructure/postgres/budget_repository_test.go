@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+)
+
+func TestBudgetRepository_Conformance(t *testing.T) {
+	pool := testPool(t)
+	rlsPool := testRLSPool(t)
+
+	for name, rls := range map[string]bool{"default": false, "row level security": true} {
+		t.Run(name, func(t *testing.T) {
+			repotest.RunBudgetRepositoryTests(t, func(t *testing.T) domain.BudgetRepository {
+				resetSubscriptions(t, pool)
+				if rls {
+					return NewBudgetRepository(rlsPool, true)
+				}
+				return NewBudgetRepository(pool, false)
+			})
+		})
+	}
+}
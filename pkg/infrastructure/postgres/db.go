@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// querier общий интерфейс пула соединений и транзакции
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// beginner источник транзакций (пул соединений)
+type beginner interface {
+	querier
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// runner выполняет fn в рамках организации или над всеми организациями: runScoped или runUnscoped
+type runner func(ctx context.Context, db beginner, rls bool, fn func(q querier) error) error
+
+// runScoped выполняет fn в рамках организации из контекста
+// Если включена row-level security, fn выполняется в транзакции с выставленным app.organization_id,
+// и политика RLS в базе дополнительно ограничивает видимые строки. Без организации политика не пропускает ни одной строки
+func runScoped(ctx context.Context, db beginner, rls bool, fn func(q querier) error) error {
+	if !rls {
+		return fn(db)
+	}
+
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(
+			ctx,
+			`SELECT set_config('app.organization_id', $1, true)`,
+			domain.OrganizationFromContext(ctx),
+		); err != nil {
+			return fmt.Errorf("failed to set organization for row-level security: %w", err)
+		}
+		return fn(tx)
+	})
+}
+
+// runTx выполняет fn в транзакции; с row-level security выставляет app.organization_id, как runScoped
+func runTx(ctx context.Context, db beginner, rls bool, fn func(q querier) error) error {
+	if rls {
+		return runScoped(ctx, db, rls, fn)
+	}
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		return fn(tx)
+	})
+}
+
+// runUnscoped выполняет fn над данными всех организаций: фоновые задачи, метрики, поиск API ключа по хешу
+// Если включена row-level security, fn выполняется в транзакции с app.bypass_rls = 'on', который политики пропускают явно
+func runUnscoped(ctx context.Context, db beginner, rls bool, fn func(q querier) error) error {
+	if !rls {
+		return fn(db)
+	}
+
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT set_config('app.bypass_rls', 'on', true)`); err != nil {
+			return fmt.Errorf("failed to bypass row-level security: %w", err)
+		}
+		return fn(tx)
+	})
+}
+
+// runUnscopedTx выполняет fn над данными всех организаций в транзакции
+func runUnscopedTx(ctx context.Context, db beginner, rls bool, fn func(q querier) error) error {
+	if rls {
+		return runUnscoped(ctx, db, rls, fn)
+	}
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		return fn(tx)
+	})
+}
+
+// BypassRowLevelSecurity выставляет app.bypass_rls = 'on' на каждое соединение пула
+// Нужен, когда репозитории работают без row-level security: политики закрыты по умолчанию
+// и без app.organization_id не пропустили бы ни одной строки. Изоляцию тогда обеспечивают условия запросов
+func BypassRowLevelSecurity(cfg *pgxpool.Config) {
+	afterConnect := cfg.AfterConnect
+	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		if afterConnect != nil {
+			if err := afterConnect(ctx, conn); err != nil {
+				return err
+			}
+		}
+		if _, err := conn.Exec(ctx, `SELECT set_config('app.bypass_rls', 'on', false)`); err != nil {
+			return fmt.Errorf("failed to bypass row-level security: %w", err)
+		}
+		return nil
+	}
+}
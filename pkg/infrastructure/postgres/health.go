@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PingCheck проверяет, что пул может получить соединение и выполнить запрос
+func PingCheck(pool *pgxpool.Pool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return pool.Ping(ctx)
+	}
+}
+
+// MigrationCheck проверяет, что схема базы находится на ожидаемой версии и последняя миграция завершилась
+// Версия читается из таблицы schema_migrations, которую ведет golang-migrate
+func MigrationCheck(pool *pgxpool.Pool, expected uint) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var version int64
+		var dirty bool
+		err := pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no migrations applied, expected version %d", expected)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read migration version: %w", err)
+		}
+
+		if dirty {
+			return fmt.Errorf("migration %d is dirty", version)
+		}
+		if version != int64(expected) {
+			return fmt.Errorf("schema version %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что JobRunRepository реализует интерфейс domain.JobRunRepository
+var _ domain.JobRunRepository = (*JobRunRepository)(nil)
+
+// JobRunRepository хранит историю запусков фоновых задач в таблице job_runs
+type JobRunRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewJobRunRepository создает новый экземпляр репозитория истории запусков
+func NewJobRunRepository(db *pgxpool.Pool) *JobRunRepository {
+	return &JobRunRepository{db: db}
+}
+
+const jobRunColumns = `id, job, trigger, status, error, instance, started_at, finished_at`
+
+func scanJobRun(row pgx.Row) (*domain.JobRun, error) {
+	var r domain.JobRun
+	err := row.Scan(
+		&r.ID,
+		&r.Job,
+		&r.Trigger,
+		&r.Status,
+		&r.Error,
+		&r.Instance,
+		&r.StartedAt,
+		&r.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Create сохраняет новый запуск
+func (r *JobRunRepository) Create(ctx context.Context, run *domain.JobRun) (*domain.JobRun, error) {
+	created, err := scanJobRun(r.db.QueryRow(
+		ctx,
+		`INSERT INTO job_runs (job, trigger, status, error, instance, started_at)
+         VALUES ($1, $2, $3, $4, $5, $6)
+         RETURNING `+jobRunColumns,
+		run.Job, run.Trigger, run.Status, run.Error, run.Instance, run.StartedAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job run: %w", err)
+	}
+
+	return created, nil
+}
+
+// Finish сохраняет результат запуска
+func (r *JobRunRepository) Finish(ctx context.Context, run *domain.JobRun) error {
+	cmdTag, err := r.db.Exec(
+		ctx,
+		`UPDATE job_runs SET status = $1, error = $2, finished_at = $3 WHERE id = $4`,
+		run.Status, run.Error, run.FinishedAt, run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish job run: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("job run not found")
+	}
+
+	return nil
+}
+
+// List возвращает запуски от новых к старым
+func (r *JobRunRepository) List(ctx context.Context, filters domain.JobRunFilters) ([]*domain.JobRun, error) {
+	query := `SELECT ` + jobRunColumns + ` FROM job_runs`
+	var args []interface{}
+
+	if filters.Job != "" {
+		args = append(args, filters.Job)
+		query += fmt.Sprintf(" WHERE job = $%d", len(args))
+	}
+	query += " ORDER BY started_at DESC"
+	if filters.Limit > 0 {
+		args = append(args, filters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*domain.JobRun
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return runs, nil
+}
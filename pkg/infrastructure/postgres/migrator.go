@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// MigrationStatus состояние схемы базы
+type MigrationStatus struct {
+	// Version примененная версия; 0, если миграции не применялись
+	Version uint
+	// Dirty означает, что миграция Version завершилась ошибкой и требует ручного вмешательства (force)
+	Dirty bool
+	// Latest версия последней миграции в бинарнике
+	Latest uint
+}
+
+// Pending сообщает, есть ли непримененные миграции
+func (s MigrationStatus) Pending() bool {
+	return s.Version < s.Latest
+}
+
+// Migrator применяет встроенные миграции к базе
+// На время изменения схемы берется advisory lock в PostgreSQL, поэтому несколько экземпляров сервиса
+// могут запускать миграции одновременно: остальные дождутся первого и увидят актуальную схему
+type Migrator struct {
+	m      *migrate.Migrate
+	db     *sql.DB
+	latest uint
+}
+
+// NewMigrator создает мигратор поверх пула соединений
+// lockTimeout ограничивает ожидание advisory lock, занятого другим экземпляром
+func NewMigrator(pool *pgxpool.Pool, source fs.FS, lockTimeout time.Duration) (*Migrator, error) {
+	latest, err := migrations.LatestVersion(source)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := iofs.New(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	db := stdlib.OpenDBFromPool(pool)
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "pgx5", driver)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	if lockTimeout > 0 {
+		m.LockTimeout = lockTimeout
+	}
+	m.Log = migrateLogger{}
+
+	return &Migrator{m: m, db: db, latest: latest}, nil
+}
+
+// Up применяет все непримененные миграции
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+// Down откатывает steps последних миграций
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive")
+	}
+	if err := m.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+	return nil
+}
+
+// Force выставляет версию схемы без выполнения миграций и снимает признак dirty
+// Используется после ручного исправления базы, когда миграция завершилась ошибкой
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force migration version: %w", err)
+	}
+	return nil
+}
+
+// Status возвращает текущую и последнюю доступную версии схемы
+func (m *Migrator) Status() (MigrationStatus, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return MigrationStatus{Latest: m.latest}, nil
+	}
+	if err != nil {
+		return MigrationStatus{}, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return MigrationStatus{Version: version, Dirty: dirty, Latest: m.latest}, nil
+}
+
+// Close освобождает соединение мигратора; пул соединений остается открытым
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr, m.db.Close())
+}
+
+// migrateLogger направляет сообщения golang-migrate в slog
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	slog.Info("migrate: " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
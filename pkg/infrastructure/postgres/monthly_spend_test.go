@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollupSummaryQuery_Args(t *testing.T) {
+	_, args := rollupSummaryQuery(context.Background(), domain.SummaryFilters{
+		PeriodStart: time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.Equal(t, 2024*12+12, args[0])
+	require.Equal(t, 2025*12+2, args[1])
+}
+
+// TestSubscriptionRepository_MonthlyRollup сверяет сумму по агрегату с расчетом по подпискам
+// после случайной последовательности записей и после полного пересчета агрегата
+func TestSubscriptionRepository_MonthlyRollup(t *testing.T) {
+	pool := testPool(t)
+	resetSubscriptions(t, pool)
+
+	ctx := context.Background()
+	live := NewSubscriptionRepository(pool)
+	rollup := NewSubscriptionRepository(pool, WithMonthlyRollup())
+
+	users := []string{"60601fee-2bf1-4721-ae6f-7636e79a0cba", "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"}
+	services := []string{"Netflix", "Spotify", "Yandex Plus"}
+	rnd := rand.New(rand.NewSource(42))
+	month := func() time.Time {
+		return time.Date(2023+rnd.Intn(3), time.Month(1+rnd.Intn(12)), 1, 0, 0, 0, 0, time.UTC)
+	}
+	randomSubscription := func() *domain.Subscription {
+		sub := &domain.Subscription{
+			UserID:      users[rnd.Intn(len(users))],
+			ServiceName: services[rnd.Intn(len(services))],
+			Price:       int64(rnd.Intn(1000)),
+			StartDate:   month(),
+		}
+		if end := month(); rnd.Intn(3) > 0 && !end.Before(sub.StartDate) {
+			sub.EndDate = sql.NullTime{Time: end, Valid: true}
+		}
+		// Пробный период и вводная цена у части подписок
+		if rnd.Intn(3) == 0 {
+			sub.TrialMonths = rnd.Intn(4)
+			sub.IntroPrice = int64(rnd.Intn(500))
+			sub.IntroMonths = rnd.Intn(6)
+		}
+		return sub
+	}
+
+	// Одна или две непересекающиеся паузы, последняя может быть бессрочной
+	randomPauses := func() []domain.Pause {
+		var pauses []domain.Pause
+		start := month()
+		for n := 1 + rnd.Intn(2); n > 0; n-- {
+			p := domain.Pause{Start: start}
+			if rnd.Intn(3) > 0 || n > 1 {
+				p.End = sql.NullTime{Time: start.AddDate(0, rnd.Intn(6), 0), Valid: true}
+				start = p.End.Time.AddDate(0, 1+rnd.Intn(6), 0)
+			}
+			pauses = append(pauses, p)
+		}
+		return pauses
+	}
+
+	// Изменения цены и тарифа в возрастающие месяцы, последним может быть отмена
+	randomChanges := func() []domain.ScheduledChange {
+		var changes []domain.ScheduledChange
+		from := month()
+		for n := 1 + rnd.Intn(3); n > 0; n-- {
+			c := domain.ScheduledChange{EffectiveFrom: from}
+			switch {
+			case n == 1 && rnd.Intn(3) == 0:
+				c.Cancel = true
+			case rnd.Intn(4) == 0:
+				c.Plan = sql.NullString{String: "Premium", Valid: true}
+			default:
+				c.Price = sql.NullInt64{Int64: int64(rnd.Intn(1000)), Valid: true}
+			}
+			changes = append(changes, c)
+			from = from.AddDate(0, 1+rnd.Intn(8), 0)
+		}
+		return changes
+	}
+
+	var ids []string
+	for i := 0; i < 200; i++ {
+		switch op := rnd.Intn(10); {
+		case op < 6 || len(ids) == 0:
+			created, err := live.Create(ctx, randomSubscription())
+			require.NoError(t, err)
+			ids = append(ids, created.ID)
+		case op < 7:
+			_, err := live.Update(ctx, ids[rnd.Intn(len(ids))], randomSubscription())
+			require.NoError(t, err)
+		case op < 8:
+			pauses := randomPauses()
+			_, err := live.UpdatePauses(ctx, ids[rnd.Intn(len(ids))], func(*domain.Subscription) ([]domain.Pause, error) { return pauses, nil })
+			require.NoError(t, err)
+		case op < 9:
+			changes := randomChanges()
+			_, err := live.UpdateChanges(ctx, ids[rnd.Intn(len(ids))], func(*domain.Subscription) ([]domain.ScheduledChange, error) { return changes, nil })
+			require.NoError(t, err)
+		default:
+			n := rnd.Intn(len(ids))
+			require.NoError(t, live.Delete(ctx, ids[n]))
+			ids = append(ids[:n], ids[n+1:]...)
+		}
+	}
+	_, err := live.DeleteMany(ctx, domain.DeleteFilters{UserID: users[0], ServiceName: services[0]})
+	require.NoError(t, err)
+
+	compare := func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			from, to := month(), month()
+			if to.Before(from) {
+				from, to = to, from
+			}
+			filters := domain.SummaryFilters{PeriodStart: from, PeriodEnd: to}
+			switch i % 4 {
+			case 1:
+				filters.UserID = users[rnd.Intn(len(users))]
+			case 2:
+				filters.ServiceName = services[rnd.Intn(len(services))]
+			case 3:
+				filters.UserID = users[rnd.Intn(len(users))]
+				filters.ServiceName = services[rnd.Intn(len(services))]
+			}
+
+			want, err := live.GetSummary(ctx, filters)
+			require.NoError(t, err)
+			got, err := rollup.GetSummary(ctx, filters)
+			require.NoError(t, err)
+			require.Equal(t, want, got, "filters %+v", filters)
+		}
+	}
+
+	t.Run("incremental", compare)
+
+	// Применение изменений не должно менять суммы ни в одном из режимов
+	_, err = live.ApplyChanges(ctx, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	t.Run("applied", compare)
+
+	require.NoError(t, rollup.RebuildMonthlySpend(ctx))
+	t.Run("rebuilt", compare)
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что OrganizationRepository реализует интерфейс domain.OrganizationRepository
+var _ domain.OrganizationRepository = (*OrganizationRepository)(nil)
+
+// OrganizationRepository хранит организации в organizations
+// Таблица относится к платформе и не защищена row-level security
+type OrganizationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewOrganizationRepository создает новый экземпляр репозитория организаций
+func NewOrganizationRepository(db *pgxpool.Pool) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+const organizationColumns = `id, name, created_at`
+
+func scanOrganization(row pgx.Row) (*domain.Organization, error) {
+	var o domain.Organization
+	if err := row.Scan(&o.ID, &o.Name, &o.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Create создает организацию
+func (r *OrganizationRepository) Create(ctx context.Context, org *domain.Organization) (*domain.Organization, error) {
+	created, err := scanOrganization(r.db.QueryRow(
+		ctx,
+		`INSERT INTO organizations (id, name) VALUES (COALESCE(NULLIF($1, '')::uuid, gen_random_uuid()), $2) RETURNING `+organizationColumns,
+		org.ID, org.Name,
+	))
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("failed to create organization: organization %q or its id is already used", org.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	return created, nil
+}
+
+// Exists сообщает, что организация существует
+func (r *OrganizationRepository) Exists(ctx context.Context, id string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM organizations WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check organization: %w", err)
+	}
+	return exists, nil
+}
+
+// List возвращает организации по названию
+func (r *OrganizationRepository) List(ctx context.Context) ([]*domain.Organization, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+organizationColumns+` FROM organizations ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	orgs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Organization, error) {
+		return scanOrganization(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	return orgs, nil
+}
@@ -0,0 +1,16 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+)
+
+func TestOrganizationRepository_Conformance(t *testing.T) {
+	pool := testPool(t)
+
+	repotest.RunOrganizationRepositoryTests(t, func(t *testing.T) domain.OrganizationRepository {
+		return NewOrganizationRepository(pool)
+	})
+}
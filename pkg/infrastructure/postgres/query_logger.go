@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+// NewQueryLogger создает трассировщик pgx, который пишет каждый запрос в лог запроса из контекста
+// Запросы пишутся на уровне debug, ошибки - на уровне error; аргументы запросов в лог не попадают
+func NewQueryLogger() *tracelog.TraceLog {
+	return &tracelog.TraceLog{
+		Logger:   tracelog.LoggerFunc(logQuery),
+		LogLevel: tracelog.LogLevelInfo,
+	}
+}
+
+func logQuery(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+	attrs := make([]any, 0, 2*len(data))
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		if k == "args" {
+			continue
+		}
+		attrs = append(attrs, k, data[k])
+	}
+
+	slogLevel := slog.LevelDebug
+	switch level {
+	case tracelog.LogLevelError:
+		slogLevel = slog.LevelError
+	case tracelog.LogLevelWarn:
+		slogLevel = slog.LevelWarn
+	}
+
+	logging.FromContext(ctx).Log(ctx, slogLevel, "pgx: "+msg, attrs...)
+}
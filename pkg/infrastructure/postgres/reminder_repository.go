@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что ReminderRepository реализует интерфейс domain.ReminderRepository
+var _ domain.ReminderRepository = (*ReminderRepository)(nil)
+
+// ReminderRepository хранит настройки пользователей в reminder_preferences и отметки об отправке в sent_reminders
+type ReminderRepository struct {
+	db  *pgxpool.Pool
+	rls bool
+}
+
+// NewReminderRepository создает новый экземпляр репозитория напоминаний
+// rls включает выполнение запросов в транзакции с app.organization_id, как WithRowLevelSecurity у подписок
+func NewReminderRepository(db *pgxpool.Pool, rls bool) *ReminderRepository {
+	return &ReminderRepository{db: db, rls: rls}
+}
+
+// dueRemindersQuery повторяет domain.ReminderKind: подписка, действующая в месяце перед датой,
+// продлевается или заканчивается, если этот месяц последний с учетом запланированной отмены;
+// цена берется за месяц даты, как domain.Subscription.PriceAt. О продлении на месяц паузы не напоминают
+const dueRemindersQuery = `
+SELECT c.organization_id, c.id, c.user_id, c.service_name, c.price, c.kind, c.due_date, COALESCE(p.email, '')
+FROM (
+    SELECT s.organization_id, s.id, s.user_id, s.service_name,
+           subscription_price_at(s, d.due_date) AS price,
+           d.due_date,
+           CASE WHEN e.last_month = (d.due_date - INTERVAL '1 month')::date THEN 'expiry' ELSE 'renewal' END AS kind
+    FROM subscriptions s
+    CROSS JOIN unnest($1::date[]) AS d(due_date)
+    CROSS JOIN LATERAL (SELECT subscription_end(s.end_date, s.scheduled_changes) AS last_month) e
+    WHERE s.start_date < d.due_date
+      AND (e.last_month IS NULL OR e.last_month >= (d.due_date - INTERVAL '1 month')::date)
+      AND (e.last_month = (d.due_date - INTERVAL '1 month')::date OR NOT subscription_paused_at(s.pauses, d.due_date))
+) c
+LEFT JOIN reminder_preferences p ON p.organization_id = c.organization_id AND p.user_id = c.user_id
+WHERE CASE c.kind WHEN 'expiry' THEN COALESCE(p.expiry, true) ELSE COALESCE(p.renewal, true) END
+  AND NOT EXISTS (
+    SELECT 1 FROM sent_reminders r
+    WHERE r.subscription_id = c.id AND r.kind = c.kind AND r.due_date = c.due_date
+  )
+ORDER BY c.due_date, c.id`
+
+// Due возвращает неотправленные напоминания всех организаций с датой в интервале (from, to]
+func (r *ReminderRepository) Due(ctx context.Context, from, to time.Time) ([]*domain.Reminder, error) {
+	dates := domain.ReminderDates(from, to)
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	var reminders []*domain.Reminder
+	err := runUnscoped(ctx, r.db, r.rls, func(q querier) error {
+		rows, err := q.Query(ctx, dueRemindersQuery, dates)
+		if err != nil {
+			return err
+		}
+		reminders, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Reminder, error) {
+			var rem domain.Reminder
+			err := row.Scan(
+				&rem.OrganizationID,
+				&rem.SubscriptionID,
+				&rem.UserID,
+				&rem.ServiceName,
+				&rem.Price,
+				&rem.Kind,
+				&rem.Date,
+				&rem.Email,
+			)
+			return &rem, err
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// Claim отмечает напоминание отправленным
+// Рассылка идет без организации в контексте, поэтому запись ограничивается организацией напоминания
+func (r *ReminderRepository) Claim(ctx context.Context, reminder *domain.Reminder) (bool, error) {
+	var claimed bool
+	err := runScoped(domain.WithOrganization(ctx, reminder.OrganizationID), r.db, r.rls, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`INSERT INTO sent_reminders (subscription_id, kind, due_date, organization_id)
+             VALUES ($1, $2, $3, $4)
+             ON CONFLICT DO NOTHING`,
+			reminder.SubscriptionID, reminder.Kind, reminder.Date, reminder.OrganizationID,
+		)
+		claimed = cmdTag.RowsAffected() == 1
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// Release снимает отметку об отправке
+func (r *ReminderRepository) Release(ctx context.Context, reminder *domain.Reminder) error {
+	err := runScoped(domain.WithOrganization(ctx, reminder.OrganizationID), r.db, r.rls, func(q querier) error {
+		_, err := q.Exec(
+			ctx,
+			`DELETE FROM sent_reminders WHERE subscription_id = $1 AND kind = $2 AND due_date = $3 AND organization_id = $4`,
+			reminder.SubscriptionID, reminder.Kind, reminder.Date, reminder.OrganizationID,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release reminder: %w", err)
+	}
+
+	return nil
+}
+
+const reminderPreferencesColumns = `user_id, email, renewal, expiry, updated_at`
+
+func scanReminderPreferences(row pgx.Row) (*domain.ReminderPreferences, error) {
+	var p domain.ReminderPreferences
+	if err := row.Scan(&p.UserID, &p.Email, &p.Renewal, &p.Expiry, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPreferences возвращает настройки пользователя или настройки по умолчанию
+func (r *ReminderRepository) GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error) {
+	var prefs *domain.ReminderPreferences
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		prefs, err = scanReminderPreferences(q.QueryRow(
+			ctx,
+			`SELECT `+reminderPreferencesColumns+` FROM reminder_preferences WHERE organization_id = $1 AND user_id = $2`,
+			domain.OrganizationFromContext(ctx), userID,
+		))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return domain.DefaultReminderPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// SavePreferences создает или заменяет настройки пользователя
+func (r *ReminderRepository) SavePreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	var saved *domain.ReminderPreferences
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		saved, err = scanReminderPreferences(q.QueryRow(
+			ctx,
+			`INSERT INTO reminder_preferences (organization_id, user_id, email, renewal, expiry)
+             VALUES ($1, $2, $3, $4, $5)
+             ON CONFLICT (organization_id, user_id)
+             DO UPDATE SET email = EXCLUDED.email, renewal = EXCLUDED.renewal, expiry = EXCLUDED.expiry, updated_at = now()
+             RETURNING `+reminderPreferencesColumns,
+			domain.OrganizationFromContext(ctx), prefs.UserID, prefs.Email, prefs.Renewal, prefs.Expiry,
+		))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reminder preferences: %w", err)
+	}
+
+	return saved, nil
+}
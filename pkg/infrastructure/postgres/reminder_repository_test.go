@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+)
+
+func TestReminderRepository_Conformance(t *testing.T) {
+	pool := testPool(t)
+	rlsPool := testRLSPool(t)
+
+	for name, rls := range map[string]bool{"default": false, "row level security": true} {
+		t.Run(name, func(t *testing.T) {
+			repotest.RunReminderRepositoryTests(t, func(t *testing.T) (domain.SubscriptionRepository, domain.ReminderRepository) {
+				resetSubscriptions(t, pool)
+				if rls {
+					return NewSubscriptionRepository(rlsPool, WithRowLevelSecurity()), NewReminderRepository(rlsPool, true)
+				}
+				return NewSubscriptionRepository(pool), NewReminderRepository(pool, false)
+			})
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionRepository_Reader(t *testing.T) {
+	primary, replica := &pgxpool.Pool{}, &pgxpool.Pool{}
+
+	t.Run("without replica", func(t *testing.T) {
+		r := NewSubscriptionRepository(primary)
+		assert.Same(t, primary, r.reader(context.Background()))
+	})
+
+	t.Run("reads go to replica", func(t *testing.T) {
+		r := NewSubscriptionRepository(primary, WithReadReplica(replica))
+		ctx := domain.WithWriteTracking(context.Background())
+		domain.MarkWritten(ctx)
+		assert.Same(t, replica, r.reader(ctx))
+	})
+
+	t.Run("read your writes", func(t *testing.T) {
+		r := NewSubscriptionRepository(primary, WithReadReplica(replica), WithReadYourWrites())
+
+		assert.Same(t, replica, r.reader(context.Background()))
+
+		ctx := domain.WithWriteTracking(context.Background())
+		assert.Same(t, replica, r.reader(ctx))
+
+		domain.MarkWritten(ctx)
+		assert.Same(t, primary, r.reader(ctx))
+
+		// Отметка о записи видна во вложенных контекстах запроса
+		assert.Same(t, primary, r.reader(context.WithValue(ctx, struct{}{}, 1)))
+
+		// Другой запрос по-прежнему читает с реплики
+		assert.Same(t, replica, r.reader(domain.WithWriteTracking(context.Background())))
+	})
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что ServiceRepository реализует интерфейс domain.ServiceRepository
+var _ domain.ServiceRepository = (*ServiceRepository)(nil)
+
+// ServiceRepository хранит каталог сервисов в services и нормализованные написания названий в service_names
+type ServiceRepository struct {
+	db  *pgxpool.Pool
+	rls bool
+}
+
+// NewServiceRepository создает новый экземпляр репозитория каталога сервисов
+// rls включает выполнение запросов в транзакции с app.organization_id, как WithRowLevelSecurity у подписок
+func NewServiceRepository(db *pgxpool.Pool, rls bool) *ServiceRepository {
+	return &ServiceRepository{db: db, rls: rls}
+}
+
+const serviceColumns = `id, organization_id, name, aliases, category, vendor_url, plans, created_at, updated_at`
+
+// servicePlan тариф в JSON-колонке plans
+type servicePlan struct {
+	Name  string `json:"name"`
+	Price int64  `json:"price"`
+}
+
+func scanService(row pgx.Row) (*domain.Service, error) {
+	var (
+		s     domain.Service
+		plans []byte
+	)
+	err := row.Scan(
+		&s.ID,
+		&s.OrganizationID,
+		&s.Name,
+		&s.Aliases,
+		&s.Category,
+		&s.VendorURL,
+		&plans,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []servicePlan
+	if err := json.Unmarshal(plans, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode plans: %w", err)
+	}
+	for _, p := range stored {
+		s.Plans = append(s.Plans, domain.ServicePlan{Name: p.Name, Price: p.Price})
+	}
+	return &s, nil
+}
+
+// encodePlans кодирует тарифы для колонки plans
+func encodePlans(plans []domain.ServicePlan) ([]byte, error) {
+	stored := make([]servicePlan, 0, len(plans))
+	for _, p := range plans {
+		stored = append(stored, servicePlan{Name: p.Name, Price: p.Price})
+	}
+	return json.Marshal(stored)
+}
+
+// Create добавляет сервис в каталог
+func (r *ServiceRepository) Create(ctx context.Context, svc *domain.Service) (*domain.Service, error) {
+	plans, err := encodePlans(svc.Plans)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service: %w", err)
+	}
+
+	var created *domain.Service
+	err = runTx(ctx, r.db, r.rls, func(tx querier) error {
+		var err error
+		created, err = scanService(tx.QueryRow(
+			ctx,
+			`INSERT INTO services (organization_id, name, aliases, category, vendor_url, plans)
+             VALUES ($1, $2, $3, $4, $5, $6)
+             RETURNING `+serviceColumns,
+			domain.OrganizationFromContext(ctx), svc.Name, aliases(svc), svc.Category, svc.VendorURL, plans,
+		))
+		if err != nil {
+			return err
+		}
+		return r.setKeys(ctx, tx, created)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID получает сервис по ID
+func (r *ServiceRepository) GetByID(ctx context.Context, id string) (*domain.Service, error) {
+	var svc *domain.Service
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		svc, err = scanService(q.QueryRow(
+			ctx,
+			`SELECT `+serviceColumns+` FROM services WHERE id = $1 AND organization_id = $2`,
+			id, domain.OrganizationFromContext(ctx),
+		))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("service not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	return svc, nil
+}
+
+// Update заменяет данные сервиса вместе с написаниями названия
+func (r *ServiceRepository) Update(ctx context.Context, id string, svc *domain.Service) (*domain.Service, error) {
+	plans, err := encodePlans(svc.Plans)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+
+	var updated *domain.Service
+	err = runTx(ctx, r.db, r.rls, func(tx querier) error {
+		var err error
+		updated, err = scanService(tx.QueryRow(
+			ctx,
+			`UPDATE services
+             SET name = $3, aliases = $4, category = $5, vendor_url = $6, plans = $7, updated_at = now()
+             WHERE id = $1 AND organization_id = $2
+             RETURNING `+serviceColumns,
+			id, domain.OrganizationFromContext(ctx), svc.Name, aliases(svc), svc.Category, svc.VendorURL, plans,
+		))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM service_names WHERE service_id = $1`, updated.ID); err != nil {
+			return err
+		}
+		return r.setKeys(ctx, tx, updated)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("service not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Delete удаляет сервис; подписки остаются со своим названием, но без ссылки на каталог
+func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
+	var deleted int64
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`DELETE FROM services WHERE id = $1 AND organization_id = $2`,
+			id, domain.OrganizationFromContext(ctx),
+		)
+		deleted = cmdTag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+
+	if deleted == 0 {
+		return fmt.Errorf("service not found")
+	}
+
+	return nil
+}
+
+// List возвращает сервисы организации из контекста по названию
+func (r *ServiceRepository) List(ctx context.Context, filters domain.ServiceFilters) ([]*domain.Service, error) {
+	var services []*domain.Service
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		rows, err := q.Query(
+			ctx,
+			`SELECT `+serviceColumns+` FROM services
+             WHERE organization_id = $1 AND ($2 = '' OR category = $2)
+             ORDER BY name, id`,
+			domain.OrganizationFromContext(ctx), filters.Category,
+		)
+		if err != nil {
+			return err
+		}
+		services, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Service, error) {
+			return scanService(row)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query services: %w", err)
+	}
+
+	return services, nil
+}
+
+// FindByName ищет сервис организации по названию или псевдониму
+func (r *ServiceRepository) FindByName(ctx context.Context, name string) (*domain.Service, error) {
+	key := domain.NormalizeServiceName(name)
+	if key == "" {
+		return nil, nil
+	}
+
+	var svc *domain.Service
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		svc, err = scanService(q.QueryRow(
+			ctx,
+			`SELECT s.id, s.organization_id, s.name, s.aliases, s.category, s.vendor_url, s.plans, s.created_at, s.updated_at
+             FROM service_names n
+             JOIN services s ON s.id = n.service_id
+             WHERE n.organization_id = $1 AND n.name_key = $2`,
+			domain.OrganizationFromContext(ctx), key,
+		))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find service: %w", err)
+	}
+
+	return svc, nil
+}
+
+// setKeys записывает нормализованные написания названия сервиса
+// Написание, занятое другим сервисом организации, отклоняется с названием этого сервиса
+func (r *ServiceRepository) setKeys(ctx context.Context, tx querier, svc *domain.Service) error {
+	keys := svc.Keys()
+	if len(keys) == 0 {
+		return fmt.Errorf("service name must contain letters or digits")
+	}
+
+	var key, owner string
+	err := tx.QueryRow(
+		ctx,
+		`SELECT n.name_key, s.name
+         FROM service_names n
+         JOIN services s ON s.id = n.service_id
+         WHERE n.organization_id = $1 AND n.name_key = ANY($2)
+         LIMIT 1`,
+		svc.OrganizationID, keys,
+	).Scan(&key, &owner)
+	if err == nil {
+		return fmt.Errorf("service name %q is already used by %s", key, owner)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO service_names (organization_id, name_key, service_id)
+         SELECT $1, k, $2 FROM unnest($3::text[]) AS k`,
+		svc.OrganizationID, svc.ID, keys,
+	)
+	// Одновременная запись того же написания другим сервисом
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("service name is already used by another service")
+	}
+	return err
+}
+
+// aliases возвращает псевдонимы сервиса, не давая записать NULL в колонку
+func aliases(svc *domain.Service) []string {
+	if svc.Aliases == nil {
+		return []string{}
+	}
+	return svc.Aliases
+}
@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+)
+
+func TestServiceRepository_Conformance(t *testing.T) {
+	pool := testPool(t)
+	rlsPool := testRLSPool(t)
+
+	for name, rls := range map[string]bool{"default": false, "row level security": true} {
+		t.Run(name, func(t *testing.T) {
+			repotest.RunServiceRepositoryTests(t, func(t *testing.T) (domain.SubscriptionRepository, domain.ServiceRepository) {
+				resetSubscriptions(t, pool)
+				if rls {
+					return NewSubscriptionRepository(rlsPool, WithRowLevelSecurity()), NewServiceRepository(rlsPool, true)
+				}
+				return NewSubscriptionRepository(pool), NewServiceRepository(pool, false)
+			})
+		})
+	}
+}
@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/sqljson"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -14,134 +17,567 @@ import (
 var _ domain.SubscriptionRepository = (*SubscriptionRepository)(nil)
 
 // SubscriptionRepository реализует интерфейс репозитория для PostgreSQL
+// Все запросы ограничены организацией из контекста (domain.OrganizationFromContext)
+// Если задана реплика, GetByID, List и GetSummary читают с нее, а записи идут в основную базу
 type SubscriptionRepository struct {
-	db *pgxpool.Pool
+	db             *pgxpool.Pool
+	replica        *pgxpool.Pool
+	readYourWrites bool
+	rls            bool
+	rollup         bool
+}
+
+// Option настраивает репозиторий подписок
+type Option func(*SubscriptionRepository)
+
+// WithRowLevelSecurity включает выполнение запросов в транзакции с app.organization_id,
+// чтобы политика row-level security в базе служила вторым уровнем изоляции организаций
+func WithRowLevelSecurity() Option {
+	return func(r *SubscriptionRepository) {
+		r.rls = true
+	}
+}
+
+// WithReadReplica направляет чтения (GetByID, List, GetSummary) в пул реплики
+func WithReadReplica(replica *pgxpool.Pool) Option {
+	return func(r *SubscriptionRepository) {
+		r.replica = replica
+	}
+}
+
+// WithReadYourWrites закрепляет запрос за основной базой после того, как он выполнил запись
+// Работает для контекстов, отмеченных domain.WithWriteTracking; без реплики ничего не меняет
+func WithReadYourWrites() Option {
+	return func(r *SubscriptionRepository) {
+		r.readYourWrites = true
+	}
+}
+
+// WithMonthlyRollup включает подсчет GetSummary по агрегату monthly_spend вместо таблицы подписок
+// Агрегат обновляется триггером в транзакции записи, поэтому результат совпадает с расчетом по подпискам
+func WithMonthlyRollup() Option {
+	return func(r *SubscriptionRepository) {
+		r.rollup = true
+	}
 }
 
 // NewSubscriptionRepository создает новый экземпляр репозитория подписок
-func NewSubscriptionRepository(db *pgxpool.Pool) *SubscriptionRepository {
-	return &SubscriptionRepository{db: db}
+func NewSubscriptionRepository(db *pgxpool.Pool, opts ...Option) *SubscriptionRepository {
+	r := &SubscriptionRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// Create создает новую подписку
-func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) (*domain.Subscription, error) {
-	var created domain.Subscription
-	err := r.db.QueryRow(
-		ctx,
-		`INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-         VALUES ($1, $2, $3, $4, $5)
-         RETURNING id, service_name, price, user_id, start_date, end_date, created_at, updated_at`,
-		sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate,
-	).Scan(
-		&created.ID,
-		&created.ServiceName,
-		&created.Price,
-		&created.UserID,
-		&created.StartDate,
-		&created.EndDate,
-		&created.CreatedAt,
-		&created.UpdatedAt,
+const subscriptionColumns = `id, organization_id, service_name, COALESCE(service_id::text, ''), plan, price, user_id, start_date, end_date, trial_months, intro_price, intro_months,
+	pauses, price_history, scheduled_changes, status, created_at, updated_at,
+	ARRAY(SELECT t.name FROM subscription_tags st JOIN tags t ON t.id = st.tag_id WHERE st.subscription_id = subscriptions.id ORDER BY t.name)`
+
+// tagFilter начало условия на тег подписки; дописывается номером параметра с названием тега и закрывающей скобкой
+const tagFilter = `EXISTS (SELECT 1 FROM subscription_tags filter_links JOIN tags filter_tags ON filter_tags.id = filter_links.tag_id
+	WHERE filter_links.subscription_id = subscriptions.id AND filter_tags.name = $`
+
+func scanSubscription(row pgx.Row) (*domain.Subscription, error) {
+	var (
+		s                             domain.Subscription
+		pauses, priceHistory, changes []byte
+	)
+	err := row.Scan(
+		&s.ID,
+		&s.OrganizationID,
+		&s.ServiceName,
+		&s.ServiceID,
+		&s.Plan,
+		&s.Price,
+		&s.UserID,
+		&s.StartDate,
+		&s.EndDate,
+		&s.TrialMonths,
+		&s.IntroPrice,
+		&s.IntroMonths,
+		&pauses,
+		&priceHistory,
+		&changes,
+		&s.Status,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&s.Tags,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Tags) == 0 {
+		s.Tags = nil
+	}
+	if s.Pauses, err = sqljson.DecodePauses(pauses); err != nil {
+		return nil, err
+	}
+	if s.PriceHistory, err = sqljson.DecodePriceHistory(priceHistory); err != nil {
+		return nil, err
+	}
+	if s.Changes, err = sqljson.DecodeChanges(changes); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// run выполняет fn в основной базе в рамках организации из контекста и отмечает запись для read-your-writes
+func (r *SubscriptionRepository) run(ctx context.Context, fn func(q querier) error) error {
+	if err := runScoped(ctx, r.db, r.rls, fn); err != nil {
+		return err
+	}
+	domain.MarkWritten(ctx)
+	return nil
+}
+
+// read выполняет fn на реплике, если она задана и запрос не закреплен за основной базой
+func (r *SubscriptionRepository) read(ctx context.Context, fn func(q querier) error) error {
+	return runScoped(ctx, r.reader(ctx), r.rls, fn)
+}
+
+// reader выбирает пул для чтения
+func (r *SubscriptionRepository) reader(ctx context.Context) *pgxpool.Pool {
+	if r.replica == nil || (r.readYourWrites && domain.HasWritten(ctx)) {
+		return r.db
+	}
+	return r.replica
+}
+
+// runTx выполняет fn в транзакции основной базы и отмечает запись для read-your-writes
+// Нужен записям из нескольких запросов, например подписки вместе с тегами
+func (r *SubscriptionRepository) runTx(ctx context.Context, fn func(q querier) error) error {
+	if err := runTx(ctx, r.db, r.rls, fn); err != nil {
+		return err
+	}
+	domain.MarkWritten(ctx)
+	return nil
+}
+
+// Create создает новую подписку вместе с тегами
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) (*domain.Subscription, error) {
+	var created *domain.Subscription
+	err := r.runTx(ctx, func(q querier) error {
+		var err error
+		created, err = scanSubscription(q.QueryRow(
+			ctx,
+			`INSERT INTO subscriptions (organization_id, service_name, price, user_id, start_date, end_date, trial_months, intro_price, intro_months, plan, service_id)
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, '')::uuid)
+             RETURNING `+subscriptionColumns,
+			domain.OrganizationFromContext(ctx), sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate,
+			sub.TrialMonths, sub.IntroPrice, sub.IntroMonths, sub.Plan, sub.ServiceID,
+		))
+		if err != nil || len(sub.Tags) == 0 {
+			return err
+		}
+		created, err = setTags(ctx, q, created.ID, sub.Tags)
+		return err
+	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create subscription: %w", err)
 	}
 
-	return &created, nil
+	return created, nil
 }
 
 // GetByID получает подписку по ID
 func (r *SubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.Subscription, error) {
-	var sub domain.Subscription
-	err := r.db.QueryRow(
-		ctx,
-		`SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at 
-         FROM subscriptions 
-         WHERE id = $1`,
-		id,
-	).Scan(
-		&sub.ID,
-		&sub.ServiceName,
-		&sub.Price,
-		&sub.UserID,
-		&sub.StartDate,
-		&sub.EndDate,
-		&sub.CreatedAt,
-		&sub.UpdatedAt,
-	)
+	var sub *domain.Subscription
+	err := r.read(ctx, func(q querier) error {
+		var err error
+		sub, err = scanSubscription(q.QueryRow(
+			ctx,
+			`SELECT `+subscriptionColumns+`
+             FROM subscriptions
+             WHERE id = $1 AND organization_id = $2`,
+			id, domain.OrganizationFromContext(ctx),
+		))
+		return err
+	})
 
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("subscription not found: %w", err)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
-	return &sub, nil
+	return sub, nil
 }
 
 // Update обновляет подписку
 func (r *SubscriptionRepository) Update(ctx context.Context, id string, sub *domain.Subscription) (*domain.Subscription, error) {
-	var updated domain.Subscription
-	err := r.db.QueryRow(
-		ctx,
-		`UPDATE subscriptions
-         SET service_name = $1,
-             price = $2,
-             start_date = $3,
-             end_date = $4,
-             updated_at = now()
-         WHERE id = $5
-         RETURNING id, service_name, price, user_id, start_date, end_date, created_at, updated_at`,
-		sub.ServiceName, sub.Price, sub.StartDate, sub.EndDate, id,
-	).Scan(
-		&updated.ID,
-		&updated.ServiceName,
-		&updated.Price,
-		&updated.UserID,
-		&updated.StartDate,
-		&updated.EndDate,
-		&updated.CreatedAt,
-		&updated.UpdatedAt,
-	)
+	var updated *domain.Subscription
+	err := r.run(ctx, func(q querier) error {
+		var err error
+		updated, err = scanSubscription(q.QueryRow(
+			ctx,
+			`UPDATE subscriptions
+             SET service_name = $1,
+                 price = $2,
+                 start_date = $3,
+                 end_date = $4,
+                 trial_months = $7,
+                 intro_price = $8,
+                 intro_months = $9,
+                 plan = $10,
+                 service_id = NULLIF($11, '')::uuid,
+                 status = CASE WHEN $4::date < date_trunc('month', now())::date THEN status ELSE 'active' END,
+                 updated_at = now()
+             WHERE id = $5 AND organization_id = $6
+             RETURNING `+subscriptionColumns,
+			sub.ServiceName, sub.Price, sub.StartDate, sub.EndDate, id, domain.OrganizationFromContext(ctx),
+			sub.TrialMonths, sub.IntroPrice, sub.IntroMonths, sub.Plan, sub.ServiceID,
+		))
+		return err
+	})
 
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("subscription not found: %w", err)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update subscription: %w", err)
 	}
 
-	return &updated, nil
+	return updated, nil
 }
 
 // Delete удаляет подписку
 func (r *SubscriptionRepository) Delete(ctx context.Context, id string) error {
-	cmdTag, err := r.db.Exec(
-		ctx,
-		`DELETE FROM subscriptions WHERE id = $1`,
-		id,
-	)
+	var affected int64
+	err := r.run(ctx, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`DELETE FROM subscriptions WHERE id = $1 AND organization_id = $2`,
+			id, domain.OrganizationFromContext(ctx),
+		)
+		affected = cmdTag.RowsAffected()
+		return err
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
 
-	if cmdTag.RowsAffected() == 0 {
+	if affected == 0 {
 		return fmt.Errorf("subscription not found")
 	}
 
 	return nil
 }
 
+// UpdatePauses заменяет приостановки подписки на возвращенные update
+// Подписка блокируется (SELECT ... FOR UPDATE) от чтения до записи, поэтому параллельные изменения пауз не теряются.
+// Триггер агрегата monthly_spend срабатывает на изменение pauses и вычитает месяцы на паузе
+func (r *SubscriptionRepository) UpdatePauses(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.Pause, error)) (*domain.Subscription, error) {
+	return r.updateJSON(ctx, id, "pauses", func(sub *domain.Subscription) ([]byte, error) {
+		pauses, err := update(sub)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pauses {
+			if p.End.Valid && monthStart(p.End.Time).Before(monthStart(p.Start)) {
+				return nil, fmt.Errorf("failed to update subscription pauses: pause end must not be before its start")
+			}
+		}
+		data, err := sqljson.EncodePauses(pauses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update subscription pauses: %w", err)
+		}
+		return data, nil
+	})
+}
+
+// UpdateChanges заменяет запланированные изменения подписки на возвращенные update
+// Подписка блокируется так же, как задачей ApplyChanges, поэтому изменение не может быть применено дважды.
+// Триггер агрегата срабатывает на изменение scheduled_changes и сразу учитывает их цены и отмену
+func (r *SubscriptionRepository) UpdateChanges(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.ScheduledChange, error)) (*domain.Subscription, error) {
+	return r.updateJSON(ctx, id, "scheduled_changes", func(sub *domain.Subscription) ([]byte, error) {
+		changes, err := update(sub)
+		if err != nil {
+			return nil, err
+		}
+		data, err := sqljson.EncodeChanges(changes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update scheduled changes: %w", err)
+		}
+		return data, nil
+	})
+}
+
+// updateJSON блокирует подписку до конца транзакции, строит по ней новое значение JSON-колонки column и записывает его
+// Ошибка build отменяет запись и возвращается без изменений; column задается только из кода репозитория
+func (r *SubscriptionRepository) updateJSON(ctx context.Context, id, column string, build func(*domain.Subscription) ([]byte, error)) (*domain.Subscription, error) {
+	var updated *domain.Subscription
+	var buildErr error
+	err := r.runTx(ctx, func(q querier) error {
+		sub, err := scanSubscription(q.QueryRow(
+			ctx,
+			`SELECT `+subscriptionColumns+`
+             FROM subscriptions
+             WHERE id = $1 AND organization_id = $2
+             FOR UPDATE`,
+			id, domain.OrganizationFromContext(ctx),
+		))
+		if err != nil {
+			return err
+		}
+
+		data, err := build(sub)
+		if err != nil {
+			buildErr = err
+			return err
+		}
+
+		updated, err = scanSubscription(q.QueryRow(
+			ctx,
+			`UPDATE subscriptions
+             SET `+column+` = $1, updated_at = now()
+             WHERE id = $2
+             RETURNING `+subscriptionColumns,
+			data, id,
+		))
+		return err
+	})
+
+	switch {
+	case buildErr != nil:
+		return nil, buildErr
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("subscription not found: %w", err)
+	case err != nil:
+		return nil, fmt.Errorf("failed to update subscription %s: %w", column, err)
+	}
+	return updated, nil
+}
+
+// SetTags заменяет теги подписки; недостающие теги организации создаются, updated_at не меняется
+func (r *SubscriptionRepository) SetTags(ctx context.Context, id string, tags []string) (*domain.Subscription, error) {
+	var updated *domain.Subscription
+	err := r.runTx(ctx, func(q querier) error {
+		var exists bool
+		if err := q.QueryRow(
+			ctx,
+			`SELECT EXISTS (SELECT 1 FROM subscriptions WHERE id = $1 AND organization_id = $2)`,
+			id, domain.OrganizationFromContext(ctx),
+		).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return pgx.ErrNoRows
+		}
+
+		var err error
+		updated, err = setTags(ctx, q, id, tags)
+		return err
+	})
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("subscription not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+
+	return updated, nil
+}
+
+// setTags заменяет связи подписки с тегами в транзакции q и возвращает подписку с новыми тегами
+func setTags(ctx context.Context, q querier, id string, tags []string) (*domain.Subscription, error) {
+	organizationID := domain.OrganizationFromContext(ctx)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	if _, err := q.Exec(ctx, `DELETE FROM subscription_tags WHERE subscription_id = $1`, id); err != nil {
+		return nil, err
+	}
+	if _, err := q.Exec(
+		ctx,
+		`INSERT INTO tags (organization_id, name)
+         SELECT $1, unnest($2::text[])
+         ON CONFLICT (organization_id, name) DO NOTHING`,
+		organizationID, tags,
+	); err != nil {
+		return nil, err
+	}
+	if _, err := q.Exec(
+		ctx,
+		`INSERT INTO subscription_tags (subscription_id, tag_id)
+         SELECT $1, id FROM tags WHERE organization_id = $2 AND name = ANY($3)`,
+		id, organizationID, tags,
+	); err != nil {
+		return nil, err
+	}
+
+	return scanSubscription(q.QueryRow(
+		ctx,
+		`SELECT `+subscriptionColumns+` FROM subscriptions WHERE id = $1`,
+		id,
+	))
+}
+
+// DeleteMany удаляет подписки, подходящие под фильтры, и возвращает их количество
+func (r *SubscriptionRepository) DeleteMany(ctx context.Context, filters domain.DeleteFilters) (int64, error) {
+	query := `DELETE FROM subscriptions WHERE organization_id = $1`
+
+	args := []interface{}{domain.OrganizationFromContext(ctx)}
+
+	if filters.UserID != "" {
+		query += " AND user_id = $" + strconv.Itoa(len(args)+1)
+		args = append(args, filters.UserID)
+	}
+	if filters.ServiceName != "" {
+		query += " AND service_name = $" + strconv.Itoa(len(args)+1)
+		args = append(args, filters.ServiceName)
+	}
+
+	var deleted int64
+	err := r.run(ctx, func(q querier) error {
+		cmdTag, err := q.Exec(ctx, query, args...)
+		deleted = cmdTag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ExpireEnded помечает истекшими подписки всех организаций, закончившиеся раньше месяца before
+// Не ограничивается организацией из контекста и всегда выполняется в основной базе
+func (r *SubscriptionRepository) ExpireEnded(ctx context.Context, before time.Time) (int64, error) {
+	var expired int64
+	err := runUnscoped(ctx, r.db, r.rls, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`UPDATE subscriptions
+             SET status = 'expired', updated_at = now()
+             WHERE status = 'active' AND end_date < $1`,
+			before,
+		)
+		expired = cmdTag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire subscriptions: %w", err)
+	}
+
+	return expired, nil
+}
+
+// LinkService связывает подписки организации с сервисом каталога
+// Написания названий сравниваются в Go, чтобы нормализация совпадала с domain.NormalizeServiceName
+func (r *SubscriptionRepository) LinkService(ctx context.Context, svc *domain.Service) (int64, error) {
+	var linked int64
+	err := r.run(ctx, func(q querier) error {
+		rows, err := q.Query(
+			ctx,
+			`SELECT id, service_name, COALESCE(service_id::text, '')
+             FROM subscriptions
+             WHERE organization_id = $1 AND (service_id IS NULL OR service_id = $2)`,
+			domain.OrganizationFromContext(ctx), svc.ID,
+		)
+		if err != nil {
+			return err
+		}
+		var (
+			ids                        []string
+			id, serviceName, serviceID string
+		)
+		_, err = pgx.ForEachRow(rows, []any{&id, &serviceName, &serviceID}, func() error {
+			if svc.Covers(serviceID, serviceName) && (serviceID != svc.ID || serviceName != svc.Name) {
+				ids = append(ids, id)
+			}
+			return nil
+		})
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		cmdTag, err := q.Exec(
+			ctx,
+			`UPDATE subscriptions
+             SET service_id = $2, service_name = $3, updated_at = now()
+             WHERE organization_id = $1 AND id = ANY($4)`,
+			domain.OrganizationFromContext(ctx), svc.ID, svc.Name, ids,
+		)
+		linked = cmdTag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to link subscriptions to service: %w", err)
+	}
+
+	return linked, nil
+}
+
+// ApplyChanges применяет запланированные изменения подписок всех организаций, вступившие в силу к месяцу month
+// Подписки блокируются до конца транзакции; не ограничивается организацией из контекста
+func (r *SubscriptionRepository) ApplyChanges(ctx context.Context, month time.Time) (int64, error) {
+	var applied int64
+	err := runUnscopedTx(ctx, r.db, r.rls, func(tx querier) error {
+		rows, err := tx.Query(
+			ctx,
+			`SELECT `+subscriptionColumns+`
+             FROM subscriptions
+             WHERE EXISTS (SELECT 1 FROM jsonb_to_recordset(scheduled_changes) AS c(effective_month DATE)
+                           WHERE c.effective_month <= $1)
+             FOR UPDATE`,
+			month,
+		)
+		if err != nil {
+			return err
+		}
+		subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Subscription, error) {
+			return scanSubscription(row)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, sub := range subs {
+			if !sub.ApplyChanges(month) {
+				continue
+			}
+			history, err := sqljson.EncodePriceHistory(sub.PriceHistory)
+			if err != nil {
+				return err
+			}
+			changes, err := sqljson.EncodeChanges(sub.Changes)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(
+				ctx,
+				`UPDATE subscriptions
+                 SET price = $1, plan = $2, end_date = $3, status = $4, price_history = $5, scheduled_changes = $6, updated_at = now()
+                 WHERE id = $7`,
+				sub.Price, sub.Plan, sub.EndDate, sub.Status, history, changes, sub.ID,
+			); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+	}
+
+	return applied, nil
+}
+
 // List возвращает список подписок с фильтрацией
 func (r *SubscriptionRepository) List(ctx context.Context, filters domain.ListFilters) ([]*domain.Subscription, error) {
-	query := `SELECT id, service_name, price, user_id, start_date, end_date, created_at, updated_at
+	query := `SELECT ` + subscriptionColumns + `
 			  FROM subscriptions
-			  WHERE 1=1`
+			  WHERE organization_id = $1`
 
-	var args []interface{}
-	argIndex := 1
+	args := []interface{}{domain.OrganizationFromContext(ctx)}
+	argIndex := 2
 
 	if filters.UserID != "" {
 		query += fmt.Sprintf(" AND user_id = $%d", argIndex)
@@ -153,27 +589,52 @@ func (r *SubscriptionRepository) List(ctx context.Context, filters domain.ListFi
 		args = append(args, filters.ServiceName)
 		argIndex++
 	}
+	if filters.Tag != "" {
+		query += fmt.Sprintf(" AND %s%d)", tagFilter, argIndex)
+		args = append(args, filters.Tag)
+		argIndex++
+	}
+	// Окончание пробного периода: первое число месяца после последнего бесплатного
+	if !filters.TrialEndsFrom.IsZero() || !filters.TrialEndsTo.IsZero() {
+		query += " AND trial_months > 0"
+	}
+	if !filters.TrialEndsFrom.IsZero() {
+		query += fmt.Sprintf(" AND (date_trunc('month', start_date) + make_interval(months => trial_months))::date >= $%d", argIndex)
+		args = append(args, filters.TrialEndsFrom)
+		argIndex++
+	}
+	if !filters.TrialEndsTo.IsZero() {
+		query += fmt.Sprintf(" AND (date_trunc('month', start_date) + make_interval(months => trial_months))::date <= $%d", argIndex)
+		args = append(args, filters.TrialEndsTo)
+		argIndex++
+	}
 
 	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
 	args = append(args, filters.Limit, filters.Offset)
 
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
-	}
-	defer rows.Close()
-
 	var subs []*domain.Subscription
-	for rows.Next() {
-		var s domain.Subscription
-		if err := rows.Scan(&s.ID, &s.ServiceName, &s.Price, &s.UserID, &s.StartDate, &s.EndDate, &s.CreatedAt, &s.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+	err := r.read(ctx, func(q querier) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query subscriptions: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			s, err := scanSubscription(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan subscription: %w", err)
+			}
+			subs = append(subs, s)
 		}
-		subs = append(subs, &s)
-	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return subs, nil
@@ -181,19 +642,116 @@ func (r *SubscriptionRepository) List(ctx context.Context, filters domain.ListFi
 
 // GetSummary вычисляет общую стоимость подписок за период
 func (r *SubscriptionRepository) GetSummary(ctx context.Context, filters domain.SummaryFilters) (int64, error) {
-	query := `SELECT COALESCE(SUM(
-		  CASE WHEN start_date <= $2 AND COALESCE(end_date, $2) >= $1
-			THEN price * (
-			  (EXTRACT(YEAR FROM LEAST(COALESCE(end_date, $2), $2)) - EXTRACT(YEAR FROM GREATEST(start_date, $1))) * 12 +
-			  EXTRACT(MONTH FROM LEAST(COALESCE(end_date, $2), $2)) - EXTRACT(MONTH FROM GREATEST(start_date, $1)) + 1
-			)
-			ELSE 0
-		  END
-		), 0) AS total
+	query, args := r.summaryQuery(ctx, filters)
+
+	var total int64
+	err := r.read(ctx, func(q querier) error {
+		return q.QueryRow(ctx, query, args...).Scan(&total)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	return total, nil
+}
+
+// summaryQuery выбирает запрос суммы: по агрегату monthly_spend, если он включен, иначе по подпискам
+// Ключ агрегата (месяц, пользователь, сервис) не содержит тегов, поэтому с фильтром по тегу сумма считается по подпискам
+func (r *SubscriptionRepository) summaryQuery(ctx context.Context, filters domain.SummaryFilters) (string, []interface{}) {
+	if r.rollup && filters.Tag == "" {
+		return rollupSummaryQuery(ctx, filters)
+	}
+	return liveSummaryQuery(ctx, filters)
+}
+
+// liveSummaryQuery считает стоимость каждой подписки за месяцы пересечения с периодом функцией subscription_total:
+// с учетом пробного периода, вводной цены, прежних и запланированных цен, отмены и месяцев на паузе
+func liveSummaryQuery(ctx context.Context, filters domain.SummaryFilters) (string, []interface{}) {
+	query := `SELECT COALESCE(SUM(subscription_total(subscriptions, $1, $2)), 0)::bigint AS total
 		FROM subscriptions
-		WHERE 1=1`
+		WHERE organization_id = $3`
 
-	args := []interface{}{filters.PeriodStart, filters.PeriodEnd}
+	args := []interface{}{filters.PeriodStart, filters.PeriodEnd, domain.OrganizationFromContext(ctx)}
+	return appendSummaryFilters(query, args, filters)
+}
+
+// appendSummaryFilters добавляет к запросу по subscriptions фильтры пользователя, сервиса и тега
+func appendSummaryFilters(query string, args []interface{}, filters domain.SummaryFilters) (string, []interface{}) {
+	if filters.UserID != "" {
+		query += " AND subscriptions.user_id = $" + strconv.Itoa(len(args)+1)
+		args = append(args, filters.UserID)
+	}
+	if filters.ServiceName != "" {
+		query += " AND subscriptions.service_name = $" + strconv.Itoa(len(args)+1)
+		args = append(args, filters.ServiceName)
+	}
+	if filters.Tag != "" {
+		query += " AND " + tagFilter + strconv.Itoa(len(args)+1) + ")"
+		args = append(args, filters.Tag)
+	}
+
+	return query, args
+}
+
+// GetGroupedSummary вычисляет суммы подписок за период по тегам или категориям каталога
+// Всегда считается по подпискам: агрегат monthly_spend не хранит ни тегов, ни категорий
+func (r *SubscriptionRepository) GetGroupedSummary(ctx context.Context, filters domain.SummaryFilters, groupBy string) ([]domain.SummaryGroup, error) {
+	var key, join string
+	switch groupBy {
+	case domain.SummaryByTag:
+		key = "COALESCE(t.name, '')"
+		join = `LEFT JOIN subscription_tags st ON st.subscription_id = subscriptions.id
+		LEFT JOIN tags t ON t.id = st.tag_id`
+	case domain.SummaryByCategory:
+		key = "COALESCE(s.category, '')"
+		join = `LEFT JOIN services s ON s.id = subscriptions.service_id`
+	default:
+		return nil, fmt.Errorf("failed to calculate summary: unknown grouping %q", groupBy)
+	}
+
+	query := `SELECT ` + key + ` AS key, SUM(subscription_total(subscriptions, $1, $2))::bigint AS total
+		FROM subscriptions
+		` + join + `
+		WHERE subscriptions.organization_id = $3`
+	args := []interface{}{filters.PeriodStart, filters.PeriodEnd, domain.OrganizationFromContext(ctx)}
+	query, args = appendSummaryFilters(query, args, filters)
+	query += ` GROUP BY 1 HAVING SUM(subscription_total(subscriptions, $1, $2)) <> 0 ORDER BY total DESC, key`
+
+	var groups []domain.SummaryGroup
+	err := r.read(ctx, func(q querier) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		groups, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.SummaryGroup, error) {
+			var g domain.SummaryGroup
+			err := row.Scan(&g.Key, &g.Total)
+			return g, err
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	return groups, nil
+}
+
+// rollupSummaryQuery суммирует изменения расхода из monthly_spend
+// Изменение delta с месяца m входит в каждый месяц периода начиная с max(m, PeriodStart), т.е. (end - max(m, start) + 1) раз
+func rollupSummaryQuery(ctx context.Context, filters domain.SummaryFilters) (string, []interface{}) {
+	query := `SELECT COALESCE(SUM(
+		  delta * ($2 - GREATEST(EXTRACT(YEAR FROM month)::int * 12 + EXTRACT(MONTH FROM month)::int, $1) + 1)
+		), 0)::bigint AS total
+		FROM monthly_spend
+		WHERE organization_id = $3 AND month <= $4`
+
+	args := []interface{}{
+		monthIndex(filters.PeriodStart),
+		monthIndex(filters.PeriodEnd),
+		domain.OrganizationFromContext(ctx),
+		filters.PeriodEnd,
+	}
 
 	if filters.UserID != "" {
 		query += " AND user_id = $" + strconv.Itoa(len(args)+1)
@@ -204,11 +762,62 @@ func (r *SubscriptionRepository) GetSummary(ctx context.Context, filters domain.
 		args = append(args, filters.ServiceName)
 	}
 
-	var total int64
-	err := r.db.QueryRow(ctx, query, args...).Scan(&total)
+	return query, args
+}
+
+// monthIndex порядковый номер месяца: year*12 + month
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month())
+}
+
+// monthStart первое число месяца t
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// RebuildMonthlySpend пересчитывает агрегат monthly_spend по всем подпискам всех организаций
+// На время пересчета запись подписок блокируется; используется для восстановления агрегата фоновой задачей
+func (r *SubscriptionRepository) RebuildMonthlySpend(ctx context.Context) error {
+	err := runUnscoped(ctx, r.db, r.rls, func(q querier) error {
+		_, err := q.Exec(ctx, `SELECT rebuild_monthly_spend()`)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to calculate summary: %w", err)
+		return fmt.Errorf("failed to rebuild monthly spend: %w", err)
+	}
+	return nil
+}
+
+// Stats возвращает число активных подписок и их суммарную стоимость за месяц по каждой организации
+// Подписки на паузе в этом месяце не учитываются.
+// Используется для метрик и не ограничивается организацией из контекста; читает с реплики, если она задана
+func (r *SubscriptionRepository) Stats(ctx context.Context, month time.Time) ([]domain.SubscriptionStats, error) {
+	var stats []domain.SubscriptionStats
+	err := runUnscoped(ctx, r.reader(ctx), r.rls, func(q querier) error {
+		rows, err := q.Query(
+			ctx,
+			`SELECT organization_id, COUNT(*),
+                    COALESCE(SUM(subscription_total(subscriptions, $1, $1)), 0)
+             FROM subscriptions
+             WHERE start_date <= $1
+               AND (subscription_end(end_date, scheduled_changes) IS NULL OR subscription_end(end_date, scheduled_changes) >= $1)
+               AND NOT subscription_paused_at(pauses, $1)
+             GROUP BY organization_id`,
+			month,
+		)
+		if err != nil {
+			return err
+		}
+		stats, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.SubscriptionStats, error) {
+			var s domain.SubscriptionStats
+			err := row.Scan(&s.OrganizationID, &s.ActiveCount, &s.MonthlySpend)
+			return s, err
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscription stats: %w", err)
 	}
 
-	return total, nil
+	return stats, nil
 }
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/migrations"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+// testPool подключается к тестовой базе из TEST_DATABASE_URL и применяет миграции
+// Соединения пропускают политики row-level security, как в приложении без TENANT_RLS.
+// Без TEST_DATABASE_URL тест пропускается; все данные в базе удаляются
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	pool := connectTestPool(t, BypassRowLevelSecurity)
+	migrator, err := NewMigrator(pool, migrations.FS, time.Minute)
+	require.NoError(t, err)
+	defer migrator.Close()
+	require.NoError(t, migrator.Up())
+
+	return pool
+}
+
+// testRLSPool подключается к тестовой базе без обхода политик row-level security
+// Вызывается после testPool, который применяет миграции
+func testRLSPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	return connectTestPool(t, func(*pgxpool.Config) {})
+}
+
+func connectTestPool(t *testing.T, configure func(*pgxpool.Config)) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	cfg, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	configure(cfg)
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// resetSubscriptions очищает подписки и создает вторую организацию для проверки изоляции
+func resetSubscriptions(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	ctx := context.Background()
+	_, err := pool.Exec(ctx, `TRUNCATE subscriptions, monthly_spend, sent_reminders, reminder_preferences, budgets, budget_alerts, services, service_names, tags, subscription_tags`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx,
+		`INSERT INTO organizations (id, name) VALUES ($1, 'repotest') ON CONFLICT DO NOTHING`,
+		repotest.OtherOrganizationID,
+	)
+	require.NoError(t, err)
+}
+
+func TestSubscriptionRepository_Conformance(t *testing.T) {
+	pool := testPool(t)
+	rlsPool := testRLSPool(t)
+
+	for name, tc := range map[string]struct {
+		pool *pgxpool.Pool
+		opts []Option
+	}{
+		"default":            {pool, nil},
+		"row level security": {rlsPool, []Option{WithRowLevelSecurity()}},
+		"monthly rollup":     {pool, []Option{WithMonthlyRollup()}},
+		"rollup with rls":    {rlsPool, []Option{WithMonthlyRollup(), WithRowLevelSecurity()}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repotest.RunSubscriptionRepositoryTests(t, func(t *testing.T) domain.SubscriptionRepository {
+				resetSubscriptions(t, pool)
+				return NewSubscriptionRepository(tc.pool, tc.opts...)
+			})
+		})
+	}
+}
+
+// TestRowLevelSecurity_FailsClosed проверяет, что без app.organization_id политики не пропускают строки,
+// а фоновые запросы по всем организациям видят их только с app.bypass_rls
+func TestRowLevelSecurity_FailsClosed(t *testing.T) {
+	pool := testPool(t)
+	rlsPool := testRLSPool(t)
+	resetSubscriptions(t, pool)
+
+	ctx := context.Background()
+	var privileged bool
+	require.NoError(t, rlsPool.QueryRow(ctx,
+		`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`,
+	).Scan(&privileged))
+	if privileged {
+		t.Skip("row-level security does not apply to superusers and BYPASSRLS roles")
+	}
+
+	repo := NewSubscriptionRepository(rlsPool, WithRowLevelSecurity())
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := repo.Create(ctx, &domain.Subscription{
+		ServiceName: "Netflix",
+		Price:       1000,
+		UserID:      "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		StartDate:   start,
+	})
+	require.NoError(t, err)
+
+	var visible int
+	require.NoError(t, rlsPool.QueryRow(ctx, `SELECT COUNT(*) FROM subscriptions`).Scan(&visible))
+	require.Zero(t, visible, "rows are visible without app.organization_id")
+
+	_, err = rlsPool.Exec(ctx,
+		`INSERT INTO subscriptions (organization_id, service_name, price, user_id, start_date) VALUES ($1, 'Spotify', 500, $2, $3)`,
+		repotest.OtherOrganizationID, "60601fee-2bf1-4721-ae6f-7636e79a0cba", start,
+	)
+	require.ErrorContains(t, err, "row-level security")
+
+	stats, err := repo.Stats(ctx, start)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, int64(1), stats[0].ActiveCount)
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Проверка, что TagRepository реализует интерфейс domain.TagRepository
+var _ domain.TagRepository = (*TagRepository)(nil)
+
+// TagRepository хранит теги организаций в tags; связи с подписками лежат в subscription_tags
+type TagRepository struct {
+	db  *pgxpool.Pool
+	rls bool
+}
+
+// NewTagRepository создает новый экземпляр репозитория тегов
+// rls включает выполнение запросов в транзакции с app.organization_id, как WithRowLevelSecurity у подписок
+func NewTagRepository(db *pgxpool.Pool, rls bool) *TagRepository {
+	return &TagRepository{db: db, rls: rls}
+}
+
+const tagColumns = `id, organization_id, name,
+	(SELECT COUNT(*) FROM subscription_tags st WHERE st.tag_id = tags.id), created_at`
+
+func scanTag(row pgx.Row) (*domain.Tag, error) {
+	var t domain.Tag
+	if err := row.Scan(&t.ID, &t.OrganizationID, &t.Name, &t.Subscriptions, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create добавляет тег организации
+func (r *TagRepository) Create(ctx context.Context, name string) (*domain.Tag, error) {
+	tag, err := r.queryRow(
+		ctx,
+		`INSERT INTO tags (organization_id, name) VALUES ($1, $2) RETURNING `+tagColumns,
+		domain.OrganizationFromContext(ctx), name,
+	)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("failed to create tag: tag %q is already used", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// GetByID получает тег по ID
+func (r *TagRepository) GetByID(ctx context.Context, id string) (*domain.Tag, error) {
+	tag, err := r.queryRow(
+		ctx,
+		`SELECT `+tagColumns+` FROM tags WHERE id = $1 AND organization_id = $2`,
+		id, domain.OrganizationFromContext(ctx),
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("tag not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// Rename меняет название тега; подписки ссылаются на тег по ID и сразу получают новое название
+func (r *TagRepository) Rename(ctx context.Context, id, name string) (*domain.Tag, error) {
+	tag, err := r.queryRow(
+		ctx,
+		`UPDATE tags SET name = $3 WHERE id = $1 AND organization_id = $2 RETURNING `+tagColumns,
+		id, domain.OrganizationFromContext(ctx), name,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("tag not found: %w", err)
+	}
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("failed to rename tag: tag %q is already used", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// Delete удаляет тег; связи с подписками удаляются каскадно
+func (r *TagRepository) Delete(ctx context.Context, id string) error {
+	var deleted int64
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		cmdTag, err := q.Exec(
+			ctx,
+			`DELETE FROM tags WHERE id = $1 AND organization_id = $2`,
+			id, domain.OrganizationFromContext(ctx),
+		)
+		deleted = cmdTag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// List возвращает теги организации по названию с числом подписок
+func (r *TagRepository) List(ctx context.Context) ([]*domain.Tag, error) {
+	var tags []*domain.Tag
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		rows, err := q.Query(
+			ctx,
+			`SELECT `+tagColumns+` FROM tags WHERE organization_id = $1 ORDER BY name`,
+			domain.OrganizationFromContext(ctx),
+		)
+		if err != nil {
+			return err
+		}
+		tags, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Tag, error) {
+			return scanTag(row)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// queryRow выполняет запрос одного тега в рамках организации из контекста
+func (r *TagRepository) queryRow(ctx context.Context, sql string, args ...any) (*domain.Tag, error) {
+	var tag *domain.Tag
+	err := runScoped(ctx, r.db, r.rls, func(q querier) error {
+		var err error
+		tag, err = scanTag(q.QueryRow(ctx, sql, args...))
+		return err
+	})
+	return tag, err
+}
+
+// isUniqueViolation сообщает, что запись нарушила ограничение уникальности
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,217 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// BudgetFactory возвращает пустой репозиторий бюджетов
+type BudgetFactory func(t *testing.T) domain.BudgetRepository
+
+// RunBudgetRepositoryTests проверяет реализацию репозитория бюджетов
+func RunBudgetRepositoryTests(t *testing.T, newRepo BudgetFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.BudgetRepository)
+	}{
+		{"CreateAndGet", testBudgetCreateAndGet},
+		{"Update", testBudgetUpdate},
+		{"Delete", testBudgetDelete},
+		{"List", testBudgetList},
+		{"Constraints", testBudgetConstraints},
+		{"ClaimAlert", testBudgetClaimAlert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func createBudget(t *testing.T, ctx context.Context, repo domain.BudgetRepository, budget domain.Budget) *domain.Budget {
+	t.Helper()
+
+	if budget.Name == "" {
+		budget.Name = "Budget"
+	}
+	if budget.Period == "" {
+		budget.Period = domain.BudgetMonthly
+	}
+	if budget.Limit == 0 {
+		budget.Limit = 1000
+	}
+	if budget.Thresholds == nil {
+		budget.Thresholds = []int{80, 100}
+	}
+
+	created, err := repo.Create(ctx, &budget)
+	require.NoError(t, err)
+	return created
+}
+
+func testBudgetCreateAndGet(t *testing.T, repo domain.BudgetRepository) {
+	ctx := context.Background()
+
+	created := createBudget(t, ctx, repo, domain.Budget{
+		Name:       "Streaming",
+		Period:     domain.BudgetYearly,
+		Limit:      12000,
+		UserID:     "60601FEE-2BF1-4721-AE6F-7636E79A0CBA",
+		Thresholds: []int{50, 90, 100},
+		Email:      "a@example.com",
+	})
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, domain.DefaultOrganizationID, created.OrganizationID)
+	assert.Equal(t, userA, created.UserID, "user_id is canonical")
+	assert.Empty(t, created.ServiceName)
+	assert.Equal(t, []int{50, 90, 100}, created.Thresholds)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	got, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, got)
+
+	global := createBudget(t, ctx, repo, domain.Budget{ServiceName: "Netflix"})
+	assert.Empty(t, global.UserID)
+	assert.Equal(t, domain.BudgetScopeService, global.Scope())
+
+	// Бюджет другой организации не виден
+	_, err = repo.GetByID(domain.WithOrganization(ctx, OtherOrganizationID), created.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = repo.GetByID(ctx, "00000000-0000-0000-0000-000000000001")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func testBudgetUpdate(t *testing.T, repo domain.BudgetRepository) {
+	ctx := context.Background()
+
+	created := createBudget(t, ctx, repo, domain.Budget{UserID: userA, ServiceName: "Netflix"})
+
+	updated, err := repo.Update(ctx, created.ID, &domain.Budget{
+		Name:       "Renamed",
+		Period:     domain.BudgetYearly,
+		Limit:      5000,
+		UserID:     userB,
+		Thresholds: []int{100},
+		Email:      "b@example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.Equal(t, domain.BudgetYearly, updated.Period)
+	assert.Equal(t, int64(5000), updated.Limit)
+	assert.Equal(t, userA, updated.UserID, "user is not changed")
+	assert.Empty(t, updated.ServiceName)
+	assert.Equal(t, []int{100}, updated.Thresholds)
+	assert.Equal(t, "b@example.com", updated.Email)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+	_, err = repo.Update(domain.WithOrganization(ctx, OtherOrganizationID), created.ID, updated)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func testBudgetDelete(t *testing.T, repo domain.BudgetRepository) {
+	ctx := context.Background()
+
+	created := createBudget(t, ctx, repo, domain.Budget{})
+
+	err := repo.Delete(domain.WithOrganization(ctx, OtherOrganizationID), created.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	require.NoError(t, repo.Delete(ctx, created.ID))
+
+	err = repo.Delete(ctx, created.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func testBudgetList(t *testing.T, repo domain.BudgetRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	first := createBudget(t, ctx, repo, domain.Budget{Name: "Global"})
+	second := createBudget(t, ctx, repo, domain.Budget{Name: "User A", UserID: userA})
+	third := createBudget(t, ctx, repo, domain.Budget{Name: "User B", UserID: userB})
+	foreign := createBudget(t, other, repo, domain.Budget{Name: "Other"})
+
+	names := func(budgets []*domain.Budget) []string {
+		var out []string
+		for _, b := range budgets {
+			out = append(out, b.Name)
+		}
+		return out
+	}
+
+	all, err := repo.List(ctx, domain.BudgetFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.Name, second.Name, third.Name}, names(all))
+
+	byUser, err := repo.List(ctx, domain.BudgetFilters{UserID: userA})
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.Name}, names(byUser))
+
+	everywhere, err := repo.ListAll(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{first.Name, second.Name, third.Name, foreign.Name}, names(everywhere))
+}
+
+func testBudgetConstraints(t *testing.T, repo domain.BudgetRepository) {
+	ctx := context.Background()
+
+	_, err := repo.Create(ctx, &domain.Budget{Name: "Zero", Period: domain.BudgetMonthly, Limit: 0, Thresholds: []int{100}})
+	assert.Error(t, err)
+
+	_, err = repo.Create(ctx, &domain.Budget{Name: "Weekly", Period: "weekly", Limit: 100, Thresholds: []int{100}})
+	assert.Error(t, err)
+
+	_, err = repo.Create(ctx, &domain.Budget{Name: "Bad user", Period: domain.BudgetMonthly, Limit: 100, UserID: "42", Thresholds: []int{100}})
+	assert.Error(t, err)
+}
+
+func testBudgetClaimAlert(t *testing.T, repo domain.BudgetRepository) {
+	ctx := context.Background()
+
+	budget := createBudget(t, ctx, repo, domain.Budget{})
+	alert := &domain.BudgetAlert{
+		OrganizationID: budget.OrganizationID,
+		BudgetID:       budget.ID,
+		PeriodStart:    month(2025, time.January),
+		Threshold:      80,
+	}
+
+	claimed, err := repo.ClaimAlert(ctx, alert)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.ClaimAlert(ctx, alert)
+	require.NoError(t, err)
+	assert.False(t, claimed, "alert must be claimed once")
+
+	// Отметка относится к порогу и периоду
+	next := *alert
+	next.Threshold = 100
+	claimed, err = repo.ClaimAlert(ctx, &next)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	next = *alert
+	next.PeriodStart = month(2025, time.February)
+	claimed, err = repo.ClaimAlert(ctx, &next)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	require.NoError(t, repo.ReleaseAlert(ctx, alert))
+	claimed, err = repo.ClaimAlert(ctx, alert)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,91 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// OrganizationFactory возвращает репозиторий организаций; хранилище может содержать организации прошлых запусков
+type OrganizationFactory func(t *testing.T) domain.OrganizationRepository
+
+// RunOrganizationRepositoryTests проверяет реализацию репозитория организаций
+func RunOrganizationRepositoryTests(t *testing.T, newRepo OrganizationFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.OrganizationRepository)
+	}{
+		{"CreateAndList", testOrganizationCreateAndList},
+		{"Duplicate", testOrganizationDuplicate},
+		{"GivenID", testOrganizationGivenID},
+		{"Exists", testOrganizationExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func testOrganizationCreateAndList(t *testing.T, repo domain.OrganizationRepository) {
+	ctx := context.Background()
+	name := "acme " + uuid.NewString()
+
+	created, err := repo.Create(ctx, &domain.Organization{Name: name})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, name, created.Name)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	orgs, err := repo.List(ctx)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(orgs))
+	for _, org := range orgs {
+		ids = append(ids, org.ID)
+	}
+	assert.Contains(t, ids, created.ID)
+	assert.Contains(t, ids, domain.DefaultOrganizationID)
+}
+
+func testOrganizationDuplicate(t *testing.T, repo domain.OrganizationRepository) {
+	ctx := context.Background()
+	name := "acme " + uuid.NewString()
+
+	_, err := repo.Create(ctx, &domain.Organization{Name: name})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, &domain.Organization{Name: name})
+	assert.ErrorContains(t, err, "already used")
+	_, err = repo.Create(ctx, &domain.Organization{ID: domain.DefaultOrganizationID, Name: "acme " + uuid.NewString()})
+	assert.ErrorContains(t, err, "already used")
+}
+
+func testOrganizationGivenID(t *testing.T, repo domain.OrganizationRepository) {
+	ctx := context.Background()
+	id := uuid.NewString()
+
+	created, err := repo.Create(ctx, &domain.Organization{ID: id, Name: "acme " + id})
+	require.NoError(t, err)
+	assert.Equal(t, id, created.ID)
+}
+
+func testOrganizationExists(t *testing.T, repo domain.OrganizationRepository) {
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, &domain.Organization{Name: "acme " + uuid.NewString()})
+	require.NoError(t, err)
+
+	for id, expected := range map[string]bool{
+		domain.DefaultOrganizationID: true,
+		created.ID:                   true,
+		uuid.NewString():             false,
+	} {
+		exists, err := repo.Exists(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, exists, id)
+	}
+}
@@ -0,0 +1,198 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ReminderFactory возвращает пустые репозитории подписок и напоминаний над одним хранилищем
+type ReminderFactory func(t *testing.T) (domain.SubscriptionRepository, domain.ReminderRepository)
+
+// RunReminderRepositoryTests проверяет реализацию репозитория напоминаний
+func RunReminderRepositoryTests(t *testing.T, newRepos ReminderFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, subs domain.SubscriptionRepository, reminders domain.ReminderRepository)
+	}{
+		{"Due", testDue},
+		{"Preferences", testPreferences},
+		{"Claim", testClaim},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, reminders := newRepos(t)
+			tt.fn(t, subs, reminders)
+		})
+	}
+}
+
+// describe кратко описывает напоминания для сравнения: сервис, вид и дата
+func describe(reminders []*domain.Reminder) []string {
+	out := make([]string, 0, len(reminders))
+	for _, r := range reminders {
+		out = append(out, fmt.Sprintf("%s %s %s", r.ServiceName, r.Kind, r.Date.Format("2006-01-02")))
+	}
+	return out
+}
+
+func due(t *testing.T, reminders domain.ReminderRepository, from, to time.Time) []*domain.Reminder {
+	t.Helper()
+
+	got, err := reminders.Due(context.Background(), from, to)
+	require.NoError(t, err)
+	return got
+}
+
+func testDue(t *testing.T, subs domain.SubscriptionRepository, reminders domain.ReminderRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	open := create(t, ctx, subs, domain.Subscription{ServiceName: "Open", Price: 100})
+	create(t, ctx, subs, domain.Subscription{ServiceName: "Ending", StartDate: month(2024, time.December), EndDate: until(month(2025, time.January))})
+	create(t, ctx, subs, domain.Subscription{ServiceName: "Ended", StartDate: month(2024, time.November), EndDate: until(month(2024, time.December))})
+	create(t, ctx, subs, domain.Subscription{ServiceName: "Future", StartDate: month(2025, time.February)})
+	create(t, other, subs, domain.Subscription{ServiceName: "Other", UserID: userB})
+
+	// О продлении на февраль, приходящийся на паузу, не напоминают
+	paused := create(t, ctx, subs, domain.Subscription{ServiceName: "Paused", StartDate: month(2024, time.December)})
+	_, err := setPauses(ctx, subs, paused.ID, []domain.Pause{{Start: month(2025, time.February), End: until(month(2025, time.February))}})
+	require.NoError(t, err)
+
+	jan20 := time.Date(2025, time.January, 20, 9, 0, 0, 0, time.UTC)
+
+	got := due(t, reminders, jan20, jan20.AddDate(0, 0, 14))
+	assert.ElementsMatch(t, []string{
+		"Ending expiry 2025-02-01",
+		"Open renewal 2025-02-01",
+		"Other renewal 2025-02-01",
+	}, describe(got))
+
+	for _, r := range got {
+		if r.SubscriptionID == open.ID {
+			assert.Equal(t, domain.DefaultOrganizationID, r.OrganizationID)
+			assert.Equal(t, userA, r.UserID)
+			assert.Equal(t, int64(100), r.Price)
+			assert.Empty(t, r.Email)
+		}
+		if r.ServiceName == "Other" {
+			assert.Equal(t, OtherOrganizationID, r.OrganizationID)
+		}
+	}
+
+	// Интервал без начала месяца
+	assert.Empty(t, due(t, reminders, jan20.AddDate(0, 0, -15), jan20))
+	// Начало интервала не входит в него
+	assert.Empty(t, due(t, reminders, month(2025, time.February), month(2025, time.February).AddDate(0, 0, 5)))
+
+	// Длинный интервал захватывает несколько месяцев
+	got = due(t, reminders, jan20, jan20.AddDate(0, 1, 14))
+	assert.ElementsMatch(t, []string{
+		"Ending expiry 2025-02-01",
+		"Open renewal 2025-02-01",
+		"Open renewal 2025-03-01",
+		"Other renewal 2025-02-01",
+		"Other renewal 2025-03-01",
+		"Future renewal 2025-03-01",
+		"Paused renewal 2025-03-01",
+	}, describe(got))
+}
+
+func testPreferences(t *testing.T, subs domain.SubscriptionRepository, reminders domain.ReminderRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	prefs, err := reminders.GetPreferences(ctx, userA)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultReminderPreferences(userA), prefs)
+
+	saved, err := reminders.SavePreferences(ctx, &domain.ReminderPreferences{UserID: userA, Email: "a@example.com", Renewal: false, Expiry: true})
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", saved.Email)
+	assert.False(t, saved.Renewal)
+	assert.True(t, saved.Expiry)
+	assert.False(t, saved.UpdatedAt.IsZero())
+
+	prefs, err = reminders.GetPreferences(ctx, userA)
+	require.NoError(t, err)
+	assert.Equal(t, saved.Email, prefs.Email)
+	assert.False(t, prefs.Renewal)
+
+	// Настройки принадлежат организации
+	prefs, err = reminders.GetPreferences(other, userA)
+	require.NoError(t, err)
+	assert.True(t, prefs.Renewal)
+
+	create(t, ctx, subs, domain.Subscription{ServiceName: "Open"})
+	create(t, ctx, subs, domain.Subscription{ServiceName: "Ending", EndDate: until(month(2025, time.January))})
+	create(t, ctx, subs, domain.Subscription{ServiceName: "Other user", UserID: userB})
+	create(t, other, subs, domain.Subscription{ServiceName: "Other organization"})
+
+	jan20 := time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC)
+	got := due(t, reminders, jan20, jan20.AddDate(0, 0, 14))
+	assert.ElementsMatch(t, []string{
+		"Ending expiry 2025-02-01",
+		"Other user renewal 2025-02-01",
+		"Other organization renewal 2025-02-01",
+	}, describe(got))
+	for _, r := range got {
+		if r.ServiceName == "Ending" {
+			assert.Equal(t, "a@example.com", r.Email)
+		}
+	}
+
+	// Повторное сохранение заменяет настройки целиком
+	_, err = reminders.SavePreferences(ctx, &domain.ReminderPreferences{UserID: userA, Renewal: true, Expiry: false})
+	require.NoError(t, err)
+	got = due(t, reminders, jan20, jan20.AddDate(0, 0, 14))
+	assert.ElementsMatch(t, []string{
+		"Open renewal 2025-02-01",
+		"Other user renewal 2025-02-01",
+		"Other organization renewal 2025-02-01",
+	}, describe(got))
+	for _, r := range got {
+		if r.ServiceName == "Open" {
+			assert.Empty(t, r.Email)
+		}
+	}
+}
+
+func testClaim(t *testing.T, subs domain.SubscriptionRepository, reminders domain.ReminderRepository) {
+	ctx := context.Background()
+
+	sub := create(t, ctx, subs, domain.Subscription{ServiceName: "Open"})
+	jan20 := time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC)
+
+	got := due(t, reminders, jan20, jan20.AddDate(0, 0, 14))
+	require.Len(t, got, 1)
+	reminder := got[0]
+
+	claimed, err := reminders.Claim(ctx, reminder)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = reminders.Claim(ctx, reminder)
+	require.NoError(t, err)
+	assert.False(t, claimed, "reminder must be claimed once")
+	assert.Empty(t, due(t, reminders, jan20, jan20.AddDate(0, 0, 14)))
+
+	// Отметка относится к конкретной дате: следующий месяц напоминается снова
+	assert.Equal(t, []string{"Open renewal 2025-03-01"}, describe(due(t, reminders, jan20, jan20.AddDate(0, 1, 14))))
+
+	require.NoError(t, reminders.Release(ctx, reminder))
+	assert.Len(t, due(t, reminders, jan20, jan20.AddDate(0, 0, 14)), 1)
+
+	claimed, err = reminders.Claim(ctx, reminder)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Удаление подписки удаляет и ее напоминания
+	require.NoError(t, subs.Delete(ctx, sub.ID))
+	assert.Empty(t, due(t, reminders, jan20, jan20.AddDate(0, 1, 14)))
+}
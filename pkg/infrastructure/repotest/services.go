@@ -0,0 +1,298 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ServiceFactory возвращает пустые репозитории подписок и каталога сервисов над одним хранилищем
+type ServiceFactory func(t *testing.T) (domain.SubscriptionRepository, domain.ServiceRepository)
+
+// RunServiceRepositoryTests проверяет реализацию репозитория каталога сервисов
+func RunServiceRepositoryTests(t *testing.T, newRepos ServiceFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, subs domain.SubscriptionRepository, services domain.ServiceRepository)
+	}{
+		{"CreateAndGet", testServiceCreateAndGet},
+		{"FindByName", testServiceFindByName},
+		{"Update", testServiceUpdate},
+		{"List", testServiceList},
+		{"Delete", testServiceDelete},
+		{"LinkSubscriptions", testServiceLinkSubscriptions},
+		{"SummaryByCategory", testServiceSummaryByCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, services := newRepos(t)
+			tt.fn(t, subs, services)
+		})
+	}
+}
+
+func createService(t *testing.T, ctx context.Context, repo domain.ServiceRepository, svc domain.Service) *domain.Service {
+	t.Helper()
+
+	created, err := repo.Create(ctx, &svc)
+	require.NoError(t, err)
+	return created
+}
+
+func yandexPlus() domain.Service {
+	return domain.Service{
+		Name:      "Yandex Plus",
+		Aliases:   []string{"Кинопоиск"},
+		Category:  "entertainment",
+		VendorURL: "https://plus.yandex.ru",
+		Plans:     []domain.ServicePlan{{Name: "Standard", Price: 399}, {Name: "Multi", Price: 549}},
+	}
+}
+
+func testServiceCreateAndGet(t *testing.T, _ domain.SubscriptionRepository, services domain.ServiceRepository) {
+	ctx := context.Background()
+
+	created := createService(t, ctx, services, yandexPlus())
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, domain.DefaultOrganizationID, created.OrganizationID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	got, err := services.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Yandex Plus", got.Name)
+	assert.Equal(t, []string{"Кинопоиск"}, got.Aliases)
+	assert.Equal(t, "entertainment", got.Category)
+	assert.Equal(t, "https://plus.yandex.ru", got.VendorURL)
+	assert.Equal(t, yandexPlus().Plans, got.Plans)
+
+	bare := createService(t, ctx, services, domain.Service{Name: "GitHub"})
+	assert.Empty(t, bare.Aliases)
+	assert.Empty(t, bare.Plans)
+
+	_, err = services.GetByID(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.ErrorContains(t, err, "not found")
+	_, err = services.GetByID(domain.WithOrganization(ctx, OtherOrganizationID), created.ID)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func testServiceFindByName(t *testing.T, _ domain.SubscriptionRepository, services domain.ServiceRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	created := createService(t, ctx, services, yandexPlus())
+
+	for _, name := range []string{"Yandex Plus", "yandex plus", "YandexPlus", "yandex-plus", "КИНОПОИСК"} {
+		found, err := services.FindByName(ctx, name)
+		require.NoError(t, err)
+		if assert.NotNil(t, found, name) {
+			assert.Equal(t, created.ID, found.ID)
+		}
+	}
+
+	for _, name := range []string{"Yandex", "", " - "} {
+		found, err := services.FindByName(ctx, name)
+		require.NoError(t, err)
+		assert.Nil(t, found, name)
+	}
+
+	// Каталог у каждой организации свой
+	found, err := services.FindByName(other, "Yandex Plus")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	createService(t, other, services, domain.Service{Name: "yandex plus"})
+
+	// Написание, занятое другим сервисом, отклоняется
+	_, err = services.Create(ctx, &domain.Service{Name: "Kinopoisk", Aliases: []string{"Кино поиск"}})
+	assert.ErrorContains(t, err, "already used by Yandex Plus")
+	_, err = services.Create(ctx, &domain.Service{Name: "!!!"})
+	assert.Error(t, err, "name without letters or digits")
+}
+
+func testServiceUpdate(t *testing.T, _ domain.SubscriptionRepository, services domain.ServiceRepository) {
+	ctx := context.Background()
+
+	created := createService(t, ctx, services, yandexPlus())
+	createService(t, ctx, services, domain.Service{Name: "Netflix"})
+
+	svc := yandexPlus()
+	svc.Aliases = []string{"Yandex Plus Multi", "Яндекс Плюс"}
+	svc.Plans = svc.Plans[:1]
+	svc.Category = "media"
+	updated, err := services.Update(ctx, created.ID, &svc)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, svc.Aliases, updated.Aliases)
+	assert.Equal(t, svc.Plans, updated.Plans)
+	assert.Equal(t, "media", updated.Category)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+	// Прежний псевдоним больше не сопоставляется, новый сопоставляется
+	found, err := services.FindByName(ctx, "Кинопоиск")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	found, err = services.FindByName(ctx, "яндекс плюс")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, created.ID, found.ID)
+
+	svc.Aliases = []string{"netflix"}
+	_, err = services.Update(ctx, created.ID, &svc)
+	assert.ErrorContains(t, err, "already used by Netflix")
+
+	_, err = services.Update(ctx, "00000000-0000-0000-0000-000000000000", &svc)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func testServiceList(t *testing.T, _ domain.SubscriptionRepository, services domain.ServiceRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	createService(t, ctx, services, domain.Service{Name: "Spotify", Category: "entertainment"})
+	createService(t, ctx, services, domain.Service{Name: "GitHub", Category: "dev tools"})
+	createService(t, ctx, services, domain.Service{Name: "Netflix", Category: "entertainment"})
+	createService(t, other, services, domain.Service{Name: "AWS", Category: "cloud"})
+
+	names := func(list []*domain.Service) []string {
+		var out []string
+		for _, s := range list {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+
+	all, err := services.List(ctx, domain.ServiceFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GitHub", "Netflix", "Spotify"}, names(all))
+
+	entertainment, err := services.List(ctx, domain.ServiceFilters{Category: "entertainment"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Netflix", "Spotify"}, names(entertainment))
+
+	cloud, err := services.List(ctx, domain.ServiceFilters{Category: "cloud"})
+	require.NoError(t, err)
+	assert.Empty(t, cloud)
+}
+
+func testServiceDelete(t *testing.T, subs domain.SubscriptionRepository, services domain.ServiceRepository) {
+	ctx := context.Background()
+
+	svc := createService(t, ctx, services, yandexPlus())
+	linked := create(t, ctx, subs, domain.Subscription{ServiceName: "Yandex Plus", ServiceID: svc.ID, Plan: "Standard", Price: 399,
+		StartDate: month(2025, time.January)})
+	assert.Equal(t, svc.ID, linked.ServiceID)
+
+	require.NoError(t, services.Delete(ctx, svc.ID))
+
+	_, err := services.GetByID(ctx, svc.ID)
+	assert.ErrorContains(t, err, "not found")
+	found, err := services.FindByName(ctx, "Кинопоиск")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	// Подписка сохраняет название, но теряет ссылку на каталог
+	got, err := subs.GetByID(ctx, linked.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.ServiceID)
+	assert.Equal(t, "Yandex Plus", got.ServiceName)
+
+	assert.ErrorContains(t, services.Delete(ctx, svc.ID), "not found")
+
+	// Написания удаленного сервиса снова свободны
+	createService(t, ctx, services, domain.Service{Name: "Кинопоиск"})
+}
+
+func testServiceLinkSubscriptions(t *testing.T, subs domain.SubscriptionRepository, services domain.ServiceRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	lower := create(t, ctx, subs, domain.Subscription{ServiceName: "yandex plus", Price: 100})
+	joined := create(t, ctx, subs, domain.Subscription{ServiceName: "YandexPlus", Price: 200})
+	alias := create(t, ctx, subs, domain.Subscription{ServiceName: "Кинопоиск", Price: 300})
+	unrelated := create(t, ctx, subs, domain.Subscription{ServiceName: "Netflix", Price: 400})
+	foreign := create(t, other, subs, domain.Subscription{ServiceName: "Yandex Plus", Price: 500})
+
+	svc := createService(t, ctx, services, yandexPlus())
+	linked, err := subs.LinkService(ctx, svc)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), linked)
+
+	for _, sub := range []*domain.Subscription{lower, joined, alias} {
+		got, err := subs.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, svc.ID, got.ServiceID)
+		assert.Equal(t, "Yandex Plus", got.ServiceName)
+	}
+	got, err := subs.GetByID(ctx, unrelated.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.ServiceID)
+	got, err = subs.GetByID(other, foreign.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.ServiceID)
+
+	// Суммы по каноническому названию учитывают все написания
+	total, err := subs.GetSummary(ctx, domain.SummaryFilters{ServiceName: "Yandex Plus",
+		PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.January)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), total)
+
+	linked, err = subs.LinkService(ctx, svc)
+	require.NoError(t, err)
+	assert.Zero(t, linked)
+
+	// Переименование сервиса переносится на связанные подписки, даже если прежнее написание больше не подходит
+	renamed := yandexPlus()
+	renamed.Name = "Яндекс Плюс"
+	renamed.Aliases = nil
+	updated, err := services.Update(ctx, svc.ID, &renamed)
+	require.NoError(t, err)
+	linked, err = subs.LinkService(ctx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), linked)
+	got, err = subs.GetByID(ctx, alias.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Яндекс Плюс", got.ServiceName)
+
+	// Подписки, связанные с другим сервисом, не перехватываются
+	netflix := createService(t, ctx, services, domain.Service{Name: "Netflix", Aliases: []string{"Yandex Plus"}})
+	linked, err = subs.LinkService(ctx, netflix)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), linked)
+	got, err = subs.GetByID(ctx, lower.ID)
+	require.NoError(t, err)
+	assert.Equal(t, svc.ID, got.ServiceID)
+}
+
+func testServiceSummaryByCategory(t *testing.T, subs domain.SubscriptionRepository, services domain.ServiceRepository) {
+	ctx := context.Background()
+	jan := month(2025, time.January)
+
+	plus := createService(t, ctx, services, yandexPlus())
+	spotify := createService(t, ctx, services, domain.Service{Name: "Spotify", Category: "entertainment"})
+	github := createService(t, ctx, services, domain.Service{Name: "GitHub", Category: "dev tools"})
+	misc := createService(t, ctx, services, domain.Service{Name: "Notion"})
+
+	create(t, ctx, subs, domain.Subscription{ServiceName: plus.Name, ServiceID: plus.ID, Price: 399, StartDate: jan})
+	create(t, ctx, subs, domain.Subscription{ServiceName: spotify.Name, ServiceID: spotify.ID, Price: 169, StartDate: jan})
+	create(t, ctx, subs, domain.Subscription{ServiceName: github.Name, ServiceID: github.ID, Price: 400, UserID: userB, StartDate: jan})
+	create(t, ctx, subs, domain.Subscription{ServiceName: misc.Name, ServiceID: misc.ID, Price: 8, StartDate: jan})
+	create(t, ctx, subs, domain.Subscription{ServiceName: "Local gym", Price: 2, StartDate: jan})
+
+	period := domain.SummaryFilters{PeriodStart: jan, PeriodEnd: jan}
+	groups, err := subs.GetGroupedSummary(ctx, period, domain.SummaryByCategory)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SummaryGroup{
+		{Key: "entertainment", Total: 399 + 169},
+		{Key: "dev tools", Total: 400},
+		{Key: "", Total: 8 + 2},
+	}, groups)
+
+	period.UserID = userB
+	groups, err = subs.GetGroupedSummary(ctx, period, domain.SummaryByCategory)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SummaryGroup{{Key: "dev tools", Total: 400}}, groups)
+}
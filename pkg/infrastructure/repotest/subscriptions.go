@@ -0,0 +1,750 @@
+// Package repotest содержит общий набор тестов, которому должна соответствовать
+// каждая реализация domain.SubscriptionRepository
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// OtherOrganizationID вторая организация для проверки изоляции; хранилища со ссылочной целостностью должны создать ее заранее
+const OtherOrganizationID = "11111111-1111-1111-1111-111111111111"
+
+// Пользователи, от имени которых создаются подписки
+const (
+	userA = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	userB = "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"
+)
+
+// Factory возвращает пустой репозиторий для очередного теста
+type Factory func(t *testing.T) domain.SubscriptionRepository
+
+// RunSubscriptionRepositoryTests проверяет реализацию репозитория подписок
+func RunSubscriptionRepositoryTests(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.SubscriptionRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"Constraints", testConstraints},
+		{"NotFound", testNotFound},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"DeleteMany", testDeleteMany},
+		{"List", testList},
+		{"Summary", testSummary},
+		{"Trials", testTrials},
+		{"Pauses", testPauses},
+		{"ConcurrentPauses", testConcurrentPauses},
+		{"ScheduledChanges", testScheduledChanges},
+		{"ConcurrentChanges", testConcurrentChanges},
+		{"ExpireEnded", testExpireEnded},
+		{"OrganizationIsolation", testOrganizationIsolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func until(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func create(t *testing.T, ctx context.Context, repo domain.SubscriptionRepository, sub domain.Subscription) *domain.Subscription {
+	t.Helper()
+
+	if sub.UserID == "" {
+		sub.UserID = userA
+	}
+	if sub.ServiceName == "" {
+		sub.ServiceName = "Yandex Plus"
+	}
+	if sub.StartDate.IsZero() {
+		sub.StartDate = month(2025, time.January)
+	}
+
+	created, err := repo.Create(ctx, &sub)
+	require.NoError(t, err)
+	return created
+}
+
+// setChanges заменяет запланированные изменения подписки целиком
+func setChanges(ctx context.Context, repo domain.SubscriptionRepository, id string, changes []domain.ScheduledChange) (*domain.Subscription, error) {
+	return repo.UpdateChanges(ctx, id, func(*domain.Subscription) ([]domain.ScheduledChange, error) { return changes, nil })
+}
+
+// setPauses заменяет паузы подписки целиком
+func setPauses(ctx context.Context, repo domain.SubscriptionRepository, id string, pauses []domain.Pause) (*domain.Subscription, error) {
+	return repo.UpdatePauses(ctx, id, func(*domain.Subscription) ([]domain.Pause, error) { return pauses, nil })
+}
+
+func ids(subs []*domain.Subscription) []string {
+	out := make([]string, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, s.ID)
+	}
+	return out
+}
+
+func testCreateAndGet(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	before := time.Now().Add(-time.Minute)
+
+	created := create(t, ctx, repo, domain.Subscription{
+		ServiceName: "Netflix",
+		Price:       799,
+		UserID:      userA,
+		StartDate:   month(2025, time.March),
+		EndDate:     until(month(2025, time.December)),
+	})
+
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, domain.DefaultOrganizationID, created.OrganizationID)
+	assert.Equal(t, "Netflix", created.ServiceName)
+	assert.Equal(t, int64(799), created.Price)
+	assert.Equal(t, userA, created.UserID)
+	assert.True(t, created.StartDate.Equal(month(2025, time.March)))
+	require.True(t, created.EndDate.Valid)
+	assert.True(t, created.EndDate.Time.Equal(month(2025, time.December)))
+	assert.True(t, created.CreatedAt.After(before))
+	assert.True(t, created.UpdatedAt.Equal(created.CreatedAt))
+
+	got, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, got.ID)
+	assert.Equal(t, created.ServiceName, got.ServiceName)
+	assert.Equal(t, created.Price, got.Price)
+	assert.True(t, got.StartDate.Equal(created.StartDate))
+	assert.True(t, got.CreatedAt.Equal(created.CreatedAt))
+
+	// Подписка без даты окончания
+	open := create(t, ctx, repo, domain.Subscription{Price: 100})
+	assert.False(t, open.EndDate.Valid)
+
+	// UUID пользователя хранится в каноническом виде
+	upper := create(t, ctx, repo, domain.Subscription{UserID: "7AD1B1C4-8F1E-4C38-9B7E-2F2D5D0F4E11"})
+	assert.Equal(t, userB, upper.UserID)
+}
+
+func testConstraints(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+
+	for name, sub := range map[string]domain.Subscription{
+		"negative price":     {ServiceName: "s", Price: -1, UserID: userA, StartDate: month(2025, time.January)},
+		"end before start":   {ServiceName: "s", UserID: userA, StartDate: month(2025, time.May), EndDate: until(month(2025, time.April))},
+		"invalid user id":    {ServiceName: "s", UserID: "not-a-uuid", StartDate: month(2025, time.January)},
+		"price out of range": {ServiceName: "s", Price: 1 << 31, UserID: userA, StartDate: month(2025, time.January)},
+		"empty user id":      {ServiceName: "s", UserID: "", StartDate: month(2025, time.January)},
+		"negative trial":     {ServiceName: "s", UserID: userA, StartDate: month(2025, time.January), TrialMonths: -1},
+		"negative intro":     {ServiceName: "s", UserID: userA, StartDate: month(2025, time.January), IntroPrice: -1, IntroMonths: 1},
+	} {
+		_, err := repo.Create(ctx, &sub)
+		assert.Error(t, err, name)
+	}
+
+	created := create(t, ctx, repo, domain.Subscription{})
+	_, err := repo.Update(ctx, created.ID, &domain.Subscription{
+		ServiceName: "s",
+		StartDate:   month(2025, time.May),
+		EndDate:     until(month(2025, time.April)),
+	})
+	assert.Error(t, err)
+
+	subs, err := repo.List(ctx, domain.ListFilters{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, subs, 1)
+}
+
+func testNotFound(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	const missing = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+
+	_, err := repo.GetByID(ctx, missing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = repo.Update(ctx, missing, &domain.Subscription{ServiceName: "s", StartDate: month(2025, time.January)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	err = repo.Delete(ctx, missing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	// Идентификатор не в формате UUID - ошибка запроса, а не отсутствие подписки
+	_, err = repo.GetByID(ctx, "not-a-uuid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid")
+	assert.NotContains(t, err.Error(), "not found")
+}
+
+func testUpdate(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	created := create(t, ctx, repo, domain.Subscription{ServiceName: "Netflix", Price: 500, UserID: userA})
+
+	updated, err := repo.Update(ctx, created.ID, &domain.Subscription{
+		ServiceName: "Netflix Premium",
+		Price:       900,
+		UserID:      userB, // владелец подписки не меняется
+		StartDate:   month(2025, time.February),
+		EndDate:     until(month(2025, time.June)),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, "Netflix Premium", updated.ServiceName)
+	assert.Equal(t, int64(900), updated.Price)
+	assert.Equal(t, userA, updated.UserID)
+	assert.True(t, updated.StartDate.Equal(month(2025, time.February)))
+	require.True(t, updated.EndDate.Valid)
+	assert.True(t, updated.EndDate.Time.Equal(month(2025, time.June)))
+	assert.True(t, updated.CreatedAt.Equal(created.CreatedAt))
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+
+	// Дата окончания снимается
+	updated, err = repo.Update(ctx, created.ID, &domain.Subscription{ServiceName: "Netflix", Price: 500, StartDate: month(2025, time.February)})
+	require.NoError(t, err)
+	assert.False(t, updated.EndDate.Valid)
+
+	got, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Netflix", got.ServiceName)
+	assert.False(t, got.EndDate.Valid)
+}
+
+func testDelete(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	created := create(t, ctx, repo, domain.Subscription{})
+	kept := create(t, ctx, repo, domain.Subscription{})
+
+	require.NoError(t, repo.Delete(ctx, created.ID))
+
+	_, err := repo.GetByID(ctx, created.ID)
+	assert.Error(t, err)
+	assert.Error(t, repo.Delete(ctx, created.ID))
+
+	_, err = repo.GetByID(ctx, kept.ID)
+	assert.NoError(t, err)
+}
+
+func testDeleteMany(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	create(t, ctx, repo, domain.Subscription{UserID: userA, ServiceName: "Netflix"})
+	create(t, ctx, repo, domain.Subscription{UserID: userA, ServiceName: "Spotify"})
+	create(t, ctx, repo, domain.Subscription{UserID: userB, ServiceName: "Netflix"})
+	create(t, ctx, repo, domain.Subscription{UserID: userB, ServiceName: "Spotify"})
+
+	deleted, err := repo.DeleteMany(ctx, domain.DeleteFilters{UserID: userA, ServiceName: "Netflix"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	deleted, err = repo.DeleteMany(ctx, domain.DeleteFilters{ServiceName: "Spotify"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	deleted, err = repo.DeleteMany(ctx, domain.DeleteFilters{UserID: userA})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+
+	subs, err := repo.List(ctx, domain.ListFilters{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, userB, subs[0].UserID)
+	assert.Equal(t, "Netflix", subs[0].ServiceName)
+}
+
+func testList(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+
+	var created []*domain.Subscription
+	for i, s := range []domain.Subscription{
+		{UserID: userA, ServiceName: "Netflix"},
+		{UserID: userB, ServiceName: "Netflix"},
+		{UserID: userA, ServiceName: "Spotify"},
+		{UserID: userA, ServiceName: "Netflix"},
+		{UserID: userB, ServiceName: "Spotify"},
+	} {
+		s.Price = int64(100 * (i + 1))
+		created = append(created, create(t, ctx, repo, s))
+		// Время создания различается, поэтому порядок выдачи определен
+		time.Sleep(2 * time.Millisecond)
+	}
+	c := func(idx ...int) []string {
+		out := make([]string, 0, len(idx))
+		for _, i := range idx {
+			out = append(out, created[i].ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		filters domain.ListFilters
+		want    []string
+	}{
+		{"all newest first", domain.ListFilters{Limit: 10}, c(4, 3, 2, 1, 0)},
+		{"by user", domain.ListFilters{UserID: userA, Limit: 10}, c(3, 2, 0)},
+		{"by service", domain.ListFilters{ServiceName: "Netflix", Limit: 10}, c(3, 1, 0)},
+		{"by user and service", domain.ListFilters{UserID: userB, ServiceName: "Spotify", Limit: 10}, c(4)},
+		{"service name is case sensitive", domain.ListFilters{ServiceName: "netflix", Limit: 10}, c()},
+		{"limit", domain.ListFilters{Limit: 2}, c(4, 3)},
+		{"offset", domain.ListFilters{Limit: 2, Offset: 2}, c(2, 1)},
+		{"offset past the end", domain.ListFilters{Limit: 2, Offset: 5}, c()},
+		{"zero limit", domain.ListFilters{Limit: 0}, c()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, err := repo.List(ctx, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(subs))
+		})
+	}
+
+	_, err := repo.List(ctx, domain.ListFilters{UserID: "not-a-uuid", Limit: 10})
+	assert.Error(t, err)
+}
+
+func testSummary(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+
+	// 100/мес: январь-июнь 2025
+	create(t, ctx, repo, domain.Subscription{UserID: userA, ServiceName: "Netflix", Price: 100,
+		StartDate: month(2025, time.January), EndDate: until(month(2025, time.June))})
+	// 10/мес: с ноября 2024 без окончания
+	create(t, ctx, repo, domain.Subscription{UserID: userA, ServiceName: "Spotify", Price: 10,
+		StartDate: month(2024, time.November)})
+	// 1000/мес: только март 2025
+	create(t, ctx, repo, domain.Subscription{UserID: userB, ServiceName: "Netflix", Price: 1000,
+		StartDate: month(2025, time.March), EndDate: until(month(2025, time.March))})
+	// 7/мес: 2023 год целиком, вне большинства периодов
+	create(t, ctx, repo, domain.Subscription{UserID: userB, ServiceName: "Spotify", Price: 7,
+		StartDate: month(2023, time.January), EndDate: until(month(2023, time.December))})
+
+	tests := []struct {
+		name    string
+		filters domain.SummaryFilters
+		want    int64
+	}{
+		{"single month", domain.SummaryFilters{PeriodStart: month(2025, time.March), PeriodEnd: month(2025, time.March)}, 100 + 10 + 1000},
+		{"whole 2025", domain.SummaryFilters{PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.December)}, 6*100 + 12*10 + 1000},
+		{"across years", domain.SummaryFilters{PeriodStart: month(2024, time.December), PeriodEnd: month(2025, time.February)}, 2*100 + 3*10},
+		{"period starts before subscription", domain.SummaryFilters{PeriodStart: month(2024, time.January), PeriodEnd: month(2024, time.December)}, 2 * 10},
+		{"period ends on subscription end", domain.SummaryFilters{PeriodStart: month(2025, time.June), PeriodEnd: month(2025, time.July)}, 100 + 2*10},
+		{"no overlap", domain.SummaryFilters{PeriodStart: month(2022, time.January), PeriodEnd: month(2022, time.December)}, 0},
+		{"multi-year", domain.SummaryFilters{PeriodStart: month(2023, time.June), PeriodEnd: month(2025, time.May)}, 7*7 + 5*100 + 7*10 + 1000},
+		{"by user", domain.SummaryFilters{UserID: userB, PeriodStart: month(2023, time.January), PeriodEnd: month(2025, time.December)}, 12*7 + 1000},
+		{"by service", domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.December)}, 6*100 + 1000},
+		{"by user and service", domain.SummaryFilters{UserID: userA, ServiceName: "Spotify", PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.January)}, 10},
+		{"unknown service", domain.SummaryFilters{ServiceName: "Kinopoisk", PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.December)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, err := repo.GetSummary(ctx, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, total)
+		})
+	}
+}
+
+func testTrials(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+
+	// Январь-февраль бесплатно, март-май по 50, затем 200 до декабря 2025
+	trial := create(t, ctx, repo, domain.Subscription{ServiceName: "Netflix", Price: 200,
+		StartDate: month(2025, time.January), EndDate: until(month(2025, time.December)),
+		TrialMonths: 2, IntroPrice: 50, IntroMonths: 3})
+	assert.Equal(t, 2, trial.TrialMonths)
+	assert.Equal(t, int64(50), trial.IntroPrice)
+	assert.Equal(t, 3, trial.IntroMonths)
+
+	got, err := repo.GetByID(ctx, trial.ID)
+	require.NoError(t, err)
+	assert.Equal(t, trial.TrialMonths, got.TrialMonths)
+	assert.Equal(t, trial.IntroPrice, got.IntroPrice)
+	assert.Equal(t, trial.IntroMonths, got.IntroMonths)
+
+	// Пробный месяц без вводной цены и подписка, закончившаяся во вводный период
+	create(t, ctx, repo, domain.Subscription{ServiceName: "Spotify", Price: 10,
+		StartDate: month(2025, time.March), TrialMonths: 1})
+	create(t, ctx, repo, domain.Subscription{ServiceName: "Kinopoisk", Price: 300,
+		StartDate: month(2025, time.January), EndDate: until(month(2025, time.February)), IntroPrice: 100, IntroMonths: 6})
+
+	tests := []struct {
+		name    string
+		filters domain.SummaryFilters
+		want    int64
+	}{
+		{"trial month", domain.SummaryFilters{PeriodStart: month(2025, time.February), PeriodEnd: month(2025, time.February)}, 100},
+		{"intro month", domain.SummaryFilters{PeriodStart: month(2025, time.April), PeriodEnd: month(2025, time.April)}, 50 + 10},
+		{"regular month", domain.SummaryFilters{PeriodStart: month(2025, time.June), PeriodEnd: month(2025, time.June)}, 200 + 10},
+		{"whole 2025", domain.SummaryFilters{PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.December)}, 3*50 + 7*200 + 9*10 + 2*100},
+		{"after the end", domain.SummaryFilters{PeriodStart: month(2026, time.January), PeriodEnd: month(2026, time.March)}, 3 * 10},
+		{"by service", domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.February), PeriodEnd: month(2025, time.June)}, 3*50 + 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, err := repo.GetSummary(ctx, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, total)
+		})
+	}
+
+	// Пробный период Netflix заканчивается 1 марта, Spotify - 1 апреля
+	ending, err := repo.List(ctx, domain.ListFilters{
+		TrialEndsFrom: month(2025, time.February),
+		TrialEndsTo:   month(2025, time.March),
+		Limit:         10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{trial.ID}, ids(ending))
+
+	ending, err = repo.List(ctx, domain.ListFilters{TrialEndsFrom: month(2025, time.March), Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, ending, 2)
+
+	// Вводная цена снимается при обновлении
+	updated, err := repo.Update(ctx, trial.ID, &domain.Subscription{ServiceName: "Netflix", Price: 200, StartDate: month(2025, time.January)})
+	require.NoError(t, err)
+	assert.Zero(t, updated.TrialMonths)
+	assert.Zero(t, updated.IntroPrice)
+	assert.Zero(t, updated.IntroMonths)
+}
+
+func testPauses(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+
+	// Пробный январь, вводная цена 50 за февраль-март, затем 200; пауза март-апрель и бессрочная с июля
+	paused := create(t, ctx, repo, domain.Subscription{ServiceName: "Netflix", Price: 200,
+		StartDate: month(2025, time.January), TrialMonths: 1, IntroPrice: 50, IntroMonths: 2})
+	create(t, ctx, repo, domain.Subscription{ServiceName: "Spotify", Price: 10,
+		StartDate: month(2025, time.January), EndDate: until(month(2025, time.December))})
+	assert.Empty(t, paused.Pauses)
+
+	// Порядок пауз восстанавливается по началу
+	updated, err := setPauses(ctx, repo, paused.ID, []domain.Pause{
+		{Start: month(2025, time.July)},
+		{Start: month(2025, time.March), End: until(month(2025, time.April))},
+	})
+	require.NoError(t, err)
+	want := []domain.Pause{
+		{Start: month(2025, time.March), End: until(month(2025, time.April))},
+		{Start: month(2025, time.July)},
+	}
+	assert.Equal(t, want, updated.Pauses)
+
+	got, err := repo.GetByID(ctx, paused.ID)
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Pauses)
+
+	// Подписка не меняет паузы при обновлении
+	_, err = repo.Update(ctx, paused.ID, &domain.Subscription{ServiceName: "Netflix", Price: 200,
+		StartDate: month(2025, time.January), TrialMonths: 1, IntroPrice: 50, IntroMonths: 2})
+	require.NoError(t, err)
+	got, err = repo.GetByID(ctx, paused.ID)
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Pauses)
+
+	tests := []struct {
+		name    string
+		filters domain.SummaryFilters
+		want    int64
+	}{
+		{"intro month", domain.SummaryFilters{PeriodStart: month(2025, time.February), PeriodEnd: month(2025, time.February)}, 50 + 10},
+		{"paused month", domain.SummaryFilters{PeriodStart: month(2025, time.April), PeriodEnd: month(2025, time.April)}, 10},
+		{"first half", domain.SummaryFilters{PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.June)}, 50 + 2*200 + 6*10},
+		{"open pause", domain.SummaryFilters{PeriodStart: month(2025, time.July), PeriodEnd: month(2026, time.March)}, 6 * 10},
+		{"by service", domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.January), PeriodEnd: month(2026, time.December)}, 50 + 2*200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, err := repo.GetSummary(ctx, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, total)
+		})
+	}
+
+	// Без пауз подписка снова оплачивается каждый месяц
+	_, err = setPauses(ctx, repo, paused.ID, nil)
+	require.NoError(t, err)
+	total, err := repo.GetSummary(ctx, domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.March), PeriodEnd: month(2025, time.April)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(50+200), total)
+
+	_, err = setPauses(ctx, repo, paused.ID, []domain.Pause{{Start: month(2025, time.May), End: until(month(2025, time.April))}})
+	assert.Error(t, err, "pause ending before its start")
+
+	_, err = setPauses(ctx, repo, "00000000-0000-0000-0000-000000000000", nil)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func testConcurrentPauses(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	sub := create(t, ctx, repo, domain.Subscription{ServiceName: "Netflix", Price: 100, StartDate: month(2025, time.January)})
+
+	// Каждая запись добавляет паузу к прочитанным в ней же; ни одна не должна потеряться
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := month(2025, time.Month(i+1))
+			_, err := repo.UpdatePauses(ctx, sub.ID, func(current *domain.Subscription) ([]domain.Pause, error) {
+				return append(current.Pauses, domain.Pause{Start: start, End: until(start)}), nil
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	got, err := repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Len(t, got.Pauses, writers)
+
+	// Ошибка update возвращается как есть, и паузы не меняются
+	rejected := errors.New("subscription is already paused")
+	_, err = repo.UpdatePauses(ctx, sub.ID, func(*domain.Subscription) ([]domain.Pause, error) { return nil, rejected })
+	assert.ErrorIs(t, err, rejected)
+	got, err = repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Len(t, got.Pauses, writers)
+}
+
+func testConcurrentChanges(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	price := func(p int64) sql.NullInt64 { return sql.NullInt64{Int64: p, Valid: true} }
+
+	// Планирование нового изменения одновременно с применением старого не должно вернуть примененное в список
+	for i := 0; i < 5; i++ {
+		sub := create(t, ctx, repo, domain.Subscription{ServiceName: "Netflix", Price: 100, StartDate: month(2025, time.January)})
+		_, err := setChanges(ctx, repo, sub.ID, []domain.ScheduledChange{{EffectiveFrom: month(2025, time.March), Price: price(150)}})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var applyErr, scheduleErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, applyErr = repo.ApplyChanges(ctx, month(2025, time.March))
+		}()
+		go func() {
+			defer wg.Done()
+			_, scheduleErr = repo.UpdateChanges(ctx, sub.ID, func(current *domain.Subscription) ([]domain.ScheduledChange, error) {
+				return append(current.Changes, domain.ScheduledChange{EffectiveFrom: month(2025, time.June), Price: price(200)}), nil
+			})
+		}()
+		wg.Wait()
+		require.NoError(t, applyErr)
+		require.NoError(t, scheduleErr)
+
+		// В любом порядке мартовское изменение применено один раз, а июньское осталось в списке
+		got, err := repo.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(150), got.Price)
+		assert.Equal(t, []domain.PricePeriod{{Until: month(2025, time.February), Price: 100}}, got.PriceHistory)
+		assert.Equal(t, []domain.ScheduledChange{{EffectiveFrom: month(2025, time.June), Price: price(200)}}, got.Changes)
+
+		_, err = repo.ApplyChanges(ctx, month(2025, time.March))
+		require.NoError(t, err)
+		got, err = repo.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Len(t, got.PriceHistory, 1)
+	}
+}
+
+func testScheduledChanges(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	// Пробный январь, 100 по март, 150 с апреля, 200 с июля, последний месяц — сентябрь
+	sub := create(t, ctx, repo, domain.Subscription{ServiceName: "Netflix", Plan: "Basic", Price: 100, TrialMonths: 1})
+	create(t, ctx, repo, domain.Subscription{ServiceName: "Spotify", Price: 10, EndDate: until(month(2025, time.December))})
+	foreign := create(t, other, repo, domain.Subscription{Price: 1})
+	assert.Equal(t, "Basic", sub.Plan)
+	assert.Empty(t, sub.Changes)
+
+	price := func(p int64) sql.NullInt64 { return sql.NullInt64{Int64: p, Valid: true} }
+	want := []domain.ScheduledChange{
+		{EffectiveFrom: month(2025, time.April), Price: price(150), Plan: sql.NullString{String: "Premium", Valid: true}},
+		{EffectiveFrom: month(2025, time.July), Price: price(200)},
+		{EffectiveFrom: month(2025, time.October), Cancel: true},
+	}
+	updated, err := setChanges(ctx, repo, sub.ID, []domain.ScheduledChange{want[2], want[0], want[1]})
+	require.NoError(t, err)
+	assert.Equal(t, want, updated.Changes)
+	_, err = setChanges(other, repo, foreign.ID, []domain.ScheduledChange{{EffectiveFrom: month(2025, time.March), Cancel: true}})
+	require.NoError(t, err)
+
+	// Подписка не меняет запланированные изменения при обновлении
+	_, err = repo.Update(ctx, sub.ID, &domain.Subscription{ServiceName: "Netflix", Plan: "Basic", Price: 100,
+		StartDate: month(2025, time.January), TrialMonths: 1})
+	require.NoError(t, err)
+	got, err := repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Changes)
+
+	tests := []struct {
+		name    string
+		filters domain.SummaryFilters
+		want    int64
+	}{
+		{"before change", domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.March), PeriodEnd: month(2025, time.March)}, 100},
+		{"new price", domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.April), PeriodEnd: month(2025, time.April)}, 150},
+		{"year", domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.December)}, 2*100 + 3*150 + 3*200},
+		{"after cancel", domain.SummaryFilters{ServiceName: "Netflix", PeriodStart: month(2025, time.October), PeriodEnd: month(2026, time.December)}, 0},
+		{"all services", domain.SummaryFilters{PeriodStart: month(2025, time.September), PeriodEnd: month(2025, time.October)}, 200 + 2*10},
+	}
+	checkSummaries := func(t *testing.T) {
+		t.Helper()
+		for _, tt := range tests {
+			total, err := repo.GetSummary(ctx, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, total, tt.name)
+		}
+	}
+	checkSummaries(t)
+
+	// Применение переносит цену в историю, суммы не меняются
+	applied, err := repo.ApplyChanges(ctx, month(2025, time.May))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), applied)
+
+	got, err = repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Premium", got.Plan)
+	assert.Equal(t, int64(150), got.Price)
+	assert.Equal(t, []domain.PricePeriod{{Until: month(2025, time.March), Price: 100}}, got.PriceHistory)
+	assert.Equal(t, want[1:], got.Changes)
+	assert.Equal(t, domain.SubscriptionActive, got.Status)
+	checkSummaries(t)
+
+	got, err = repo.GetByID(other, foreign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, until(month(2025, time.February)), got.EndDate)
+	assert.Equal(t, domain.SubscriptionExpired, got.Status)
+	assert.Empty(t, got.Changes)
+
+	applied, err = repo.ApplyChanges(ctx, month(2025, time.May))
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+
+	applied, err = repo.ApplyChanges(ctx, month(2025, time.November))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), applied)
+
+	got, err = repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), got.Price)
+	assert.Equal(t, until(month(2025, time.September)), got.EndDate)
+	assert.Equal(t, domain.SubscriptionExpired, got.Status)
+	assert.Equal(t, []domain.PricePeriod{
+		{Until: month(2025, time.March), Price: 100},
+		{Until: month(2025, time.June), Price: 150},
+	}, got.PriceHistory)
+	assert.Empty(t, got.Changes)
+	checkSummaries(t)
+
+	_, err = setChanges(ctx, repo, "00000000-0000-0000-0000-000000000000", nil)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func testExpireEnded(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	ended := create(t, ctx, repo, domain.Subscription{Price: 100, EndDate: until(month(2025, time.January))})
+	longEnded := create(t, ctx, repo, domain.Subscription{Price: 10, StartDate: month(2023, time.January), EndDate: until(month(2023, time.June))})
+	endsThisMonth := create(t, ctx, repo, domain.Subscription{Price: 1, EndDate: until(month(2025, time.February))})
+	open := create(t, ctx, repo, domain.Subscription{Price: 1})
+	foreign := create(t, other, repo, domain.Subscription{Price: 1, EndDate: until(month(2025, time.January))})
+	assert.Equal(t, domain.SubscriptionActive, ended.Status)
+
+	expired, err := repo.ExpireEnded(ctx, month(2025, time.February))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), expired, "subscriptions of every organization ended before the month expire")
+
+	status := func(ctx context.Context, id string) string {
+		t.Helper()
+		sub, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		return sub.Status
+	}
+	assert.Equal(t, domain.SubscriptionExpired, status(ctx, ended.ID))
+	assert.Equal(t, domain.SubscriptionExpired, status(ctx, longEnded.ID))
+	assert.Equal(t, domain.SubscriptionActive, status(ctx, endsThisMonth.ID))
+	assert.Equal(t, domain.SubscriptionActive, status(ctx, open.ID))
+	assert.Equal(t, domain.SubscriptionExpired, status(other, foreign.ID))
+
+	expired, err = repo.ExpireEnded(ctx, month(2025, time.February))
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+
+	// Состояние не влияет на сумму
+	total, err := repo.GetSummary(ctx, domain.SummaryFilters{PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.January)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100+1+1), total)
+
+	// Изменение, после которого подписка все еще закончилась, сохраняет состояние; продление возвращает активное
+	updated, err := repo.Update(ctx, ended.ID, &domain.Subscription{ServiceName: "s", Price: 200, StartDate: month(2025, time.January), EndDate: until(month(2025, time.January))})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SubscriptionExpired, updated.Status)
+
+	updated, err = repo.Update(ctx, ended.ID, &domain.Subscription{ServiceName: "s", Price: 200, StartDate: month(2025, time.January)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SubscriptionActive, updated.Status)
+}
+
+func testOrganizationIsolation(t *testing.T, repo domain.SubscriptionRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	own := create(t, ctx, repo, domain.Subscription{UserID: userA, ServiceName: "Netflix", Price: 100})
+	foreign := create(t, other, repo, domain.Subscription{UserID: userA, ServiceName: "Netflix", Price: 1000})
+	assert.Equal(t, OtherOrganizationID, foreign.OrganizationID)
+
+	_, err := repo.GetByID(ctx, foreign.ID)
+	assert.Error(t, err)
+	_, err = repo.Update(ctx, foreign.ID, &domain.Subscription{ServiceName: "s", StartDate: month(2025, time.January)})
+	assert.Error(t, err)
+	assert.Error(t, repo.Delete(ctx, foreign.ID))
+
+	subs, err := repo.List(ctx, domain.ListFilters{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{own.ID}, ids(subs))
+
+	total, err := repo.GetSummary(ctx, domain.SummaryFilters{PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.January)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), total)
+
+	deleted, err := repo.DeleteMany(ctx, domain.DeleteFilters{ServiceName: "Netflix"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	got, err := repo.GetByID(other, foreign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), got.Price)
+}
@@ -0,0 +1,256 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TagFactory возвращает пустые репозитории подписок и тегов над одним хранилищем
+type TagFactory func(t *testing.T) (domain.SubscriptionRepository, domain.TagRepository)
+
+// RunTagRepositoryTests проверяет реализацию репозитория тегов и теги в репозитории подписок
+func RunTagRepositoryTests(t *testing.T, newRepos TagFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, subs domain.SubscriptionRepository, tags domain.TagRepository)
+	}{
+		{"CreateAndList", testTagCreateAndList},
+		{"SubscriptionTags", testSubscriptionTags},
+		{"Rename", testTagRename},
+		{"Delete", testTagDelete},
+		{"Filters", testTagFilters},
+		{"GroupedSummary", testTagGroupedSummary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, tags := newRepos(t)
+			tt.fn(t, subs, tags)
+		})
+	}
+}
+
+func tagNames(tags []*domain.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func testTagCreateAndList(t *testing.T, _ domain.SubscriptionRepository, tags domain.TagRepository) {
+	ctx := context.Background()
+	other := domain.WithOrganization(ctx, OtherOrganizationID)
+
+	created, err := tags.Create(ctx, "project x")
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, domain.DefaultOrganizationID, created.OrganizationID)
+	assert.Equal(t, "project x", created.Name)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	_, err = tags.Create(ctx, "project x")
+	assert.ErrorContains(t, err, "already used")
+
+	_, err = tags.Create(ctx, "cost center 42")
+	require.NoError(t, err)
+	// Названия уникальны только в пределах организации
+	_, err = tags.Create(other, "project x")
+	require.NoError(t, err)
+
+	list, err := tags.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cost center 42", "project x"}, tagNames(list))
+
+	got, err := tags.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "project x", got.Name)
+	assert.Zero(t, got.Subscriptions)
+
+	_, err = tags.GetByID(other, created.ID)
+	assert.ErrorContains(t, err, "not found")
+	_, err = tags.GetByID(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func testSubscriptionTags(t *testing.T, subs domain.SubscriptionRepository, tags domain.TagRepository) {
+	ctx := context.Background()
+
+	created := create(t, ctx, subs, domain.Subscription{Price: 100, Tags: []string{"project x", "cost center 42", "project x"}})
+	assert.Equal(t, []string{"cost center 42", "project x"}, created.Tags)
+
+	got, err := subs.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cost center 42", "project x"}, got.Tags)
+
+	untagged := create(t, ctx, subs, domain.Subscription{Price: 10})
+	assert.Empty(t, untagged.Tags)
+
+	// Недостающие теги создаются вместе с подпиской
+	list, err := tags.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cost center 42", "project x"}, tagNames(list))
+	assert.Equal(t, int64(1), list[1].Subscriptions)
+
+	updated, err := subs.SetTags(ctx, created.ID, []string{"infra", "project x"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"infra", "project x"}, updated.Tags)
+	assert.Equal(t, created.UpdatedAt, updated.UpdatedAt)
+
+	// Тег без подписок остается в списке организации
+	list, err = tags.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cost center 42", "infra", "project x"}, tagNames(list))
+	assert.Zero(t, list[0].Subscriptions)
+
+	cleared, err := subs.SetTags(ctx, created.ID, nil)
+	require.NoError(t, err)
+	assert.Empty(t, cleared.Tags)
+	got, err = subs.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Tags)
+
+	_, err = subs.SetTags(ctx, "00000000-0000-0000-0000-000000000000", []string{"infra"})
+	assert.ErrorContains(t, err, "not found")
+	_, err = subs.SetTags(domain.WithOrganization(ctx, OtherOrganizationID), created.ID, []string{"infra"})
+	assert.ErrorContains(t, err, "not found")
+
+	// Удаление подписки снимает ее теги
+	_, err = subs.SetTags(ctx, untagged.ID, []string{"infra"})
+	require.NoError(t, err)
+	require.NoError(t, subs.Delete(ctx, untagged.ID))
+	list, err = tags.List(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, list[1].Subscriptions)
+}
+
+func testTagRename(t *testing.T, subs domain.SubscriptionRepository, tags domain.TagRepository) {
+	ctx := context.Background()
+
+	sub := create(t, ctx, subs, domain.Subscription{Price: 100, Tags: []string{"project x", "infra"}})
+	list, err := tags.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	projectX := list[1]
+
+	renamed, err := tags.Rename(ctx, projectX.ID, "alpha")
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", renamed.Name)
+	assert.Equal(t, int64(1), renamed.Subscriptions)
+
+	got, err := subs.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "infra"}, got.Tags)
+
+	_, err = tags.Rename(ctx, projectX.ID, "infra")
+	assert.ErrorContains(t, err, "already used")
+	_, err = tags.Rename(ctx, "00000000-0000-0000-0000-000000000000", "beta")
+	assert.ErrorContains(t, err, "not found")
+	_, err = tags.Rename(domain.WithOrganization(ctx, OtherOrganizationID), projectX.ID, "beta")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func testTagDelete(t *testing.T, subs domain.SubscriptionRepository, tags domain.TagRepository) {
+	ctx := context.Background()
+
+	sub := create(t, ctx, subs, domain.Subscription{Price: 100, Tags: []string{"project x", "infra"}})
+	list, err := tags.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	assert.ErrorContains(t, tags.Delete(domain.WithOrganization(ctx, OtherOrganizationID), list[0].ID), "not found")
+	require.NoError(t, tags.Delete(ctx, list[0].ID))
+
+	got, err := subs.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"project x"}, got.Tags)
+
+	_, err = tags.GetByID(ctx, list[0].ID)
+	assert.ErrorContains(t, err, "not found")
+	assert.ErrorContains(t, tags.Delete(ctx, list[0].ID), "not found")
+}
+
+func testTagFilters(t *testing.T, subs domain.SubscriptionRepository, _ domain.TagRepository) {
+	ctx := context.Background()
+	jan := month(2025, time.January)
+
+	both := create(t, ctx, subs, domain.Subscription{UserID: userA, Price: 100, Tags: []string{"project x", "infra"}})
+	infra := create(t, ctx, subs, domain.Subscription{UserID: userB, Price: 10, Tags: []string{"infra"}})
+	create(t, ctx, subs, domain.Subscription{UserID: userA, Price: 1})
+	create(t, domain.WithOrganization(ctx, OtherOrganizationID), subs, domain.Subscription{Price: 1000, Tags: []string{"infra"}})
+
+	tests := []struct {
+		name    string
+		filters domain.ListFilters
+		want    []string
+	}{
+		{"by tag", domain.ListFilters{Tag: "infra", Limit: 10}, []string{infra.ID, both.ID}},
+		{"by tag and user", domain.ListFilters{Tag: "infra", UserID: userA, Limit: 10}, []string{both.ID}},
+		{"unknown tag", domain.ListFilters{Tag: "marketing", Limit: 10}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := subs.List(ctx, tt.filters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(found))
+		})
+	}
+
+	total, err := subs.GetSummary(ctx, domain.SummaryFilters{Tag: "infra", PeriodStart: jan, PeriodEnd: jan})
+	require.NoError(t, err)
+	assert.Equal(t, int64(110), total)
+	total, err = subs.GetSummary(ctx, domain.SummaryFilters{Tag: "project x", UserID: userB, PeriodStart: jan, PeriodEnd: jan})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func testTagGroupedSummary(t *testing.T, subs domain.SubscriptionRepository, _ domain.TagRepository) {
+	ctx := context.Background()
+
+	// 100/мес с января 2025 в двух проектах
+	create(t, ctx, subs, domain.Subscription{UserID: userA, Price: 100, Tags: []string{"project x", "project y"},
+		StartDate: month(2025, time.January)})
+	// 30/мес только в феврале 2025
+	create(t, ctx, subs, domain.Subscription{UserID: userB, Price: 30, Tags: []string{"project y"},
+		StartDate: month(2025, time.February), EndDate: until(month(2025, time.February))})
+	// 5/мес без тегов
+	create(t, ctx, subs, domain.Subscription{UserID: userA, Price: 5, StartDate: month(2025, time.January)})
+	// Закончилась до периода и не дает пустой группы
+	create(t, ctx, subs, domain.Subscription{UserID: userA, Price: 7, Tags: []string{"legacy"},
+		StartDate: month(2023, time.January), EndDate: until(month(2023, time.December))})
+	create(t, domain.WithOrganization(ctx, OtherOrganizationID), subs, domain.Subscription{Price: 1000, Tags: []string{"project x"}})
+
+	period := domain.SummaryFilters{PeriodStart: month(2025, time.January), PeriodEnd: month(2025, time.March)}
+	groups, err := subs.GetGroupedSummary(ctx, period, domain.SummaryByTag)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SummaryGroup{
+		{Key: "project y", Total: 330},
+		{Key: "project x", Total: 300},
+		{Key: "", Total: 15},
+	}, groups)
+
+	byUser := period
+	byUser.UserID = userB
+	groups, err = subs.GetGroupedSummary(ctx, byUser, domain.SummaryByTag)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SummaryGroup{{Key: "project y", Total: 30}}, groups)
+
+	byTag := period
+	byTag.Tag = "project x"
+	groups, err = subs.GetGroupedSummary(ctx, byTag, domain.SummaryByTag)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SummaryGroup{{Key: "project x", Total: 300}, {Key: "project y", Total: 300}}, groups)
+
+	empty := domain.SummaryFilters{PeriodStart: month(2022, time.January), PeriodEnd: month(2022, time.December)}
+	groups, err = subs.GetGroupedSummary(ctx, empty, domain.SummaryByTag)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	_, err = subs.GetGroupedSummary(ctx, period, "user")
+	assert.ErrorContains(t, err, "unknown grouping")
+}
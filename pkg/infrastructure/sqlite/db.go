@@ -0,0 +1,68 @@
+// Package sqlite реализует репозитории поверх встраиваемой базы SQLite (драйвер modernc.org/sqlite, без cgo)
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+
+	"github.com/golang-migrate/migrate/v4"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Open открывает базу по пути к файлу (":memory:" - база в памяти) и включает проверку внешних ключей,
+// ожидание блокировок и журнал WAL
+// SQLite допускает одного писателя, поэтому пул ограничен одним соединением
+func Open(path string) (*sql.DB, error) {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	if path != ":memory:" {
+		query.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// Migrate применяет к базе непримененные миграции из source и возвращает версию схемы
+func Migrate(db *sql.DB, source fs.FS) (uint, error) {
+	src, err := iofs.New(source, ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	driver, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	// Close у мигратора закрыл бы и переданное соединение с базой, поэтому он не вызывается
+	m, err := migrate.NewWithInstance("iofs", src, "sqlite", driver)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create migrator: %w", err)
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return 0, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	version, _, err := m.Version()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
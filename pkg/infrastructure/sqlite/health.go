@@ -0,0 +1,13 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// PingCheck проверяет, что база доступна и отвечает на запросы
+func PingCheck(db *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
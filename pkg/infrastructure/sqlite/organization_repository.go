@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что OrganizationRepository реализует интерфейс domain.OrganizationRepository
+var _ domain.OrganizationRepository = (*OrganizationRepository)(nil)
+
+// OrganizationRepository хранит организации в organizations
+type OrganizationRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewOrganizationRepository создает новый экземпляр репозитория организаций
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db, now: time.Now}
+}
+
+const organizationColumns = `id, name, created_at`
+
+func scanOrganization(row interface{ Scan(dest ...any) error }) (*domain.Organization, error) {
+	var (
+		o         domain.Organization
+		createdAt string
+	)
+	if err := row.Scan(&o.ID, &o.Name, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if o.CreatedAt, err = time.Parse(timestampLayout, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	return &o, nil
+}
+
+// Create создает организацию
+func (r *OrganizationRepository) Create(ctx context.Context, org *domain.Organization) (*domain.Organization, error) {
+	id := org.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	created, err := scanOrganization(r.db.QueryRowContext(
+		ctx,
+		`INSERT INTO organizations (id, name, created_at) VALUES (?, ?, ?) RETURNING `+organizationColumns,
+		id, org.Name, r.now().UTC().Format(timestampLayout),
+	))
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("failed to create organization: organization %q or its id is already used", org.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	return created, nil
+}
+
+// Exists сообщает, что организация существует
+func (r *OrganizationRepository) Exists(ctx context.Context, id string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM organizations WHERE id = ?)`, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check organization: %w", err)
+	}
+	return exists, nil
+}
+
+// List возвращает организации по названию
+func (r *OrganizationRepository) List(ctx context.Context) ([]*domain.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+organizationColumns+` FROM organizations ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []*domain.Organization
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return orgs, nil
+}
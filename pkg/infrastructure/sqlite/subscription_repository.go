@@ -0,0 +1,763 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/sqljson"
+	"github.com/google/uuid"
+)
+
+// Проверка, что SubscriptionRepository реализует интерфейс domain.SubscriptionRepository
+var _ domain.SubscriptionRepository = (*SubscriptionRepository)(nil)
+
+// Форматы хранения дат и меток времени
+// Метки времени в UTC фиксированной длины с микросекундами, как в PostgreSQL, поэтому сортировка строк хронологическая
+const (
+	dateLayout      = "2006-01-02"
+	timestampLayout = "2006-01-02T15:04:05.000000Z"
+)
+
+// SubscriptionRepository реализует интерфейс репозитория для SQLite
+// Все запросы ограничены организацией из контекста (domain.OrganizationFromContext)
+type SubscriptionRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSubscriptionRepository создает новый экземпляр репозитория подписок
+func NewSubscriptionRepository(db *sql.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db, now: time.Now}
+}
+
+const subscriptionColumns = `id, organization_id, service_name, COALESCE(service_id, ''), plan, price, user_id, start_date, end_date, trial_months, intro_price, intro_months,
+	pauses, price_history, scheduled_changes, status, created_at, updated_at,
+	(SELECT json_group_array(t.name) FROM subscription_tags st JOIN tags t ON t.id = st.tag_id WHERE st.subscription_id = subscriptions.id)`
+
+func scanSubscription(row interface{ Scan(dest ...any) error }) (*domain.Subscription, error) {
+	var (
+		s                    domain.Subscription
+		startDate            string
+		endDate              sql.NullString
+		pauses, priceHistory string
+		changes              string
+		createdAt, updatedAt string
+		tags                 string
+	)
+	err := row.Scan(
+		&s.ID,
+		&s.OrganizationID,
+		&s.ServiceName,
+		&s.ServiceID,
+		&s.Plan,
+		&s.Price,
+		&s.UserID,
+		&startDate,
+		&endDate,
+		&s.TrialMonths,
+		&s.IntroPrice,
+		&s.IntroMonths,
+		&pauses,
+		&priceHistory,
+		&changes,
+		&s.Status,
+		&createdAt,
+		&updatedAt,
+		&tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.StartDate, err = time.Parse(dateLayout, startDate); err != nil {
+		return nil, fmt.Errorf("invalid start_date %q: %w", startDate, err)
+	}
+	if endDate.Valid {
+		t, err := time.Parse(dateLayout, endDate.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_date %q: %w", endDate.String, err)
+		}
+		s.EndDate = sql.NullTime{Time: t, Valid: true}
+	}
+	if s.Pauses, err = sqljson.DecodePauses([]byte(pauses)); err != nil {
+		return nil, err
+	}
+	if s.PriceHistory, err = sqljson.DecodePriceHistory([]byte(priceHistory)); err != nil {
+		return nil, err
+	}
+	if s.Changes, err = sqljson.DecodeChanges([]byte(changes)); err != nil {
+		return nil, err
+	}
+	// json_group_array не гарантирует порядок, поэтому теги сортируются здесь
+	if err := json.Unmarshal([]byte(tags), &s.Tags); err != nil {
+		return nil, fmt.Errorf("invalid tags %q: %w", tags, err)
+	}
+	if len(s.Tags) == 0 {
+		s.Tags = nil
+	}
+	sort.Strings(s.Tags)
+	if s.CreatedAt, err = time.Parse(timestampLayout, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	if s.UpdatedAt, err = time.Parse(timestampLayout, updatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at %q: %w", updatedAt, err)
+	}
+
+	return &s, nil
+}
+
+// Create создает новую подписку вместе с тегами
+func (r *SubscriptionRepository) Create(ctx context.Context, sub *domain.Subscription) (*domain.Subscription, error) {
+	userID, err := parseUUID(sub.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := r.timestamp()
+	created, err := scanSubscription(tx.QueryRowContext(
+		ctx,
+		`INSERT INTO subscriptions (id, organization_id, service_name, plan, price, user_id, start_date, end_date,
+                                    trial_months, intro_price, intro_months, created_at, updated_at, service_id)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))
+         RETURNING `+subscriptionColumns,
+		uuid.NewString(), domain.OrganizationFromContext(ctx), sub.ServiceName, sub.Plan, sub.Price, userID,
+		formatDate(sub.StartDate), formatNullDate(sub.EndDate), sub.TrialMonths, sub.IntroPrice, sub.IntroMonths, now, now,
+		sub.ServiceID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	if len(sub.Tags) > 0 {
+		if created, err = r.setTags(ctx, tx, created.ID, sub.Tags); err != nil {
+			return nil, fmt.Errorf("failed to create subscription: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return created, nil
+}
+
+// GetByID получает подписку по ID
+func (r *SubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.Subscription, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	sub, err := scanSubscription(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+subscriptionColumns+`
+         FROM subscriptions
+         WHERE id = ? AND organization_id = ?`,
+		key, domain.OrganizationFromContext(ctx),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("subscription not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Update обновляет подписку
+func (r *SubscriptionRepository) Update(ctx context.Context, id string, sub *domain.Subscription) (*domain.Subscription, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	updated, err := scanSubscription(r.db.QueryRowContext(
+		ctx,
+		`UPDATE subscriptions
+         SET service_name = ?1,
+             price = ?2,
+             start_date = ?3,
+             end_date = ?4,
+             trial_months = ?9,
+             intro_price = ?10,
+             intro_months = ?11,
+             plan = ?12,
+             service_id = NULLIF(?13, ''),
+             status = CASE WHEN ?4 < ?8 THEN status ELSE 'active' END,
+             updated_at = ?5
+         WHERE id = ?6 AND organization_id = ?7
+         RETURNING `+subscriptionColumns,
+		sub.ServiceName, sub.Price, formatDate(sub.StartDate), formatNullDate(sub.EndDate), r.timestamp(),
+		key, domain.OrganizationFromContext(ctx), formatDate(r.monthStart()),
+		sub.TrialMonths, sub.IntroPrice, sub.IntroMonths, sub.Plan, sub.ServiceID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("subscription not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Delete удаляет подписку
+func (r *SubscriptionRepository) Delete(ctx context.Context, id string) error {
+	key, err := parseUUID(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	res, err := r.db.ExecContext(
+		ctx,
+		`DELETE FROM subscriptions WHERE id = ? AND organization_id = ?`,
+		key, domain.OrganizationFromContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	return nil
+}
+
+// UpdatePauses заменяет приостановки подписки на возвращенные update
+// Чтение и запись идут в одной транзакции на единственном соединении, поэтому параллельные изменения пауз не теряются
+func (r *SubscriptionRepository) UpdatePauses(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.Pause, error)) (*domain.Subscription, error) {
+	return r.updateJSON(ctx, id, "pauses", func(sub *domain.Subscription) ([]byte, error) {
+		pauses, err := update(sub)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pauses {
+			if p.End.Valid && monthOf(p.End.Time).Before(monthOf(p.Start)) {
+				return nil, fmt.Errorf("failed to update subscription pauses: pause end must not be before its start")
+			}
+		}
+		data, err := sqljson.EncodePauses(pauses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update subscription pauses: %w", err)
+		}
+		return data, nil
+	})
+}
+
+// UpdateChanges заменяет запланированные изменения подписки на возвращенные update
+// Чтение и запись идут в одной транзакции, поэтому изменение не может быть применено задачей ApplyChanges дважды
+func (r *SubscriptionRepository) UpdateChanges(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.ScheduledChange, error)) (*domain.Subscription, error) {
+	return r.updateJSON(ctx, id, "scheduled_changes", func(sub *domain.Subscription) ([]byte, error) {
+		changes, err := update(sub)
+		if err != nil {
+			return nil, err
+		}
+		data, err := sqljson.EncodeChanges(changes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update scheduled changes: %w", err)
+		}
+		return data, nil
+	})
+}
+
+// updateJSON читает подписку, строит по ней новое значение JSON-колонки column и записывает его в той же транзакции
+// Ошибка build отменяет запись и возвращается без изменений; column задается только из кода репозитория
+func (r *SubscriptionRepository) updateJSON(ctx context.Context, id, column string, build func(*domain.Subscription) ([]byte, error)) (*domain.Subscription, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription %s: %w", column, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription %s: %w", column, err)
+	}
+	defer tx.Rollback()
+
+	sub, err := scanSubscription(tx.QueryRowContext(
+		ctx,
+		`SELECT `+subscriptionColumns+`
+         FROM subscriptions
+         WHERE id = ? AND organization_id = ?`,
+		key, domain.OrganizationFromContext(ctx),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("subscription not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription %s: %w", column, err)
+	}
+
+	data, err := build(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := scanSubscription(tx.QueryRowContext(
+		ctx,
+		`UPDATE subscriptions
+         SET `+column+` = ?, updated_at = ?
+         WHERE id = ?
+         RETURNING `+subscriptionColumns,
+		string(data), r.timestamp(), key,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription %s: %w", column, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update subscription %s: %w", column, err)
+	}
+	return updated, nil
+}
+
+// SetTags заменяет теги подписки; недостающие теги организации создаются, updated_at не меняется
+func (r *SubscriptionRepository) SetTags(ctx context.Context, id string, tags []string) (*domain.Subscription, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM subscriptions WHERE id = ? AND organization_id = ?)`,
+		key, domain.OrganizationFromContext(ctx),
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("subscription not found")
+	}
+
+	updated, err := r.setTags(ctx, tx, key, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
+	}
+	return updated, nil
+}
+
+// setTags заменяет связи подписки с тегами в транзакции tx и возвращает подписку с новыми тегами
+func (r *SubscriptionRepository) setTags(ctx context.Context, tx *sql.Tx, id string, tags []string) (*domain.Subscription, error) {
+	organizationID := domain.OrganizationFromContext(ctx)
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_tags WHERE subscription_id = ?`, id); err != nil {
+		return nil, err
+	}
+	for _, name := range tags {
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO tags (id, organization_id, name, created_at) VALUES (?, ?, ?, ?)
+             ON CONFLICT (organization_id, name) DO NOTHING`,
+			uuid.NewString(), organizationID, name, r.timestamp(),
+		); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO subscription_tags (subscription_id, tag_id)
+             SELECT ?, id FROM tags WHERE organization_id = ? AND name = ?
+             ON CONFLICT DO NOTHING`,
+			id, organizationID, name,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return scanSubscription(tx.QueryRowContext(
+		ctx,
+		`SELECT `+subscriptionColumns+` FROM subscriptions WHERE id = ?`,
+		id,
+	))
+}
+
+// DeleteMany удаляет подписки, подходящие под фильтры, и возвращает их количество
+func (r *SubscriptionRepository) DeleteMany(ctx context.Context, filters domain.DeleteFilters) (int64, error) {
+	query := `DELETE FROM subscriptions WHERE organization_id = ?`
+	args := []any{domain.OrganizationFromContext(ctx)}
+
+	query, args, err := appendFilters(query, args, filters.UserID, filters.ServiceName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// List возвращает список подписок с фильтрацией
+func (r *SubscriptionRepository) List(ctx context.Context, filters domain.ListFilters) ([]*domain.Subscription, error) {
+	if filters.Limit < 0 || filters.Offset < 0 {
+		return nil, fmt.Errorf("failed to query subscriptions: LIMIT and OFFSET must not be negative")
+	}
+
+	query := `SELECT ` + subscriptionColumns + `
+			  FROM subscriptions
+			  WHERE organization_id = ?`
+	args := []any{domain.OrganizationFromContext(ctx)}
+
+	query, args, err := appendFilters(query, args, filters.UserID, filters.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	query, args = appendTagFilter(query, args, filters.Tag)
+	// Окончание пробного периода: первое число месяца после последнего бесплатного
+	if !filters.TrialEndsFrom.IsZero() || !filters.TrialEndsTo.IsZero() {
+		query += ` AND trial_months > 0`
+	}
+	if !filters.TrialEndsFrom.IsZero() {
+		args = append(args, filters.TrialEndsFrom.UTC().Format(dateLayout))
+		query += fmt.Sprintf(` AND date(start_date, 'start of month', '+' || trial_months || ' months') >= ?%d`, len(args))
+	}
+	if !filters.TrialEndsTo.IsZero() {
+		args = append(args, filters.TrialEndsTo.UTC().Format(dateLayout))
+		query += fmt.Sprintf(` AND date(start_date, 'start of month', '+' || trial_months || ' months') <= ?%d`, len(args))
+	}
+
+	// rowid различает подписки, созданные в одну микросекунду
+	query += ` ORDER BY created_at DESC, rowid DESC LIMIT ? OFFSET ?`
+	args = append(args, filters.Limit, filters.Offset)
+
+	subs, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// GetSummary вычисляет общую стоимость подписок за период
+// Стоимость каждой подписки считается domain.Subscription.Cost: с паузами, прежними и запланированными ценами
+// выражение в SQL было бы слишком громоздким для SQLite, а объемы встроенной базы невелики
+func (r *SubscriptionRepository) GetSummary(ctx context.Context, filters domain.SummaryFilters) (int64, error) {
+	subs, err := r.summarySubscriptions(ctx, filters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	var total int64
+	for _, sub := range subs {
+		total += sub.Cost(filters.PeriodStart, filters.PeriodEnd)
+	}
+	return total, nil
+}
+
+// GetGroupedSummary вычисляет суммы подписок за период по тегам или категориям каталога
+// Каталог сервисов в SQLite недоступен, поэтому при группировке по категории все подписки попадают в группу без категории
+func (r *SubscriptionRepository) GetGroupedSummary(ctx context.Context, filters domain.SummaryFilters, groupBy string) ([]domain.SummaryGroup, error) {
+	var keys func(*domain.Subscription) []string
+	switch groupBy {
+	case domain.SummaryByTag:
+		keys = func(sub *domain.Subscription) []string { return sub.Tags }
+	case domain.SummaryByCategory:
+		// Каталога сервисов в SQLite нет, а сводка из одной пустой группы выглядела бы как настоящая
+		return nil, fmt.Errorf("invalid group_by %q: grouping by category requires the service catalog", groupBy)
+	default:
+		return nil, fmt.Errorf("failed to calculate summary: unknown grouping %q", groupBy)
+	}
+
+	subs, err := r.summarySubscriptions(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	return domain.GroupCosts(subs, filters.PeriodStart, filters.PeriodEnd, keys), nil
+}
+
+// summarySubscriptions выбирает подписки, начавшиеся не позже конца периода и подходящие под фильтры
+func (r *SubscriptionRepository) summarySubscriptions(ctx context.Context, filters domain.SummaryFilters) ([]*domain.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE organization_id = ?1 AND start_date <= ?2`
+	args := []any{domain.OrganizationFromContext(ctx), formatDate(filters.PeriodEnd)}
+
+	query, args, err := appendFilters(query, args, filters.UserID, filters.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	query, args = appendTagFilter(query, args, filters.Tag)
+
+	return r.query(ctx, query, args...)
+}
+
+// LinkService связывает подписки организации с сервисом каталога
+// Написания названий сравниваются в Go, чтобы нормализация совпадала с domain.NormalizeServiceName
+func (r *SubscriptionRepository) LinkService(ctx context.Context, svc *domain.Service) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to link subscriptions to service: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT id, service_name, COALESCE(service_id, '')
+         FROM subscriptions
+         WHERE organization_id = ? AND (service_id IS NULL OR service_id = ?)`,
+		domain.OrganizationFromContext(ctx), svc.ID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to link subscriptions to service: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id, serviceName, serviceID string
+		if err := rows.Scan(&id, &serviceName, &serviceID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to link subscriptions to service: %w", err)
+		}
+		if svc.Covers(serviceID, serviceName) && (serviceID != svc.ID || serviceName != svc.Name) {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to link subscriptions to service: %w", err)
+	}
+
+	now := r.timestamp()
+	for _, id := range ids {
+		if _, err := tx.ExecContext(
+			ctx,
+			`UPDATE subscriptions SET service_id = ?, service_name = ?, updated_at = ? WHERE id = ?`,
+			svc.ID, svc.Name, now, id,
+		); err != nil {
+			return 0, fmt.Errorf("failed to link subscriptions to service: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to link subscriptions to service: %w", err)
+	}
+	return int64(len(ids)), nil
+}
+
+// ExpireEnded помечает истекшими подписки всех организаций, закончившиеся раньше месяца before
+func (r *SubscriptionRepository) ExpireEnded(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE subscriptions
+         SET status = 'expired', updated_at = ?
+         WHERE status = 'active' AND end_date < ?`,
+		r.timestamp(), formatDate(before),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire subscriptions: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// Stats возвращает число активных подписок и их суммарную стоимость за месяц по каждой организации
+// Подписки на паузе в этом месяце не учитываются.
+// Используется для метрик и не ограничивается организацией из контекста
+func (r *SubscriptionRepository) Stats(ctx context.Context, month time.Time) ([]domain.SubscriptionStats, error) {
+	subs, err := r.query(
+		ctx,
+		`SELECT `+subscriptionColumns+`
+         FROM subscriptions
+         WHERE start_date <= ?1 AND (end_date IS NULL OR end_date >= ?1)
+         ORDER BY organization_id`,
+		formatDate(month),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscription stats: %w", err)
+	}
+
+	var stats []domain.SubscriptionStats
+	for _, sub := range subs {
+		if end := sub.EffectiveEnd(); (end.Valid && end.Time.Before(month)) || sub.PausedAt(month) {
+			continue
+		}
+		if len(stats) == 0 || stats[len(stats)-1].OrganizationID != sub.OrganizationID {
+			stats = append(stats, domain.SubscriptionStats{OrganizationID: sub.OrganizationID})
+		}
+		stats[len(stats)-1].ActiveCount++
+		stats[len(stats)-1].MonthlySpend += sub.Cost(month, month)
+	}
+
+	return stats, nil
+}
+
+// ApplyChanges применяет запланированные изменения подписок всех организаций, вступившие в силу к месяцу month
+// Не ограничивается организацией из контекста
+func (r *SubscriptionRepository) ApplyChanges(ctx context.Context, month time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT `+subscriptionColumns+`
+         FROM subscriptions
+         WHERE EXISTS (SELECT 1 FROM json_each(scheduled_changes) AS c
+                       WHERE json_extract(c.value, '$.effective_month') <= ?)`,
+		formatDate(month),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+	}
+	subs, err := scanSubscriptions(rows)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+	}
+
+	var applied int64
+	for _, sub := range subs {
+		if !sub.ApplyChanges(month) {
+			continue
+		}
+		history, err := sqljson.EncodePriceHistory(sub.PriceHistory)
+		if err != nil {
+			return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+		}
+		changes, err := sqljson.EncodeChanges(sub.Changes)
+		if err != nil {
+			return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`UPDATE subscriptions
+             SET price = ?, plan = ?, end_date = ?, status = ?, price_history = ?, scheduled_changes = ?, updated_at = ?
+             WHERE id = ?`,
+			sub.Price, sub.Plan, formatNullDate(sub.EndDate), sub.Status, string(history), string(changes), r.timestamp(), sub.ID,
+		); err != nil {
+			return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+		}
+		applied++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to apply scheduled changes: %w", err)
+	}
+	return applied, nil
+}
+
+// query выполняет запрос подписок
+func (r *SubscriptionRepository) query(ctx context.Context, query string, args ...any) ([]*domain.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanSubscriptions(rows)
+}
+
+// scanSubscriptions читает подписки из rows и закрывает их
+func scanSubscriptions(rows *sql.Rows) ([]*domain.Subscription, error) {
+	defer rows.Close()
+
+	var subs []*domain.Subscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return subs, nil
+}
+
+func (r *SubscriptionRepository) timestamp() string {
+	return r.now().UTC().Format(timestampLayout)
+}
+
+// monthStart первый день текущего месяца в UTC
+func (r *SubscriptionRepository) monthStart() time.Time {
+	now := r.now().UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// appendFilters добавляет к запросу условия по пользователю и сервису
+// Параметры нумеруются явно (?N), так как запросы ссылаются на один параметр несколько раз
+func appendFilters(query string, args []any, userID, serviceName string) (string, []any, error) {
+	if userID != "" {
+		key, err := parseUUID(userID)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, key)
+		query += fmt.Sprintf(" AND user_id = ?%d", len(args))
+	}
+	if serviceName != "" {
+		args = append(args, serviceName)
+		query += fmt.Sprintf(" AND service_name = ?%d", len(args))
+	}
+	return query, args, nil
+}
+
+// appendTagFilter добавляет к запросу условие на тег подписки
+func appendTagFilter(query string, args []any, tag string) (string, []any) {
+	if tag == "" {
+		return query, args
+	}
+	args = append(args, tag)
+	query += fmt.Sprintf(` AND EXISTS (SELECT 1 FROM subscription_tags filter_links JOIN tags filter_tags ON filter_tags.id = filter_links.tag_id
+		WHERE filter_links.subscription_id = subscriptions.id AND filter_tags.name = ?%d)`, len(args))
+	return query, args
+}
+
+// parseUUID проверяет идентификатор и приводит его к каноническому виду, как тип UUID в PostgreSQL
+func parseUUID(id string) (string, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid input syntax for type uuid: %q", id)
+	}
+	return parsed.String(), nil
+}
+
+// monthOf первое число месяца t
+func monthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func formatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+func formatNullDate(t sql.NullTime) any {
+	if !t.Valid {
+		return nil
+	}
+	return formatDate(t.Time)
+}
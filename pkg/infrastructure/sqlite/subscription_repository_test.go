@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/migrations"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionRepository_Conformance(t *testing.T) {
+	repotest.RunSubscriptionRepositoryTests(t, func(t *testing.T) domain.SubscriptionRepository {
+		db, err := Open(filepath.Join(t.TempDir(), "subscriptions.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		_, err = Migrate(db, migrations.SQLiteFS)
+		require.NoError(t, err)
+
+		return NewSubscriptionRepository(db)
+	})
+}
+
+func TestTagRepository_Conformance(t *testing.T) {
+	repotest.RunTagRepositoryTests(t, func(t *testing.T) (domain.SubscriptionRepository, domain.TagRepository) {
+		db, err := Open(filepath.Join(t.TempDir(), "subscriptions.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		_, err = Migrate(db, migrations.SQLiteFS)
+		require.NoError(t, err)
+
+		return NewSubscriptionRepository(db), NewTagRepository(db)
+	})
+}
+
+func TestOrganizationRepository_Conformance(t *testing.T) {
+	repotest.RunOrganizationRepositoryTests(t, func(t *testing.T) domain.OrganizationRepository {
+		db, err := Open(filepath.Join(t.TempDir(), "subscriptions.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		_, err = Migrate(db, migrations.SQLiteFS)
+		require.NoError(t, err)
+
+		return NewOrganizationRepository(db)
+	})
+}
+
+func TestSubscriptionRepository_GroupedSummaryByCategory(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "subscriptions.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = Migrate(db, migrations.SQLiteFS)
+	require.NoError(t, err)
+
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	period := domain.SummaryFilters{PeriodStart: jan, PeriodEnd: jan}
+	_, err = NewSubscriptionRepository(db).GetGroupedSummary(context.Background(), period, domain.SummaryByCategory)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "grouping by category requires the service catalog")
+}
+
+func TestMigrate(t *testing.T) {
+	db, err := Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	latest, err := migrations.LatestVersion(migrations.SQLiteFS)
+	require.NoError(t, err)
+
+	version, err := Migrate(db, migrations.SQLiteFS)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	// Повторный запуск ничего не меняет
+	version, err = Migrate(db, migrations.SQLiteFS)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+}
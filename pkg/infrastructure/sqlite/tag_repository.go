@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/google/uuid"
+)
+
+// Проверка, что TagRepository реализует интерфейс domain.TagRepository
+var _ domain.TagRepository = (*TagRepository)(nil)
+
+// TagRepository хранит теги организаций в tags; связи с подписками лежат в subscription_tags
+type TagRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewTagRepository создает новый экземпляр репозитория тегов
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db, now: time.Now}
+}
+
+const tagColumns = `id, organization_id, name,
+	(SELECT COUNT(*) FROM subscription_tags st WHERE st.tag_id = tags.id), created_at`
+
+func scanTag(row interface{ Scan(dest ...any) error }) (*domain.Tag, error) {
+	var (
+		t         domain.Tag
+		createdAt string
+	)
+	if err := row.Scan(&t.ID, &t.OrganizationID, &t.Name, &t.Subscriptions, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if t.CreatedAt, err = time.Parse(timestampLayout, createdAt); err != nil {
+		return nil, fmt.Errorf("invalid created_at %q: %w", createdAt, err)
+	}
+	return &t, nil
+}
+
+// Create добавляет тег организации
+func (r *TagRepository) Create(ctx context.Context, name string) (*domain.Tag, error) {
+	tag, err := scanTag(r.db.QueryRowContext(
+		ctx,
+		`INSERT INTO tags (id, organization_id, name, created_at) VALUES (?, ?, ?, ?) RETURNING `+tagColumns,
+		uuid.NewString(), domain.OrganizationFromContext(ctx), name, r.now().UTC().Format(timestampLayout),
+	))
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("failed to create tag: tag %q is already used", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// GetByID получает тег по ID
+func (r *TagRepository) GetByID(ctx context.Context, id string) (*domain.Tag, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	tag, err := scanTag(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+tagColumns+` FROM tags WHERE id = ? AND organization_id = ?`,
+		key, domain.OrganizationFromContext(ctx),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("tag not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// Rename меняет название тега; подписки ссылаются на тег по ID и сразу получают новое название
+func (r *TagRepository) Rename(ctx context.Context, id, name string) (*domain.Tag, error) {
+	key, err := parseUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	tag, err := scanTag(r.db.QueryRowContext(
+		ctx,
+		`UPDATE tags SET name = ? WHERE id = ? AND organization_id = ? RETURNING `+tagColumns,
+		name, key, domain.OrganizationFromContext(ctx),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("tag not found: %w", err)
+	}
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("failed to rename tag: tag %q is already used", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// Delete удаляет тег; связи с подписками удаляются каскадно
+func (r *TagRepository) Delete(ctx context.Context, id string) error {
+	key, err := parseUUID(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	res, err := r.db.ExecContext(
+		ctx,
+		`DELETE FROM tags WHERE id = ? AND organization_id = ?`,
+		key, domain.OrganizationFromContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// List возвращает теги организации по названию с числом подписок
+func (r *TagRepository) List(ctx context.Context) ([]*domain.Tag, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+tagColumns+` FROM tags WHERE organization_id = ? ORDER BY name`,
+		domain.OrganizationFromContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*domain.Tag
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// isUniqueViolation сообщает, что запись нарушила ограничение уникальности
+// Проверяется текст ошибки, чтобы не зависеть от типов ошибок драйвера напрямую
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,159 @@
+// Package sqljson кодирует вложенные значения подписки (паузы, прежние цены, запланированные изменения)
+// для JSON-колонок PostgreSQL и SQLite. Месяцы хранятся первыми числами в формате YYYY-MM-DD,
+// чтобы SQL-функции обеих баз читали их как даты
+package sqljson
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+const dateLayout = "2006-01-02"
+
+type pause struct {
+	StartMonth string  `json:"start_month"`
+	EndMonth   *string `json:"end_month"`
+}
+
+type pricePeriod struct {
+	UntilMonth string `json:"until_month"`
+	Price      int64  `json:"price"`
+}
+
+type change struct {
+	EffectiveMonth string  `json:"effective_month"`
+	Price          *int64  `json:"price"`
+	Plan           *string `json:"plan"`
+	Cancel         bool    `json:"cancel"`
+}
+
+// EncodePauses кодирует паузы в порядке начала
+func EncodePauses(pauses []domain.Pause) ([]byte, error) {
+	sorted := append([]domain.Pause(nil), pauses...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	records := make([]pause, 0, len(sorted))
+	for _, p := range sorted {
+		rec := pause{StartMonth: formatMonth(p.Start)}
+		if p.End.Valid {
+			end := formatMonth(p.End.Time)
+			rec.EndMonth = &end
+		}
+		records = append(records, rec)
+	}
+	return json.Marshal(records)
+}
+
+// DecodePauses разбирает паузы; пустой массив дает nil
+func DecodePauses(data []byte) ([]domain.Pause, error) {
+	var records []pause
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid pauses: %w", err)
+	}
+
+	var pauses []domain.Pause
+	for _, rec := range records {
+		start, err := parseMonth(rec.StartMonth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pause start: %w", err)
+		}
+		p := domain.Pause{Start: start}
+		if rec.EndMonth != nil {
+			end, err := parseMonth(*rec.EndMonth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pause end: %w", err)
+			}
+			p.End = sql.NullTime{Time: end, Valid: true}
+		}
+		pauses = append(pauses, p)
+	}
+	return pauses, nil
+}
+
+// EncodePriceHistory кодирует прежние цены в порядке месяцев
+func EncodePriceHistory(history []domain.PricePeriod) ([]byte, error) {
+	records := make([]pricePeriod, 0, len(history))
+	for _, p := range history {
+		records = append(records, pricePeriod{UntilMonth: formatMonth(p.Until), Price: p.Price})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].UntilMonth < records[j].UntilMonth })
+	return json.Marshal(records)
+}
+
+// DecodePriceHistory разбирает прежние цены; пустой массив дает nil
+func DecodePriceHistory(data []byte) ([]domain.PricePeriod, error) {
+	var records []pricePeriod
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid price history: %w", err)
+	}
+
+	var history []domain.PricePeriod
+	for _, rec := range records {
+		until, err := parseMonth(rec.UntilMonth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price history: %w", err)
+		}
+		history = append(history, domain.PricePeriod{Until: until, Price: rec.Price})
+	}
+	return history, nil
+}
+
+// EncodeChanges кодирует запланированные изменения в порядке месяцев
+func EncodeChanges(changes []domain.ScheduledChange) ([]byte, error) {
+	records := make([]change, 0, len(changes))
+	for _, c := range changes {
+		rec := change{EffectiveMonth: formatMonth(c.EffectiveFrom), Cancel: c.Cancel}
+		if c.Price.Valid {
+			rec.Price = &c.Price.Int64
+		}
+		if c.Plan.Valid {
+			rec.Plan = &c.Plan.String
+		}
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].EffectiveMonth < records[j].EffectiveMonth })
+	return json.Marshal(records)
+}
+
+// DecodeChanges разбирает запланированные изменения; пустой массив дает nil
+func DecodeChanges(data []byte) ([]domain.ScheduledChange, error) {
+	var records []change
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid scheduled changes: %w", err)
+	}
+
+	var changes []domain.ScheduledChange
+	for _, rec := range records {
+		from, err := parseMonth(rec.EffectiveMonth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduled change: %w", err)
+		}
+		c := domain.ScheduledChange{EffectiveFrom: from, Cancel: rec.Cancel}
+		if rec.Price != nil {
+			c.Price = sql.NullInt64{Int64: *rec.Price, Valid: true}
+		}
+		if rec.Plan != nil {
+			c.Plan = sql.NullString{String: *rec.Plan, Valid: true}
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// formatMonth первое число месяца t
+func formatMonth(t time.Time) string {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Format(dateLayout)
+}
+
+func parseMonth(s string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("month %q: %w", s, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"context"
+	"time"
+)
+
+// Observer пишет в лог запроса каждый вызов метода use case на уровне debug
+// Реализует usecase.Observer
+type Observer struct{}
+
+// NewObserver создает наблюдателя, пишущего вызовы use case в лог
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// Start реализует usecase.Observer
+func (o *Observer) Start(ctx context.Context, useCase, method string) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		logger := FromContext(ctx).With("usecase", useCase, "method", method, "duration", time.Since(start))
+		if err != nil {
+			logger.DebugContext(ctx, "Use case call failed", "error", err)
+			return
+		}
+		logger.DebugContext(ctx, "Use case call completed")
+	}
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsTimeout ограничивает время запроса бизнес-показателей при сборе метрик
+const statsTimeout = 5 * time.Second
+
+// StatsSource источник агрегированных показателей подписок
+// Интерфейс определен здесь, так как используется здесь (dependency rule)
+type StatsSource interface {
+	Stats(ctx context.Context, month time.Time) ([]domain.SubscriptionStats, error)
+}
+
+// BusinessCollector отдает число активных подписок и их стоимость за текущий месяц по организациям
+// Запрос к источнику обходит все организации, поэтому показатели обновляются по таймеру (Run),
+// а сбор метрик отдает последние полученные значения
+type BusinessCollector struct {
+	source StatsSource
+	now    func() time.Time
+
+	mu    sync.RWMutex
+	stats []domain.SubscriptionStats
+
+	activeCount  *prometheus.Desc
+	monthlySpend *prometheus.Desc
+	scrapeErrors prometheus.Counter
+}
+
+// NewBusinessCollector создает коллектор бизнес-показателей
+func NewBusinessCollector(source StatsSource) *BusinessCollector {
+	return &BusinessCollector{
+		source: source,
+		now:    time.Now,
+		activeCount: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "active"),
+			"Number of subscriptions active in the current month.",
+			[]string{"organization_id"}, nil,
+		),
+		monthlySpend: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "monthly_spend_rub"),
+			"Total monthly price of subscriptions active in the current month.",
+			[]string{"organization_id"}, nil,
+		),
+		scrapeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stats_scrape_errors_total",
+			Help:      "Number of failed attempts to read business statistics.",
+		}),
+	}
+}
+
+// Describe реализует prometheus.Collector
+func (c *BusinessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeCount
+	ch <- c.monthlySpend
+	c.scrapeErrors.Describe(ch)
+}
+
+// Run обновляет показатели сразу и затем каждые interval, пока не отменен ctx
+func (c *BusinessCollector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh запрашивает показатели за текущий месяц из источника
+// При ошибке остаются прежние значения, а счетчик ошибок увеличивается
+func (c *BusinessCollector) Refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, statsTimeout)
+	defer cancel()
+
+	now := c.now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	stats, err := c.source.Stats(ctx, month)
+	if err != nil {
+		slog.Error("Failed to collect subscription stats", "error", err)
+		c.scrapeErrors.Inc()
+		return
+	}
+
+	c.mu.Lock()
+	c.stats = stats
+	c.mu.Unlock()
+}
+
+// Collect реализует prometheus.Collector
+// Отдает показатели последнего обновления и не обращается к источнику
+func (c *BusinessCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	stats := c.stats
+	c.mu.RUnlock()
+
+	for _, s := range stats {
+		ch <- prometheus.MustNewConstMetric(c.activeCount, prometheus.GaugeValue, float64(s.ActiveCount), s.OrganizationID)
+		ch <- prometheus.MustNewConstMetric(c.monthlySpend, prometheus.GaugeValue, float64(s.MonthlySpend), s.OrganizationID)
+	}
+	c.scrapeErrors.Collect(ch)
+}
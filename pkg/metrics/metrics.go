@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace общий префикс метрик сервиса
+const namespace = "subscriptions"
+
+// Metrics хранит реестр и метрики сервиса
+// Реализует usecase.Observer для учета вызовов use case
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration    *prometheus.HistogramVec
+	useCaseDuration *prometheus.HistogramVec
+	useCaseErrors   *prometheus.CounterVec
+}
+
+// New создает реестр с метриками HTTP, use case, а также стандартными метриками Go и процесса
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		useCaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "usecase",
+			Name:      "duration_seconds",
+			Help:      "Duration of use case method calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"usecase", "method"}),
+		useCaseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "usecase",
+			Name:      "errors_total",
+			Help:      "Number of use case method calls that returned an error.",
+		}, []string{"usecase", "method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.useCaseDuration,
+		m.useCaseErrors,
+	)
+
+	return m
+}
+
+// Register добавляет дополнительные коллекторы (пул соединений, бизнес-показатели)
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler возвращает HTTP обработчик для отдачи метрик в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTP учитывает обработанный HTTP запрос
+// route - шаблон маршрута (например /subscriptions/:id), чтобы число серий не зависело от ID в пути
+func (m *Metrics) ObserveHTTP(method, route string, status int, duration time.Duration) {
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// Start реализует usecase.Observer: замеряет длительность вызова и учитывает ошибки
+func (m *Metrics) Start(ctx context.Context, useCase, method string) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		m.useCaseDuration.WithLabelValues(useCase, method).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.useCaseErrors.WithLabelValues(useCase, method).Inc()
+		}
+	}
+}
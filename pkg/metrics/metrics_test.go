@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubStats struct {
+	stats []domain.SubscriptionStats
+	err   error
+	month time.Time
+	calls int
+}
+
+func (s *stubStats) Stats(_ context.Context, month time.Time) ([]domain.SubscriptionStats, error) {
+	s.month = month
+	s.calls++
+	return s.stats, s.err
+}
+
+func TestMetrics_UseCaseObserver(t *testing.T) {
+	m := New()
+
+	_, end := m.Start(context.Background(), "subscriptions", "GetSubscription")
+	end(nil)
+	_, end = m.Start(context.Background(), "subscriptions", "GetSubscription")
+	end(errors.New("subscription not found"))
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.useCaseDuration))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.useCaseErrors.WithLabelValues("subscriptions", "GetSubscription")))
+}
+
+func TestMetrics_ObserveHTTP(t *testing.T) {
+	m := New()
+
+	m.ObserveHTTP("GET", "/subscriptions/:id", 200, 10*time.Millisecond)
+	m.ObserveHTTP("GET", "/subscriptions/:id", 404, 5*time.Millisecond)
+
+	// Отдельная серия на каждый статус, ID из пути в метки не попадает
+	count, err := testutil.GatherAndCount(m.registry, "subscriptions_http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestBusinessCollector(t *testing.T) {
+	source := &stubStats{stats: []domain.SubscriptionStats{
+		{OrganizationID: domain.DefaultOrganizationID, ActiveCount: 3, MonthlySpend: 1200},
+	}}
+	c := NewBusinessCollector(source)
+	c.now = func() time.Time { return time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC) }
+
+	expected := `
+# HELP subscriptions_active Number of subscriptions active in the current month.
+# TYPE subscriptions_active gauge
+subscriptions_active{organization_id="00000000-0000-0000-0000-000000000000"} 3
+# HELP subscriptions_monthly_spend_rub Total monthly price of subscriptions active in the current month.
+# TYPE subscriptions_monthly_spend_rub gauge
+subscriptions_monthly_spend_rub{organization_id="00000000-0000-0000-0000-000000000000"} 1200
+`
+	// До первого обновления бизнес-показателей нет
+	assert.Equal(t, 1, testutil.CollectAndCount(c))
+
+	c.Refresh(context.Background())
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), source.month)
+
+	// Сбор метрик отдает сохраненные значения и не обращается к источнику
+	for i := 0; i < 2; i++ {
+		require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "subscriptions_active", "subscriptions_monthly_spend_rub"))
+	}
+	assert.Equal(t, 1, source.calls)
+
+	// Ошибка источника оставляет прежние значения
+	source.err = errors.New("connection refused")
+	source.stats = nil
+	c.Refresh(context.Background())
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "subscriptions_active", "subscriptions_monthly_spend_rub"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.scrapeErrors))
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
 	"github.com/stretchr/testify/mock"
@@ -40,6 +41,35 @@ func (m *SubscriptionRepository) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *SubscriptionRepository) UpdatePauses(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.Pause, error)) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *SubscriptionRepository) UpdateChanges(ctx context.Context, id string, update func(*domain.Subscription) ([]domain.ScheduledChange, error)) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *SubscriptionRepository) SetTags(ctx context.Context, id string, tags []string) (*domain.Subscription, error) {
+	args := m.Called(ctx, id, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *SubscriptionRepository) DeleteMany(ctx context.Context, filters domain.DeleteFilters) (int64, error) {
+	args := m.Called(ctx, filters)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *SubscriptionRepository) List(ctx context.Context, filters domain.ListFilters) ([]*domain.Subscription, error) {
 	args := m.Called(ctx, filters)
 	if args.Get(0) == nil {
@@ -52,3 +82,26 @@ func (m *SubscriptionRepository) GetSummary(ctx context.Context, filters domain.
 	args := m.Called(ctx, filters)
 	return args.Get(0).(int64), args.Error(1)
 }
+
+func (m *SubscriptionRepository) GetGroupedSummary(ctx context.Context, filters domain.SummaryFilters, groupBy string) ([]domain.SummaryGroup, error) {
+	args := m.Called(ctx, filters, groupBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SummaryGroup), args.Error(1)
+}
+
+func (m *SubscriptionRepository) ExpireEnded(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *SubscriptionRepository) LinkService(ctx context.Context, svc *domain.Service) (int64, error) {
+	args := m.Called(ctx, svc)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *SubscriptionRepository) ApplyChanges(ctx context.Context, month time.Time) (int64, error) {
+	args := m.Called(ctx, month)
+	return args.Get(0).(int64), args.Error(1)
+}
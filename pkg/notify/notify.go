@@ -0,0 +1,108 @@
+// Package notify доставляет напоминания о подписках и уведомления о бюджетах по почте (SMTP) и на webhook
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+)
+
+// dateLayout формат дат в тексте напоминаний
+const dateLayout = "2006-01-02"
+
+// Notifier канал доставки напоминаний и уведомлений о бюджетах
+type Notifier interface {
+	Notify(ctx context.Context, r *domain.Reminder) error
+	NotifyBudget(ctx context.Context, a *domain.BudgetAlert) error
+}
+
+// Multi отправляет уведомление во все каналы
+// Уведомление считается доставленным, если его принял хотя бы один канал: повторная отправка
+// продублировала бы его в остальных. Ошибки отдельных каналов при этом только пишутся в лог
+type Multi []Notifier
+
+// Notify отправляет напоминание во все каналы
+func (m Multi) Notify(ctx context.Context, r *domain.Reminder) error {
+	return m.deliver(ctx, func(n Notifier) error { return n.Notify(ctx, r) }, "subscription_id", r.SubscriptionID)
+}
+
+// NotifyBudget отправляет уведомление о бюджете во все каналы
+func (m Multi) NotifyBudget(ctx context.Context, a *domain.BudgetAlert) error {
+	return m.deliver(ctx, func(n Notifier) error { return n.NotifyBudget(ctx, a) }, "budget_id", a.BudgetID)
+}
+
+// deliver отправляет уведомление через send в каждый канал; logArgs описывают уведомление в логе
+func (m Multi) deliver(ctx context.Context, send func(n Notifier) error, logArgs ...any) error {
+	var errs []error
+	delivered, skipped := 0, 0
+	for _, n := range m {
+		err := send(n)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, domain.ErrNoRecipient):
+			skipped++
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	switch {
+	case delivered > 0 && len(errs) > 0:
+		logging.FromContext(ctx).Warn("Notification channel failed", append(logArgs, "error", errors.Join(errs...))...)
+		return nil
+	case delivered > 0:
+		return nil
+	case len(errs) > 0:
+		return errors.Join(errs...)
+	case skipped > 0:
+		return domain.ErrNoRecipient
+	}
+	return nil
+}
+
+// subject тема напоминания
+func subject(r *domain.Reminder) string {
+	if r.Kind == domain.ReminderExpiry {
+		return fmt.Sprintf("%s ends on %s", r.ServiceName, lastDay(r).Format(dateLayout))
+	}
+	return fmt.Sprintf("%s renews on %s", r.ServiceName, r.Date.Format(dateLayout))
+}
+
+// text текст напоминания
+func text(r *domain.Reminder) string {
+	if r.Kind == domain.ReminderExpiry {
+		return fmt.Sprintf("Your %s subscription (%d RUB per month) ends on %s and will not be renewed.",
+			r.ServiceName, r.Price, lastDay(r).Format(dateLayout))
+	}
+	return fmt.Sprintf("Your %s subscription renews on %s: %d RUB will be charged for the month.",
+		r.ServiceName, r.Date.Format(dateLayout), r.Price)
+}
+
+// lastDay последний день действия заканчивающейся подписки
+func lastDay(r *domain.Reminder) time.Time {
+	return r.Date.AddDate(0, 0, -1)
+}
+
+// budgetSubject тема уведомления о бюджете
+func budgetSubject(a *domain.BudgetAlert) string {
+	return fmt.Sprintf("Budget %s reached %d%% for %s", a.Name, a.Threshold, budgetPeriod(a))
+}
+
+// budgetText текст уведомления о бюджете
+func budgetText(a *domain.BudgetAlert) string {
+	return fmt.Sprintf("Projected spend for %s is %d RUB, %d%% of the %s budget %s (%d RUB).",
+		budgetPeriod(a), a.Spent, a.Spent*100/a.Limit, a.Period, a.Name, a.Limit)
+}
+
+// budgetPeriod название периода бюджета: месяц или год
+func budgetPeriod(a *domain.BudgetAlert) string {
+	if a.Period == domain.BudgetYearly {
+		return a.PeriodStart.Format("2006")
+	}
+	return a.PeriodStart.Format("2006-01")
+}
@@ -0,0 +1,301 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reminder(kind string) *domain.Reminder {
+	return &domain.Reminder{
+		OrganizationID: domain.DefaultOrganizationID,
+		SubscriptionID: "0b7ee1a4-6d4f-4d0c-9d47-37a3b0c1a2b3",
+		UserID:         "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		ServiceName:    "Netflix",
+		Price:          400,
+		Kind:           kind,
+		Date:           time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Email:          "user@example.com",
+	}
+}
+
+func budgetAlert() *domain.BudgetAlert {
+	return &domain.BudgetAlert{
+		OrganizationID: domain.DefaultOrganizationID,
+		BudgetID:       "5d0c2a8e-1f4b-4a51-9d3e-8c7b6a5f4e3d",
+		Name:           "Streaming",
+		Period:         domain.BudgetMonthly,
+		PeriodStart:    time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Threshold:      80,
+		Limit:          1000,
+		Spent:          850,
+		ServiceName:    "Netflix",
+		Email:          "finance@example.com",
+	}
+}
+
+// smtpMessage письмо, принятое тестовым SMTP сервером
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer минимальный SMTP сервер без TLS и аутентификации
+// Получателей из reject отклоняет кодом 550
+type smtpServer struct {
+	addr   string
+	reject string
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func startSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	s := &smtpServer{addr: ln.Addr().String()}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	var msg smtpMessage
+	_ = tp.PrintfLine("220 localhost ESMTP test")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		// Адрес в MAIL FROM:<...> и RCPT TO:<...>; параметры после него отбрасываются
+		arg := func() string {
+			addr := line[strings.Index(line, "<")+1:]
+			return addr[:strings.Index(addr, ">")]
+		}
+
+		switch cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-localhost")
+			_ = tp.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			msg = smtpMessage{from: arg()}
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			if arg() == s.reject {
+				_ = tp.PrintfLine("550 mailbox unavailable")
+				continue
+			}
+			msg.to = append(msg.to, arg())
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := startSMTPServer(t)
+	server.reject = "gone@example.com"
+
+	n, err := NewSMTPNotifier(SMTPConfig{Addr: server.addr, From: "reminders@example.com"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, n.Notify(ctx, reminder(domain.ReminderRenewal)))
+	require.NoError(t, n.Notify(ctx, reminder(domain.ReminderExpiry)))
+
+	messages := server.received()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "reminders@example.com", messages[0].from)
+	assert.Equal(t, []string{"user@example.com"}, messages[0].to)
+	assert.Contains(t, messages[0].data, "Subject: Netflix renews on 2025-02-01\n")
+	assert.Contains(t, messages[0].data, "To: user@example.com\n")
+	assert.Contains(t, messages[0].data, "400 RUB will be charged")
+	assert.Contains(t, messages[1].data, "Subject: Netflix ends on 2025-01-31\n")
+
+	// Без адреса письмо не отправляется
+	noEmail := reminder(domain.ReminderRenewal)
+	noEmail.Email = ""
+	assert.ErrorIs(t, n.Notify(ctx, noEmail), domain.ErrNoRecipient)
+
+	rejected := reminder(domain.ReminderRenewal)
+	rejected.Email = "gone@example.com"
+	assert.ErrorContains(t, n.Notify(ctx, rejected), "RCPT TO")
+
+	assert.Len(t, server.received(), 2)
+
+	// Переводы строк в названии сервиса не превращаются в заголовки
+	injected := reminder(domain.ReminderRenewal)
+	injected.ServiceName = "Netflix\r\nBcc: victim@example.com"
+	require.NoError(t, n.Notify(ctx, injected))
+	headers, _, _ := strings.Cut(server.received()[2].data, "\n\n")
+	assert.NotContains(t, headers, "\nBcc:")
+
+	// Уведомление о бюджете уходит на адрес бюджета
+	require.NoError(t, n.NotifyBudget(ctx, budgetAlert()))
+	require.Len(t, server.received(), 4)
+	assert.Equal(t, []string{"finance@example.com"}, server.received()[3].to)
+	assert.Contains(t, server.received()[3].data, "Subject: Budget Streaming reached 80% for 2025-02\n")
+	assert.Contains(t, server.received()[3].data, "Projected spend for 2025-02 is 850 RUB, 85% of the monthly budget Streaming (1000 RUB).")
+
+	noBudgetEmail := budgetAlert()
+	noBudgetEmail.Email = ""
+	assert.ErrorIs(t, n.NotifyBudget(ctx, noBudgetEmail), domain.ErrNoRecipient)
+
+	_, err = NewSMTPNotifier(SMTPConfig{Addr: "localhost", From: "reminders@example.com"})
+	assert.Error(t, err, "address without port")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies [][]byte
+		status = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+		assert.Equal(t, "0b7ee1a4-6d4f-4d0c-9d47-37a3b0c1a2b3:renewal:2025-02-01", r.Header.Get(ReminderIDHeader))
+
+		mu.Lock()
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(WebhookConfig{URL: server.URL, Secret: "secret", Timeout: time.Second})
+
+	require.NoError(t, n.Notify(context.Background(), reminder(domain.ReminderRenewal)))
+	require.Len(t, bodies, 1)
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(bodies[0], &payload))
+	assert.Equal(t, WebhookPayload{
+		Event:          "subscription.reminder",
+		Kind:           domain.ReminderRenewal,
+		Date:           "2025-02-01",
+		Subject:        "Netflix renews on 2025-02-01",
+		Text:           "Your Netflix subscription renews on 2025-02-01: 400 RUB will be charged for the month.",
+		OrganizationID: domain.DefaultOrganizationID,
+		SubscriptionID: "0b7ee1a4-6d4f-4d0c-9d47-37a3b0c1a2b3",
+		UserID:         "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		ServiceName:    "Netflix",
+		Price:          400,
+		Email:          "user@example.com",
+	}, payload)
+
+	mu.Lock()
+	status = http.StatusBadGateway
+	mu.Unlock()
+	assert.ErrorContains(t, n.Notify(context.Background(), reminder(domain.ReminderRenewal)), "status 502")
+}
+
+func TestWebhookNotifier_Budget(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+		assert.Equal(t, "5d0c2a8e-1f4b-4a51-9d3e-8c7b6a5f4e3d:2025-02-01:80", r.Header.Get(BudgetAlertIDHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(WebhookConfig{URL: server.URL, Secret: "secret", Timeout: time.Second})
+	require.NoError(t, n.NotifyBudget(context.Background(), budgetAlert()))
+
+	var payload BudgetWebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, BudgetWebhookPayload{
+		Event:          "budget.threshold",
+		Subject:        "Budget Streaming reached 80% for 2025-02",
+		Text:           "Projected spend for 2025-02 is 850 RUB, 85% of the monthly budget Streaming (1000 RUB).",
+		OrganizationID: domain.DefaultOrganizationID,
+		BudgetID:       "5d0c2a8e-1f4b-4a51-9d3e-8c7b6a5f4e3d",
+		Name:           "Streaming",
+		Period:         domain.BudgetMonthly,
+		PeriodStart:    "2025-02-01",
+		Threshold:      80,
+		Limit:          1000,
+		Spent:          850,
+		ServiceName:    "Netflix",
+		Email:          "finance@example.com",
+	}, payload)
+}
+
+// notifierFunc канал доставки, возвращающий ошибку функции для любого уведомления
+type notifierFunc func() error
+
+func (f notifierFunc) Notify(context.Context, *domain.Reminder) error          { return f() }
+func (f notifierFunc) NotifyBudget(context.Context, *domain.BudgetAlert) error { return f() }
+
+func TestMulti(t *testing.T) {
+	ok := notifierFunc(func() error { return nil })
+	failing := notifierFunc(func() error { return errors.New("boom") })
+	noAddress := notifierFunc(func() error { return domain.ErrNoRecipient })
+
+	r := reminder(domain.ReminderRenewal)
+	ctx := context.Background()
+
+	assert.NoError(t, Multi{ok, failing}.Notify(ctx, r), "delivered through one channel")
+	assert.NoError(t, Multi{noAddress, ok}.Notify(ctx, r))
+	assert.ErrorContains(t, Multi{failing, noAddress}.Notify(ctx, r), "boom")
+	assert.ErrorIs(t, Multi{noAddress, noAddress}.Notify(ctx, r), domain.ErrNoRecipient)
+
+	a := budgetAlert()
+	assert.NoError(t, Multi{failing, ok}.NotifyBudget(ctx, a))
+	assert.ErrorIs(t, Multi{noAddress}.NotifyBudget(ctx, a), domain.ErrNoRecipient)
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// SMTPConfig параметры почтового сервера
+type SMTPConfig struct {
+	// Addr адрес сервера host:port
+	Addr string
+	// Username и Password включают аутентификацию PLAIN; net/smtp разрешает ее только по TLS или на localhost
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier отправляет напоминания письмами на адрес из настроек пользователя, а уведомления о бюджетах - на адрес бюджета
+type SMTPNotifier struct {
+	cfg  SMTPConfig
+	host string
+	now  func() time.Time
+}
+
+// NewSMTPNotifier создает канал доставки по почте
+func NewSMTPNotifier(cfg SMTPConfig) (*SMTPNotifier, error) {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp address %q: %w", cfg.Addr, err)
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid smtp sender %q: %w", cfg.From, err)
+	}
+	return &SMTPNotifier{cfg: cfg, host: host, now: time.Now}, nil
+}
+
+// Notify отправляет напоминание письмом; без адреса пользователя возвращает domain.ErrNoRecipient
+func (n *SMTPNotifier) Notify(ctx context.Context, r *domain.Reminder) error {
+	return n.send(ctx, r.Email, subject(r), text(r))
+}
+
+// NotifyBudget отправляет уведомление о бюджете письмом; без адреса бюджета возвращает domain.ErrNoRecipient
+func (n *SMTPNotifier) NotifyBudget(ctx context.Context, a *domain.BudgetAlert) error {
+	return n.send(ctx, a.Email, budgetSubject(a), budgetText(a))
+}
+
+// send отправляет письмо на адрес to
+// STARTTLS используется, если сервер его поддерживает
+func (n *SMTPNotifier) send(ctx context.Context, to, subject, text string) error {
+	if to == "" {
+		return domain.ErrNoRecipient
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.cfg.From); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(n.message(to, subject, text)); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// message собирает письмо в формате RFC 5322
+func (n *SMTPNotifier) message(to, subject, text string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", n.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(text)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
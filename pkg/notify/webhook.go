@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// Типы событий в теле запроса
+const (
+	webhookEvent       = "subscription.reminder"
+	budgetWebhookEvent = "budget.threshold"
+)
+
+// Заголовки запроса webhook
+const (
+	// SignatureHeader подпись тела: "sha256=" и HMAC-SHA256 в hex
+	SignatureHeader = "X-Signature-256"
+	// ReminderIDHeader постоянный идентификатор напоминания для отбрасывания повторов на стороне получателя
+	ReminderIDHeader = "X-Reminder-ID"
+	// BudgetAlertIDHeader постоянный идентификатор уведомления о бюджете: бюджет, период и порог
+	BudgetAlertIDHeader = "X-Budget-Alert-ID"
+)
+
+// WebhookConfig параметры webhook
+type WebhookConfig struct {
+	URL string
+	// Secret ключ подписи тела; пустой - запросы не подписываются
+	Secret  string
+	Timeout time.Duration
+}
+
+// WebhookPayload тело запроса webhook
+type WebhookPayload struct {
+	Event          string `json:"event"`
+	Kind           string `json:"kind"`
+	Date           string `json:"date"`
+	Subject        string `json:"subject"`
+	Text           string `json:"text"`
+	OrganizationID string `json:"organization_id"`
+	SubscriptionID string `json:"subscription_id"`
+	UserID         string `json:"user_id"`
+	ServiceName    string `json:"service_name"`
+	Price          int64  `json:"price"`
+	Email          string `json:"email,omitempty"`
+}
+
+// BudgetWebhookPayload тело запроса webhook с уведомлением о бюджете
+type BudgetWebhookPayload struct {
+	Event          string `json:"event"`
+	Subject        string `json:"subject"`
+	Text           string `json:"text"`
+	OrganizationID string `json:"organization_id"`
+	BudgetID       string `json:"budget_id"`
+	Name           string `json:"name"`
+	Period         string `json:"period"`
+	PeriodStart    string `json:"period_start"`
+	Threshold      int    `json:"threshold"`
+	Limit          int64  `json:"limit"`
+	Spent          int64  `json:"spent"`
+	UserID         string `json:"user_id,omitempty"`
+	ServiceName    string `json:"service_name,omitempty"`
+	Email          string `json:"email,omitempty"`
+}
+
+// WebhookNotifier отправляет напоминания и уведомления о бюджетах POST запросом с JSON телом
+type WebhookNotifier struct {
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookNotifier создает канал доставки на webhook
+func NewWebhookNotifier(cfg WebhookConfig) *WebhookNotifier {
+	return &WebhookNotifier{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Notify отправляет напоминание; ответ вне диапазона 2xx считается ошибкой
+func (n *WebhookNotifier) Notify(ctx context.Context, r *domain.Reminder) error {
+	id := fmt.Sprintf("%s:%s:%s", r.SubscriptionID, r.Kind, r.Date.Format(dateLayout))
+	return n.post(ctx, ReminderIDHeader, id, WebhookPayload{
+		Event:          webhookEvent,
+		Kind:           r.Kind,
+		Date:           r.Date.Format(dateLayout),
+		Subject:        subject(r),
+		Text:           text(r),
+		OrganizationID: r.OrganizationID,
+		SubscriptionID: r.SubscriptionID,
+		UserID:         r.UserID,
+		ServiceName:    r.ServiceName,
+		Price:          r.Price,
+		Email:          r.Email,
+	})
+}
+
+// NotifyBudget отправляет уведомление о бюджете; ответ вне диапазона 2xx считается ошибкой
+func (n *WebhookNotifier) NotifyBudget(ctx context.Context, a *domain.BudgetAlert) error {
+	id := fmt.Sprintf("%s:%s:%d", a.BudgetID, a.PeriodStart.Format(dateLayout), a.Threshold)
+	return n.post(ctx, BudgetAlertIDHeader, id, BudgetWebhookPayload{
+		Event:          budgetWebhookEvent,
+		Subject:        budgetSubject(a),
+		Text:           budgetText(a),
+		OrganizationID: a.OrganizationID,
+		BudgetID:       a.BudgetID,
+		Name:           a.Name,
+		Period:         a.Period,
+		PeriodStart:    a.PeriodStart.Format(dateLayout),
+		Threshold:      a.Threshold,
+		Limit:          a.Limit,
+		Spent:          a.Spent,
+		UserID:         a.UserID,
+		ServiceName:    a.ServiceName,
+		Email:          a.Email,
+	})
+}
+
+// post отправляет payload в JSON; idHeader и id позволяют получателю отбрасывать повторы
+func (n *WebhookNotifier) post(ctx context.Context, idHeader, id string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idHeader, id)
+	if n.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.cfg.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign возвращает значение заголовка SignatureHeader для тела body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit описывает квоту корзины токенов: скорость пополнения и емкость
+type Limit struct {
+	// Rate количество токенов, добавляемых в секунду
+	Rate float64
+	// Burst емкость корзины, то есть максимальное число запросов подряд
+	Burst int
+}
+
+// Zero сообщает, что квота не задана и ограничение не применяется
+func (l Limit) Zero() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// ParseLimit разбирает квоту вида "100/m" или "10/s:20" (после двоеточия - емкость корзины)
+// Допустимые единицы: s, m, h
+func ParseLimit(input string) (Limit, error) {
+	spec, burstStr, hasBurst := strings.Cut(strings.TrimSpace(input), ":")
+	countStr, unit, found := strings.Cut(spec, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected N/s, N/m or N/h", input)
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit count in %q", input)
+	}
+
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit unit in %q, expected s, m or h", input)
+	}
+
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(burstStr)
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit burst in %q", input)
+		}
+	}
+
+	return Limit{Rate: float64(count) / per.Seconds(), Burst: burst}, nil
+}
+
+// ParseRoutes разбирает квоты маршрутов вида "GET /subscriptions/summary=10/m; POST /subscriptions/=30/m"
+func ParseRoutes(input string) (map[string]Limit, error) {
+	routes := make(map[string]Limit)
+	for _, entry := range strings.Split(input, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, spec, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid route rate limit %q, expected \"METHOD /path=N/unit\"", entry)
+		}
+
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.Join(strings.Fields(route), " ")] = limit
+	}
+	return routes, nil
+}
+
+// Result описывает решение по одному запросу
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter время до полного восстановления корзины
+	ResetAfter time.Duration
+	// RetryAfter время до появления следующего токена, если запрос отклонен
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние корзин токенов
+// Реализация по умолчанию - MemoryStore; для нескольких реплик нужна реализация поверх общего хранилища
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Config задает квоты: общую и для отдельных маршрутов
+type Config struct {
+	Default Limit
+	// Routes квоты по маршрутам вида "GET /subscriptions/summary"
+	Routes map[string]Limit
+	// PerIP общая квота клиентского IP по всем маршрутам; проверяется до аутентификации,
+	// чтобы запросы без учетных данных и перебор API ключей тоже расходовали квоту
+	PerIP Limit
+}
+
+// Limiter применяет квоты маршрутов к вызывающим
+type Limiter struct {
+	store  Store
+	config Config
+}
+
+// NewLimiter создает ограничитель поверх хранилища корзин
+func NewLimiter(store Store, config Config) *Limiter {
+	return &Limiter{store: store, config: config}
+}
+
+// LimitFor возвращает квоту маршрута
+func (l *Limiter) LimitFor(route string) Limit {
+	if limit, ok := l.config.Routes[route]; ok {
+		return limit
+	}
+	return l.config.Default
+}
+
+// Allow расходует токен вызывающего caller на маршруте route
+// Второе значение false означает, что для маршрута квота не задана
+func (l *Limiter) Allow(ctx context.Context, route, caller string) (Result, bool, error) {
+	limit := l.LimitFor(route)
+	if limit.Zero() {
+		return Result{}, false, nil
+	}
+
+	res, err := l.store.Take(ctx, route+"|"+caller, limit)
+	return res, true, err
+}
+
+// AllowIP расходует токен общей квоты клиентского IP
+// Второе значение false означает, что квота по IP не задана
+func (l *Limiter) AllowIP(ctx context.Context, ip string) (Result, bool, error) {
+	if l.config.PerIP.Zero() {
+		return Result{}, false, nil
+	}
+
+	res, err := l.store.Take(ctx, "ip|"+ip, l.config.PerIP)
+	return res, true, err
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limit := Limit{Rate: 1, Burst: 2}
+
+	res, err := store.Take(context.Background(), "k", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+
+	res, _ = store.Take(context.Background(), "k", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, _ = store.Take(context.Background(), "k", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 2*time.Second, res.ResetAfter)
+
+	// Другой ключ расходует свою корзину
+	res, _ = store.Take(context.Background(), "other", limit)
+	assert.True(t, res.Allowed)
+
+	// Через секунду появляется один токен
+	now = now.Add(time.Second)
+	res, _ = store.Take(context.Background(), "k", limit)
+	assert.True(t, res.Allowed)
+	res, _ = store.Take(context.Background(), "k", limit)
+	assert.False(t, res.Allowed)
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Limit
+		wantErr bool
+	}{
+		{"10/s", Limit{Rate: 10, Burst: 10}, false},
+		{"120/m", Limit{Rate: 2, Burst: 120}, false},
+		{"60/m:5", Limit{Rate: 1, Burst: 5}, false},
+		{"3600/h", Limit{Rate: 1, Burst: 3600}, false},
+		{"10", Limit{}, true},
+		{"10/d", Limit{}, true},
+		{"0/s", Limit{}, true},
+		{"10/s:x", Limit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLimit(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("GET  /subscriptions/summary=60/m; POST /subscriptions/=1/s:5;")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Limit{
+		"GET /subscriptions/summary": {Rate: 1, Burst: 60},
+		"POST /subscriptions/":       {Rate: 1, Burst: 5},
+	}, routes)
+
+	_, err = ParseRoutes("GET /subscriptions")
+	assert.Error(t, err)
+}
@@ -0,0 +1,415 @@
+// Package scheduler запускает фоновые задачи по расписанию cron
+//
+// Задачи по расписанию выполняет только экземпляр, удерживающий блокировку лидера; остальные экземпляры
+// лишь пересчитывают время следующего запуска. Каждая задача на время выполнения берет собственную блокировку,
+// поэтому ручной запуск на любом экземпляре не пересекается с запуском по расписанию.
+// Каждый запуск записывается в историю
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+)
+
+// leaderLock имя блокировки лидера; блокировки задач называются "job:<имя задачи>"
+const leaderLock = "scheduler:leader"
+
+// ErrStopped возвращается при запуске задачи после остановки планировщика
+var ErrStopped = errors.New("scheduler is stopped")
+
+// Job фоновая задача
+type Job struct {
+	Name string
+	// Schedule расписание cron из пяти полей ("30 3 * * *") или дескриптор (@daily, @every 1h)
+	Schedule    string
+	Description string
+	Run         func(ctx context.Context) error
+}
+
+// JobInfo описание задачи и ее состояние на этом экземпляре
+type JobInfo struct {
+	Name        string
+	Schedule    string
+	Description string
+	// Next время следующего запуска по расписанию
+	Next time.Time
+	// Running задача выполняется на этом экземпляре
+	Running bool
+}
+
+// Config параметры планировщика
+type Config struct {
+	// Instance имя экземпляра в истории запусков; по умолчанию hostname-pid
+	Instance string
+	// PollInterval как часто проверяются расписания и блокировка лидера
+	PollInterval time.Duration
+}
+
+// ParseSchedule разбирает расписание задачи
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// Scheduler планировщик фоновых задач
+type Scheduler struct {
+	locker   domain.Locker
+	history  domain.JobRunRepository
+	instance string
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	jobs     []*entry
+	leader   domain.Lock
+	started  bool
+	stopping bool
+
+	// runCtx контекст выполнения задач; отменяется, если задачи не успели завершиться при остановке
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	running    sync.WaitGroup
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+	next     time.Time
+	running  bool
+}
+
+// New создает планировщик; задачи добавляются через Add до вызова Start
+func New(locker domain.Locker, history domain.JobRunRepository, cfg Config) *Scheduler {
+	instance := cfg.Instance
+	if instance == "" {
+		host, _ := os.Hostname()
+		instance = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		locker:     locker,
+		history:    history,
+		instance:   instance,
+		interval:   interval,
+		now:        time.Now,
+		runCtx:     runCtx,
+		cancelRuns: cancel,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Add регистрирует задачу
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job name and run function are required")
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %q: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.jobs {
+		if e.job.Name == job.Name {
+			return fmt.Errorf("job %q is already registered", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &entry{job: job, schedule: schedule, next: schedule.Next(s.now())})
+	return nil
+}
+
+// Start запускает проверку расписаний в фоне
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started || s.stopping {
+		return
+	}
+	s.started = true
+	go s.loop()
+}
+
+// Stop прекращает запуск новых задач и ждет завершения выполняющихся
+// Если ctx истекает раньше, контекст задач отменяется и возвращается ошибка
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopping = true
+	started := s.started
+	s.mu.Unlock()
+
+	if started {
+		close(s.stop)
+		<-s.done
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(finished)
+	}()
+
+	var err error
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		err = fmt.Errorf("jobs did not finish before shutdown: %w", ctx.Err())
+	}
+	s.cancelRuns()
+
+	s.mu.Lock()
+	if s.leader != nil {
+		s.leader.Unlock()
+		s.leader = nil
+	}
+	s.mu.Unlock()
+
+	return err
+}
+
+// Trigger запускает задачу вне расписания и возвращает запись о запуске; задача выполняется в фоне
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*domain.JobRun, error) {
+	s.mu.Lock()
+	e := s.find(name)
+	s.mu.Unlock()
+
+	if e == nil {
+		return nil, fmt.Errorf("job %q not found", name)
+	}
+	return s.start(ctx, e, domain.JobTriggerManual)
+}
+
+// Jobs возвращает зарегистрированные задачи в порядке имен
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		infos = append(infos, JobInfo{
+			Name:        e.job.Name,
+			Schedule:    e.job.Schedule,
+			Description: e.job.Description,
+			Next:        e.next,
+			Running:     e.running,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Leader сообщает, удерживает ли экземпляр блокировку лидера
+func (s *Scheduler) Leader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader != nil
+}
+
+// Instance возвращает имя экземпляра в истории запусков
+func (s *Scheduler) Instance() string {
+	return s.instance
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.tick()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
+
+// tick запускает задачи, время которых наступило, если экземпляр является лидером
+func (s *Scheduler) tick() {
+	leader := s.ensureLeader(s.runCtx)
+	now := s.now()
+
+	s.mu.Lock()
+	var due []*entry
+	for _, e := range s.jobs {
+		if now.Before(e.next) {
+			continue
+		}
+		if leader {
+			due = append(due, e)
+		}
+		e.next = e.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for _, e := range due {
+		if _, err := s.start(s.runCtx, e, domain.JobTriggerSchedule); err != nil {
+			slog.Warn("Scheduled job skipped", "job", e.job.Name, "error", err)
+		}
+	}
+}
+
+// ensureLeader проверяет удерживаемую блокировку лидера или пытается ее взять
+func (s *Scheduler) ensureLeader(ctx context.Context) bool {
+	s.mu.Lock()
+	lock := s.leader
+	s.mu.Unlock()
+
+	if lock != nil {
+		err := lock.Check(ctx)
+		if err == nil {
+			return true
+		}
+		slog.Warn("Scheduler leadership lost", "instance", s.instance, "error", err)
+		lock.Unlock()
+	}
+
+	lock, err := s.locker.TryLock(ctx, leaderLock)
+	if err != nil {
+		slog.Warn("Failed to acquire scheduler leader lock", "error", err)
+	}
+	if lock != nil {
+		slog.Info("Scheduler leadership acquired", "instance", s.instance)
+	}
+
+	s.mu.Lock()
+	s.leader = lock
+	s.mu.Unlock()
+
+	return lock != nil
+}
+
+// start берет блокировку задачи, записывает запуск в историю и выполняет задачу в фоне
+func (s *Scheduler) start(ctx context.Context, e *entry, trigger string) (*domain.JobRun, error) {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return nil, ErrStopped
+	}
+	if e.running {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("job %q is already running", e.job.Name)
+	}
+	e.running = true
+	s.running.Add(1)
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		e.running = false
+		s.mu.Unlock()
+		s.running.Done()
+	}
+
+	lock, err := s.locker.TryLock(ctx, "job:"+e.job.Name)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("failed to lock job %q: %w", e.job.Name, err)
+	}
+	if lock == nil {
+		release()
+		return nil, fmt.Errorf("job %q is already running on another instance", e.job.Name)
+	}
+
+	run, err := s.history.Create(ctx, &domain.JobRun{
+		Job:       e.job.Name,
+		Trigger:   trigger,
+		Status:    domain.JobRunRunning,
+		Instance:  s.instance,
+		StartedAt: s.now(),
+	})
+	if err != nil {
+		lock.Unlock()
+		release()
+		return nil, fmt.Errorf("failed to record job run: %w", err)
+	}
+	started := *run
+
+	go func() {
+		defer release()
+		defer lock.Unlock()
+		s.execute(e.job, run)
+	}()
+
+	return &started, nil
+}
+
+// execute выполняет задачу и сохраняет результат запуска
+func (s *Scheduler) execute(job Job, run *domain.JobRun) {
+	logger := slog.Default().With("job", job.Name, "run_id", run.ID, "trigger", run.Trigger)
+	ctx := logging.WithLogger(s.runCtx, logger)
+
+	logger.Info("Job started")
+	err := runJob(ctx, job)
+
+	run.FinishedAt = sql.NullTime{Time: s.now(), Valid: true}
+	run.Status = domain.JobRunSucceeded
+	if err != nil {
+		run.Status = domain.JobRunFailed
+		run.Error = err.Error()
+	}
+	duration := run.FinishedAt.Time.Sub(run.StartedAt)
+
+	// Результат сохраняется и после отмены контекста задач
+	if err := s.history.Finish(context.WithoutCancel(ctx), run); err != nil {
+		logger.Error("Failed to record job result", "error", err)
+	}
+
+	if run.Status == domain.JobRunFailed {
+		logger.Error("Job failed", "duration", duration, "error", err)
+		return
+	}
+	logger.Info("Job finished", "duration", duration)
+}
+
+// runJob выполняет задачу, превращая панику в ошибку
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// find ищет задачу по имени; вызывается под блокировкой
+func (s *Scheduler) find(name string) *entry {
+	for _, e := range s.jobs {
+		if e.job.Name == name {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock управляемое время планировщика
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestScheduler(t *testing.T, locker domain.Locker, history domain.JobRunRepository, instance string) (*Scheduler, *clock) {
+	t.Helper()
+
+	c := &clock{now: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	s := New(locker, history, Config{Instance: instance})
+	s.now = c.Now
+	t.Cleanup(func() { _ = s.Stop(context.Background()) })
+	return s, c
+}
+
+// waitRuns ждет, пока в истории не останется незавершенных запусков, и возвращает ее
+func waitRuns(t *testing.T, history domain.JobRunRepository, job string) []*domain.JobRun {
+	t.Helper()
+
+	var runs []*domain.JobRun
+	require.Eventually(t, func() bool {
+		var err error
+		runs, err = history.List(context.Background(), domain.JobRunFilters{Job: job})
+		require.NoError(t, err)
+		for _, run := range runs {
+			if run.Status == domain.JobRunRunning {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 5*time.Millisecond)
+	return runs
+}
+
+func TestScheduler_RunsOnSchedule(t *testing.T) {
+	history := memory.NewJobRunRepository()
+	s, c := newTestScheduler(t, memory.NewLocker(), history, "a")
+
+	var calls int
+	var mu sync.Mutex
+	require.NoError(t, s.Add(Job{Name: "count", Schedule: "@every 1m", Run: func(context.Context) error {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return nil
+	}}))
+
+	s.tick()
+	assert.True(t, s.Leader())
+	assert.Empty(t, waitRuns(t, history, "count"), "job must not run before its time")
+
+	c.Add(time.Minute)
+	s.tick()
+	runs := waitRuns(t, history, "count")
+	require.Len(t, runs, 1)
+	assert.Equal(t, domain.JobRunSucceeded, runs[0].Status)
+	assert.Equal(t, domain.JobTriggerSchedule, runs[0].Trigger)
+	assert.Equal(t, "a", runs[0].Instance)
+	assert.True(t, runs[0].FinishedAt.Valid)
+
+	// Пропущенные запуски не наверстываются
+	c.Add(10 * time.Minute)
+	s.tick()
+	assert.Len(t, waitRuns(t, history, "count"), 2)
+
+	mu.Lock()
+	assert.Equal(t, 2, calls)
+	mu.Unlock()
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, c.Now().Add(time.Minute), jobs[0].Next)
+}
+
+func TestScheduler_OnlyLeaderRunsScheduledJobs(t *testing.T) {
+	locker := memory.NewLocker()
+	history := memory.NewJobRunRepository()
+	leader, leaderClock := newTestScheduler(t, locker, history, "leader")
+	follower, followerClock := newTestScheduler(t, locker, history, "follower")
+
+	for _, s := range []*Scheduler{leader, follower} {
+		require.NoError(t, s.Add(Job{Name: "job", Schedule: "@every 1m", Run: func(context.Context) error { return nil }}))
+	}
+
+	leader.tick()
+	follower.tick()
+	assert.True(t, leader.Leader())
+	assert.False(t, follower.Leader())
+
+	followerClock.Add(time.Minute)
+	follower.tick()
+	assert.Empty(t, waitRuns(t, history, "job"))
+
+	leaderClock.Add(time.Minute)
+	leader.tick()
+	runs := waitRuns(t, history, "job")
+	require.Len(t, runs, 1)
+	assert.Equal(t, "leader", runs[0].Instance)
+
+	// После остановки лидера блокировку берет другой экземпляр
+	require.NoError(t, leader.Stop(context.Background()))
+	follower.tick()
+	assert.True(t, follower.Leader())
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	history := memory.NewJobRunRepository()
+	s, _ := newTestScheduler(t, memory.NewLocker(), history, "a")
+
+	release := make(chan struct{})
+	require.NoError(t, s.Add(Job{Name: "slow", Schedule: "@daily", Run: func(context.Context) error {
+		<-release
+		return nil
+	}}))
+	require.NoError(t, s.Add(Job{Name: "failing", Schedule: "@daily", Run: func(context.Context) error {
+		return errors.New("boom")
+	}}))
+	require.NoError(t, s.Add(Job{Name: "panicking", Schedule: "@daily", Run: func(context.Context) error {
+		panic("oops")
+	}}))
+
+	run, err := s.Trigger(context.Background(), "slow")
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobRunRunning, run.Status)
+	assert.Equal(t, domain.JobTriggerManual, run.Trigger)
+
+	_, err = s.Trigger(context.Background(), "slow")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already running")
+	close(release)
+	assert.Len(t, waitRuns(t, history, "slow"), 1)
+
+	_, err = s.Trigger(context.Background(), "failing")
+	require.NoError(t, err)
+	runs := waitRuns(t, history, "failing")
+	require.Len(t, runs, 1)
+	assert.Equal(t, domain.JobRunFailed, runs[0].Status)
+	assert.Equal(t, "boom", runs[0].Error)
+
+	_, err = s.Trigger(context.Background(), "panicking")
+	require.NoError(t, err)
+	runs = waitRuns(t, history, "panicking")
+	require.Len(t, runs, 1)
+	assert.Equal(t, "panic: oops", runs[0].Error)
+
+	_, err = s.Trigger(context.Background(), "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestScheduler_JobLockIsShared(t *testing.T) {
+	locker := memory.NewLocker()
+	history := memory.NewJobRunRepository()
+	a, _ := newTestScheduler(t, locker, history, "a")
+	b, _ := newTestScheduler(t, locker, history, "b")
+
+	release := make(chan struct{})
+	defer close(release)
+	for _, s := range []*Scheduler{a, b} {
+		require.NoError(t, s.Add(Job{Name: "job", Schedule: "@daily", Run: func(context.Context) error {
+			<-release
+			return nil
+		}}))
+	}
+
+	_, err := a.Trigger(context.Background(), "job")
+	require.NoError(t, err)
+	_, err = b.Trigger(context.Background(), "job")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "another instance")
+}
+
+func TestScheduler_Stop(t *testing.T) {
+	t.Run("waits for running jobs", func(t *testing.T) {
+		history := memory.NewJobRunRepository()
+		s, _ := newTestScheduler(t, memory.NewLocker(), history, "a")
+
+		var finished bool
+		require.NoError(t, s.Add(Job{Name: "job", Schedule: "@daily", Run: func(context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			finished = true
+			return nil
+		}}))
+		s.Start()
+
+		_, err := s.Trigger(context.Background(), "job")
+		require.NoError(t, err)
+		require.NoError(t, s.Stop(context.Background()))
+		assert.True(t, finished)
+
+		_, err = s.Trigger(context.Background(), "job")
+		assert.ErrorIs(t, err, ErrStopped)
+	})
+
+	t.Run("cancels jobs after timeout", func(t *testing.T) {
+		history := memory.NewJobRunRepository()
+		s, _ := newTestScheduler(t, memory.NewLocker(), history, "a")
+
+		require.NoError(t, s.Add(Job{Name: "job", Schedule: "@daily", Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}))
+
+		_, err := s.Trigger(context.Background(), "job")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+
+		runs := waitRuns(t, history, "job")
+		require.Len(t, runs, 1)
+		assert.Equal(t, domain.JobRunFailed, runs[0].Status)
+		assert.Equal(t, context.Canceled.Error(), runs[0].Error)
+	})
+}
+
+func TestAdd(t *testing.T) {
+	s := New(memory.NewLocker(), memory.NewJobRunRepository(), Config{})
+	noop := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Add(Job{Name: "job", Schedule: "30 3 * * *", Run: noop}))
+	assert.Error(t, s.Add(Job{Name: "job", Schedule: "@daily", Run: noop}), "duplicate name")
+	assert.Error(t, s.Add(Job{Name: "other", Schedule: "every day", Run: noop}), "invalid schedule")
+	assert.Error(t, s.Add(Job{Name: "", Schedule: "@daily", Run: noop}), "missing name")
+}
@@ -0,0 +1,139 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Режимы проверки клиентских сертификатов
+const (
+	// ClientAuthNone клиентские сертификаты не запрашиваются
+	ClientAuthNone = "none"
+	// ClientAuthOptional сертификат проверяется, если клиент его предъявил
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire соединение без действительного клиентского сертификата отклоняется
+	ClientAuthRequire = "require"
+)
+
+// Config параметры TLS сервера
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile набор CA в PEM для проверки клиентских сертификатов (mTLS); пусто - mTLS выключен
+	ClientCAFile string
+	// ClientAuth режим проверки клиентских сертификатов: none, optional или require
+	ClientAuth string
+}
+
+// Reloader хранит сертификат сервера и CA клиентов и умеет перечитывать их с диска без перезапуска
+// Новые значения применяются к следующим TLS рукопожатиям; установленные соединения не разрываются
+type Reloader struct {
+	cfg Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// NewReloader загружает сертификат и CA клиентов
+func NewReloader(cfg Config) (*Reloader, error) {
+	if cfg.ClientAuth == "" {
+		cfg.ClientAuth = ClientAuthNone
+		if cfg.ClientCAFile != "" {
+			cfg.ClientAuth = ClientAuthOptional
+		}
+	}
+	if _, err := clientAuthType(cfg.ClientAuth); err != nil {
+		return nil, err
+	}
+	if cfg.ClientAuth != ClientAuthNone && cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("client CA file is required for client auth %q", cfg.ClientAuth)
+	}
+
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload перечитывает файлы сертификата, ключа и CA клиентов
+// При ошибке продолжают использоваться ранее загруженные значения
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pool, err = LoadCertPool(r.cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = pool
+
+	return nil
+}
+
+// MutualTLS сообщает, проверяются ли клиентские сертификаты
+func (r *Reloader) MutualTLS() bool {
+	return r.cfg.ClientAuth != ClientAuthNone
+}
+
+// TLSConfig возвращает конфигурацию для http.Server
+// Сертификат и CA клиентов берутся из Reloader на каждом рукопожатии
+func (r *Reloader) TLSConfig() *tls.Config {
+	clientAuth, _ := clientAuthType(r.cfg.ClientAuth)
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    r.clientCAs,
+				NextProtos:   []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+}
+
+// LoadCertPool читает набор сертификатов CA в формате PEM
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+func clientAuthType(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("unknown client auth mode %q, expected none, optional or require", mode)
+	}
+}
@@ -0,0 +1,193 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert сертификат с ключом, выпущенный тестовым CA
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func issue(t *testing.T, subject pkix.Name, parent *testCert, isCA bool, serial int64) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               subject,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{"localhost"},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	if keyFile == "" {
+		return
+	}
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// servedSerial возвращает серийный номер сертификата, который сервер отдаст на рукопожатии
+func servedSerial(t *testing.T, r *Reloader) int64 {
+	t.Helper()
+
+	cfg, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Len(t, cfg.Certificates, 1)
+	cert, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return cert.SerialNumber.Int64()
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	ca := issue(t, pkix.Name{CommonName: "test CA"}, nil, true, 1)
+	issue(t, pkix.Name{CommonName: "localhost"}, ca, false, 10).write(t, certFile, keyFile)
+
+	r, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	assert.False(t, r.MutualTLS())
+	assert.Equal(t, int64(10), servedSerial(t, r))
+
+	// Ротация сертификата подхватывается без перезапуска
+	issue(t, pkix.Name{CommonName: "localhost"}, ca, false, 11).write(t, certFile, keyFile)
+	require.NoError(t, r.Reload())
+	assert.Equal(t, int64(11), servedSerial(t, r))
+
+	// Некорректные файлы не заменяют рабочий сертификат
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	assert.Error(t, r.Reload())
+	assert.Equal(t, int64(11), servedSerial(t, r))
+}
+
+func TestNewReloader_Validation(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	issue(t, pkix.Name{CommonName: "localhost"}, nil, false, 1).write(t, certFile, keyFile)
+
+	_, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequire})
+	assert.Error(t, err)
+
+	_, err = NewReloader(Config{CertFile: certFile, KeyFile: keyFile, ClientAuth: "always"})
+	assert.Error(t, err)
+
+	_, err = NewReloader(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile + ".missing"})
+	assert.Error(t, err)
+}
+
+func TestReloader_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	ca := issue(t, pkix.Name{CommonName: "test CA"}, nil, true, 1)
+	ca.write(t, caFile, "")
+	issue(t, pkix.Name{CommonName: "localhost"}, ca, false, 2).write(t, certFile, keyFile)
+	client := issue(t, pkix.Name{CommonName: "billing"}, ca, false, 3)
+	stranger := issue(t, pkix.Name{CommonName: "stranger"}, issue(t, pkix.Name{CommonName: "other CA"}, nil, true, 4), false, 5)
+
+	newServer := func(mode string) *httptest.Server {
+		r, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: mode})
+		require.NoError(t, err)
+		require.True(t, r.MutualTLS())
+
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if len(req.TLS.VerifiedChains) == 0 {
+				_, _ = w.Write([]byte("anonymous"))
+				return
+			}
+			_, _ = w.Write([]byte(req.TLS.VerifiedChains[0][0].Subject.CommonName))
+		}))
+		srv.TLS = r.TLSConfig()
+		srv.StartTLS()
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(url string, cert *testCert) (string, error) {
+		cfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if cert != nil {
+			// Сертификат отправляется даже если сервер не указал его CA среди допустимых
+			cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				c := cert.tlsCertificate()
+				return &c, nil
+			}
+		}
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+		defer httpClient.CloseIdleConnections()
+
+		resp, err := httpClient.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		buf := make([]byte, 64)
+		n, _ := resp.Body.Read(buf)
+		return string(buf[:n]), nil
+	}
+
+	optional := newServer(ClientAuthOptional)
+	body, err := get(optional.URL, client)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", body)
+
+	body, err = get(optional.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "anonymous", body)
+
+	_, err = get(optional.URL, stranger)
+	assert.Error(t, err)
+
+	required := newServer(ClientAuthRequire)
+	_, err = get(required.URL, nil)
+	assert.Error(t, err)
+}
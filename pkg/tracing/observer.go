@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Observer создает дочерний спан на каждый вызов метода use case
+// Реализует usecase.Observer
+type Observer struct {
+	tracer trace.Tracer
+}
+
+// NewObserver создает наблюдателя, использующего глобальный TracerProvider
+func NewObserver() *Observer {
+	return &Observer{tracer: otel.Tracer(instrumentationName)}
+}
+
+// Start открывает спан "<use case>.<метод>" и возвращает контекст с ним,
+// чтобы запросы репозитория стали дочерними спанами вызова
+func (o *Observer) Start(ctx context.Context, useCase, method string) (context.Context, func(err error)) {
+	ctx, span := o.tracer.Start(ctx, useCase+"."+method,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("usecase.name", useCase),
+			attribute.String("usecase.method", method),
+		),
+	)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PgxTracer записывает каждый SQL запрос как клиентский спан
+// Реализует pgx.QueryTracer и подключается через pgx.ConnConfig.Tracer
+type PgxTracer struct {
+	tracer trace.Tracer
+}
+
+// Проверка, что PgxTracer реализует интерфейс pgx.QueryTracer
+var _ pgx.QueryTracer = (*PgxTracer)(nil)
+
+// NewPgxTracer создает трассировщик запросов, использующий глобальный TracerProvider
+func NewPgxTracer() *PgxTracer {
+	return &PgxTracer{tracer: otel.Tracer(instrumentationName)}
+}
+
+// TraceQueryStart открывает спан запроса
+// Аргументы запроса в спан не попадают, записывается только текст SQL
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := queryOperation(data.SQL)
+	ctx, _ = t.tracer.Start(ctx, "db."+strings.ToLower(operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd закрывает спан запроса и записывает число затронутых строк или ошибку
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+// queryOperation возвращает первое ключевое слово запроса (SELECT, INSERT и т.д.)
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
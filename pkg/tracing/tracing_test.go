@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestObserver(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	o := NewObserver()
+
+	ctx, end := o.Start(context.Background(), "subscriptions", "GetSubscriptionsSummary")
+	_, endChild := o.Start(ctx, "subscriptions", "GetSubscription")
+	endChild(errors.New("subscription not found"))
+	end(nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	child, parent := spans[0], spans[1]
+	assert.Equal(t, "subscriptions.GetSubscription", child.Name())
+	assert.Equal(t, codes.Error, child.Status().Code)
+	assert.Equal(t, parent.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, "subscriptions.GetSubscriptionsSummary", parent.Name())
+	assert.Equal(t, codes.Unset, parent.Status().Code)
+}
+
+func TestQueryOperation(t *testing.T) {
+	assert.Equal(t, "SELECT", queryOperation("\n\tselect id FROM subscriptions"))
+	assert.Equal(t, "INSERT", queryOperation("INSERT INTO subscriptions VALUES ($1)"))
+	assert.Equal(t, "QUERY", queryOperation("   "))
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// scopeUserFilter подменяет фильтр по user_id на идентификатор вызывающего,
+// если у него нет права all на данные всех пользователей
+func scopeUserFilter(ctx context.Context, policy *auth.Policy, own, all auth.Permission, userID string) (string, error) {
+	ownUserID, restricted, err := policy.ScopeUserID(ctx, own, all)
+	if err != nil {
+		return "", err
+	}
+	if restricted {
+		return ownUserID, nil
+	}
+	return userID, nil
+}
+
+// checkOwnership проверяет, что вызывающий имеет доступ к подписке
+func checkOwnership(ctx context.Context, policy *auth.Policy, own, all auth.Permission, sub *domain.Subscription) error {
+	ownUserID, restricted, err := policy.ScopeUserID(ctx, own, all)
+	if err != nil {
+		return err
+	}
+	if restricted && sub.UserID != ownUserID {
+		return fmt.Errorf("%w: subscription belongs to another user", auth.ErrForbidden)
+	}
+	return nil
+}
+
+// checkUserAccess проверяет, что вызывающий имеет доступ к данным пользователя userID
+func checkUserAccess(ctx context.Context, policy *auth.Policy, own, all auth.Permission, userID string) error {
+	ownUserID, restricted, err := policy.ScopeUserID(ctx, own, all)
+	if err != nil {
+		return err
+	}
+	if restricted && userID != ownUserID {
+		return fmt.Errorf("%w: settings belong to another user", auth.ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+)
+
+// apiKeyBytes длина случайной части API ключа
+const apiKeyBytes = 32
+
+// APIKeyUseCase содержит бизнес-логику управления API ключами
+// Также реализует auth.Authenticator для проверки ключей в запросах
+type APIKeyUseCase struct {
+	repo     domain.APIKeyRepository
+	policy   *auth.Policy
+	observer Observer
+	now      func() time.Time
+}
+
+// Проверка, что APIKeyUseCase реализует интерфейс auth.Authenticator
+var _ auth.Authenticator = (*APIKeyUseCase)(nil)
+
+// NewAPIKeyUseCase создает новый экземпляр use case для API ключей
+// Права вызывающих проверяются по policy; nil означает политику по умолчанию
+func NewAPIKeyUseCase(repo domain.APIKeyRepository, policy *auth.Policy, opts ...Option) *APIKeyUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &APIKeyUseCase{repo: repo, policy: policy, observer: o.observer, now: time.Now}
+}
+
+// apiKeysUseCase имя use case для наблюдателей
+const apiKeysUseCase = "api_keys"
+
+// CreateAPIKey создает новый ключ и возвращает его вместе с открытым значением
+// Открытое значение возвращается только один раз и нигде не сохраняется
+func (uc *APIKeyUseCase) CreateAPIKey(ctx context.Context, req CreateAPIKeyInput) (_ *domain.APIKey, _ string, err error) {
+	ctx, end := uc.observer.Start(ctx, apiKeysUseCase, "CreateAPIKey")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermAPIKeysManage); err != nil {
+		return nil, "", err
+	}
+
+	// Валидация бизнес-правил
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, "", fmt.Errorf("name is required")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, "", fmt.Errorf("scopes are required")
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains([]string{domain.ScopeRead, domain.ScopeWrite, domain.ScopeAdmin}, scope) {
+			return nil, "", fmt.Errorf("invalid scope %q", scope)
+		}
+		// Ключ не может получить права, которых нет у создающего его
+		for _, role := range scopeRoles([]string{scope}) {
+			if err := uc.policy.AuthorizeRole(ctx, role); err != nil {
+				return nil, "", fmt.Errorf("scope %q: %w", scope, err)
+			}
+		}
+	}
+
+	// Валидация и парсинг срока действия
+	expiresAt := sql.NullTime{Valid: false}
+	if req.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid expires_at format, expected RFC3339: %w", err)
+		}
+		if !t.After(uc.now()) {
+			return nil, "", fmt.Errorf("expires_at must be in the future")
+		}
+		expiresAt = sql.NullTime{Time: t, Valid: true}
+	}
+
+	// Генерация ключа
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plaintext := auth.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &domain.APIKey{
+		Name:      req.Name,
+		Prefix:    plaintext[:len(auth.APIKeyPrefix)+6],
+		Hash:      hashAPIKey(plaintext),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(req.Scopes))),
+		ExpiresAt: expiresAt,
+	}
+
+	created, err := uc.repo.Create(ctx, key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return created, plaintext, nil
+}
+
+// ListAPIKeys возвращает список всех ключей
+func (uc *APIKeyUseCase) ListAPIKeys(ctx context.Context) (_ []*domain.APIKey, err error) {
+	ctx, end := uc.observer.Start(ctx, apiKeysUseCase, "ListAPIKeys")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermAPIKeysManage); err != nil {
+		return nil, err
+	}
+
+	keys, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ
+func (uc *APIKeyUseCase) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	ctx, end := uc.observer.Start(ctx, apiKeysUseCase, "RevokeAPIKey")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermAPIKeysManage); err != nil {
+		return err
+	}
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+
+	if err := uc.repo.Revoke(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate проверяет API ключ и возвращает сервисного вызывающего
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, token string) (_ *auth.Principal, err error) {
+	ctx, end := uc.observer.Start(ctx, apiKeysUseCase, "Authenticate")
+	defer func() { end(err) }()
+
+	key, err := uc.repo.GetByHash(ctx, hashAPIKey(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrUnauthorized, err)
+	}
+
+	now := uc.now()
+	if !key.Active(now) {
+		return nil, fmt.Errorf("%w: api key is revoked or expired", auth.ErrUnauthorized)
+	}
+
+	// Ошибка обновления времени использования не должна блокировать запрос
+	if err := uc.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+		logging.FromContext(ctx).Warn("Failed to record api key usage", "id", key.ID, "error", err)
+	}
+
+	return &auth.Principal{
+		Subject:        "api-key:" + key.ID,
+		Roles:          scopeRoles(key.Scopes),
+		OrganizationID: key.OrganizationID,
+	}, nil
+}
+
+// scopeRoles сопоставляет области доступа ключа ролям политики
+func scopeRoles(scopes []string) []string {
+	roles := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		switch scope {
+		case domain.ScopeRead:
+			roles = append(roles, auth.RoleServiceReader)
+		case domain.ScopeWrite:
+			roles = append(roles, auth.RoleServiceWriter)
+		case domain.ScopeAdmin:
+			roles = append(roles, auth.RoleAdmin)
+		}
+	}
+	return roles
+}
+
+// hashAPIKey вычисляет хэш ключа для хранения и поиска
+// Ключ содержит 256 бит случайных данных, поэтому медленное хэширование не требуется
+func hashAPIKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// CreateAPIKeyInput представляет входные данные для создания API ключа
+type CreateAPIKeyInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt string
+}
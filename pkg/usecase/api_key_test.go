@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyUseCase_CreateAPIKey(t *testing.T) {
+	adminCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []string{auth.RoleAdmin}})
+
+	t.Run("returns plaintext key and stores only its hash", func(t *testing.T) {
+		mockRepo := &mocks.APIKeyRepository{}
+		useCase := NewAPIKeyUseCase(mockRepo, nil)
+
+		var stored *domain.APIKey
+		mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.APIKey")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.APIKey) }).
+			Return(&domain.APIKey{ID: "key-1"}, nil)
+
+		_, plaintext, err := useCase.CreateAPIKey(adminCtx, CreateAPIKeyInput{
+			Name:   "billing-export",
+			Scopes: []string{"write", "read", "read"},
+		})
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(plaintext, auth.APIKeyPrefix))
+		assert.Equal(t, hashAPIKey(plaintext), stored.Hash)
+		assert.Equal(t, []string{"read", "write"}, stored.Scopes)
+		assert.True(t, strings.HasPrefix(plaintext, stored.Prefix))
+	})
+
+	t.Run("non admin is forbidden", func(t *testing.T) {
+		mockRepo := &mocks.APIKeyRepository{}
+		useCase := NewAPIKeyUseCase(mockRepo, nil)
+		userCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "u", UserID: "u"})
+
+		_, _, err := useCase.CreateAPIKey(userCtx, CreateAPIKeyInput{Name: "x", Scopes: []string{"read"}})
+
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("scope beyond caller permissions is forbidden", func(t *testing.T) {
+		policy, err := auth.ParsePolicy([]byte(`
+roles:
+  key_manager: [api_keys:manage, subscriptions:read_all, summary:read_all, budgets:read_all, catalog:read, tags:read]
+  admin: ["*"]
+  service_reader: [subscriptions:read_all, summary:read_all, budgets:read_all, catalog:read, tags:read]
+  service_writer: [subscriptions:write_all]
+`))
+		require.NoError(t, err)
+		mockRepo := &mocks.APIKeyRepository{}
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(&domain.APIKey{ID: "key-1"}, nil)
+		useCase := NewAPIKeyUseCase(mockRepo, policy)
+		managerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "m", Roles: []string{"key_manager"}})
+
+		for _, scope := range []string{"admin", "write"} {
+			_, _, err = useCase.CreateAPIKey(managerCtx, CreateAPIKeyInput{Name: "x", Scopes: []string{"read", scope}})
+			assert.ErrorIs(t, err, auth.ErrForbidden, scope)
+		}
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+		_, _, err = useCase.CreateAPIKey(managerCtx, CreateAPIKeyInput{Name: "x", Scopes: []string{"read"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		useCase := NewAPIKeyUseCase(&mocks.APIKeyRepository{}, nil)
+
+		_, _, err := useCase.CreateAPIKey(adminCtx, CreateAPIKeyInput{Name: "x", Scopes: []string{"superuser"}})
+
+		assert.ErrorContains(t, err, "invalid scope")
+	})
+}
+
+func TestAPIKeyUseCase_Authenticate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	const token = auth.APIKeyPrefix + "secret"
+
+	tests := []struct {
+		name      string
+		key       *domain.APIKey
+		repoErr   error
+		wantErr   bool
+		wantAdmin bool
+		wantRoles []string
+	}{
+		{
+			name:      "active read key",
+			key:       &domain.APIKey{ID: "k1", Scopes: []string{"read"}},
+			wantRoles: []string{auth.RoleServiceReader},
+		},
+		{
+			name:      "admin key",
+			key:       &domain.APIKey{ID: "k2", Scopes: []string{"admin"}},
+			wantAdmin: true,
+			wantRoles: []string{auth.RoleAdmin},
+		},
+		{
+			name:    "revoked key",
+			key:     &domain.APIKey{ID: "k3", Scopes: []string{"read"}, RevokedAt: sql.NullTime{Time: now, Valid: true}},
+			wantErr: true,
+		},
+		{
+			name:    "expired key",
+			key:     &domain.APIKey{ID: "k4", Scopes: []string{"read"}, ExpiresAt: sql.NullTime{Time: now.Add(-time.Second), Valid: true}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			repoErr: errors.New("api key not found"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mocks.APIKeyRepository{}
+			useCase := NewAPIKeyUseCase(mockRepo, nil)
+			useCase.now = func() time.Time { return now }
+
+			if tt.repoErr != nil {
+				mockRepo.On("GetByHash", mock.Anything, hashAPIKey(token)).Return(nil, tt.repoErr)
+			} else {
+				mockRepo.On("GetByHash", mock.Anything, hashAPIKey(token)).Return(tt.key, nil)
+			}
+			if !tt.wantErr {
+				mockRepo.On("TouchLastUsed", mock.Anything, tt.key.ID, now).Return(nil)
+			}
+
+			principal, err := useCase.Authenticate(context.Background(), token)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, auth.ErrUnauthorized)
+				mockRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAdmin, principal.IsAdmin())
+			assert.Equal(t, tt.wantRoles, principal.Roles)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
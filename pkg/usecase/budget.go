@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+)
+
+// maxBudgetThreshold наибольший порог уведомления в процентах от лимита
+const maxBudgetThreshold = 1000
+
+// BudgetNotifier определяет канал доставки уведомлений о бюджетах
+// Возвращает domain.ErrNoRecipient, если уведомление некуда доставить
+type BudgetNotifier interface {
+	NotifyBudget(ctx context.Context, a *domain.BudgetAlert) error
+}
+
+// BudgetUseCase содержит логику бюджетов и проверки расходов по ним
+// Расходы считаются тем же репозиторием, что и сумма подписок
+type BudgetUseCase struct {
+	repo     domain.BudgetRepository
+	subs     domain.SubscriptionRepository
+	catalog  domain.ServiceRepository
+	notifier BudgetNotifier
+	policy   *auth.Policy
+	observer Observer
+	now      func() time.Time
+}
+
+// NewBudgetUseCase создает новый экземпляр use case для бюджетов
+// notifier может быть nil, если каналы доставки не настроены
+func NewBudgetUseCase(repo domain.BudgetRepository, subs domain.SubscriptionRepository, notifier BudgetNotifier, policy *auth.Policy, opts ...Option) *BudgetUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &BudgetUseCase{
+		repo:     repo,
+		subs:     subs,
+		catalog:  o.catalog,
+		notifier: notifier,
+		policy:   policy,
+		observer: o.observer,
+		now:      time.Now,
+	}
+}
+
+// budgetsUseCase имя use case для наблюдателей
+const budgetsUseCase = "budgets"
+
+// CreateBudget создает новый бюджет
+// Бюджет пользователя можно создать для себя, бюджет сервиса или организации требует права на бюджеты всех пользователей
+func (uc *BudgetUseCase) CreateBudget(ctx context.Context, req CreateBudgetInput) (_ *domain.Budget, err error) {
+	ctx, end := uc.observer.Start(ctx, budgetsUseCase, "CreateBudget")
+	defer func() { end(err) }()
+
+	budget, err := newBudget(req.Name, req.Period, req.Limit, req.ServiceName, req.Thresholds, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if budget.ServiceName, err = uc.canonicalName(ctx, budget.ServiceName); err != nil {
+		return nil, err
+	}
+	if req.UserID != "" {
+		parsed, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user_id %q", req.UserID)
+		}
+		budget.UserID = parsed.String()
+	}
+
+	if err := uc.authorize(ctx, auth.PermBudgetsWrite, auth.PermBudgetsWriteAll, budget); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.repo.Create(ctx, budget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetBudget получает бюджет по ID
+func (uc *BudgetUseCase) GetBudget(ctx context.Context, id string) (_ *domain.Budget, err error) {
+	ctx, end := uc.observer.Start(ctx, budgetsUseCase, "GetBudget")
+	defer func() { end(err) }()
+
+	return uc.get(ctx, id, auth.PermBudgetsRead, auth.PermBudgetsReadAll)
+}
+
+// UpdateBudget заменяет параметры бюджета; пользователь бюджета не меняется
+func (uc *BudgetUseCase) UpdateBudget(ctx context.Context, id string, req UpdateBudgetInput) (_ *domain.Budget, err error) {
+	ctx, end := uc.observer.Start(ctx, budgetsUseCase, "UpdateBudget")
+	defer func() { end(err) }()
+
+	budget, err := newBudget(req.Name, req.Period, req.Limit, req.ServiceName, req.Thresholds, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if budget.ServiceName, err = uc.canonicalName(ctx, budget.ServiceName); err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.get(ctx, id, auth.PermBudgetsWrite, auth.PermBudgetsWriteAll); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.repo.Update(ctx, id, budget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteBudget удаляет бюджет
+func (uc *BudgetUseCase) DeleteBudget(ctx context.Context, id string) (err error) {
+	ctx, end := uc.observer.Start(ctx, budgetsUseCase, "DeleteBudget")
+	defer func() { end(err) }()
+
+	if _, err := uc.get(ctx, id, auth.PermBudgetsWrite, auth.PermBudgetsWriteAll); err != nil {
+		return err
+	}
+
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	return nil
+}
+
+// ListBudgets возвращает бюджеты организации
+// Вызывающий без права на бюджеты всех пользователей видит только свои бюджеты
+func (uc *BudgetUseCase) ListBudgets(ctx context.Context, filters BudgetFiltersInput) (_ []*domain.Budget, err error) {
+	ctx, end := uc.observer.Start(ctx, budgetsUseCase, "ListBudgets")
+	defer func() { end(err) }()
+
+	userID, err := scopeUserFilter(ctx, uc.policy, auth.PermBudgetsRead, auth.PermBudgetsReadAll, filters.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := uc.repo.List(ctx, domain.BudgetFilters{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+
+	return budgets, nil
+}
+
+// GetBudgetStatus возвращает ожидаемые расходы за текущий период бюджета
+func (uc *BudgetUseCase) GetBudgetStatus(ctx context.Context, id string) (_ *domain.BudgetStatus, err error) {
+	ctx, end := uc.observer.Start(ctx, budgetsUseCase, "GetBudgetStatus")
+	defer func() { end(err) }()
+
+	budget, err := uc.get(ctx, id, auth.PermBudgetsRead, auth.PermBudgetsReadAll)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.status(ctx, budget)
+}
+
+// BudgetReport итог проверки бюджетов
+type BudgetReport struct {
+	Budgets int
+	Sent    int
+	Skipped int
+	Failed  int
+}
+
+// EvaluateBudgets проверяет бюджеты всех организаций и уведомляет о достигнутых порогах
+// О каждом пороге уведомляется один раз за период; если за один запуск достигнуто несколько порогов,
+// отправляется одно уведомление о наибольшем. При ошибке доставки или отсутствии адреса отметки снимаются
+// и уведомление повторяется при следующем запуске. Бюджет, который не удалось проверить, учитывается в Failed,
+// проверка остальных продолжается. Вызывается планировщиком, права не проверяются
+func (uc *BudgetUseCase) EvaluateBudgets(ctx context.Context) (_ *BudgetReport, err error) {
+	ctx, end := uc.observer.Start(ctx, budgetsUseCase, "EvaluateBudgets")
+	defer func() { end(err) }()
+
+	if uc.notifier == nil {
+		return nil, fmt.Errorf("no notification channels configured")
+	}
+
+	budgets, err := uc.repo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+
+	logger := logging.FromContext(ctx)
+	report := &BudgetReport{Budgets: len(budgets)}
+	for _, budget := range budgets {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		// Ошибка одного бюджета не останавливает проверку остальных
+		orgCtx := domain.WithOrganization(ctx, budget.OrganizationID)
+		status, err := uc.status(orgCtx, budget)
+		if err != nil {
+			report.Failed++
+			logger.Warn("Failed to evaluate budget", "budget_id", budget.ID, "error", err)
+			continue
+		}
+
+		alerts, err := uc.claimReached(orgCtx, status)
+		if err != nil {
+			report.Failed++
+			logger.Warn("Failed to evaluate budget", "budget_id", budget.ID, "error", err)
+			continue
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+
+		alert := alerts[len(alerts)-1]
+		err = uc.notifier.NotifyBudget(orgCtx, alert)
+		if err == nil {
+			report.Sent++
+			logger.Info("Budget alert sent", "budget_id", budget.ID, "threshold", alert.Threshold, "spent", alert.Spent, "limit", alert.Limit)
+			continue
+		}
+
+		uc.releaseAlerts(orgCtx, alerts)
+		if errors.Is(err, domain.ErrNoRecipient) {
+			report.Skipped++
+			continue
+		}
+		report.Failed++
+		logger.Warn("Failed to send budget alert", "budget_id", budget.ID, "threshold", alert.Threshold, "error", err)
+	}
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("failed to evaluate or send alerts for %d of %d budgets", report.Failed, report.Budgets)
+	}
+
+	return report, nil
+}
+
+// claimReached отмечает достигнутые пороги, о которых еще не уведомляли, и возвращает их по возрастанию
+func (uc *BudgetUseCase) claimReached(ctx context.Context, status *domain.BudgetStatus) ([]*domain.BudgetAlert, error) {
+	budget := status.Budget
+
+	var alerts []*domain.BudgetAlert
+	for _, threshold := range status.Reached() {
+		alert := &domain.BudgetAlert{
+			OrganizationID: budget.OrganizationID,
+			BudgetID:       budget.ID,
+			Name:           budget.Name,
+			Period:         budget.Period,
+			PeriodStart:    status.PeriodStart,
+			Threshold:      threshold,
+			Limit:          budget.Limit,
+			Spent:          status.Spent,
+			UserID:         budget.UserID,
+			ServiceName:    budget.ServiceName,
+			Email:          budget.Email,
+		}
+		claimed, err := uc.repo.ClaimAlert(ctx, alert)
+		if err != nil {
+			// Уже отмеченные пороги снимаются, чтобы уведомление о них ушло при следующем запуске
+			uc.releaseAlerts(ctx, alerts)
+			return nil, fmt.Errorf("failed to claim budget alert: %w", err)
+		}
+		if claimed {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+// releaseAlerts снимает отметки порогов, уведомление о которых не доставлено
+// Снятие выполняется и после отмены контекста запуска, ошибки только логируются
+func (uc *BudgetUseCase) releaseAlerts(ctx context.Context, alerts []*domain.BudgetAlert) {
+	for _, a := range alerts {
+		if err := uc.repo.ReleaseAlert(context.WithoutCancel(ctx), a); err != nil {
+			logging.FromContext(ctx).Error("Failed to release budget alert", "budget_id", a.BudgetID, "threshold", a.Threshold, "error", err)
+		}
+	}
+}
+
+// status считает ожидаемые расходы по бюджету за период, в который попадает текущий момент
+func (uc *BudgetUseCase) status(ctx context.Context, budget *domain.Budget) (*domain.BudgetStatus, error) {
+	// Сервис мог быть переименован в каталоге после сохранения бюджета
+	serviceName, err := uc.canonicalName(ctx, budget.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := budget.PeriodAt(uc.now())
+	spent, err := uc.subs.GetSummary(ctx, domain.SummaryFilters{
+		UserID:      budget.UserID,
+		ServiceName: serviceName,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate budget spend: %w", err)
+	}
+
+	return &domain.BudgetStatus{Budget: budget, PeriodStart: start, PeriodEnd: end, Spent: spent}, nil
+}
+
+// canonicalName заменяет название сервиса бюджета каноническим из каталога,
+// чтобы бюджет на "yandex plus" считал подписки "Yandex Plus"
+func (uc *BudgetUseCase) canonicalName(ctx context.Context, serviceName string) (string, error) {
+	if uc.catalog == nil || serviceName == "" {
+		return serviceName, nil
+	}
+
+	svc, err := uc.catalog.FindByName(ctx, serviceName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find service: %w", err)
+	}
+	if svc == nil {
+		return serviceName, nil
+	}
+	return svc.Name, nil
+}
+
+// get получает бюджет и проверяет доступ вызывающего к нему
+func (uc *BudgetUseCase) get(ctx context.Context, id string, own, all auth.Permission) (*domain.Budget, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	budget, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if err := uc.authorize(ctx, own, all, budget); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+// authorize проверяет доступ к бюджету: вызывающий без права all имеет доступ только к бюджетам на себя
+func (uc *BudgetUseCase) authorize(ctx context.Context, own, all auth.Permission, budget *domain.Budget) error {
+	ownUserID, restricted, err := uc.policy.ScopeUserID(ctx, own, all)
+	if err != nil || !restricted {
+		return err
+	}
+	if budget.UserID == "" {
+		return fmt.Errorf("%w: %s permission required for organization budgets", auth.ErrForbidden, all)
+	}
+	if budget.UserID != ownUserID {
+		return fmt.Errorf("%w: budget belongs to another user", auth.ErrForbidden)
+	}
+	return nil
+}
+
+// newBudget проверяет параметры бюджета, общие для создания и изменения
+// Пороги сортируются и очищаются от повторов; без порогов используются domain.DefaultBudgetThresholds
+func newBudget(name, period string, limit int64, serviceName string, thresholds []int, email string) (*domain.Budget, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if period != domain.BudgetMonthly && period != domain.BudgetYearly {
+		return nil, fmt.Errorf("invalid period %q, expected %s or %s", period, domain.BudgetMonthly, domain.BudgetYearly)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	if len(thresholds) == 0 {
+		thresholds = domain.DefaultBudgetThresholds
+	}
+	for _, threshold := range thresholds {
+		if threshold <= 0 || threshold > maxBudgetThreshold {
+			return nil, fmt.Errorf("thresholds must be between 1 and %d percent", maxBudgetThreshold)
+		}
+	}
+
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Name != "" {
+			return nil, fmt.Errorf("invalid email %q", email)
+		}
+	}
+
+	return &domain.Budget{
+		Name:        name,
+		Period:      period,
+		Limit:       limit,
+		ServiceName: serviceName,
+		Thresholds:  slices.Compact(slices.Sorted(slices.Values(thresholds))),
+		Email:       email,
+	}, nil
+}
+
+// CreateBudgetInput представляет входные данные для создания бюджета
+// Пустые UserID и ServiceName означают бюджет на все подписки организации
+type CreateBudgetInput struct {
+	Name        string
+	Period      string
+	Limit       int64
+	UserID      string
+	ServiceName string
+	Thresholds  []int
+	Email       string
+}
+
+// UpdateBudgetInput представляет входные данные для изменения бюджета
+type UpdateBudgetInput struct {
+	Name        string
+	Period      string
+	Limit       int64
+	ServiceName string
+	Thresholds  []int
+	Email       string
+}
+
+// BudgetFiltersInput представляет входные данные для получения списка бюджетов
+type BudgetFiltersInput struct {
+	UserID string
+}
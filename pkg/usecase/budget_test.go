@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingBudgetNotifier запоминает доставленные уведомления; err возвращается вместо доставки
+type recordingBudgetNotifier struct {
+	sent []*domain.BudgetAlert
+	err  error
+}
+
+func (n *recordingBudgetNotifier) NotifyBudget(_ context.Context, a *domain.BudgetAlert) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, a)
+	return nil
+}
+
+// brokenSummaryRepository не может посчитать сумму подписок сервиса service
+type brokenSummaryRepository struct {
+	domain.SubscriptionRepository
+	service string
+}
+
+func (r *brokenSummaryRepository) GetSummary(ctx context.Context, filters domain.SummaryFilters) (int64, error) {
+	if filters.ServiceName == r.service {
+		return 0, errors.New("connection reset")
+	}
+	return r.SubscriptionRepository.GetSummary(ctx, filters)
+}
+
+func TestBudgetUseCase_Access(t *testing.T) {
+	const (
+		userID  = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+		otherID = "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"
+	)
+	viewerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "v", UserID: userID, Roles: []string{"viewer"}})
+	editorCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "e", UserID: userID, Roles: []string{"editor"}})
+	financeCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "f", UserID: otherID, Roles: []string{"finance"}})
+
+	useCase := NewBudgetUseCase(memory.NewBudgetRepository(), memory.NewSubscriptionRepository(), nil, nil)
+
+	own, err := useCase.CreateBudget(editorCtx, CreateBudgetInput{Name: "Mine", Period: domain.BudgetMonthly, Limit: 1000, UserID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, []int{80, 100}, own.Thresholds, "default thresholds")
+
+	_, err = useCase.CreateBudget(viewerCtx, CreateBudgetInput{Name: "Mine", Period: domain.BudgetMonthly, Limit: 1000, UserID: userID})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	_, err = useCase.CreateBudget(editorCtx, CreateBudgetInput{Name: "Theirs", Period: domain.BudgetMonthly, Limit: 1000, UserID: otherID})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	_, err = useCase.CreateBudget(editorCtx, CreateBudgetInput{Name: "Company", Period: domain.BudgetYearly, Limit: 100000})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	company, err := useCase.CreateBudget(financeCtx, CreateBudgetInput{Name: "Company", Period: domain.BudgetYearly, Limit: 100000, Thresholds: []int{100, 50, 100}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{50, 100}, company.Thresholds, "thresholds are sorted and deduplicated")
+
+	// Пользователь видит только свои бюджеты
+	budgets, err := useCase.ListBudgets(viewerCtx, BudgetFiltersInput{})
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.Equal(t, own.ID, budgets[0].ID)
+
+	budgets, err = useCase.ListBudgets(financeCtx, BudgetFiltersInput{})
+	require.NoError(t, err)
+	assert.Len(t, budgets, 2)
+
+	_, err = useCase.GetBudget(viewerCtx, company.ID)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	_, err = useCase.GetBudgetStatus(viewerCtx, own.ID)
+	assert.NoError(t, err)
+
+	updated, err := useCase.UpdateBudget(editorCtx, own.ID, UpdateBudgetInput{Name: "Renamed", Period: domain.BudgetYearly, Limit: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.Equal(t, userID, updated.UserID)
+
+	_, err = useCase.UpdateBudget(editorCtx, company.ID, UpdateBudgetInput{Name: "Mine now", Period: domain.BudgetYearly, Limit: 1})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	assert.ErrorIs(t, useCase.DeleteBudget(viewerCtx, own.ID), auth.ErrForbidden)
+	assert.NoError(t, useCase.DeleteBudget(editorCtx, own.ID))
+}
+
+func TestBudgetUseCase_Validation(t *testing.T) {
+	useCase := NewBudgetUseCase(memory.NewBudgetRepository(), memory.NewSubscriptionRepository(), nil, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		input CreateBudgetInput
+		err   string
+	}{
+		{"missing name", CreateBudgetInput{Period: domain.BudgetMonthly, Limit: 100}, "name is required"},
+		{"unknown period", CreateBudgetInput{Name: "B", Period: "weekly", Limit: 100}, "invalid period"},
+		{"zero limit", CreateBudgetInput{Name: "B", Period: domain.BudgetMonthly}, "limit must be positive"},
+		{"zero threshold", CreateBudgetInput{Name: "B", Period: domain.BudgetMonthly, Limit: 100, Thresholds: []int{0}}, "thresholds must be between"},
+		{"invalid user", CreateBudgetInput{Name: "B", Period: domain.BudgetMonthly, Limit: 100, UserID: "42"}, "invalid user_id"},
+		{"invalid email", CreateBudgetInput{Name: "B", Period: domain.BudgetMonthly, Limit: 100, Email: "nope"}, "invalid email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := useCase.CreateBudget(ctx, tt.input)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestBudgetUseCase_Status(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	ctx := context.Background()
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := memory.NewSubscriptionRepository()
+	for _, sub := range []*domain.Subscription{
+		{ServiceName: "Netflix", Price: 400, UserID: userID, StartDate: jan},
+		{ServiceName: "Spotify", Price: 200, UserID: userID, StartDate: jan, EndDate: sql.NullTime{Time: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), Valid: true}},
+	} {
+		_, err := subs.Create(ctx, sub)
+		require.NoError(t, err)
+	}
+
+	useCase := NewBudgetUseCase(memory.NewBudgetRepository(), subs, nil, nil)
+	useCase.now = func() time.Time { return time.Date(2025, time.March, 15, 12, 0, 0, 0, time.UTC) }
+
+	monthly, err := useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Monthly", Period: domain.BudgetMonthly, Limit: 500, UserID: userID})
+	require.NoError(t, err)
+	status, err := useCase.GetBudgetStatus(ctx, monthly.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), status.PeriodStart)
+	assert.Equal(t, status.PeriodStart, status.PeriodEnd)
+	assert.Equal(t, int64(600), status.Spent)
+	assert.Equal(t, int64(120), status.Percent())
+	assert.Equal(t, int64(-100), status.Remaining())
+	assert.True(t, status.Exceeded())
+	assert.Equal(t, []int{80, 100}, status.Reached())
+
+	// Годовой бюджет учитывает весь год, включая будущие месяцы
+	yearly, err := useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Yearly", Period: domain.BudgetYearly, Limit: 8000, ServiceName: "Netflix"})
+	require.NoError(t, err)
+	status, err = useCase.GetBudgetStatus(ctx, yearly.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jan, status.PeriodStart)
+	assert.Equal(t, time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC), status.PeriodEnd)
+	assert.Equal(t, int64(4800), status.Spent)
+	assert.Equal(t, int64(60), status.Percent())
+	assert.False(t, status.Exceeded())
+	assert.Empty(t, status.Reached())
+}
+
+func TestBudgetUseCase_CatalogServiceName(t *testing.T) {
+	ctx := context.Background()
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := memory.NewSubscriptionRepository()
+	catalog := memory.NewServiceRepository(subs)
+	_, err := catalog.Create(ctx, &domain.Service{Name: "Yandex Plus", Aliases: []string{"Кинопоиск"}})
+	require.NoError(t, err)
+	_, err = subs.Create(ctx, &domain.Subscription{ServiceName: "Yandex Plus", Price: 300, UserID: "60601fee-2bf1-4721-ae6f-7636e79a0cba", StartDate: jan})
+	require.NoError(t, err)
+
+	useCase := NewBudgetUseCase(memory.NewBudgetRepository(), subs, nil, nil, WithServiceCatalog(catalog))
+	useCase.now = func() time.Time { return time.Date(2025, time.March, 15, 12, 0, 0, 0, time.UTC) }
+
+	// Бюджет хранит каноническое название и считает подписки, записанные под ним
+	budget, err := useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Plus", Period: domain.BudgetMonthly, Limit: 500, ServiceName: "yandex plus"})
+	require.NoError(t, err)
+	assert.Equal(t, "Yandex Plus", budget.ServiceName)
+	status, err := useCase.GetBudgetStatus(ctx, budget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), status.Spent)
+
+	updated, err := useCase.UpdateBudget(ctx, budget.ID, UpdateBudgetInput{Name: "Plus", Period: domain.BudgetMonthly, Limit: 500, ServiceName: "кинопоиск"})
+	require.NoError(t, err)
+	assert.Equal(t, "Yandex Plus", updated.ServiceName)
+
+	// Сервис вне каталога сохраняется как есть
+	other, err := useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Other", Period: domain.BudgetMonthly, Limit: 500, ServiceName: "Netflix"})
+	require.NoError(t, err)
+	assert.Equal(t, "Netflix", other.ServiceName)
+}
+
+func TestBudgetUseCase_EvaluateBudgets(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	ctx := context.Background()
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := memory.NewSubscriptionRepository()
+	netflix, err := subs.Create(ctx, &domain.Subscription{ServiceName: "Netflix", Price: 850, UserID: userID, StartDate: jan})
+	require.NoError(t, err)
+
+	notifier := &recordingBudgetNotifier{}
+	useCase := NewBudgetUseCase(memory.NewBudgetRepository(), subs, notifier, nil)
+	useCase.now = func() time.Time { return time.Date(2025, time.January, 10, 0, 0, 0, 0, time.UTC) }
+
+	budget, err := useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Streaming", Period: domain.BudgetMonthly, Limit: 1000, Email: "f@example.com"})
+	require.NoError(t, err)
+	_, err = useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Roomy", Period: domain.BudgetMonthly, Limit: 100000})
+	require.NoError(t, err)
+
+	// Доставка не удалась: уведомление повторяется при следующем запуске
+	notifier.err = errors.New("smtp is down")
+	report, err := useCase.EvaluateBudgets(ctx)
+	require.Error(t, err)
+	assert.Equal(t, &BudgetReport{Budgets: 2, Failed: 1}, report)
+
+	notifier.err = nil
+	report, err = useCase.EvaluateBudgets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &BudgetReport{Budgets: 2, Sent: 1}, report)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, budget.ID, notifier.sent[0].BudgetID)
+	assert.Equal(t, 80, notifier.sent[0].Threshold)
+	assert.Equal(t, int64(850), notifier.sent[0].Spent)
+	assert.Equal(t, "f@example.com", notifier.sent[0].Email)
+
+	// Порог уведомляется один раз за период
+	report, err = useCase.EvaluateBudgets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &BudgetReport{Budgets: 2}, report)
+
+	// При достижении следующего порога уходит новое уведомление
+	_, err = subs.Update(ctx, netflix.ID, &domain.Subscription{ServiceName: "Netflix", Price: 1200, StartDate: jan})
+	require.NoError(t, err)
+	_, err = useCase.EvaluateBudgets(ctx)
+	require.NoError(t, err)
+	require.Len(t, notifier.sent, 2)
+	assert.Equal(t, 100, notifier.sent[1].Threshold)
+
+	// В новом периоде сразу достигнуты оба порога: уведомление о наибольшем
+	useCase.now = func() time.Time { return time.Date(2025, time.February, 10, 0, 0, 0, 0, time.UTC) }
+	_, err = useCase.EvaluateBudgets(ctx)
+	require.NoError(t, err)
+	require.Len(t, notifier.sent, 3)
+	assert.Equal(t, 100, notifier.sent[2].Threshold)
+	assert.Equal(t, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), notifier.sent[2].PeriodStart)
+
+	// Без каналов доставки проверка невозможна
+	_, err = NewBudgetUseCase(memory.NewBudgetRepository(), subs, nil, nil).EvaluateBudgets(ctx)
+	assert.Error(t, err)
+}
+
+func TestBudgetUseCase_EvaluateBudgetsContinuesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := memory.NewSubscriptionRepository()
+	_, err := subs.Create(ctx, &domain.Subscription{ServiceName: "Netflix", Price: 850, UserID: "60601fee-2bf1-4721-ae6f-7636e79a0cba", StartDate: jan})
+	require.NoError(t, err)
+
+	notifier := &recordingBudgetNotifier{}
+	useCase := NewBudgetUseCase(memory.NewBudgetRepository(), &brokenSummaryRepository{SubscriptionRepository: subs, service: "Broken"}, notifier, nil)
+	useCase.now = func() time.Time { return time.Date(2025, time.January, 10, 0, 0, 0, 0, time.UTC) }
+
+	_, err = useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Broken", Period: domain.BudgetMonthly, Limit: 1000, ServiceName: "Broken"})
+	require.NoError(t, err)
+	budget, err := useCase.CreateBudget(ctx, CreateBudgetInput{Name: "Streaming", Period: domain.BudgetMonthly, Limit: 1000, Email: "f@example.com"})
+	require.NoError(t, err)
+
+	// Ошибка одного бюджета попадает в отчет, остальные проверяются
+	report, err := useCase.EvaluateBudgets(ctx)
+	assert.ErrorContains(t, err, "1 of 2 budgets")
+	assert.Equal(t, &BudgetReport{Budgets: 2, Sent: 1, Failed: 1}, report)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, budget.ID, notifier.sent[0].BudgetID)
+}
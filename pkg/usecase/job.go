@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/scheduler"
+)
+
+// Размер страницы истории запусков
+const (
+	defaultJobRunsLimit = 20
+	maxJobRunsLimit     = 100
+)
+
+// JobScheduler определяет интерфейс планировщика фоновых задач
+type JobScheduler interface {
+	Jobs() []scheduler.JobInfo
+	Trigger(ctx context.Context, name string) (*domain.JobRun, error)
+	Leader() bool
+	Instance() string
+}
+
+// JobUseCase содержит логику управления фоновыми задачами
+type JobUseCase struct {
+	scheduler JobScheduler
+	runs      domain.JobRunRepository
+	policy    *auth.Policy
+	observer  Observer
+}
+
+// NewJobUseCase создает новый экземпляр use case для фоновых задач
+// Права вызывающих проверяются по policy; nil означает политику по умолчанию
+func NewJobUseCase(s JobScheduler, runs domain.JobRunRepository, policy *auth.Policy, opts ...Option) *JobUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &JobUseCase{scheduler: s, runs: runs, policy: policy, observer: o.observer}
+}
+
+// jobsUseCase имя use case для наблюдателей
+const jobsUseCase = "jobs"
+
+// Job задача планировщика с последним запуском
+type Job struct {
+	scheduler.JobInfo
+	// LastRun nil, если задача еще не запускалась
+	LastRun *domain.JobRun
+}
+
+// JobList задачи планировщика и состояние экземпляра, ответившего на запрос
+type JobList struct {
+	Instance string
+	Leader   bool
+	Jobs     []Job
+}
+
+// ListJobs возвращает задачи с их последними запусками
+func (uc *JobUseCase) ListJobs(ctx context.Context) (_ *JobList, err error) {
+	ctx, end := uc.observer.Start(ctx, jobsUseCase, "ListJobs")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermPlatformJobs); err != nil {
+		return nil, err
+	}
+
+	list := &JobList{Instance: uc.scheduler.Instance(), Leader: uc.scheduler.Leader()}
+	for _, info := range uc.scheduler.Jobs() {
+		runs, err := uc.runs.List(ctx, domain.JobRunFilters{Job: info.Name, Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list job runs: %w", err)
+		}
+
+		job := Job{JobInfo: info}
+		if len(runs) > 0 {
+			job.LastRun = runs[0]
+		}
+		list.Jobs = append(list.Jobs, job)
+	}
+
+	return list, nil
+}
+
+// ListJobRuns возвращает историю запусков задачи от новых к старым
+func (uc *JobUseCase) ListJobRuns(ctx context.Context, req JobRunsInput) (_ []*domain.JobRun, err error) {
+	ctx, end := uc.observer.Start(ctx, jobsUseCase, "ListJobRuns")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermPlatformJobs); err != nil {
+		return nil, err
+	}
+	if !uc.exists(req.Job) {
+		return nil, fmt.Errorf("job %q not found", req.Job)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultJobRunsLimit
+	}
+	if limit > maxJobRunsLimit {
+		return nil, fmt.Errorf("limit must be at most %d", maxJobRunsLimit)
+	}
+
+	runs, err := uc.runs.List(ctx, domain.JobRunFilters{Job: req.Job, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// TriggerJob запускает задачу вне расписания; задача выполняется в фоне
+func (uc *JobUseCase) TriggerJob(ctx context.Context, name string) (_ *domain.JobRun, err error) {
+	ctx, end := uc.observer.Start(ctx, jobsUseCase, "TriggerJob")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermPlatformJobs); err != nil {
+		return nil, err
+	}
+
+	return uc.scheduler.Trigger(ctx, name)
+}
+
+func (uc *JobUseCase) exists(name string) bool {
+	for _, info := range uc.scheduler.Jobs() {
+		if info.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// JobRunsInput представляет параметры выборки истории запусков
+type JobRunsInput struct {
+	Job   string
+	Limit int
+}
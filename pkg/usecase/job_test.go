@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubScheduler планировщик с фиксированным набором задач
+type stubScheduler struct {
+	jobs      []scheduler.JobInfo
+	triggered []string
+}
+
+func (s *stubScheduler) Jobs() []scheduler.JobInfo { return s.jobs }
+func (s *stubScheduler) Leader() bool              { return true }
+func (s *stubScheduler) Instance() string          { return "host-1" }
+
+func (s *stubScheduler) Trigger(_ context.Context, name string) (*domain.JobRun, error) {
+	for _, job := range s.jobs {
+		if job.Name == name {
+			s.triggered = append(s.triggered, name)
+			return &domain.JobRun{ID: "run-1", Job: name, Trigger: domain.JobTriggerManual, Status: domain.JobRunRunning}, nil
+		}
+	}
+	return nil, fmt.Errorf("job %q not found", name)
+}
+
+func TestJobUseCase(t *testing.T) {
+	adminCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []string{auth.RolePlatformAdmin}})
+	orgAdminCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "org-admin", Roles: []string{auth.RoleAdmin}})
+	userCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "u", UserID: "u"})
+
+	sched := &stubScheduler{jobs: []scheduler.JobInfo{{Name: "expire"}, {Name: "rollup"}}}
+	runs := memory.NewJobRunRepository()
+	started := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := runs.Create(context.Background(), &domain.JobRun{Job: "expire", StartedAt: started.Add(time.Duration(i) * time.Hour)})
+		require.NoError(t, err)
+	}
+	useCase := NewJobUseCase(sched, runs, nil)
+
+	t.Run("list jobs with last run", func(t *testing.T) {
+		list, err := useCase.ListJobs(adminCtx)
+		require.NoError(t, err)
+		assert.Equal(t, "host-1", list.Instance)
+		assert.True(t, list.Leader)
+		require.Len(t, list.Jobs, 2)
+		require.NotNil(t, list.Jobs[0].LastRun)
+		assert.Equal(t, started.Add(2*time.Hour), list.Jobs[0].LastRun.StartedAt)
+		assert.Nil(t, list.Jobs[1].LastRun)
+	})
+
+	t.Run("list runs", func(t *testing.T) {
+		got, err := useCase.ListJobRuns(adminCtx, JobRunsInput{Job: "expire", Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+
+		_, err = useCase.ListJobRuns(adminCtx, JobRunsInput{Job: "missing"})
+		assert.ErrorContains(t, err, "not found")
+
+		_, err = useCase.ListJobRuns(adminCtx, JobRunsInput{Job: "expire", Limit: 1000})
+		assert.ErrorContains(t, err, "must be at most")
+	})
+
+	t.Run("trigger", func(t *testing.T) {
+		run, err := useCase.TriggerJob(adminCtx, "rollup")
+		require.NoError(t, err)
+		assert.Equal(t, "rollup", run.Job)
+		assert.Equal(t, []string{"rollup"}, sched.triggered)
+	})
+
+	t.Run("non admin is forbidden", func(t *testing.T) {
+		_, err := useCase.ListJobs(userCtx)
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		_, err = useCase.ListJobRuns(userCtx, JobRunsInput{Job: "expire"})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		_, err = useCase.TriggerJob(userCtx, "expire")
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+	})
+
+	t.Run("organization admin is forbidden", func(t *testing.T) {
+		_, err := useCase.ListJobs(orgAdminCtx)
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		_, err = useCase.ListJobRuns(orgAdminCtx, JobRunsInput{Job: "expire"})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		_, err = useCase.TriggerJob(orgAdminCtx, "expire")
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+	})
+}
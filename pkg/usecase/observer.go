@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// Observer получает уведомления о вызовах методов use case (метрики, трассировка)
+type Observer interface {
+	// Start вызывается перед выполнением метода и возвращает контекст вызова
+	// и функцию, которую нужно вызвать по завершении с результатом метода
+	Start(ctx context.Context, useCase, method string) (context.Context, func(err error))
+}
+
+// Option настраивает use case
+type Option func(*options)
+
+type options struct {
+	observer Observer
+	catalog  domain.ServiceRepository
+}
+
+// WithObserver подключает наблюдателя за вызовами методов use case
+// Несколько наблюдателей вызываются в порядке подключения
+func WithObserver(o Observer) Option {
+	return func(opts *options) {
+		if opts.observer == nil {
+			opts.observer = o
+			return
+		}
+		opts.observer = observers{opts.observer, o}
+	}
+}
+
+// WithServiceCatalog подключает каталог сервисов: названия подписок приводятся к каноническим,
+// а цена по умолчанию берется из тарифа
+func WithServiceCatalog(repo domain.ServiceRepository) Option {
+	return func(opts *options) {
+		opts.catalog = repo
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.observer == nil {
+		o.observer = noopObserver{}
+	}
+	return o
+}
+
+// noopObserver наблюдатель по умолчанию, ничего не делает
+type noopObserver struct{}
+
+func (noopObserver) Start(ctx context.Context, _, _ string) (context.Context, func(error)) {
+	return ctx, func(error) {}
+}
+
+// observers объединяет нескольких наблюдателей
+type observers []Observer
+
+func (obs observers) Start(ctx context.Context, useCase, method string) (context.Context, func(error)) {
+	ends := make([]func(error), 0, len(obs))
+	for _, o := range obs {
+		var end func(error)
+		ctx, end = o.Start(ctx, useCase, method)
+		ends = append(ends, end)
+	}
+	return ctx, func(err error) {
+		// Завершение в обратном порядке, как у вложенных вызовов
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/utils"
+)
+
+// maxOrganizationNameLength наибольшая длина названия организации в символах
+const maxOrganizationNameLength = 100
+
+// OrganizationUseCase содержит логику учета организаций платформы
+type OrganizationUseCase struct {
+	repo     domain.OrganizationRepository
+	policy   *auth.Policy
+	observer Observer
+	// known организации, существование которых уже подтверждено; организации не удаляются, поэтому записи не устаревают
+	known sync.Map
+}
+
+// NewOrganizationUseCase создает новый экземпляр use case для организаций
+func NewOrganizationUseCase(repo domain.OrganizationRepository, policy *auth.Policy, opts ...Option) *OrganizationUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &OrganizationUseCase{repo: repo, policy: policy, observer: o.observer}
+}
+
+// organizationsUseCase имя use case для наблюдателей
+const organizationsUseCase = "organizations"
+
+// CreateOrganizationInput представляет входные данные для создания организации
+type CreateOrganizationInput struct {
+	// ID организации в токенах провайдера идентификации (claim org_id); пустой ID генерируется
+	ID   string
+	Name string
+}
+
+// CreateOrganization создает организацию; после этого запросы с её ID допускаются к данным
+func (uc *OrganizationUseCase) CreateOrganization(ctx context.Context, req CreateOrganizationInput) (_ *domain.Organization, err error) {
+	ctx, end := uc.observer.Start(ctx, organizationsUseCase, "CreateOrganization")
+	defer func() { end(err) }()
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("organization name is required")
+	}
+	if utf8.RuneCountInString(name) > maxOrganizationNameLength {
+		return nil, fmt.Errorf("invalid organization name: must be at most %d characters", maxOrganizationNameLength)
+	}
+	id := strings.ToLower(strings.TrimSpace(req.ID))
+	if id != "" && !utils.IsUUID(id) {
+		return nil, fmt.Errorf("invalid organization id: must be a UUID")
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermPlatformOrganizations); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.repo.Create(ctx, &domain.Organization{ID: id, Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	uc.known.Store(created.ID, struct{}{})
+	return created, nil
+}
+
+// ListOrganizations возвращает организации платформы по названию
+func (uc *OrganizationUseCase) ListOrganizations(ctx context.Context) (_ []*domain.Organization, err error) {
+	ctx, end := uc.observer.Start(ctx, organizationsUseCase, "ListOrganizations")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermPlatformOrganizations); err != nil {
+		return nil, err
+	}
+
+	orgs, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// OrganizationExists сообщает, что организация существует
+// Вызывается для каждого запроса до проверки прав, поэтому не требует их и не сообщает наблюдателям
+func (uc *OrganizationUseCase) OrganizationExists(ctx context.Context, id string) (bool, error) {
+	if _, ok := uc.known.Load(id); ok {
+		return true, nil
+	}
+
+	exists, err := uc.repo.Exists(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to check organization: %w", err)
+	}
+	if exists {
+		uc.known.Store(id, struct{}{})
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationUseCase(t *testing.T) {
+	platformCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []string{auth.RolePlatformAdmin}})
+	orgAdminCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "org-admin", Roles: []string{auth.RoleAdmin}})
+
+	useCase := NewOrganizationUseCase(memory.NewOrganizationRepository(), nil)
+
+	t.Run("platform admin creates and lists organizations", func(t *testing.T) {
+		created, err := useCase.CreateOrganization(platformCtx, CreateOrganizationInput{Name: "  Acme  "})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", created.Name)
+
+		// ID из токенов провайдера идентификации сохраняется как есть
+		const globexID = "5e0c3a8e-0f4d-4b8e-9a57-3c1f2a7d9b10"
+		globex, err := useCase.CreateOrganization(platformCtx, CreateOrganizationInput{ID: globexID, Name: "Globex"})
+		require.NoError(t, err)
+		assert.Equal(t, globexID, globex.ID)
+
+		orgs, err := useCase.ListOrganizations(platformCtx)
+		require.NoError(t, err)
+		require.Len(t, orgs, 3)
+		assert.Equal(t, "Acme", orgs[0].Name)
+		assert.Equal(t, globexID, orgs[1].ID)
+		assert.Equal(t, domain.DefaultOrganizationID, orgs[2].ID)
+
+		exists, err := useCase.OrganizationExists(context.Background(), created.ID)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("organization admin is forbidden", func(t *testing.T) {
+		_, err := useCase.CreateOrganization(orgAdminCtx, CreateOrganizationInput{Name: "Initech"})
+		assert.ErrorContains(t, err, "forbidden")
+		_, err = useCase.ListOrganizations(orgAdminCtx)
+		assert.ErrorContains(t, err, "forbidden")
+	})
+
+	t.Run("invalid names are rejected", func(t *testing.T) {
+		_, err := useCase.CreateOrganization(platformCtx, CreateOrganizationInput{Name: " "})
+		assert.ErrorContains(t, err, "required")
+		_, err = useCase.CreateOrganization(platformCtx, CreateOrganizationInput{ID: "acme", Name: "Initech"})
+		assert.ErrorContains(t, err, "must be a UUID")
+		_, err = useCase.CreateOrganization(platformCtx, CreateOrganizationInput{Name: "Acme"})
+		assert.ErrorContains(t, err, "already used")
+		_, err = useCase.CreateOrganization(platformCtx, CreateOrganizationInput{ID: domain.DefaultOrganizationID, Name: "Initech"})
+		assert.ErrorContains(t, err, "already used")
+	})
+
+	t.Run("unknown organization does not exist", func(t *testing.T) {
+		exists, err := useCase.OrganizationExists(context.Background(), uuid.NewString())
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
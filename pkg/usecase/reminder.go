@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+)
+
+// Notifier определяет канал доставки напоминаний
+// Возвращает domain.ErrNoRecipient, если пользователю некуда доставить напоминание
+type Notifier interface {
+	Notify(ctx context.Context, r *domain.Reminder) error
+}
+
+// ReminderUseCase содержит логику напоминаний о продлении и окончании подписок
+type ReminderUseCase struct {
+	repo      domain.ReminderRepository
+	notifier  Notifier
+	daysAhead int
+	policy    *auth.Policy
+	observer  Observer
+	now       func() time.Time
+}
+
+// NewReminderUseCase создает новый экземпляр use case для напоминаний
+// Напоминания отправляются за daysAhead дней; notifier может быть nil, если каналы доставки не настроены
+func NewReminderUseCase(repo domain.ReminderRepository, notifier Notifier, daysAhead int, policy *auth.Policy, opts ...Option) *ReminderUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &ReminderUseCase{
+		repo:      repo,
+		notifier:  notifier,
+		daysAhead: daysAhead,
+		policy:    policy,
+		observer:  o.observer,
+		now:       time.Now,
+	}
+}
+
+// remindersUseCase имя use case для наблюдателей
+const remindersUseCase = "reminders"
+
+// ReminderReport итог отправки напоминаний
+type ReminderReport struct {
+	Due     int
+	Sent    int
+	Skipped int
+	Failed  int
+}
+
+// SendDue отправляет напоминания всех организаций о списаниях и окончаниях в ближайшие daysAhead дней
+// Каждое напоминание отмечается до отправки, поэтому уходит не больше одного раза; при ошибке доставки
+// или отсутствии адреса отметка снимается и напоминание повторяется при следующем запуске.
+// Вызывается планировщиком, права не проверяются
+func (uc *ReminderUseCase) SendDue(ctx context.Context) (_ *ReminderReport, err error) {
+	ctx, end := uc.observer.Start(ctx, remindersUseCase, "SendDue")
+	defer func() { end(err) }()
+
+	if uc.notifier == nil {
+		return nil, fmt.Errorf("no reminder channels configured")
+	}
+
+	now := uc.now()
+	due, err := uc.repo.Due(ctx, now, now.AddDate(0, 0, uc.daysAhead))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due reminders: %w", err)
+	}
+
+	logger := logging.FromContext(ctx)
+	report := &ReminderReport{Due: len(due)}
+	for _, r := range due {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		claimed, err := uc.repo.Claim(ctx, r)
+		if err != nil {
+			return report, fmt.Errorf("failed to claim reminder: %w", err)
+		}
+		if !claimed {
+			continue
+		}
+
+		err = uc.notifier.Notify(domain.WithOrganization(ctx, r.OrganizationID), r)
+		if err == nil {
+			report.Sent++
+			logger.Info("Reminder sent", "subscription_id", r.SubscriptionID, "kind", r.Kind, "date", r.Date.Format(time.DateOnly))
+			continue
+		}
+
+		if releaseErr := uc.repo.Release(context.WithoutCancel(ctx), r); releaseErr != nil {
+			return report, fmt.Errorf("failed to release reminder: %w", releaseErr)
+		}
+		if errors.Is(err, domain.ErrNoRecipient) {
+			report.Skipped++
+			continue
+		}
+		report.Failed++
+		logger.Warn("Failed to send reminder", "subscription_id", r.SubscriptionID, "kind", r.Kind, "error", err)
+	}
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("failed to send %d of %d reminders", report.Failed, report.Due)
+	}
+
+	return report, nil
+}
+
+// GetPreferences возвращает настройки напоминаний пользователя
+func (uc *ReminderUseCase) GetPreferences(ctx context.Context, userID string) (_ *domain.ReminderPreferences, err error) {
+	ctx, end := uc.observer.Start(ctx, remindersUseCase, "GetPreferences")
+	defer func() { end(err) }()
+
+	userID, err = uc.authorizeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := uc.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences меняет настройки напоминаний пользователя; непереданные поля сохраняют значения
+func (uc *ReminderUseCase) UpdatePreferences(ctx context.Context, userID string, req UpdateReminderPreferencesInput) (_ *domain.ReminderPreferences, err error) {
+	ctx, end := uc.observer.Start(ctx, remindersUseCase, "UpdatePreferences")
+	defer func() { end(err) }()
+
+	userID, err = uc.authorizeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Email != nil && *req.Email != "" {
+		addr, err := mail.ParseAddress(*req.Email)
+		if err != nil || addr.Name != "" {
+			return nil, fmt.Errorf("invalid email %q", *req.Email)
+		}
+	}
+
+	prefs, err := uc.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder preferences: %w", err)
+	}
+	if req.Email != nil {
+		prefs.Email = *req.Email
+	}
+	if req.Renewal != nil {
+		prefs.Renewal = *req.Renewal
+	}
+	if req.Expiry != nil {
+		prefs.Expiry = *req.Expiry
+	}
+
+	saved, err := uc.repo.SavePreferences(ctx, prefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reminder preferences: %w", err)
+	}
+
+	return saved, nil
+}
+
+// authorizeUser проверяет user_id и доступ вызывающего к настройкам пользователя
+// Возвращает user_id в каноническом виде
+func (uc *ReminderUseCase) authorizeUser(ctx context.Context, userID string) (string, error) {
+	if userID == "" {
+		return "", fmt.Errorf("user_id is required")
+	}
+	parsed, err := uuid.Parse(userID)
+	if err != nil {
+		return "", fmt.Errorf("invalid user_id %q", userID)
+	}
+	userID = parsed.String()
+
+	if err := checkUserAccess(ctx, uc.policy, auth.PermRemindersManage, auth.PermRemindersManageAll, userID); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// UpdateReminderPreferencesInput представляет изменения настроек напоминаний
+// nil означает, что поле не меняется; пустой Email удаляет адрес
+type UpdateReminderPreferencesInput struct {
+	Email   *string
+	Renewal *bool
+	Expiry  *bool
+}
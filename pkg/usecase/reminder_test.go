@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier запоминает доставленные напоминания; ошибку для сервиса можно задать в fail
+type recordingNotifier struct {
+	sent []*domain.Reminder
+	fail map[string]error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, r *domain.Reminder) error {
+	if err := n.fail[r.ServiceName]; err != nil {
+		return err
+	}
+	n.sent = append(n.sent, r)
+	return nil
+}
+
+func TestReminderUseCase_SendDue(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	ctx := context.Background()
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := memory.NewSubscriptionRepository()
+	for _, name := range []string{"Netflix", "Spotify", "Broken"} {
+		_, err := subs.Create(ctx, &domain.Subscription{ServiceName: name, Price: 100, UserID: userID, StartDate: jan})
+		require.NoError(t, err)
+	}
+	repo := memory.NewReminderRepository(subs)
+
+	notifier := &recordingNotifier{fail: map[string]error{
+		"Spotify": domain.ErrNoRecipient,
+		"Broken":  errors.New("smtp is down"),
+	}}
+	useCase := NewReminderUseCase(repo, notifier, 3, nil)
+	useCase.now = func() time.Time { return time.Date(2025, time.January, 29, 8, 0, 0, 0, time.UTC) }
+
+	report, err := useCase.SendDue(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to send 1 of 3 reminders")
+	assert.Equal(t, &ReminderReport{Due: 3, Sent: 1, Skipped: 1, Failed: 1}, report)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "Netflix", notifier.sent[0].ServiceName)
+	assert.Equal(t, domain.ReminderRenewal, notifier.sent[0].Kind)
+
+	// Отправленное напоминание не повторяется, неотправленные повторяются
+	notifier.fail = nil
+	report, err = useCase.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &ReminderReport{Due: 2, Sent: 2}, report)
+	assert.Len(t, notifier.sent, 3)
+
+	report, err = useCase.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &ReminderReport{}, report)
+
+	// Без каналов доставки отправка невозможна
+	_, err = NewReminderUseCase(repo, nil, 3, nil).SendDue(ctx)
+	assert.Error(t, err)
+}
+
+func TestReminderUseCase_Preferences(t *testing.T) {
+	const (
+		userID  = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+		otherID = "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"
+	)
+	userCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "u", UserID: userID, Roles: []string{"viewer"}})
+	adminCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []string{auth.RoleAdmin}})
+
+	useCase := NewReminderUseCase(memory.NewReminderRepository(memory.NewSubscriptionRepository()), nil, 3, nil)
+
+	prefs, err := useCase.GetPreferences(userCtx, userID)
+	require.NoError(t, err)
+	assert.True(t, prefs.Renewal)
+	assert.True(t, prefs.Expiry)
+
+	email, off := "user@example.com", false
+	prefs, err = useCase.UpdatePreferences(userCtx, userID, UpdateReminderPreferencesInput{Email: &email, Renewal: &off})
+	require.NoError(t, err)
+	assert.Equal(t, email, prefs.Email)
+	assert.False(t, prefs.Renewal)
+	assert.True(t, prefs.Expiry, "omitted field keeps its value")
+
+	// Идентификатор приводится к каноническому виду
+	prefs, err = useCase.GetPreferences(adminCtx, "60601FEE-2BF1-4721-AE6F-7636E79A0CBA")
+	require.NoError(t, err)
+	assert.Equal(t, email, prefs.Email)
+
+	_, err = useCase.GetPreferences(userCtx, otherID)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	_, err = useCase.UpdatePreferences(adminCtx, otherID, UpdateReminderPreferencesInput{Renewal: &off})
+	assert.NoError(t, err)
+
+	bad := "not an email"
+	_, err = useCase.UpdatePreferences(userCtx, userID, UpdateReminderPreferencesInput{Email: &bad})
+	assert.ErrorContains(t, err, "invalid email")
+	_, err = useCase.GetPreferences(userCtx, "42")
+	assert.ErrorContains(t, err, "invalid user_id")
+}
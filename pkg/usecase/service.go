@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/logging"
+)
+
+// ServiceUseCase содержит логику каталога сервисов
+// После изменения записи каталога подписки с подходящими названиями связываются с ней
+type ServiceUseCase struct {
+	repo     domain.ServiceRepository
+	subs     domain.SubscriptionRepository
+	policy   *auth.Policy
+	observer Observer
+}
+
+// NewServiceUseCase создает новый экземпляр use case для каталога сервисов
+func NewServiceUseCase(repo domain.ServiceRepository, subs domain.SubscriptionRepository, policy *auth.Policy, opts ...Option) *ServiceUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &ServiceUseCase{repo: repo, subs: subs, policy: policy, observer: o.observer}
+}
+
+// servicesUseCase имя use case для наблюдателей
+const servicesUseCase = "services"
+
+// CreateService добавляет сервис в каталог и связывает с ним подписки с тем же названием или псевдонимом
+func (uc *ServiceUseCase) CreateService(ctx context.Context, req ServiceInput) (_ *domain.Service, err error) {
+	ctx, end := uc.observer.Start(ctx, servicesUseCase, "CreateService")
+	defer func() { end(err) }()
+
+	svc, err := newService(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermCatalogWrite); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.repo.Create(ctx, svc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service: %w", err)
+	}
+	if err := uc.link(ctx, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetService получает сервис каталога по ID
+func (uc *ServiceUseCase) GetService(ctx context.Context, id string) (_ *domain.Service, err error) {
+	ctx, end := uc.observer.Start(ctx, servicesUseCase, "GetService")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermCatalogRead); err != nil {
+		return nil, err
+	}
+
+	svc, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	return svc, nil
+}
+
+// UpdateService заменяет данные сервиса; связанные подписки получают новое каноническое название
+func (uc *ServiceUseCase) UpdateService(ctx context.Context, id string, req ServiceInput) (_ *domain.Service, err error) {
+	ctx, end := uc.observer.Start(ctx, servicesUseCase, "UpdateService")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	svc, err := newService(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermCatalogWrite); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.repo.Update(ctx, id, svc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+	if err := uc.link(ctx, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteService удаляет сервис из каталога; подписки сохраняют название, но теряют ссылку на каталог
+func (uc *ServiceUseCase) DeleteService(ctx context.Context, id string) (err error) {
+	ctx, end := uc.observer.Start(ctx, servicesUseCase, "DeleteService")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermCatalogWrite); err != nil {
+		return err
+	}
+
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+
+	return nil
+}
+
+// ListServices возвращает каталог сервисов организации
+func (uc *ServiceUseCase) ListServices(ctx context.Context, filters ServiceFiltersInput) (_ []*domain.Service, err error) {
+	ctx, end := uc.observer.Start(ctx, servicesUseCase, "ListServices")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermCatalogRead); err != nil {
+		return nil, err
+	}
+
+	services, err := uc.repo.List(ctx, domain.ServiceFilters{Category: normalizeCategory(filters.Category)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	return services, nil
+}
+
+// link связывает с сервисом подписки организации с подходящими названиями
+func (uc *ServiceUseCase) link(ctx context.Context, svc *domain.Service) error {
+	linked, err := uc.subs.LinkService(ctx, svc)
+	if err != nil {
+		return fmt.Errorf("failed to link subscriptions: %w", err)
+	}
+	if linked > 0 {
+		logging.FromContext(ctx).Info("Subscriptions linked to service", "service_id", svc.ID, "count", linked)
+	}
+	return nil
+}
+
+// newService проверяет входные данные и строит запись каталога
+// Пустые и повторяющиеся после нормализации псевдонимы отбрасываются
+func newService(req ServiceInput) (*domain.Service, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if domain.NormalizeServiceName(name) == "" {
+		return nil, fmt.Errorf("name must contain letters or digits")
+	}
+
+	svc := &domain.Service{
+		Name:     name,
+		Category: normalizeCategory(req.Category),
+	}
+
+	seen := map[string]bool{domain.NormalizeServiceName(name): true}
+	for _, alias := range req.Aliases {
+		alias = strings.TrimSpace(alias)
+		key := domain.NormalizeServiceName(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		svc.Aliases = append(svc.Aliases, alias)
+	}
+
+	if req.VendorURL != "" {
+		u, err := url.Parse(req.VendorURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid vendor_url %q", req.VendorURL)
+		}
+		svc.VendorURL = req.VendorURL
+	}
+
+	plans := make(map[string]bool)
+	for _, p := range req.Plans {
+		planName := strings.TrimSpace(p.Name)
+		key := domain.NormalizeServiceName(planName)
+		if key == "" {
+			return nil, fmt.Errorf("plan name is required")
+		}
+		if plans[key] {
+			return nil, fmt.Errorf("invalid plans: %q is listed twice", planName)
+		}
+		if p.Price < 0 {
+			return nil, fmt.Errorf("plan price must be non-negative")
+		}
+		plans[key] = true
+		svc.Plans = append(svc.Plans, domain.ServicePlan{Name: planName, Price: int64(p.Price)})
+	}
+
+	return svc, nil
+}
+
+// normalizeCategory приводит категорию к нижнему регистру, чтобы "Cloud" и "cloud" были одной категорией
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// ServiceInput представляет входные данные для создания и изменения записи каталога
+type ServiceInput struct {
+	Name      string
+	Aliases   []string
+	Category  string
+	VendorURL string
+	Plans     []ServicePlanInput
+}
+
+// ServicePlanInput представляет тариф сервиса с ценой по умолчанию
+type ServicePlanInput struct {
+	Name  string
+	Price int
+}
+
+// ServiceFiltersInput представляет входные данные для получения каталога
+type ServiceFiltersInput struct {
+	Category string
+}
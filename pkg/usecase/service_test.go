@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceUseCase_Catalog(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	viewerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "v", UserID: userID, Roles: []string{"viewer"}})
+	editorCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "e", UserID: userID, Roles: []string{"editor"}})
+	financeCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "f", UserID: userID, Roles: []string{"finance"}})
+
+	subs := memory.NewSubscriptionRepository()
+	catalog := memory.NewServiceRepository(subs)
+	services := NewServiceUseCase(catalog, subs, nil)
+	subscriptions := NewSubscriptionUseCase(subs, nil, WithServiceCatalog(catalog))
+
+	// Подписка, созданная до появления сервиса в каталоге
+	old, err := subscriptions.CreateSubscription(editorCtx, CreateSubscriptionInput{
+		ServiceName: "yandex plus", Price: intPtr(299), UserID: userID, StartDate: "01-2025",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, old.ServiceID)
+
+	input := ServiceInput{
+		Name:      " Yandex Plus ",
+		Aliases:   []string{"Кинопоиск", "YandexPlus", " "},
+		Category:  "Entertainment",
+		VendorURL: "https://plus.yandex.ru",
+		Plans:     []ServicePlanInput{{Name: "Standard", Price: 399}, {Name: "Multi", Price: 549}},
+	}
+	_, err = services.CreateService(viewerCtx, input)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	svc, err := services.CreateService(financeCtx, input)
+	require.NoError(t, err)
+	assert.Equal(t, "Yandex Plus", svc.Name)
+	assert.Equal(t, []string{"Кинопоиск"}, svc.Aliases, "blank aliases and spellings of the name are dropped")
+	assert.Equal(t, "entertainment", svc.Category)
+
+	// Существующая подписка связана с каталогом
+	linked, err := subscriptions.GetSubscription(editorCtx, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, svc.ID, linked.ServiceID)
+	assert.Equal(t, "Yandex Plus", linked.ServiceName)
+
+	// Название сопоставляется через псевдоним, цена берется из тарифа
+	sub, err := subscriptions.CreateSubscription(editorCtx, CreateSubscriptionInput{
+		ServiceName: "кинопоиск", Plan: "multi", UserID: userID, StartDate: "02-2025",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, svc.ID, sub.ServiceID)
+	assert.Equal(t, "Yandex Plus", sub.ServiceName)
+	assert.Equal(t, "Multi", sub.Plan)
+	assert.Equal(t, int64(549), sub.Price)
+
+	// Явная цена важнее цены тарифа
+	sub, err = subscriptions.CreateSubscription(editorCtx, CreateSubscriptionInput{
+		ServiceID: svc.ID, Plan: "Standard", Price: intPtr(199), UserID: userID, StartDate: "02-2025",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Yandex Plus", sub.ServiceName)
+	assert.Equal(t, int64(199), sub.Price)
+
+	_, err = subscriptions.CreateSubscription(editorCtx, CreateSubscriptionInput{
+		ServiceID: svc.ID, Plan: "Family", UserID: userID, StartDate: "02-2025",
+	})
+	assert.ErrorContains(t, err, `invalid plan "Family" for Yandex Plus`)
+	_, err = subscriptions.CreateSubscription(editorCtx, CreateSubscriptionInput{
+		ServiceID: svc.ID, UserID: userID, StartDate: "02-2025",
+	})
+	assert.ErrorContains(t, err, "price is required")
+
+	// Фильтр по любому написанию находит все подписки сервиса
+	list, err := subscriptions.ListSubscriptions(viewerCtx, ListFiltersInput{ServiceName: "YANDEX-PLUS"})
+	require.NoError(t, err)
+	assert.Len(t, list, 3)
+
+	// Переименование меняет название связанных подписок
+	input.Name = "Яндекс Плюс"
+	input.Aliases = []string{"Yandex Plus", "Кинопоиск"}
+	_, err = services.UpdateService(financeCtx, svc.ID, input)
+	require.NoError(t, err)
+	linked, err = subscriptions.GetSubscription(editorCtx, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Яндекс Плюс", linked.ServiceName)
+
+	catalogList, err := services.ListServices(viewerCtx, ServiceFiltersInput{Category: "ENTERTAINMENT"})
+	require.NoError(t, err)
+	assert.Len(t, catalogList, 1)
+
+	// Удаление сервиса оставляет подписки без ссылки на каталог
+	require.NoError(t, services.DeleteService(financeCtx, svc.ID))
+	linked, err = subscriptions.GetSubscription(editorCtx, old.ID)
+	require.NoError(t, err)
+	assert.Empty(t, linked.ServiceID)
+	assert.Equal(t, "Яндекс Плюс", linked.ServiceName)
+}
+
+func TestServiceUseCase_Validation(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	services := NewServiceUseCase(memory.NewServiceRepository(subs), subs, nil)
+
+	invalid := []struct {
+		name  string
+		input ServiceInput
+		err   string
+	}{
+		{"missing name", ServiceInput{Name: " "}, "name is required"},
+		{"name without letters", ServiceInput{Name: "+++"}, "name must contain letters or digits"},
+		{"bad vendor url", ServiceInput{Name: "Netflix", VendorURL: "netflix.com"}, "invalid vendor_url"},
+		{"missing plan name", ServiceInput{Name: "Netflix", Plans: []ServicePlanInput{{Price: 100}}}, "plan name is required"},
+		{"negative plan price", ServiceInput{Name: "Netflix", Plans: []ServicePlanInput{{Name: "Basic", Price: -1}}}, "plan price must be non-negative"},
+		{"duplicate plan", ServiceInput{Name: "Netflix", Plans: []ServicePlanInput{{Name: "Basic"}, {Name: "basic"}}}, "invalid plans"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.CreateService(ctx, tt.input)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := services.CreateService(ctx, ServiceInput{Name: "Netflix"})
+	require.NoError(t, err)
+	_, err = services.CreateService(ctx, ServiceInput{Name: "Netflix Basic", Aliases: []string{"NETFLIX"}})
+	assert.ErrorContains(t, err, "already used")
+
+	// Без каталога ссылка на сервис не принимается
+	_, err = NewSubscriptionUseCase(subs, nil).CreateSubscription(ctx, CreateSubscriptionInput{
+		ServiceID: "8f5d2c9e-4a4b-4d8e-9f4e-2b9e4a7c1d11", Price: intPtr(100), UserID: "60601fee-2bf1-4721-ae6f-7636e79a0cba", StartDate: "01-2025",
+	})
+	assert.ErrorContains(t, err, "the service catalog is not available")
+}
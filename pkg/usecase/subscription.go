@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/utils"
 )
@@ -12,17 +15,32 @@ import (
 // SubscriptionUseCase содержит бизнес-логику для работы с подписками
 // Реализует интерфейс SubscriptionUseCase (определен в api слое)
 type SubscriptionUseCase struct {
-	repo domain.SubscriptionRepository
+	repo     domain.SubscriptionRepository
+	catalog  domain.ServiceRepository
+	policy   *auth.Policy
+	observer Observer
+	now      func() time.Time
 }
 
 // NewSubscriptionUseCase создает новый экземпляр use case для подписок
-func NewSubscriptionUseCase(repo domain.SubscriptionRepository) *SubscriptionUseCase {
-	return &SubscriptionUseCase{repo: repo}
+// Права вызывающих проверяются по policy; nil означает политику по умолчанию
+func NewSubscriptionUseCase(repo domain.SubscriptionRepository, policy *auth.Policy, opts ...Option) *SubscriptionUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &SubscriptionUseCase{repo: repo, catalog: o.catalog, policy: policy, observer: o.observer, now: time.Now}
 }
 
+// subscriptionsUseCase имя use case для наблюдателей
+const subscriptionsUseCase = "subscriptions"
+
 // CreateSubscription создает новую подписку
 // Реализует интерфейс api.SubscriptionUseCase
-func (uc *SubscriptionUseCase) CreateSubscription(ctx context.Context, req CreateSubscriptionInput) (*domain.Subscription, error) {
+func (uc *SubscriptionUseCase) CreateSubscription(ctx context.Context, req CreateSubscriptionInput) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "CreateSubscription")
+	defer func() { end(err) }()
+
 	// Валидация и парсинг даты начала
 	startDate, err := utils.ParseToMonthYear(req.StartDate)
 	if err != nil {
@@ -39,24 +57,44 @@ func (uc *SubscriptionUseCase) CreateSubscription(ctx context.Context, req Creat
 		endDate = sql.NullTime{Time: date, Valid: true}
 	}
 
+	// Сопоставление с каталогом сервисов: каноническое название и цена тарифа по умолчанию
+	sub := &domain.Subscription{
+		ServiceName: req.ServiceName,
+		ServiceID:   req.ServiceID,
+		Plan:        req.Plan,
+		UserID:      req.UserID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		TrialMonths: req.TrialMonths,
+		IntroPrice:  int64(req.IntroPrice),
+		IntroMonths: req.IntroMonths,
+	}
+	price, err := uc.applyCatalog(ctx, sub, req.Price)
+	if err != nil {
+		return nil, err
+	}
+
 	// Валидация бизнес-правил
-	if req.ServiceName == "" {
+	if sub.ServiceName == "" {
 		return nil, fmt.Errorf("service_name is required")
 	}
-	if req.Price < 0 {
-		return nil, fmt.Errorf("price must be non-negative")
+	if err := validatePrice(price); err != nil {
+		return nil, err
+	}
+	sub.Price = int64(*price)
+	if err := validatePromo(req.TrialMonths, req.IntroPrice, req.IntroMonths); err != nil {
+		return nil, err
 	}
 	if req.UserID == "" {
 		return nil, fmt.Errorf("user_id is required")
 	}
+	if sub.Tags, err = normalizeTags(req.Tags); err != nil {
+		return nil, err
+	}
 
-	// Создание доменной модели
-	sub := &domain.Subscription{
-		ServiceName: req.ServiceName,
-		Price:       int64(req.Price),
-		UserID:      req.UserID,
-		StartDate:   startDate,
-		EndDate:     endDate,
+	// Проверка прав: пользователь может создавать подписки только для себя
+	if err := checkOwnership(ctx, uc.policy, auth.PermSubscriptionsWrite, auth.PermSubscriptionsWriteAll, &domain.Subscription{UserID: req.UserID}); err != nil {
+		return nil, err
 	}
 
 	// Сохранение через репозиторий
@@ -69,7 +107,10 @@ func (uc *SubscriptionUseCase) CreateSubscription(ctx context.Context, req Creat
 }
 
 // GetSubscription получает подписку по ID
-func (uc *SubscriptionUseCase) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+func (uc *SubscriptionUseCase) GetSubscription(ctx context.Context, id string) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "GetSubscription")
+	defer func() { end(err) }()
+
 	if id == "" {
 		return nil, fmt.Errorf("id is required")
 	}
@@ -79,12 +120,20 @@ func (uc *SubscriptionUseCase) GetSubscription(ctx context.Context, id string) (
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
+	// Проверка прав доступа к подписке
+	if err := checkOwnership(ctx, uc.policy, auth.PermSubscriptionsRead, auth.PermSubscriptionsReadAll, sub); err != nil {
+		return nil, err
+	}
+
 	return sub, nil
 }
 
 // UpdateSubscription обновляет подписку
 // Реализует интерфейс api.SubscriptionUseCase
-func (uc *SubscriptionUseCase) UpdateSubscription(ctx context.Context, id string, req UpdateSubscriptionInput) (*domain.Subscription, error) {
+func (uc *SubscriptionUseCase) UpdateSubscription(ctx context.Context, id string, req UpdateSubscriptionInput) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "UpdateSubscription")
+	defer func() { end(err) }()
+
 	if id == "" {
 		return nil, fmt.Errorf("id is required")
 	}
@@ -105,20 +154,37 @@ func (uc *SubscriptionUseCase) UpdateSubscription(ctx context.Context, id string
 		endDate = sql.NullTime{Time: date, Valid: true}
 	}
 
+	// Сопоставление с каталогом сервисов: каноническое название и цена тарифа по умолчанию
+	sub := &domain.Subscription{
+		ServiceName: req.ServiceName,
+		ServiceID:   req.ServiceID,
+		Plan:        req.Plan,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		TrialMonths: req.TrialMonths,
+		IntroPrice:  int64(req.IntroPrice),
+		IntroMonths: req.IntroMonths,
+	}
+	price, err := uc.applyCatalog(ctx, sub, req.Price)
+	if err != nil {
+		return nil, err
+	}
+
 	// Валидация бизнес-правил
-	if req.ServiceName == "" {
+	if sub.ServiceName == "" {
 		return nil, fmt.Errorf("service_name is required")
 	}
-	if req.Price < 0 {
-		return nil, fmt.Errorf("price must be non-negative")
+	if err := validatePrice(price); err != nil {
+		return nil, err
+	}
+	sub.Price = int64(*price)
+	if err := validatePromo(req.TrialMonths, req.IntroPrice, req.IntroMonths); err != nil {
+		return nil, err
 	}
 
-	// Создание доменной модели для обновления
-	sub := &domain.Subscription{
-		ServiceName: req.ServiceName,
-		Price:       int64(req.Price),
-		StartDate:   startDate,
-		EndDate:     endDate,
+	// Проверка прав доступа к подписке
+	if err := uc.authorizeWrite(ctx, id); err != nil {
+		return nil, err
 	}
 
 	// Обновление через репозиторий
@@ -131,12 +197,20 @@ func (uc *SubscriptionUseCase) UpdateSubscription(ctx context.Context, id string
 }
 
 // DeleteSubscription удаляет подписку
-func (uc *SubscriptionUseCase) DeleteSubscription(ctx context.Context, id string) error {
+func (uc *SubscriptionUseCase) DeleteSubscription(ctx context.Context, id string) (err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "DeleteSubscription")
+	defer func() { end(err) }()
+
 	if id == "" {
 		return fmt.Errorf("id is required")
 	}
 
-	err := uc.repo.Delete(ctx, id)
+	// Проверка прав доступа к подписке
+	if err := uc.authorizeWrite(ctx, id); err != nil {
+		return err
+	}
+
+	err = uc.repo.Delete(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
@@ -144,9 +218,43 @@ func (uc *SubscriptionUseCase) DeleteSubscription(ctx context.Context, id string
 	return nil
 }
 
+// DeleteSubscriptions удаляет все подписки, подходящие под фильтры
+// Требует права на массовое удаление и хотя бы одного фильтра
+func (uc *SubscriptionUseCase) DeleteSubscriptions(ctx context.Context, filters DeleteFiltersInput) (_ int64, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "DeleteSubscriptions")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermSubscriptionsBulkDelete); err != nil {
+		return 0, err
+	}
+
+	// Валидация бизнес-правил: удаление всех подписок без фильтров не допускается
+	if filters.UserID == "" && filters.ServiceName == "" {
+		return 0, fmt.Errorf("user_id or service_name is required")
+	}
+
+	serviceName, err := uc.canonicalName(ctx, filters.ServiceName)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := uc.repo.DeleteMany(ctx, domain.DeleteFilters{
+		UserID:      filters.UserID,
+		ServiceName: serviceName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+
+	return deleted, nil
+}
+
 // ListSubscriptions возвращает список подписок с фильтрацией
 // Реализует интерфейс api.SubscriptionUseCase
-func (uc *SubscriptionUseCase) ListSubscriptions(ctx context.Context, filters ListFiltersInput) ([]*domain.Subscription, error) {
+func (uc *SubscriptionUseCase) ListSubscriptions(ctx context.Context, filters ListFiltersInput) (_ []*domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "ListSubscriptions")
+	defer func() { end(err) }()
+
 	// Валидация параметров пагинации
 	if filters.Limit <= 0 {
 		filters.Limit = 10 // значение по умолчанию
@@ -154,15 +262,38 @@ func (uc *SubscriptionUseCase) ListSubscriptions(ctx context.Context, filters Li
 	if filters.Offset < 0 {
 		filters.Offset = 0
 	}
+	if filters.TrialEndingDays < 0 {
+		return nil, fmt.Errorf("trial_ending_days must be non-negative")
+	}
+
+	// Ограничение выборки подписками вызывающего
+	userID, err := scopeUserFilter(ctx, uc.policy, auth.PermSubscriptionsRead, auth.PermSubscriptionsReadAll, filters.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName, err := uc.canonicalName(ctx, filters.ServiceName)
+	if err != nil {
+		return nil, err
+	}
 
 	// Преобразование запроса в доменные фильтры
 	domainFilters := domain.ListFilters{
-		UserID:      filters.UserID,
-		ServiceName: filters.ServiceName,
+		UserID:      userID,
+		ServiceName: serviceName,
+		Tag:         domain.NormalizeTag(filters.Tag),
 		Limit:       filters.Limit,
 		Offset:      filters.Offset,
 	}
 
+	// Пробные периоды, которые заканчиваются с сегодняшнего дня по TrialEndingDays дней вперед
+	if filters.TrialEndingDays > 0 {
+		now := uc.now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		domainFilters.TrialEndsFrom = today
+		domainFilters.TrialEndsTo = today.AddDate(0, 0, filters.TrialEndingDays)
+	}
+
 	// Получение списка через репозиторий
 	subs, err := uc.repo.List(ctx, domainFilters)
 	if err != nil {
@@ -174,74 +305,486 @@ func (uc *SubscriptionUseCase) ListSubscriptions(ctx context.Context, filters Li
 
 // GetSubscriptionsSummary вычисляет общую стоимость подписок за период
 // Реализует интерфейс api.SubscriptionUseCase
-func (uc *SubscriptionUseCase) GetSubscriptionsSummary(ctx context.Context, filters SummaryFiltersInput) (int64, error) {
+func (uc *SubscriptionUseCase) GetSubscriptionsSummary(ctx context.Context, filters SummaryFiltersInput) (_ int64, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "GetSubscriptionsSummary")
+	defer func() { end(err) }()
+
+	domainFilters, err := uc.summaryFilters(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	// Вычисление суммы через репозиторий
+	total, err := uc.repo.GetSummary(ctx, domainFilters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetSubscriptionsSummaryGroups вычисляет стоимость подписок за период по тегам или категориям каталога
+// Подписка с несколькими тегами входит в каждую группу, поэтому сумма групп может превышать общую стоимость
+func (uc *SubscriptionUseCase) GetSubscriptionsSummaryGroups(ctx context.Context, filters SummaryFiltersInput, groupBy string) (_ []domain.SummaryGroup, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "GetSubscriptionsSummaryGroups")
+	defer func() { end(err) }()
+
+	if groupBy != domain.SummaryByTag && groupBy != domain.SummaryByCategory {
+		return nil, fmt.Errorf("invalid group_by %q: must be %s or %s", groupBy, domain.SummaryByTag, domain.SummaryByCategory)
+	}
+
+	domainFilters, err := uc.summaryFilters(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := uc.repo.GetGroupedSummary(ctx, domainFilters, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate summary: %w", err)
+	}
+
+	return groups, nil
+}
+
+// summaryFilters проверяет период суммы и ограничивает подсчет подписками вызывающего
+func (uc *SubscriptionUseCase) summaryFilters(ctx context.Context, filters SummaryFiltersInput) (domain.SummaryFilters, error) {
 	// Валидация обязательных полей
 	if filters.PeriodStart == "" || filters.PeriodEnd == "" {
-		return 0, fmt.Errorf("period_start and period_end are required")
+		return domain.SummaryFilters{}, fmt.Errorf("period_start and period_end are required")
 	}
 
 	// Парсинг дат
 	periodStart, err := utils.ParseToMonthYear(filters.PeriodStart)
 	if err != nil {
-		return 0, fmt.Errorf("invalid period_start format: %w", err)
+		return domain.SummaryFilters{}, fmt.Errorf("invalid period_start format: %w", err)
 	}
 
 	periodEnd, err := utils.ParseToMonthYear(filters.PeriodEnd)
 	if err != nil {
-		return 0, fmt.Errorf("invalid period_end format: %w", err)
+		return domain.SummaryFilters{}, fmt.Errorf("invalid period_end format: %w", err)
 	}
 
 	// Валидация бизнес-правил
 	if periodStart.After(periodEnd) {
-		return 0, fmt.Errorf("period_start must be before or equal to period_end")
+		return domain.SummaryFilters{}, fmt.Errorf("period_start must be before or equal to period_end")
+	}
+
+	// Ограничение подсчета подписками вызывающего
+	userID, err := scopeUserFilter(ctx, uc.policy, auth.PermSummaryRead, auth.PermSummaryReadAll, filters.UserID)
+	if err != nil {
+		return domain.SummaryFilters{}, err
+	}
+
+	serviceName, err := uc.canonicalName(ctx, filters.ServiceName)
+	if err != nil {
+		return domain.SummaryFilters{}, err
 	}
 
 	// Преобразование запроса в доменные фильтры
-	domainFilters := domain.SummaryFilters{
-		UserID:      filters.UserID,
-		ServiceName: filters.ServiceName,
+	return domain.SummaryFilters{
+		UserID:      userID,
+		ServiceName: serviceName,
+		Tag:         domain.NormalizeTag(filters.Tag),
 		PeriodStart: periodStart,
 		PeriodEnd:   periodEnd,
+	}, nil
+}
+
+// SetSubscriptionTags заменяет теги подписки; новые теги добавляются в список тегов организации
+func (uc *SubscriptionUseCase) SetSubscriptionTags(ctx context.Context, id string, names []string) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "SetSubscriptionTags")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	tags, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorizeWrite(ctx, id); err != nil {
+		return nil, err
 	}
 
-	// Вычисление суммы через репозиторий
-	total, err := uc.repo.GetSummary(ctx, domainFilters)
+	updated, err := uc.repo.SetTags(ctx, id, tags)
 	if err != nil {
-		return 0, fmt.Errorf("failed to calculate summary: %w", err)
+		return nil, fmt.Errorf("failed to update subscription tags: %w", err)
 	}
 
-	return total, nil
+	return updated, nil
+}
+
+// PauseSubscription приостанавливает оплату подписки с месяца From по месяц Until включительно
+// Без From пауза начинается с текущего месяца, без Until длится до возобновления
+func (uc *SubscriptionUseCase) PauseSubscription(ctx context.Context, id string, req PauseInput) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "PauseSubscription")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	from, err := uc.monthOrCurrent(req.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from format: %w", err)
+	}
+	pause := domain.Pause{Start: from}
+	if req.Until != "" {
+		until, err := utils.ParseToMonthYear(req.Until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until format: %w", err)
+		}
+		if until.Before(from) {
+			return nil, fmt.Errorf("until must be on or after from")
+		}
+		pause.End = sql.NullTime{Time: until, Valid: true}
+	}
+
+	// Проверки и запись идут над заблокированной подпиской, чтобы параллельная пауза не перезаписала эту
+	return uc.repo.UpdatePauses(ctx, id, func(sub *domain.Subscription) ([]domain.Pause, error) {
+		if err := checkOwnership(ctx, uc.policy, auth.PermSubscriptionsWrite, auth.PermSubscriptionsWriteAll, sub); err != nil {
+			return nil, err
+		}
+
+		// Пауза должна начинаться в пределах действия подписки
+		if from.Before(sub.StartDate) || (sub.EndDate.Valid && from.After(sub.EndDate.Time)) {
+			return nil, fmt.Errorf("pause start must be within the subscription period")
+		}
+		for _, p := range sub.Pauses {
+			if overlaps(p, pause) {
+				return nil, fmt.Errorf("subscription is already paused from %s", p.Start.Format("01-2006"))
+			}
+		}
+		return append(sub.Pauses, pause), nil
+	})
+}
+
+// ResumeSubscription возобновляет оплату подписки с месяца From (по умолчанию текущего)
+// Пауза, начинающаяся не раньше From, отменяется целиком, иначе заканчивается месяцем перед From
+func (uc *SubscriptionUseCase) ResumeSubscription(ctx context.Context, id string, req ResumeInput) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "ResumeSubscription")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	from, err := uc.monthOrCurrent(req.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from format: %w", err)
+	}
+
+	return uc.repo.UpdatePauses(ctx, id, func(sub *domain.Subscription) ([]domain.Pause, error) {
+		if err := checkOwnership(ctx, uc.policy, auth.PermSubscriptionsWrite, auth.PermSubscriptionsWriteAll, sub); err != nil {
+			return nil, err
+		}
+
+		// Возобновляется пауза, на которую приходится From, а если ее нет, то бессрочная пауза в будущем
+		target := -1
+		for i, p := range sub.Pauses {
+			if p.Covers(from) || (target < 0 && !p.End.Valid) {
+				target = i
+			}
+		}
+		if target < 0 {
+			return nil, fmt.Errorf("subscription is not paused")
+		}
+
+		pauses := make([]domain.Pause, 0, len(sub.Pauses))
+		for i, p := range sub.Pauses {
+			if i == target {
+				if !from.After(p.Start) {
+					continue
+				}
+				p.End = sql.NullTime{Time: from.AddDate(0, -1, 0), Valid: true}
+			}
+			pauses = append(pauses, p)
+		}
+		return pauses, nil
+	})
+}
+
+// ScheduleChange планирует новую цену, тариф или отмену подписки с будущего месяца EffectiveFrom
+// Изменение применяется фоновой задачей, но суммы и прогнозы учитывают его сразу
+func (uc *SubscriptionUseCase) ScheduleChange(ctx context.Context, id string, req ScheduleChangeInput) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "ScheduleChange")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if req.EffectiveFrom == "" {
+		return nil, fmt.Errorf("effective_from is required")
+	}
+	from, err := utils.ParseToMonthYear(req.EffectiveFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective_from format: %w", err)
+	}
+	current, _ := uc.monthOrCurrent("")
+	if !from.After(current) {
+		return nil, fmt.Errorf("effective_from must be after the current month")
+	}
+
+	change := domain.ScheduledChange{EffectiveFrom: from, Cancel: req.Cancel}
+	if req.Price != nil {
+		if *req.Price < 0 {
+			return nil, fmt.Errorf("price must be non-negative")
+		}
+		change.Price = sql.NullInt64{Int64: int64(*req.Price), Valid: true}
+	}
+	if req.Plan != nil {
+		change.Plan = sql.NullString{String: *req.Plan, Valid: true}
+	}
+	switch {
+	case req.Cancel && (change.Price.Valid || change.Plan.Valid):
+		return nil, fmt.Errorf("cancel must not be combined with price or plan")
+	case !req.Cancel && !change.Price.Valid && !change.Plan.Valid:
+		return nil, fmt.Errorf("price, plan or cancel is required")
+	}
+
+	// Проверки и запись идут над заблокированной подпиской: иначе задача ApplyChanges могла бы применить изменение
+	// между чтением и записью, и запись вернула бы его в список, а цена попала бы в историю дважды
+	return uc.repo.UpdateChanges(ctx, id, func(sub *domain.Subscription) ([]domain.ScheduledChange, error) {
+		if err := checkOwnership(ctx, uc.policy, auth.PermSubscriptionsWrite, auth.PermSubscriptionsWriteAll, sub); err != nil {
+			return nil, err
+		}
+
+		// Изменение с месяца начала заменило бы саму подписку, а после окончания ни на что не влияет
+		if !from.After(sub.StartDate) || (sub.EndDate.Valid && from.After(sub.EndDate.Time)) {
+			return nil, fmt.Errorf("effective_from must be within the subscription period")
+		}
+		// После отмены изменения бессмысленны, поэтому отмена должна быть последней
+		for _, c := range sub.Changes {
+			if c.EffectiveFrom.Equal(from) || (c.Cancel && c.EffectiveFrom.Before(from)) || (req.Cancel && c.EffectiveFrom.After(from)) {
+				return nil, fmt.Errorf("a change is already scheduled for %s", c.EffectiveFrom.Format("01-2006"))
+			}
+		}
+		return append(sub.Changes, change), nil
+	})
+}
+
+// DeleteScheduledChange отменяет запланированное на месяц month изменение подписки
+func (uc *SubscriptionUseCase) DeleteScheduledChange(ctx context.Context, id, month string) (_ *domain.Subscription, err error) {
+	ctx, end := uc.observer.Start(ctx, subscriptionsUseCase, "DeleteScheduledChange")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	from, err := utils.ParseToMonthYear(month)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month format: %w", err)
+	}
+
+	return uc.repo.UpdateChanges(ctx, id, func(sub *domain.Subscription) ([]domain.ScheduledChange, error) {
+		if err := checkOwnership(ctx, uc.policy, auth.PermSubscriptionsWrite, auth.PermSubscriptionsWriteAll, sub); err != nil {
+			return nil, err
+		}
+
+		changes := make([]domain.ScheduledChange, 0, len(sub.Changes))
+		for _, c := range sub.Changes {
+			if !c.EffectiveFrom.Equal(from) {
+				changes = append(changes, c)
+			}
+		}
+		if len(changes) == len(sub.Changes) {
+			return nil, fmt.Errorf("scheduled change not found")
+		}
+		return changes, nil
+	})
+}
+
+// monthOrCurrent разбирает месяц в формате MM-YYYY; пустая строка означает текущий месяц
+func (uc *SubscriptionUseCase) monthOrCurrent(input string) (time.Time, error) {
+	if input == "" {
+		now := uc.now().UTC()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return utils.ParseToMonthYear(input)
+}
+
+// overlaps проверяет, есть ли у приостановок общие месяцы
+func overlaps(a, b domain.Pause) bool {
+	return (!a.End.Valid || !a.End.Time.Before(b.Start)) && (!b.End.Valid || !b.End.Time.Before(a.Start))
+}
+
+// authorizeWrite проверяет право вызывающего на изменение существующей подписки
+// Если право не ограничено собственными подписками, запрос в репозиторий не выполняется
+func (uc *SubscriptionUseCase) authorizeWrite(ctx context.Context, id string) error {
+	_, restricted, err := uc.policy.ScopeUserID(ctx, auth.PermSubscriptionsWrite, auth.PermSubscriptionsWriteAll)
+	if err != nil || !restricted {
+		return err
+	}
+
+	sub, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	return checkOwnership(ctx, uc.policy, auth.PermSubscriptionsWrite, auth.PermSubscriptionsWriteAll, sub)
+}
+
+// applyCatalog связывает подписку с записью каталога по ServiceID или по названию и возвращает цену:
+// явно указанную или цену тарифа по умолчанию. Без каталога подписка сохраняется как есть
+func (uc *SubscriptionUseCase) applyCatalog(ctx context.Context, sub *domain.Subscription, price *int) (*int, error) {
+	svc, err := uc.matchService(ctx, sub.ServiceID, sub.ServiceName)
+	if err != nil || svc == nil {
+		return price, err
+	}
+
+	sub.ServiceID = svc.ID
+	sub.ServiceName = svc.Name
+	if sub.Plan == "" || len(svc.Plans) == 0 {
+		return price, nil
+	}
+
+	plan, ok := svc.Plan(sub.Plan)
+	if !ok {
+		return nil, fmt.Errorf("invalid plan %q for %s", sub.Plan, svc.Name)
+	}
+	sub.Plan = plan.Name
+	if price == nil {
+		p := int(plan.Price)
+		price = &p
+	}
+	return price, nil
+}
+
+// matchService ищет запись каталога; nil без ошибки означает, что подписка не относится к каталогу
+func (uc *SubscriptionUseCase) matchService(ctx context.Context, serviceID, serviceName string) (*domain.Service, error) {
+	if serviceID != "" {
+		if uc.catalog == nil {
+			return nil, fmt.Errorf("invalid service_id: the service catalog is not available")
+		}
+		svc, err := uc.catalog.GetByID(ctx, serviceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service_id: %w", err)
+		}
+		return svc, nil
+	}
+	if uc.catalog == nil || strings.TrimSpace(serviceName) == "" {
+		return nil, nil
+	}
+
+	svc, err := uc.catalog.FindByName(ctx, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find service: %w", err)
+	}
+	return svc, nil
+}
+
+// canonicalName заменяет название в фильтре каноническим, чтобы "yandex plus" находил подписки "Yandex Plus"
+func (uc *SubscriptionUseCase) canonicalName(ctx context.Context, serviceName string) (string, error) {
+	svc, err := uc.matchService(ctx, "", serviceName)
+	if err != nil || svc == nil {
+		return serviceName, err
+	}
+	return svc.Name, nil
+}
+
+// validatePrice проверяет цену подписки после подстановки цены тарифа
+func validatePrice(price *int) error {
+	if price == nil {
+		return fmt.Errorf("price is required")
+	}
+	if *price < 0 {
+		return fmt.Errorf("price must be non-negative")
+	}
+	return nil
+}
+
+// validatePromo проверяет пробный период и вводную цену
+func validatePromo(trialMonths, introPrice, introMonths int) error {
+	if trialMonths < 0 || trialMonths > domain.MaxPromoMonths {
+		return fmt.Errorf("trial_months must be between 0 and %d", domain.MaxPromoMonths)
+	}
+	if introMonths < 0 || introMonths > domain.MaxPromoMonths {
+		return fmt.Errorf("intro_months must be between 0 and %d", domain.MaxPromoMonths)
+	}
+	if introPrice < 0 {
+		return fmt.Errorf("intro_price must be non-negative")
+	}
+	if introPrice > 0 && introMonths == 0 {
+		return fmt.Errorf("intro_months is required with intro_price")
+	}
+	return nil
 }
 
 // CreateSubscriptionInput представляет входные данные для создания подписки
 type CreateSubscriptionInput struct {
 	ServiceName string
-	Price       int
+	// ServiceID ссылка на каталог; при ней ServiceName можно не указывать
+	ServiceID string
+	Plan      string
+	// Price nil означает цену тарифа Plan из каталога
+	Price       *int
 	UserID      string
 	StartDate   string
 	EndDate     string
+	TrialMonths int
+	IntroPrice  int
+	IntroMonths int
+	// Tags теги подписки; регистр и лишние пробелы не учитываются
+	Tags []string
 }
 
 // UpdateSubscriptionInput представляет входные данные для обновления подписки
 type UpdateSubscriptionInput struct {
 	ServiceName string
-	Price       int
+	// ServiceID ссылка на каталог; при ней ServiceName можно не указывать
+	ServiceID string
+	Plan      string
+	// Price nil означает цену тарифа Plan из каталога
+	Price       *int
 	StartDate   string
 	EndDate     string
+	TrialMonths int
+	IntroPrice  int
+	IntroMonths int
 }
 
 // ListFiltersInput представляет входные данные для получения списка подписок
 type ListFiltersInput struct {
 	UserID      string
 	ServiceName string
-	Limit       int
-	Offset      int
+	Tag         string
+	// TrialEndingDays больше нуля отбирает подписки, пробный период которых заканчивается в ближайшие дни
+	TrialEndingDays int
+	Limit           int
+	Offset          int
+}
+
+// PauseInput представляет входные данные для приостановки подписки
+type PauseInput struct {
+	From  string
+	Until string
+}
+
+// ResumeInput представляет входные данные для возобновления подписки
+type ResumeInput struct {
+	From string
+}
+
+// ScheduleChangeInput представляет входные данные для планирования изменения подписки
+// Price и Plan не заданы, если не меняются; Cancel с ними не сочетается
+type ScheduleChangeInput struct {
+	EffectiveFrom string
+	Price         *int
+	Plan          *string
+	Cancel        bool
+}
+
+// DeleteFiltersInput представляет входные данные для массового удаления подписок
+type DeleteFiltersInput struct {
+	UserID      string
+	ServiceName string
 }
 
 // SummaryFiltersInput представляет входные данные для получения суммы подписок
 type SummaryFiltersInput struct {
 	UserID      string
 	ServiceName string
+	Tag         string
 	PeriodStart string
 	PeriodEnd   string
 }
@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
 	"github.com/asgard-born/rest_service_subscriptions/pkg/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -15,7 +17,7 @@ import (
 
 func TestSubscriptionUseCase_CreateSubscription(t *testing.T) {
 	mockRepo := &mocks.SubscriptionRepository{}
-	useCase := NewSubscriptionUseCase(mockRepo)
+	useCase := NewSubscriptionUseCase(mockRepo, nil)
 
 	tests := []struct {
 		name        string
@@ -28,7 +30,7 @@ func TestSubscriptionUseCase_CreateSubscription(t *testing.T) {
 			name: "successful creation",
 			input: CreateSubscriptionInput{
 				ServiceName: "Netflix",
-				Price:       1000,
+				Price:       intPtr(1000),
 				UserID:      "user-123",
 				StartDate:   "2024-01-01",
 				EndDate:     "2024-12-31",
@@ -67,7 +69,7 @@ func TestSubscriptionUseCase_CreateSubscription(t *testing.T) {
 			name: "invalid price",
 			input: CreateSubscriptionInput{
 				ServiceName: "Netflix",
-				Price:       -100, // Отрицательная цена
+				Price:       intPtr(-100), // Отрицательная цена
 				UserID:      "user-123",
 				StartDate:   "2024-01-01",
 			},
@@ -79,7 +81,7 @@ func TestSubscriptionUseCase_CreateSubscription(t *testing.T) {
 			name: "missing required fields",
 			input: CreateSubscriptionInput{
 				ServiceName: "", // Пустое имя сервиса
-				Price:       1000,
+				Price:       intPtr(1000),
 				UserID:      "",
 				StartDate:   "2024-01-01",
 			},
@@ -111,7 +113,7 @@ func TestSubscriptionUseCase_CreateSubscription(t *testing.T) {
 
 func TestSubscriptionUseCase_GetSubscription(t *testing.T) {
 	mockRepo := &mocks.SubscriptionRepository{}
-	useCase := NewSubscriptionUseCase(mockRepo)
+	useCase := NewSubscriptionUseCase(mockRepo, nil)
 
 	t.Run("success", func(t *testing.T) {
 		expectedSub := &domain.Subscription{
@@ -143,12 +145,12 @@ func TestSubscriptionUseCase_GetSubscription(t *testing.T) {
 
 func TestSubscriptionUseCase_UpdateSubscription(t *testing.T) {
 	mockRepo := &mocks.SubscriptionRepository{}
-	useCase := NewSubscriptionUseCase(mockRepo)
+	useCase := NewSubscriptionUseCase(mockRepo, nil)
 
 	t.Run("success", func(t *testing.T) {
 		input := UpdateSubscriptionInput{
 			ServiceName: "Netflix Premium",
-			Price:       1500,
+			Price:       intPtr(1500),
 			StartDate:   "2024-01-01",
 			EndDate:     "2024-12-31",
 		}
@@ -173,7 +175,7 @@ func TestSubscriptionUseCase_UpdateSubscription(t *testing.T) {
 
 func TestSubscriptionUseCase_ListSubscriptions(t *testing.T) {
 	mockRepo := &mocks.SubscriptionRepository{}
-	useCase := NewSubscriptionUseCase(mockRepo)
+	useCase := NewSubscriptionUseCase(mockRepo, nil)
 
 	t.Run("with filters", func(t *testing.T) {
 		filters := ListFiltersInput{
@@ -199,6 +201,127 @@ func TestSubscriptionUseCase_ListSubscriptions(t *testing.T) {
 	})
 }
 
+func TestSubscriptionUseCase_AccessScoping(t *testing.T) {
+	userCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "user-123", UserID: "user-123"})
+	adminCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "root", Roles: []string{auth.RoleAdmin}})
+
+	t.Run("list is forced to caller user_id", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+
+		mockRepo.On("List", mock.Anything, domain.ListFilters{UserID: "user-123", Limit: 10}).
+			Return([]*domain.Subscription{}, nil)
+
+		_, err := useCase.ListSubscriptions(userCtx, ListFiltersInput{UserID: "other-user", Limit: 10})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("admin keeps requested user_id", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+
+		mockRepo.On("List", mock.Anything, domain.ListFilters{UserID: "other-user", Limit: 10}).
+			Return([]*domain.Subscription{}, nil)
+
+		_, err := useCase.ListSubscriptions(adminCtx, ListFiltersInput{UserID: "other-user", Limit: 10})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("get of another user's subscription is forbidden", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+
+		mockRepo.On("GetByID", mock.Anything, "sub-1").
+			Return(&domain.Subscription{ID: "sub-1", UserID: "other-user"}, nil)
+
+		result, err := useCase.GetSubscription(userCtx, "sub-1")
+
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		assert.Nil(t, result)
+	})
+
+	t.Run("delete of another user's subscription is forbidden", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+
+		mockRepo.On("GetByID", mock.Anything, "sub-1").
+			Return(&domain.Subscription{ID: "sub-1", UserID: "other-user"}, nil)
+
+		err := useCase.DeleteSubscription(userCtx, "sub-1")
+
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, "sub-1")
+	})
+
+	t.Run("finance sees summaries of all users", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+		financeCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "f", UserID: "f", Roles: []string{"finance"}})
+
+		mockRepo.On("GetSummary", mock.Anything, mock.MatchedBy(func(f domain.SummaryFilters) bool {
+			return f.UserID == "other-user"
+		})).Return(int64(500), nil)
+
+		total, err := useCase.GetSubscriptionsSummary(financeCtx, SummaryFiltersInput{
+			UserID:      "other-user",
+			PeriodStart: "01-2024",
+			PeriodEnd:   "12-2024",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(500), total)
+	})
+
+	t.Run("viewer cannot update", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+		viewerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "v", UserID: "v", Roles: []string{"viewer"}})
+
+		_, err := useCase.UpdateSubscription(viewerCtx, "sub-1", UpdateSubscriptionInput{
+			ServiceName: "Netflix",
+			Price:       intPtr(100),
+			StartDate:   "01-2024",
+		})
+
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("only admin can bulk delete", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+
+		_, err := useCase.DeleteSubscriptions(userCtx, DeleteFiltersInput{UserID: "user-123"})
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+
+		mockRepo.On("DeleteMany", mock.Anything, domain.DeleteFilters{UserID: "user-123"}).Return(int64(3), nil)
+
+		deleted, err := useCase.DeleteSubscriptions(adminCtx, DeleteFiltersInput{UserID: "user-123"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), deleted)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create for another user is forbidden", func(t *testing.T) {
+		mockRepo := &mocks.SubscriptionRepository{}
+		useCase := NewSubscriptionUseCase(mockRepo, nil)
+
+		_, err := useCase.CreateSubscription(userCtx, CreateSubscriptionInput{
+			ServiceName: "Netflix",
+			Price:       intPtr(100),
+			UserID:      "other-user",
+			StartDate:   "01-2024",
+		})
+
+		assert.ErrorIs(t, err, auth.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
 // Вспомогательная функция для парсинга дат
 func mustParseDate(dateStr string) time.Time {
 	t, err := time.Parse("2006-01-02", dateStr)
@@ -207,3 +330,203 @@ func mustParseDate(dateStr string) time.Time {
 	}
 	return t
 }
+
+// Вспомогательная функция для необязательной цены
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestSubscriptionUseCase_Trials(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	ctx := context.Background()
+
+	useCase := NewSubscriptionUseCase(memory.NewSubscriptionRepository(), nil)
+	useCase.now = func() time.Time { return time.Date(2025, time.March, 20, 15, 0, 0, 0, time.UTC) }
+
+	invalid := []struct {
+		name  string
+		input CreateSubscriptionInput
+		err   string
+	}{
+		{"negative trial", CreateSubscriptionInput{TrialMonths: -1}, "trial_months must be between"},
+		{"too long intro", CreateSubscriptionInput{IntroPrice: 10, IntroMonths: 121}, "intro_months must be between"},
+		{"negative intro price", CreateSubscriptionInput{IntroPrice: -1, IntroMonths: 1}, "intro_price must be non-negative"},
+		{"intro price without months", CreateSubscriptionInput{IntroPrice: 10}, "intro_months is required"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.ServiceName, tt.input.Price, tt.input.UserID, tt.input.StartDate = "Netflix", intPtr(100), userID, "01-2025"
+			_, err := useCase.CreateSubscription(ctx, tt.input)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// Пробный период заканчивается 1 апреля, через 12 дней
+	ending, err := useCase.CreateSubscription(ctx, CreateSubscriptionInput{
+		ServiceName: "Netflix", Price: intPtr(100), UserID: userID, StartDate: "02-2025", TrialMonths: 2, IntroPrice: 50, IntroMonths: 1,
+	})
+	assert.NoError(t, err)
+	_, err = useCase.CreateSubscription(ctx, CreateSubscriptionInput{
+		ServiceName: "Spotify", Price: intPtr(10), UserID: userID, StartDate: "03-2025", TrialMonths: 3,
+	})
+	assert.NoError(t, err)
+
+	subs, err := useCase.ListSubscriptions(ctx, ListFiltersInput{TrialEndingDays: 14})
+	assert.NoError(t, err)
+	if assert.Len(t, subs, 1) {
+		assert.Equal(t, ending.ID, subs[0].ID)
+	}
+
+	subs, err = useCase.ListSubscriptions(ctx, ListFiltersInput{TrialEndingDays: 7})
+	assert.NoError(t, err)
+	assert.Empty(t, subs)
+
+	_, err = useCase.ListSubscriptions(ctx, ListFiltersInput{TrialEndingDays: -1})
+	assert.Error(t, err)
+
+	total, err := useCase.GetSubscriptionsSummary(ctx, SummaryFiltersInput{PeriodStart: "02-2025", PeriodEnd: "06-2025"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50+2*100+10), total)
+}
+
+func TestSubscriptionUseCase_Pauses(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	ctx := context.Background()
+
+	useCase := NewSubscriptionUseCase(memory.NewSubscriptionRepository(), nil)
+	useCase.now = func() time.Time { return time.Date(2025, time.March, 20, 15, 0, 0, 0, time.UTC) }
+
+	sub, err := useCase.CreateSubscription(ctx, CreateSubscriptionInput{
+		ServiceName: "Netflix", Price: intPtr(100), UserID: userID, StartDate: "01-2025", EndDate: "12-2025",
+	})
+	assert.NoError(t, err)
+
+	invalid := []struct {
+		name  string
+		input PauseInput
+		err   string
+	}{
+		{"bad month", PauseInput{From: "2025-13"}, "invalid from format"},
+		{"until before from", PauseInput{From: "05-2025", Until: "04-2025"}, "until must be on or after from"},
+		{"before start", PauseInput{From: "12-2024"}, "pause start must be within the subscription period"},
+		{"after end", PauseInput{From: "01-2026"}, "pause start must be within the subscription period"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := useCase.PauseSubscription(ctx, sub.ID, tt.input)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// Пауза с текущего месяца до возобновления
+	paused, err := useCase.PauseSubscription(ctx, sub.ID, PauseInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Pause{{Start: mustParseDate("2025-03-01")}}, paused.Pauses)
+
+	_, err = useCase.PauseSubscription(ctx, sub.ID, PauseInput{From: "06-2025", Until: "07-2025"})
+	assert.ErrorContains(t, err, "already paused")
+
+	// Возобновление с мая закрывает паузу апрелем
+	resumed, err := useCase.ResumeSubscription(ctx, sub.ID, ResumeInput{From: "05-2025"})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Pause{{Start: mustParseDate("2025-03-01"), End: sql.NullTime{Time: mustParseDate("2025-04-01"), Valid: true}}}, resumed.Pauses)
+
+	_, err = useCase.ResumeSubscription(ctx, sub.ID, ResumeInput{From: "06-2025"})
+	assert.ErrorContains(t, err, "not paused")
+
+	// Запланированная пауза отменяется целиком при возобновлении до ее начала
+	_, err = useCase.PauseSubscription(ctx, sub.ID, PauseInput{From: "09-2025"})
+	assert.NoError(t, err)
+	resumed, err = useCase.ResumeSubscription(ctx, sub.ID, ResumeInput{From: "08-2025"})
+	assert.NoError(t, err)
+	assert.Len(t, resumed.Pauses, 1)
+
+	total, err := useCase.GetSubscriptionsSummary(ctx, SummaryFiltersInput{PeriodStart: "01-2025", PeriodEnd: "12-2025"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10*100), total)
+
+	// Изменять паузы чужой подписки нельзя
+	otherCtx := auth.WithPrincipal(ctx, &auth.Principal{Subject: "other", UserID: "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"})
+	_, err = useCase.PauseSubscription(otherCtx, sub.ID, PauseInput{From: "10-2025"})
+	assert.ErrorContains(t, err, "forbidden")
+}
+
+func TestSubscriptionUseCase_ScheduledChanges(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	ctx := context.Background()
+
+	repo := memory.NewSubscriptionRepository()
+	useCase := NewSubscriptionUseCase(repo, nil)
+	useCase.now = func() time.Time { return time.Date(2025, time.March, 20, 15, 0, 0, 0, time.UTC) }
+
+	sub, err := useCase.CreateSubscription(ctx, CreateSubscriptionInput{
+		ServiceName: "Netflix", Plan: "Basic", Price: intPtr(100), UserID: userID, StartDate: "01-2025", EndDate: "12-2025",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic", sub.Plan)
+
+	price, plan := 150, "Premium"
+	negative := -1
+	invalid := []struct {
+		name  string
+		input ScheduleChangeInput
+		err   string
+	}{
+		{"missing month", ScheduleChangeInput{Price: &price}, "effective_from is required"},
+		{"bad month", ScheduleChangeInput{EffectiveFrom: "2025-13", Price: &price}, "invalid effective_from format"},
+		{"current month", ScheduleChangeInput{EffectiveFrom: "03-2025", Price: &price}, "effective_from must be after the current month"},
+		{"nothing to change", ScheduleChangeInput{EffectiveFrom: "05-2025"}, "price, plan or cancel is required"},
+		{"negative price", ScheduleChangeInput{EffectiveFrom: "05-2025", Price: &negative}, "price must be non-negative"},
+		{"cancel with price", ScheduleChangeInput{EffectiveFrom: "05-2025", Price: &price, Cancel: true}, "cancel must not be combined"},
+		{"after end", ScheduleChangeInput{EffectiveFrom: "01-2026", Price: &price}, "effective_from must be within the subscription period"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := useCase.ScheduleChange(ctx, sub.ID, tt.input)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// Новая цена и тариф с мая, отмена с октября: последний месяц — сентябрь
+	_, err = useCase.ScheduleChange(ctx, sub.ID, ScheduleChangeInput{EffectiveFrom: "05-2025", Price: &price, Plan: &plan})
+	assert.NoError(t, err)
+	scheduled, err := useCase.ScheduleChange(ctx, sub.ID, ScheduleChangeInput{EffectiveFrom: "10-2025", Cancel: true})
+	assert.NoError(t, err)
+	assert.Len(t, scheduled.Changes, 2)
+	assert.Equal(t, mustParseDate("2025-09-01"), scheduled.EffectiveEnd().Time)
+
+	_, err = useCase.ScheduleChange(ctx, sub.ID, ScheduleChangeInput{EffectiveFrom: "06-2025", Cancel: true})
+	assert.ErrorContains(t, err, "a change is already scheduled for 10-2025")
+	_, err = useCase.ScheduleChange(ctx, sub.ID, ScheduleChangeInput{EffectiveFrom: "11-2025", Price: &price})
+	assert.ErrorContains(t, err, "a change is already scheduled for 10-2025")
+
+	total, err := useCase.GetSubscriptionsSummary(ctx, SummaryFiltersInput{PeriodStart: "01-2025", PeriodEnd: "12-2025"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4*100+5*150), total)
+
+	// Применение изменений не меняет сумму
+	applied, err := repo.ApplyChanges(ctx, mustParseDate("2025-05-01"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), applied)
+	got, err := useCase.GetSubscription(ctx, sub.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Premium", got.Plan)
+	assert.Equal(t, int64(150), got.Price)
+	assert.Len(t, got.Changes, 1)
+
+	total, err = useCase.GetSubscriptionsSummary(ctx, SummaryFiltersInput{PeriodStart: "01-2025", PeriodEnd: "12-2025"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4*100+5*150), total)
+
+	_, err = useCase.DeleteScheduledChange(ctx, sub.ID, "11-2025")
+	assert.ErrorContains(t, err, "scheduled change not found")
+	deleted, err := useCase.DeleteScheduledChange(ctx, sub.ID, "10-2025")
+	assert.NoError(t, err)
+	assert.Empty(t, deleted.Changes)
+	assert.Equal(t, mustParseDate("2025-12-01"), deleted.EffectiveEnd().Time)
+
+	// Планировать изменения чужой подписки нельзя
+	otherCtx := auth.WithPrincipal(ctx, &auth.Principal{Subject: "other", UserID: "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"})
+	_, err = useCase.ScheduleChange(otherCtx, sub.ID, ScheduleChangeInput{EffectiveFrom: "11-2025", Cancel: true})
+	assert.ErrorContains(t, err, "forbidden")
+}
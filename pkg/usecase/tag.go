@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+)
+
+// TagUseCase содержит логику управления тегами организации
+// Подписки получают теги через SubscriptionUseCase; здесь теги переименовываются и удаляются целиком
+type TagUseCase struct {
+	repo     domain.TagRepository
+	policy   *auth.Policy
+	observer Observer
+}
+
+// NewTagUseCase создает новый экземпляр use case для тегов
+func NewTagUseCase(repo domain.TagRepository, policy *auth.Policy, opts ...Option) *TagUseCase {
+	if policy == nil {
+		policy = auth.DefaultPolicy()
+	}
+	o := newOptions(opts)
+	return &TagUseCase{repo: repo, policy: policy, observer: o.observer}
+}
+
+// tagsUseCase имя use case для наблюдателей
+const tagsUseCase = "tags"
+
+// ListTags возвращает теги организации с числом подписок
+func (uc *TagUseCase) ListTags(ctx context.Context) (_ []*domain.Tag, err error) {
+	ctx, end := uc.observer.Start(ctx, tagsUseCase, "ListTags")
+	defer func() { end(err) }()
+
+	if err := uc.policy.Authorize(ctx, auth.PermTagsRead); err != nil {
+		return nil, err
+	}
+
+	tags, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// CreateTag добавляет тег организации заранее, до его назначения подпискам
+func (uc *TagUseCase) CreateTag(ctx context.Context, name string) (_ *domain.Tag, err error) {
+	ctx, end := uc.observer.Start(ctx, tagsUseCase, "CreateTag")
+	defer func() { end(err) }()
+
+	name, err = normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermTagsManage); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.repo.Create(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return created, nil
+}
+
+// RenameTag переименовывает тег у всех подписок организации
+func (uc *TagUseCase) RenameTag(ctx context.Context, id, name string) (_ *domain.Tag, err error) {
+	ctx, end := uc.observer.Start(ctx, tagsUseCase, "RenameTag")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	name, err = normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermTagsManage); err != nil {
+		return nil, err
+	}
+
+	renamed, err := uc.repo.Rename(ctx, id, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	return renamed, nil
+}
+
+// DeleteTag удаляет тег и снимает его со всех подписок
+func (uc *TagUseCase) DeleteTag(ctx context.Context, id string) (err error) {
+	ctx, end := uc.observer.Start(ctx, tagsUseCase, "DeleteTag")
+	defer func() { end(err) }()
+
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+	if err := uc.policy.Authorize(ctx, auth.PermTagsManage); err != nil {
+		return err
+	}
+
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeTag приводит тег к хранимому виду и проверяет его длину
+func normalizeTag(name string) (string, error) {
+	tag := domain.NormalizeTag(name)
+	if tag == "" {
+		return "", fmt.Errorf("tag name is required")
+	}
+	if utf8.RuneCountInString(tag) > domain.MaxTagLength {
+		return "", fmt.Errorf("invalid tag %q: must be at most %d characters", tag, domain.MaxTagLength)
+	}
+	return tag, nil
+}
+
+// normalizeTags нормализует теги подписки и убирает повторы
+func normalizeTags(names []string) ([]string, error) {
+	var tags []string
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
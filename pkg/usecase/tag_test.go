@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/asgard-born/rest_service_subscriptions/pkg/auth"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/domain"
+	"github.com/asgard-born/rest_service_subscriptions/pkg/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagUseCase_Tags(t *testing.T) {
+	const (
+		userID  = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+		otherID = "7ad1b1c4-8f1e-4c38-9b7e-2f2d5d0f4e11"
+	)
+	viewerCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "v", UserID: userID, Roles: []string{"viewer"}})
+	editorCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "e", UserID: userID, Roles: []string{"editor"}})
+	otherCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "o", UserID: otherID, Roles: []string{"editor"}})
+	financeCtx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "f", UserID: otherID, Roles: []string{"finance"}})
+
+	subs := memory.NewSubscriptionRepository()
+	tags := NewTagUseCase(memory.NewTagRepository(subs), nil)
+	subscriptions := NewSubscriptionUseCase(subs, nil)
+
+	// Теги нормализуются и не повторяются
+	sub, err := subscriptions.CreateSubscription(editorCtx, CreateSubscriptionInput{
+		ServiceName: "GitHub", Price: intPtr(400), UserID: userID, StartDate: "01-2025",
+		Tags: []string{" Project  X ", "project x", "Cost Center 42"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cost center 42", "project x"}, sub.Tags)
+
+	_, err = subscriptions.CreateSubscription(editorCtx, CreateSubscriptionInput{
+		ServiceName: "GitHub", Price: intPtr(400), UserID: userID, StartDate: "01-2025", Tags: []string{" "},
+	})
+	assert.ErrorContains(t, err, "tag name is required")
+	_, err = subscriptions.SetSubscriptionTags(editorCtx, sub.ID, []string{strings.Repeat("x", domain.MaxTagLength+1)})
+	assert.ErrorContains(t, err, "invalid tag")
+
+	// Теги чужой подписки менять нельзя
+	_, err = subscriptions.SetSubscriptionTags(otherCtx, sub.ID, []string{"mine"})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	updated, err := subscriptions.SetSubscriptionTags(editorCtx, sub.ID, []string{"Project X", "Infra"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"infra", "project x"}, updated.Tags)
+
+	// Фильтр по тегу не зависит от регистра
+	found, err := subscriptions.ListSubscriptions(editorCtx, ListFiltersInput{Tag: "INFRA"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	total, err := subscriptions.GetSubscriptionsSummary(editorCtx, SummaryFiltersInput{Tag: "Project X", PeriodStart: "01-2025", PeriodEnd: "03-2025"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1200), total)
+
+	groups, err := subscriptions.GetSubscriptionsSummaryGroups(editorCtx, SummaryFiltersInput{PeriodStart: "01-2025", PeriodEnd: "01-2025"}, domain.SummaryByTag)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SummaryGroup{{Key: "infra", Total: 400}, {Key: "project x", Total: 400}}, groups)
+	_, err = subscriptions.GetSubscriptionsSummaryGroups(editorCtx, SummaryFiltersInput{PeriodStart: "01-2025", PeriodEnd: "01-2025"}, "user")
+	assert.ErrorContains(t, err, `invalid group_by "user"`)
+	// Вызывающий без summary:read_all видит только свои группы
+	groups, err = subscriptions.GetSubscriptionsSummaryGroups(otherCtx, SummaryFiltersInput{PeriodStart: "01-2025", PeriodEnd: "01-2025"}, domain.SummaryByTag)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	// Список тегов доступен на чтение, управление - только с tags:manage
+	list, err := tags.ListTags(viewerCtx)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, "cost center 42", list[0].Name)
+	assert.Zero(t, list[0].Subscriptions)
+
+	_, err = tags.CreateTag(editorCtx, "marketing")
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	created, err := tags.CreateTag(financeCtx, " Marketing ")
+	require.NoError(t, err)
+	assert.Equal(t, "marketing", created.Name)
+	_, err = tags.CreateTag(financeCtx, "MARKETING")
+	assert.ErrorContains(t, err, "already used")
+
+	_, err = tags.RenameTag(editorCtx, list[2].ID, "project y")
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	renamed, err := tags.RenameTag(financeCtx, list[2].ID, "Project Y")
+	require.NoError(t, err)
+	assert.Equal(t, "project y", renamed.Name)
+	got, err := subscriptions.GetSubscription(editorCtx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"infra", "project y"}, got.Tags)
+
+	assert.ErrorIs(t, tags.DeleteTag(editorCtx, list[1].ID), auth.ErrForbidden)
+	require.NoError(t, tags.DeleteTag(financeCtx, list[1].ID))
+	got, err = subscriptions.GetSubscription(editorCtx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"project y"}, got.Tags)
+	assert.ErrorContains(t, tags.DeleteTag(financeCtx, list[1].ID), "not found")
+}
@@ -0,0 +1,10 @@
+package utils
+
+import "regexp"
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID проверяет, что строка является UUID в каноническом текстовом виде
+func IsUUID(input string) bool {
+	return uuidPattern.MatchString(input)
+}
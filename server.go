@@ -2,34 +2,65 @@ package rest_service
 
 import (
 	"context"
+	"crypto/tls"
 	"log"
 	"net/http"
 	"time"
 )
 
+// Значения таймаутов по умолчанию, если они не заданы в Server
+const (
+	defaultReadTimeout  = 10 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+)
+
 type Server struct {
 	httpServer *http.Server
+
+	// ReadTimeout, WriteTimeout и IdleTimeout передаются в http.Server
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// TLSConfig включает HTTPS; сертификат должен быть задан в Certificates или GetCertificate
+	TLSConfig *tls.Config
 }
 
 func (s *Server) Run(port string, handler http.Handler) error {
+	readTimeout, writeTimeout := s.ReadTimeout, s.WriteTimeout
+	if readTimeout == 0 {
+		readTimeout = defaultReadTimeout
+	}
+	if writeTimeout == 0 {
+		writeTimeout = defaultWriteTimeout
+	}
+
 	s.httpServer = &http.Server{
 		Addr:           ":" + port,
 		Handler:        handler,
 		MaxHeaderBytes: 1 << 20, // 1 MB
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
+		IdleTimeout:    s.IdleTimeout,
+		TLSConfig:      s.TLSConfig,
 	}
 
-	log.Printf("starting server on port %s", port)
-	err := s.httpServer.ListenAndServe()
+	var err error
+	if s.TLSConfig != nil {
+		log.Printf("starting HTTPS server on port %s", port)
+		err = s.httpServer.ListenAndServeTLS("", "")
+	} else {
+		log.Printf("starting server on port %s", port)
+		err = s.httpServer.ListenAndServe()
+	}
 
 	if err != nil && err != http.ErrServerClosed {
 		log.Printf("server error: %v", err)
 		return err
 	}
 
+	// ErrServerClosed означает штатную остановку через Shutdown, а не ошибку
 	log.Println("server stopped gracefully")
-	return err
+	return nil
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {